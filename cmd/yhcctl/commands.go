@@ -3,10 +3,14 @@ package main
 import (
 	"yhc/commons/flags"
 	checkcontroller "yhc/internal/api/controller/yhcctlcontroller/check"
+	packagecontroller "yhc/internal/api/controller/yhcctlcontroller/package"
+	resultscontroller "yhc/internal/api/controller/yhcctlcontroller/results"
 )
 
 type App struct {
 	flags.Globals
 	Check        checkcontroller.CheckCmd        `cmd:"check" name:"check" help:"The check command is used to yashan health check."`
 	AfterInstall checkcontroller.AfterInstallCmd `cmd:"after-install" name:"after-install" help:"The after-install command is used to verify the installation of Yashandb after it has been installed."`
+	Package      packagecontroller.PackageCmd    `cmd:"package" name:"package" help:"The package command is used to manage the result packages of health checks."`
+	Results      resultscontroller.ResultsCmd    `cmd:"results" name:"results" help:"The results command is used to list and prune the result packages in the output dir."`
 }
@@ -2,8 +2,10 @@ package main
 
 import (
 	"yhc/commons/flags"
+	"yhc/internal/api/controller/yhcdcontroller/yhcd"
 )
 
 type App struct {
 	flags.Globals
+	yhcd.YHCDCmd
 }
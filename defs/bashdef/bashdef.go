@@ -23,6 +23,10 @@ const (
 	CMD_IPTABLES      = "iptables"
 	CMD_GREP          = "grep"
 	CMD_YASBOOT       = "yasboot"
+	CMD_CHRONYC       = "chronyc"
+	CMD_NTPQ          = "ntpq"
+	CMD_TIMEDATECTL   = "timedatectl"
+	CMD_JOURNALCTL    = "journalctl"
 )
 
 const (
@@ -20,6 +20,12 @@ func InitYHCConf(yhcConf string) error {
 	if err := initNodesConfig(_yhcConf.NodesConfigPath); err != nil {
 		return err
 	}
+	if err := initRedactConf(_yhcConf.RedactPath); err != nil {
+		return err
+	}
+	if err := initSysctlBaseline(_yhcConf.SysctlBaselinePath); err != nil {
+		return err
+	}
 	return nil
 }
 
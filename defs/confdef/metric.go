@@ -49,6 +49,7 @@ type YHCMetric struct {
 	ItemNames      map[string]string         `toml:"item_names,omitempty"`
 	NumberColumns  []string                  `toml:"number_columns,omitempty"`
 	Labels         []string                  `toml:"labels,omitempty"`
+	Thresholds     map[string]float64        `toml:"thresholds,omitempty"` // the thresholds of the workload fields to count the seconds above
 	AlertRules     map[string][]AlertDetails `toml:"alert_rules,omitempty"`
 	SQL            string                    `toml:"sql,omitempty"`     // SQL类型的指标的sql语句
 	Command        string                    `toml:"command,omitempty"` // bash类型指标的bash命令
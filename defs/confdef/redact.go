@@ -0,0 +1,65 @@
+package confdef
+
+import (
+	"path"
+
+	"yhc/defs/errdef"
+	"yhc/defs/runtimedef"
+
+	"git.yasdb.com/go/yasutil/fs"
+	"github.com/BurntSushi/toml"
+)
+
+const (
+	REDACT_MODE_KEEP = "keep"
+	REDACT_MODE_MASK = "mask"
+	REDACT_MODE_HASH = "hash"
+)
+
+var _redactConf *RedactConf
+
+// RedactRule controls how the values of the keys are redacted, keys are case-insensitive.
+type RedactRule struct {
+	Mode string   `toml:"mode"`
+	Keys []string `toml:"keys"`
+}
+
+type SqlRedactRule struct {
+	StripLiterals bool     `toml:"strip_literals"`
+	Keys          []string `toml:"keys"`
+}
+
+type RedactConf struct {
+	// Enabled means the result package of every check is redacted
+	Enabled     bool          `toml:"enabled"`
+	Salt        string        `toml:"salt"`
+	Hostname    RedactRule    `toml:"hostname"`
+	IP          RedactRule    `toml:"ip"`
+	Username    RedactRule    `toml:"username"`
+	Path        RedactRule    `toml:"path"`
+	Sql         SqlRedactRule `toml:"sql"`
+	DropMetrics []string      `toml:"drop_metrics"`
+}
+
+func GetRedactConf() *RedactConf {
+	return _redactConf
+}
+
+func initRedactConf(p string) error {
+	if len(p) == 0 {
+		_redactConf = &RedactConf{}
+		return nil
+	}
+	conf := &RedactConf{}
+	if !path.IsAbs(p) {
+		p = path.Join(runtimedef.GetYHCHome(), p)
+	}
+	if !fs.IsFileExist(p) {
+		return &errdef.ErrFileNotFound{FName: p}
+	}
+	if _, err := toml.DecodeFile(p, conf); err != nil {
+		return &errdef.ErrFileParseFailed{FName: p, Err: err}
+	}
+	_redactConf = conf
+	return nil
+}
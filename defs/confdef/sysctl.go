@@ -0,0 +1,74 @@
+package confdef
+
+import (
+	"path"
+
+	"yhc/defs/errdef"
+	"yhc/defs/runtimedef"
+	"yhc/i18n"
+
+	"git.yasdb.com/go/yasutil/fs"
+	"github.com/BurntSushi/toml"
+)
+
+const (
+	SYSCTL_COMPARE_EQ    = "eq"    // every field equals to the recommended value
+	SYSCTL_COMPARE_GE    = "ge"    // every field is greater than or equal to the recommended value
+	SYSCTL_COMPARE_LE    = "le"    // every field is less than or equal to the recommended value
+	SYSCTL_COMPARE_RANGE = "range" // the range of two fields covers the recommended range
+
+	SYSCTL_UNIT_BYTES  = "bytes"
+	SYSCTL_UNIT_KBYTES = "kbytes"
+	SYSCTL_UNIT_PAGES  = "pages"
+)
+
+var _sysctlBaseline *SysctlBaseline
+
+// SysctlBaseline is the recommended kernel parameters of YashanDB hosts.
+type SysctlBaseline struct {
+	Parameters []*SysctlParameter `toml:"parameters"`
+}
+
+// SysctlParameter is a kernel parameter in /proc/sys, the recommended value is either a fixed value,
+// or memory_ratio of the host memory converted to unit and limited by min and max.
+type SysctlParameter struct {
+	Name          string  `toml:"name"`
+	Compare       string  `toml:"compare"`
+	Value         string  `toml:"value"`
+	MemoryRatio   float64 `toml:"memory_ratio"`
+	Unit          string  `toml:"unit"`
+	Min           int64   `toml:"min"`
+	Max           int64   `toml:"max"`
+	Description   string  `toml:"description"`
+	DescriptionEn string  `toml:"description_en"`
+}
+
+func GetSysctlBaseline() *SysctlBaseline {
+	return _sysctlBaseline
+}
+
+func (p *SysctlParameter) GetDescription() string {
+	if i18n.GetLanguage() == i18n.EnUS && p.DescriptionEn != "" {
+		return p.DescriptionEn
+	}
+	return p.Description
+}
+
+func initSysctlBaseline(p string) error {
+	if len(p) == 0 {
+		_sysctlBaseline = &SysctlBaseline{}
+		return nil
+	}
+	conf := &SysctlBaseline{}
+	if !path.IsAbs(p) {
+		p = path.Join(runtimedef.GetYHCHome(), p)
+	}
+	if !fs.IsFileExist(p) {
+		return &errdef.ErrFileNotFound{FName: p}
+	}
+	if _, err := toml.DecodeFile(p, conf); err != nil {
+		return &errdef.ErrFileParseFailed{FName: p, Err: err}
+	}
+	_sysctlBaseline = conf
+	return nil
+}
@@ -1,14 +1,23 @@
 package confdef
 
 import (
+	"path"
 	"regexp"
 	"strings"
 	"time"
 
+	"yhc/defs/runtimedef"
+	"yhc/utils/mathutil"
 	"yhc/utils/stringutil"
 	"yhc/utils/timeutil"
 )
 
+const (
+	_default_sampler_dir       = "./data/sampler"
+	_default_sampler_interval  = 60
+	_default_sampler_retention = 7 * 24 * time.Hour
+)
+
 var _yhcConf YHC
 
 type YHC struct {
@@ -31,6 +40,28 @@ type YHC struct {
 	NetworkIODiscard       string   `toml:"network_io_discard"`
 	SkipGenWordReport      bool     `toml:"skip_gen_word_report"`
 	SkipGenHtmlReport      bool     `toml:"skip_gen_html_report"`
+	ExportFormats          []string `toml:"export_formats"`
+	HtmlChunkRows          int      `toml:"html_chunk_rows"`
+	SignKeyPath            string   `toml:"sign_key_path"`
+	RedactPath             string   `toml:"redact_path"`
+	SysctlBaselinePath     string   `toml:"sysctl_baseline_path"`
+	// retention of the result packages in output, zero values mean no limit
+	RetentionMaxCount           int    `toml:"retention_max_count"`
+	RetentionMaxAge             string `toml:"retention_max_age"`
+	RetentionMaxTotalSize       string `toml:"retention_max_total_size"`
+	RetentionKeepLastPerProfile int    `toml:"retention_keep_last_per_profile"`
+	// the built-in host sampler of yhcd, used as the history workload when there is no sar data
+	SamplerDir       string `toml:"sampler_dir"`
+	SamplerInterval  int    `toml:"sampler_interval"`
+	SamplerRetention string `toml:"sampler_retention"`
+}
+
+// RetentionPolicy limits the result packages kept in the output directory, zero values mean no limit.
+type RetentionPolicy struct {
+	MaxCount           int
+	MaxAge             time.Duration
+	MaxTotalSize       int64
+	KeepLastPerProfile int
 }
 
 func GetYHCConf() YHC {
@@ -95,6 +126,72 @@ func (c YHC) GetScrapeTimes() (times int) {
 	return c.ScrapeTimes
 }
 
+// GetSignKeyPath returns the path of the ed25519 private key to sign the result package, empty means no signing.
+func (c YHC) GetSignKeyPath() string {
+	if len(c.SignKeyPath) == 0 || path.IsAbs(c.SignKeyPath) {
+		return c.SignKeyPath
+	}
+	return path.Join(runtimedef.GetYHCHome(), c.SignKeyPath)
+}
+
+// GetOutput returns the absolute path of the output directory.
+func (c YHC) GetOutput() string {
+	if path.IsAbs(c.Output) {
+		return path.Clean(c.Output)
+	}
+	return path.Join(runtimedef.GetYHCHome(), c.Output)
+}
+
+// GetSamplerDir returns the absolute path of the directory storing the samples of yhcd.
+func (c YHC) GetSamplerDir() string {
+	dir := c.SamplerDir
+	if len(dir) == 0 {
+		dir = _default_sampler_dir
+	}
+	if path.IsAbs(dir) {
+		return path.Clean(dir)
+	}
+	return path.Join(runtimedef.GetYHCHome(), dir)
+}
+
+// GetSamplerInterval returns the seconds between the samples of yhcd.
+func (c YHC) GetSamplerInterval() int {
+	if c.SamplerInterval <= 0 {
+		return _default_sampler_interval
+	}
+	return c.SamplerInterval
+}
+
+// GetSamplerRetention returns how long the samples of yhcd are kept.
+func (c YHC) GetSamplerRetention() time.Duration {
+	r, err := timeutil.GetDuration(c.SamplerRetention)
+	if err != nil || r <= 0 {
+		return _default_sampler_retention
+	}
+	return r
+}
+
+func (c YHC) GetRetentionPolicy() (policy RetentionPolicy, err error) {
+	policy.MaxCount = c.RetentionMaxCount
+	policy.KeepLastPerProfile = c.RetentionKeepLastPerProfile
+	if !stringutil.IsEmpty(c.RetentionMaxAge) {
+		if policy.MaxAge, err = timeutil.GetDuration(c.RetentionMaxAge); err != nil {
+			return
+		}
+	}
+	if !stringutil.IsEmpty(c.RetentionMaxTotalSize) {
+		if policy.MaxTotalSize, err = mathutil.ParseSize(c.RetentionMaxTotalSize); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// IsEmpty returns true if no limit is configured.
+func (p RetentionPolicy) IsEmpty() bool {
+	return p.MaxCount <= 0 && p.MaxAge <= 0 && p.MaxTotalSize <= 0
+}
+
 func (c YHC) GetNetworkIODiscard() []string {
 	return strings.Split(c.NetworkIODiscard, stringutil.STR_COMMA)
 }
@@ -1,6 +1,9 @@
 package checkcontroller
 
-import "yhc/defs/confdef"
+import (
+	"yhc/defs/confdef"
+	"yhc/internal/modules/yhc/check/define"
+)
 
 type AfterInstallCmd struct {
 	CheckGlobal
@@ -10,7 +13,7 @@ func (c *AfterInstallCmd) Run() error {
 	if err := c.InitConfig(); err != nil {
 		return err
 	}
-	return c.Check()
+	return c.Check(define.PROFILE_AFTER_INSTALL)
 }
 
 func (c *AfterInstallCmd) InitConfig() error {
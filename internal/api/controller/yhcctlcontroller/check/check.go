@@ -2,6 +2,7 @@ package checkcontroller
 
 import (
 	"yhc/defs/confdef"
+	"yhc/internal/modules/yhc/check/define"
 )
 
 type CheckCmd struct {
@@ -13,7 +14,7 @@ func (c *CheckCmd) Run() error {
 	if err := c.initConfig(); err != nil {
 		return err
 	}
-	return c.Check()
+	return c.Check(define.PROFILE_CHECK)
 }
 
 func (c *CheckCmd) initConfig() error {
@@ -10,6 +10,7 @@ import (
 	"sync"
 	"time"
 
+	"yhc/commons/constants"
 	"yhc/commons/std"
 	"yhc/commons/yasdb"
 	"yhc/defs/confdef"
@@ -37,7 +38,14 @@ const (
 	f_end    = "end"
 	f_output = "output"
 
-	range_help = "you must ensure that the number before (M|d|h|m) is greater than 0"
+	f_begin_snap         = "begin-snap"
+	f_end_snap           = "end-snap"
+	f_compare_begin_snap = "compare-begin-snap"
+	f_compare_end_snap   = "compare-end-snap"
+	f_compare_offset     = "compare-offset"
+
+	range_help     = "you must ensure that the number before (M|d|h|m) is greater than 0"
+	snap_pair_help = "you must give both the begin and the end snapshot, and the begin snapshot must be less than the end snapshot"
 )
 
 var (
@@ -66,9 +74,14 @@ type CheckGlobal struct {
 	YasdbData          string `name:"yasdb-data"          help:"Data path of YashanDB(env: YASDB_DATA)."`
 	YasdbUser          string `name:"user"          short:"u"          help:"YashanDB user for checking."`
 	YasdbPassword      string `name:"password"      short:"p"          help:"YashanDB user password for checking."`
+	BeginSnap          int64  `name:"begin-snap"          help:"The begin snapshot id of the workload report, picked by the check time if not given."`
+	EndSnap            int64  `name:"end-snap"            help:"The end snapshot id of the workload report, picked by the check time if not given."`
+	CompareBeginSnap   int64  `name:"compare-begin-snap"  help:"The begin snapshot id of the period to compare the workload report with."`
+	CompareEndSnap     int64  `name:"compare-end-snap"    help:"The end snapshot id of the period to compare the workload report with."`
+	CompareOffset      string `name:"compare-offset"      help:"Compare the workload report with the period before it by the offset, such as '7d', '1d'. If <compare-begin-snap> and <compare-end-snap> are given, <compare-offset> will be discard."`
 }
 
-func (c *CheckGlobal) Check() error {
+func (c *CheckGlobal) Check(profile string) error {
 	c.fillDefault()
 	if err := c.validate(); err != nil {
 		return err
@@ -107,7 +120,7 @@ func (c *CheckGlobal) Check() error {
 	if globalExitCode != EXIT_CONTINUE {
 		return errors.New(exitCodeMap[globalExitCode])
 	}
-	checkerBase := c.genCheckBase(globalYasdb, c.MultipleNodes)
+	checkerBase := c.genCheckBase(globalYasdb, c.MultipleNodes, profile)
 	// write user choose yashan health check to console.log
 	c.writeUserChoose()
 	// globalFilterModule will be fill after user choose metrics
@@ -308,9 +321,52 @@ func (c *CheckGlobal) validate() error {
 	if err := c.validateOutput(); err != nil {
 		return err
 	}
+	if err := c.validateSnapshots(); err != nil {
+		return err
+	}
 	return nil
 }
 
+func (c *CheckGlobal) validateSnapshots() error {
+	if err := c.validateSnapPair(f_begin_snap+"/"+f_end_snap, c.BeginSnap, c.EndSnap); err != nil {
+		return err
+	}
+	if err := c.validateSnapPair(f_compare_begin_snap+"/"+f_compare_end_snap, c.CompareBeginSnap, c.CompareEndSnap); err != nil {
+		return err
+	}
+	if stringutil.IsEmpty(c.CompareOffset) {
+		return nil
+	}
+	if !regexpdef.RangeRegexp.MatchString(c.CompareOffset) {
+		return errdef.NewErrYHCFlag(f_compare_offset, c.CompareOffset, _examplesRange, range_help)
+	}
+	return nil
+}
+
+func (c *CheckGlobal) validateSnapPair(flag string, begin, end int64) error {
+	if begin == 0 && end == 0 {
+		return nil
+	}
+	if begin <= 0 || end <= begin {
+		return errdef.NewErrYHCFlag(flag, strconv.FormatInt(begin, constants.BASE_DECIMAL)+"/"+strconv.FormatInt(end, constants.BASE_DECIMAL), nil, snap_pair_help)
+	}
+	return nil
+}
+
+func (c *CheckGlobal) getSnapshotOptions() define.SnapshotOptions {
+	opts := define.SnapshotOptions{
+		BeginSnap:        c.BeginSnap,
+		EndSnap:          c.EndSnap,
+		CompareBeginSnap: c.CompareBeginSnap,
+		CompareEndSnap:   c.CompareEndSnap,
+	}
+	if !stringutil.IsEmpty(c.CompareOffset) {
+		// the offset is validated before
+		opts.CompareOffset, _ = timeutil.GetDuration(c.CompareOffset)
+	}
+	return opts
+}
+
 func (c *CheckGlobal) validateRange() error {
 	conf := confdef.GetYHCConf()
 	log.Controller.Debugf("conf: %s\n", jsonutil.ToJSONString(conf))
@@ -391,7 +447,7 @@ func (c *CheckGlobal) validateStartAndEnd() error {
 	return nil
 }
 
-func (c *CheckGlobal) genCheckBase(db *YashanDB, multipleNodes bool) *define.CheckerBase {
+func (c *CheckGlobal) genCheckBase(db *YashanDB, multipleNodes bool, profile string) *define.CheckerBase {
 	start, end, _ := c.getStartAndEnd()
 	var nodes []*yasdb.NodeInfo
 	for _, node := range db.Nodes {
@@ -409,5 +465,7 @@ func (c *CheckGlobal) genCheckBase(db *YashanDB, multipleNodes bool) *define.Che
 		Output:        c.Output,
 		NodeInfos:     nodes,
 		MultipleNodes: multipleNodes,
+		Profile:       profile,
+		Snapshots:     c.getSnapshotOptions(),
 	}
 }
@@ -0,0 +1,6 @@
+package packagecontroller
+
+type PackageCmd struct {
+	Verify verifyCmd `cmd:"verify" name:"verify" help:"Verify the integrity and signature of a result package."`
+	Redact redactCmd `cmd:"redact" name:"redact" help:"Redact sensitive data of a result package by the redaction rules."`
+}
@@ -0,0 +1,31 @@
+package packagecontroller
+
+import (
+	"yhc/defs/confdef"
+	packagehandler "yhc/internal/api/handler/yhcctlhandler/package"
+)
+
+type redactCmd struct {
+	Path   string `arg:"" name:"path" help:"The result package (yhc-*.tar.gz) or the unpacked directory to redact."`
+	Output string `name:"output" short:"o" help:"The directory of the redacted package, default is the directory of the origin package."`
+}
+
+// [Interface Func]
+func (c redactCmd) Run() error {
+	if err := c.initConfig(); err != nil {
+		return err
+	}
+	return packagehandler.NewRedactHandler(c.Path, c.Output).Redact()
+}
+
+// initConfig loads the metrics and modules, which are used to regenerate the reports.
+func (c redactCmd) initConfig() error {
+	yhcConf := confdef.GetYHCConf()
+	if err := confdef.InitMetricConf(yhcConf.MetricPaths); err != nil {
+		return err
+	}
+	if err := confdef.InitModuleConf(yhcConf.DefaultModulePath); err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,15 @@
+package packagecontroller
+
+import (
+	packagehandler "yhc/internal/api/handler/yhcctlhandler/package"
+)
+
+type verifyCmd struct {
+	Path      string `arg:"" name:"path" help:"The result package (yhc-*.tar.gz) or the unpacked directory to verify."`
+	PublicKey string `name:"public-key" short:"k" help:"The PEM encoded ed25519 public key, if given, the package must be signed by the key."`
+}
+
+// [Interface Func]
+func (c verifyCmd) Run() error {
+	return packagehandler.NewVerifyHandler(c.Path, c.PublicKey).Verify()
+}
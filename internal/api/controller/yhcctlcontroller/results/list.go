@@ -0,0 +1,14 @@
+package resultscontroller
+
+import (
+	resultshandler "yhc/internal/api/handler/yhcctlhandler/results"
+)
+
+type listCmd struct {
+	Output string `name:"output" short:"o" help:"The output dir of the checks, default is the output in yhc.toml."`
+}
+
+// [Interface Func]
+func (c listCmd) Run() error {
+	return resultshandler.NewListHandler(getOutput(c.Output)).List()
+}
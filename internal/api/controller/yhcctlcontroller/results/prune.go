@@ -0,0 +1,22 @@
+package resultscontroller
+
+import (
+	"yhc/defs/confdef"
+	resultshandler "yhc/internal/api/handler/yhcctlhandler/results"
+	"yhc/log"
+)
+
+type pruneCmd struct {
+	Output string `name:"output" short:"o" help:"The output dir of the checks, default is the output in yhc.toml."`
+	DryRun bool   `name:"dry-run" help:"Only print the result packages to be removed."`
+}
+
+// [Interface Func]
+func (c pruneCmd) Run() error {
+	policy, err := confdef.GetYHCConf().GetRetentionPolicy()
+	if err != nil {
+		log.Controller.Errorf("get retention policy err: %s", err.Error())
+		return err
+	}
+	return resultshandler.NewPruneHandler(getOutput(c.Output), policy, c.DryRun).Prune()
+}
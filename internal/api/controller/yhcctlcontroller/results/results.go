@@ -0,0 +1,25 @@
+package resultscontroller
+
+import (
+	"path"
+
+	"yhc/defs/confdef"
+	"yhc/defs/runtimedef"
+	"yhc/utils/stringutil"
+)
+
+type ResultsCmd struct {
+	List  listCmd  `cmd:"list" name:"list" help:"List the result packages in the output directory."`
+	Prune pruneCmd `cmd:"prune" name:"prune" help:"Remove the result packages which exceed the retention policy."`
+}
+
+// getOutput returns the output flag or the configured output, relative paths are relative to YHC_HOME.
+func getOutput(output string) string {
+	if stringutil.IsEmpty(output) {
+		return confdef.GetYHCConf().GetOutput()
+	}
+	if !path.IsAbs(output) {
+		output = path.Join(runtimedef.GetYHCHome(), output)
+	}
+	return path.Clean(output)
+}
@@ -1,9 +1,27 @@
 package yhcd
 
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"yhc/defs/confdef"
+	"yhc/internal/modules/yhc/sampler"
+	"yhc/log"
+)
+
 type YHCDCmd struct {
 }
 
 // [Interface Func]
 func (cmd *YHCDCmd) Run() error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	conf := confdef.GetYHCConf()
+	dir, interval, retention := conf.GetSamplerDir(), conf.GetSamplerInterval(), conf.GetSamplerRetention()
+	log.Controller.Infof("start sampling the host workload into %s every %ds, retention: %s", dir, interval, retention)
+	sampler.NewSampler(log.Module.M("sampler"), sampler.NewStore(dir, retention), interval).Run(ctx)
+	log.Controller.Infof("stop sampling the host workload")
 	return nil
 }
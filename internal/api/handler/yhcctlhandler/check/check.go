@@ -12,6 +12,8 @@ import (
 	yhccheck "yhc/internal/modules/yhc/check"
 	"yhc/internal/modules/yhc/check/define"
 	"yhc/internal/modules/yhc/check/reporter"
+	"yhc/internal/modules/yhc/redactor"
+	"yhc/internal/modules/yhc/results"
 	"yhc/log"
 	"yhc/utils/terminalutil/barutil"
 
@@ -48,6 +50,7 @@ func NewCheckHandler(modules []*constdef.ModuleMetrics, base *define.CheckerBase
 		}
 	}
 	handler.checker = yhccheck.NewYHCChecker(base, metrics)
+	handler.reporter.Metrics = metrics
 	return handler
 }
 
@@ -86,14 +89,44 @@ func (c *CheckHandler) afterCheck() error {
 	c.reporter.EndTime = time.Now()
 	fmt.Print(i18n.T("check.packing_results"))
 	c.reporter.Items, c.reporter.Report, c.reporter.FailedItem = c.getResults(c.reporter.BeginTime, c.reporter.EndTime)
+	c.reporter.EvaluateResult = c.checker.GetEvaluateResult()
+	if redactConf := confdef.GetRedactConf(); redactConf != nil && redactConf.Enabled {
+		r, err := redactor.NewRedactor(redactConf)
+		if err != nil {
+			log.Handler.Errorf("new redactor err: %s", err.Error())
+			return err
+		}
+		c.reporter.Redactor = r
+	}
 	path, err := c.reporter.GenResult()
 	if err != nil {
 		return err
 	}
 	fmt.Printf(i18n.T("check.result_saved"), bashdef.WithColor(path, bashdef.COLOR_BLUE))
+	c.pruneResults()
 	return nil
 }
 
+// pruneResults removes the expired result packages by the retention policy, failures do not fail the check.
+func (c *CheckHandler) pruneResults() {
+	policy, err := confdef.GetYHCConf().GetRetentionPolicy()
+	if err != nil {
+		log.Handler.Warnf("get retention policy err: %s", err.Error())
+		return
+	}
+	if policy.IsEmpty() {
+		return
+	}
+	res, err := results.Prune(c.getOutputDir(), policy, false)
+	if err != nil {
+		log.Handler.Warnf("prune result packages err: %s", err.Error())
+	}
+	if res != nil && len(res.Expired) != 0 {
+		log.Handler.Infof("%d result packages are pruned, %d bytes freed", len(res.Expired), res.FreedSize)
+		fmt.Printf(i18n.T("check.results_pruned"), len(res.Expired))
+	}
+}
+
 func (c *CheckHandler) moduleMetricsFunc() (moduleCheckFunc map[string]map[string]func(string) error) {
 	moduleCheckFunc = make(map[string]map[string]func(string) error)
 	for module, metrics := range c.metrics {
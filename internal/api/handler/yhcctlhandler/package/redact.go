@@ -0,0 +1,230 @@
+package packagehandler
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"yhc/commons/yasdb"
+	"yhc/defs/bashdef"
+	"yhc/defs/confdef"
+	"yhc/defs/runtimedef"
+	"yhc/defs/timedef"
+	"yhc/i18n"
+	"yhc/internal/modules/yhc/check/define"
+	"yhc/internal/modules/yhc/check/reporter"
+	"yhc/internal/modules/yhc/redactor"
+	"yhc/internal/modules/yhc/resultpkg"
+	"yhc/log"
+	"yhc/utils/stringutil"
+	"yhc/utils/tarutil"
+
+	"git.yasdb.com/go/yasutil/fs"
+)
+
+const (
+	REDACTED_SUFFIX = "-redacted"
+
+	_package_prefix   = "yhc-"
+	_tar_gz_ext       = ".tar.gz"
+	_dir_data         = "data"
+	_data_json        = "data-%s.json"
+	_report_json      = "report-%s.json"
+	_failed_item_json = "failed-%s.json"
+	_tmp_dir_pattern  = ".yhc-redact-*"
+)
+
+type RedactHandler struct {
+	path   string
+	output string
+}
+
+func NewRedactHandler(path, output string) *RedactHandler {
+	return &RedactHandler{
+		path:   path,
+		output: output,
+	}
+}
+
+// Redact redacts an existing result package and generates a new package with the suffix '-redacted',
+// the reports are regenerated from the redacted data, so that all the files are consistent.
+func (h *RedactHandler) Redact() error {
+	output, err := h.getOutputDir()
+	if err != nil {
+		return err
+	}
+	if err := fs.Mkdir(output); err != nil {
+		log.Handler.Errorf("mkdir: %s err: %s", output, err.Error())
+		return err
+	}
+	packageDir, cleanup, err := h.unpack(output)
+	if err != nil {
+		log.Handler.Errorf("unpack %s err: %s", h.path, err.Error())
+		return err
+	}
+	defer cleanup()
+	r, err := redactor.NewRedactor(confdef.GetRedactConf())
+	if err != nil {
+		log.Handler.Errorf("new redactor err: %s", err.Error())
+		return err
+	}
+	report, err := h.loadReport(packageDir, output)
+	if err != nil {
+		log.Handler.Errorf("load result package %s err: %s", h.path, err.Error())
+		return err
+	}
+	report.Redactor = r
+	report.NameSuffix = REDACTED_SUFFIX
+	fmt.Print(i18n.T("check.packing_results"))
+	p, err := report.GenResult()
+	if err != nil {
+		return err
+	}
+	fmt.Printf(i18n.T("package.redact_saved"), bashdef.WithColor(p, bashdef.COLOR_BLUE))
+	return nil
+}
+
+func (h *RedactHandler) getOutputDir() (string, error) {
+	if !stringutil.IsEmpty(h.output) {
+		return filepath.Abs(h.output)
+	}
+	p, err := filepath.Abs(h.path)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Dir(p), nil
+}
+
+// unpack returns the directory of the package, the tar file is unpacked to a temporary directory in output.
+func (h *RedactHandler) unpack(output string) (string, func(), error) {
+	info, err := os.Stat(h.path)
+	if err != nil {
+		return "", nil, err
+	}
+	if info.IsDir() {
+		return filepath.Clean(h.path), func() {}, nil
+	}
+	tmp, err := os.MkdirTemp(output, _tmp_dir_pattern)
+	if err != nil {
+		return "", nil, err
+	}
+	cleanup := func() {
+		if err := os.RemoveAll(tmp); err != nil {
+			log.Handler.Warnf("remove %s err: %s", tmp, err.Error())
+		}
+	}
+	if err := tarutil.UntarGz(h.path, tmp); err != nil {
+		cleanup()
+		return "", nil, err
+	}
+	entries, err := os.ReadDir(tmp)
+	if err != nil {
+		cleanup()
+		return "", nil, err
+	}
+	for _, entry := range entries {
+		if entry.IsDir() && strings.HasPrefix(entry.Name(), _package_prefix) {
+			return path.Join(tmp, entry.Name()), cleanup, nil
+		}
+	}
+	cleanup()
+	return "", nil, fmt.Errorf("no result package directory found in %s", h.path)
+}
+
+// loadReport loads the data of the package, the check begin time is parsed from the package name.
+func (h *RedactHandler) loadReport(packageDir, output string) (*reporter.YHCReport, error) {
+	name := strings.TrimSuffix(filepath.Base(packageDir), _tar_gz_ext)
+	if strings.HasSuffix(name, REDACTED_SUFFIX) {
+		return nil, fmt.Errorf("%s has been redacted", h.path)
+	}
+	timeStr := strings.TrimPrefix(name, _package_prefix)
+	beginTime, err := time.ParseInLocation(timedef.TIME_FORMAT_IN_FILE, timeStr, time.Local)
+	if err != nil {
+		return nil, fmt.Errorf("invalid result package name %s: %s", name, err.Error())
+	}
+	dataDir := path.Join(packageDir, _dir_data)
+	items := make(map[define.MetricName][]*define.YHCItem)
+	if err := readJson(path.Join(dataDir, fmt.Sprintf(_data_json, timeStr)), &items); err != nil {
+		return nil, err
+	}
+	failedItems := make(map[define.MetricName][]*define.YHCItem)
+	if err := readJson(path.Join(dataDir, fmt.Sprintf(_failed_item_json, timeStr)), &failedItems); err != nil {
+		return nil, err
+	}
+	pandoraReport := new(define.PandoraReport)
+	if err := readJson(path.Join(dataDir, fmt.Sprintf(_report_json, timeStr)), pandoraReport); err != nil {
+		return nil, err
+	}
+	base := &define.CheckerBase{Output: output}
+	report := reporter.NewYHCReport(runtimedef.GetYHCHome(), base)
+	report.BeginTime = beginTime
+	report.EndTime = beginTime
+	report.Items = fillItemNames(items)
+	report.FailedItem = fillItemNames(failedItems)
+	report.Report = pandoraReport
+	report.Metrics = h.getMetrics(items)
+	for _, metricItems := range items {
+		for _, item := range metricItems {
+			if !stringutil.IsEmpty(item.NodeID) {
+				base.MultipleNodes = true
+			}
+		}
+	}
+	manifest := new(resultpkg.Manifest)
+	manifestFile := path.Join(packageDir, resultpkg.MANIFEST_FILE)
+	if !fs.IsFileExist(manifestFile) {
+		log.Handler.Warnf("%s not found, the manifest of the redacted package is generated by current host", manifestFile)
+		return report, nil
+	}
+	if err := readJson(manifestFile, manifest); err != nil {
+		return nil, err
+	}
+	base.Start, base.End = manifest.CheckWindow.Start, manifest.CheckWindow.End
+	report.EndTime = manifest.CheckWindow.CheckEnd
+	if manifest.Database != nil {
+		base.DBInfo = &yasdb.YashanDB{
+			DatabaseName: manifest.Database.DatabaseName,
+			YasdbHome:    manifest.Database.YasdbHome,
+			YasdbData:    manifest.Database.YasdbData,
+			ListenAddr:   manifest.Database.ListenAddr,
+		}
+	}
+	report.BaseManifest = manifest
+	return report, nil
+}
+
+// getMetrics returns the configured metrics which have data in the package, they are used to export tables.
+func (h *RedactHandler) getMetrics(items map[define.MetricName][]*define.YHCItem) []*confdef.YHCMetric {
+	metrics := []*confdef.YHCMetric{}
+	conf := confdef.GetMetricConf()
+	if conf == nil {
+		return metrics
+	}
+	for _, metric := range conf.Metrics {
+		if _, ok := items[define.MetricName(metric.Name)]; ok {
+			metrics = append(metrics, metric)
+		}
+	}
+	return metrics
+}
+
+func fillItemNames(items map[define.MetricName][]*define.YHCItem) map[define.MetricName][]*define.YHCItem {
+	for name, metricItems := range items {
+		for _, item := range metricItems {
+			item.Name = name
+		}
+	}
+	return items
+}
+
+func readJson(fname string, v interface{}) error {
+	data, err := os.ReadFile(fname)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
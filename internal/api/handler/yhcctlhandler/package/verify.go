@@ -0,0 +1,129 @@
+package packagehandler
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+	"time"
+
+	"yhc/defs/bashdef"
+	"yhc/defs/timedef"
+	"yhc/i18n"
+	"yhc/internal/modules/yhc/resultpkg"
+	"yhc/log"
+	"yhc/utils/stringutil"
+
+	"git.yasdb.com/go/yasutil/tabler"
+)
+
+var (
+	ErrVerifyFailed = errors.New("result package verification failed")
+)
+
+type VerifyHandler struct {
+	path          string
+	publicKeyPath string
+}
+
+func NewVerifyHandler(path, publicKeyPath string) *VerifyHandler {
+	return &VerifyHandler{
+		path:          path,
+		publicKeyPath: publicKeyPath,
+	}
+}
+
+func (h *VerifyHandler) Verify() error {
+	var publicKey ed25519.PublicKey
+	if !stringutil.IsEmpty(h.publicKeyPath) {
+		key, err := resultpkg.LoadPublicKey(h.publicKeyPath)
+		if err != nil {
+			log.Handler.Errorf("load public key %s err: %s", h.publicKeyPath, err.Error())
+			return err
+		}
+		publicKey = key
+	}
+	result, err := resultpkg.VerifyPackage(h.path, publicKey)
+	if err != nil {
+		log.Handler.Errorf("verify package %s err: %s", h.path, err.Error())
+		return err
+	}
+	fmt.Println(h.genManifestStr(result.Manifest))
+	if problems := h.genProblemsStr(result); !stringutil.IsEmpty(problems) {
+		fmt.Println(problems)
+	}
+	h.printSignature(result)
+	if !result.OK() {
+		return ErrVerifyFailed
+	}
+	fmt.Println(bashdef.WithColor(fmt.Sprintf(i18n.T("package.verify_passed"), len(result.Manifest.Files)), bashdef.COLOR_GREEN))
+	return nil
+}
+
+func (h *VerifyHandler) genManifestStr(manifest *resultpkg.Manifest) string {
+	table := tabler.NewTable(i18n.T("package.manifest"),
+		tabler.NewRowTitle("KEY", 20),
+		tabler.NewRowTitle("VALUE", 50),
+	)
+	_ = table.AddColumn("App Version", manifest.App.Version)
+	_ = table.AddColumn("Git Commit", manifest.App.GitCommitID)
+	_ = table.AddColumn("Hostname", manifest.Host.Hostname)
+	_ = table.AddColumn("OS", manifest.Host.OS)
+	if manifest.Database != nil {
+		_ = table.AddColumn("Database", manifest.Database.DatabaseName)
+		_ = table.AddColumn("YASDB_HOME", manifest.Database.YasdbHome)
+		_ = table.AddColumn("YASDB_DATA", manifest.Database.YasdbData)
+	}
+	_ = table.AddColumn("Check Window", fmt.Sprintf("%s ~ %s", formatTime(manifest.CheckWindow.Start), formatTime(manifest.CheckWindow.End)))
+	_ = table.AddColumn("Check Time", fmt.Sprintf("%s ~ %s", formatTime(manifest.CheckWindow.CheckBegin), formatTime(manifest.CheckWindow.CheckEnd)))
+	_ = table.AddColumn("Files", fmt.Sprint(len(manifest.Files)))
+	return table.String()
+}
+
+func (h *VerifyHandler) genProblemsStr(result *resultpkg.VerifyResult) string {
+	if len(result.Mismatched)+len(result.Missing)+len(result.Unexpected) == 0 {
+		return ""
+	}
+	table := tabler.NewTable(i18n.T("package.problem_files"),
+		tabler.NewRowTitle("FILE", 50),
+		tabler.NewRowTitle("STATUS", 20),
+	)
+	for _, files := range []struct {
+		names  []string
+		status string
+	}{
+		{names: result.Mismatched, status: i18n.T("package.file_mismatched")},
+		{names: result.Missing, status: i18n.T("package.file_missing")},
+		{names: result.Unexpected, status: i18n.T("package.file_unexpected")},
+	} {
+		for _, name := range files.names {
+			if err := table.AddColumn(name, files.status); err != nil {
+				log.Handler.Errorf("add columns err: %s", err.Error())
+			}
+		}
+	}
+	return table.String()
+}
+
+func (h *VerifyHandler) printSignature(result *resultpkg.VerifyResult) {
+	if !result.Signed {
+		fmt.Println(bashdef.WithColor(i18n.T("package.not_signed"), bashdef.COLOR_YELLOW))
+	} else if !stringutil.IsEmpty(result.Fingerprint) {
+		fmt.Printf(i18n.T("package.signer_fingerprint"), result.Fingerprint)
+	}
+	if result.SignatureError != nil {
+		fmt.Println(bashdef.WithColor(fmt.Sprintf(i18n.T("package.signature_invalid"), result.SignatureError.Error()), bashdef.COLOR_RED))
+		return
+	}
+	if result.Trusted {
+		fmt.Println(bashdef.WithColor(i18n.T("package.signature_trusted"), bashdef.COLOR_GREEN))
+	} else if result.Signed {
+		fmt.Println(bashdef.WithColor(i18n.T("package.signature_untrusted"), bashdef.COLOR_YELLOW))
+	}
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return t.Format(timedef.TIME_FORMAT)
+}
@@ -0,0 +1,89 @@
+package resultshandler
+
+import (
+	"fmt"
+
+	"yhc/defs/timedef"
+	"yhc/i18n"
+	"yhc/internal/modules/yhc/results"
+	"yhc/log"
+	"yhc/utils/stringutil"
+
+	"git.yasdb.com/go/yasutil/size"
+	"git.yasdb.com/go/yasutil/tabler"
+)
+
+const (
+	_empty_value  = "-"
+	_size_decimal = 2
+)
+
+type ListHandler struct {
+	output string
+}
+
+func NewListHandler(output string) *ListHandler {
+	return &ListHandler{
+		output: output,
+	}
+}
+
+// List prints the result packages in the output directory, newest first.
+func (h *ListHandler) List() error {
+	pkgs, err := results.List(h.output)
+	if err != nil {
+		log.Handler.Errorf("list result packages in %s err: %s", h.output, err.Error())
+		return err
+	}
+	if len(pkgs) == 0 {
+		fmt.Printf(i18n.T("results.no_package"), h.output)
+		return nil
+	}
+	table := tabler.NewTable(h.output,
+		tabler.NewRowTitle("PACKAGE", 36),
+		tabler.NewRowTitle("CHECK TIME", 20),
+		tabler.NewRowTitle("PROFILE", 14),
+		tabler.NewRowTitle("DATABASE", 16),
+		tabler.NewRowTitle("SCORE", 8),
+		tabler.NewRowTitle("HEALTH", 10),
+		tabler.NewRowTitle("CRIT/WARN/INFO", 16),
+		tabler.NewRowTitle("SIZE", 10),
+	)
+	var totalSize int64
+	for _, pkg := range pkgs {
+		if err := results.LoadSummary(pkg); err != nil {
+			log.Handler.Warnf("load summary of %s err: %s", pkg.Path, err.Error())
+		}
+		totalSize += pkg.Size
+		score, health, alerts := _empty_value, _empty_value, _empty_value
+		if pkg.Summary != nil {
+			// packages without manifest have no score
+			if pkg.Manifest != nil {
+				score, health = fmt.Sprintf("%.2f", pkg.Summary.Score), orEmpty(pkg.Summary.HealthStatus)
+			}
+			alerts = fmt.Sprintf("%d/%d/%d", pkg.Summary.CriticalCount, pkg.Summary.WarningCount, pkg.Summary.InfoCount)
+		}
+		if err := table.AddColumn(
+			pkg.Name,
+			pkg.Time.Format(timedef.TIME_FORMAT),
+			orEmpty(pkg.GetProfile()),
+			orEmpty(pkg.GetDatabaseName()),
+			score,
+			health,
+			alerts,
+			size.GenHumanReadableSize(float64(pkg.Size), _size_decimal),
+		); err != nil {
+			log.Handler.Errorf("add columns err: %s", err.Error())
+		}
+	}
+	fmt.Println(table.String())
+	fmt.Printf(i18n.T("results.total"), len(pkgs), size.GenHumanReadableSize(float64(totalSize), _size_decimal))
+	return nil
+}
+
+func orEmpty(s string) string {
+	if stringutil.IsEmpty(s) {
+		return _empty_value
+	}
+	return s
+}
@@ -0,0 +1,70 @@
+package resultshandler
+
+import (
+	"fmt"
+
+	"yhc/defs/bashdef"
+	"yhc/defs/confdef"
+	"yhc/defs/timedef"
+	"yhc/i18n"
+	"yhc/internal/modules/yhc/results"
+	"yhc/log"
+
+	"git.yasdb.com/go/yasutil/size"
+	"git.yasdb.com/go/yasutil/tabler"
+)
+
+type PruneHandler struct {
+	output string
+	policy confdef.RetentionPolicy
+	dryRun bool
+}
+
+func NewPruneHandler(output string, policy confdef.RetentionPolicy, dryRun bool) *PruneHandler {
+	return &PruneHandler{
+		output: output,
+		policy: policy,
+		dryRun: dryRun,
+	}
+}
+
+// Prune removes the result packages which exceed the retention policy, only prints them in dry-run mode.
+func (h *PruneHandler) Prune() error {
+	if h.policy.IsEmpty() {
+		fmt.Println(i18n.T("results.no_retention_policy"))
+		return nil
+	}
+	res, err := results.Prune(h.output, h.policy, h.dryRun)
+	if res != nil && len(res.Expired) != 0 {
+		fmt.Println(h.genExpiredStr(res))
+	}
+	if err != nil {
+		log.Handler.Errorf("prune result packages in %s err: %s", h.output, err.Error())
+		return err
+	}
+	if len(res.Expired) == 0 {
+		fmt.Println(i18n.T("results.nothing_to_prune"))
+		return nil
+	}
+	freed := size.GenHumanReadableSize(float64(res.FreedSize), _size_decimal)
+	if h.dryRun {
+		fmt.Println(bashdef.WithColor(fmt.Sprintf(i18n.T("results.prune_dry_run"), len(res.Expired), freed), bashdef.COLOR_YELLOW))
+		return nil
+	}
+	fmt.Println(bashdef.WithColor(fmt.Sprintf(i18n.T("results.pruned"), len(res.Expired), freed), bashdef.COLOR_GREEN))
+	return nil
+}
+
+func (h *PruneHandler) genExpiredStr(res *results.PruneResult) string {
+	table := tabler.NewTable(i18n.T("results.expired_packages"),
+		tabler.NewRowTitle("PACKAGE", 36),
+		tabler.NewRowTitle("CHECK TIME", 20),
+		tabler.NewRowTitle("SIZE", 10),
+	)
+	for _, pkg := range res.Expired {
+		if err := table.AddColumn(pkg.Name, pkg.Time.Format(timedef.TIME_FORMAT), size.GenHumanReadableSize(float64(pkg.Size), _size_decimal)); err != nil {
+			log.Handler.Errorf("add columns err: %s", err.Error())
+		}
+	}
+	return table.String()
+}
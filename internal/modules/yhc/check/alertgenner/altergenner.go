@@ -5,13 +5,24 @@ import (
 	"strings"
 
 	"yhc/defs/confdef"
+	"yhc/internal/modules/yhc/check/awr"
 	"yhc/internal/modules/yhc/check/define"
+	"yhc/internal/modules/yhc/check/wlstats"
 
 	"git.yasdb.com/go/yaslog"
 	"git.yasdb.com/pandora/alertql"
 	"git.yasdb.com/pandora/alertql/defs/metricdef"
 )
 
+// the suffixes of the sub-metrics of the workload statistics
+const (
+	SUFFIX_MIN           = "_min"
+	SUFFIX_AVG           = "_avg"
+	SUFFIX_P95           = "_p95"
+	SUFFIX_MAX           = "_max"
+	SUFFIX_ABOVE_SECONDS = "_above_seconds"
+)
+
 type AlertGenner struct {
 	log     yaslog.YasLog
 	metrics []*confdef.YHCMetric
@@ -99,6 +110,10 @@ func (a *AlertGenner) dealItem(pool *metricdef.MetricsPool, metric *confdef.YHCM
 			for _, data := range detail {
 				a.dealSingleAnyRow(pool, metric, data)
 			}
+		case define.WorkloadOutput:
+			a.dealWorkload(pool, metric, detail)
+		case *awr.Report:
+			a.log.Debugf("unsupport alert type *awr.Report, skip")
 		default:
 			a.log.Errorf("unsupport data type %T", detail)
 		}
@@ -153,3 +168,41 @@ func (a *AlertGenner) dealSingleAnyRow(pool *metricdef.MetricsPool, metric *conf
 		(*pool)[subMetricName] = metrics
 	}
 }
+
+// dealWorkload exposes the statistics of every numeric field of every workload item as the sub-metrics,
+// the sub-metric of the field itself is the average so that the rules are evaluated against a sustained value,
+// and the suffixes _min, _avg, _p95, _max and _above_seconds (if the field has a threshold) are the statistics.
+func (a *AlertGenner) dealWorkload(pool *metricdef.MetricsPool, metric *confdef.YHCMetric, output define.WorkloadOutput) {
+	series, err := wlstats.FromWorkload(output, metric.Labels)
+	if err != nil {
+		a.log.Errorf("failed to parse workload of %s, err: %v", metric.Name, err)
+		return
+	}
+	for _, s := range series {
+		subMetricName, ok := metric.ItemNames[s.Field]
+		if !ok {
+			subMetricName = fmt.Sprintf("%s_%s", metric.Name, strings.ToLower(s.Field))
+		}
+		labelsMap := make(map[string]string)
+		for _, label := range metric.Labels {
+			labelsMap[label] = s.Labels[label]
+		}
+		stats := wlstats.Summarize(s.Points)
+		values := map[string]interface{}{
+			subMetricName:              stats.Avg,
+			subMetricName + SUFFIX_MIN: stats.Min,
+			subMetricName + SUFFIX_AVG: stats.Avg,
+			subMetricName + SUFFIX_P95: stats.P95,
+			subMetricName + SUFFIX_MAX: stats.Max,
+		}
+		if threshold, ok := metric.Thresholds[s.Field]; ok {
+			values[subMetricName+SUFFIX_ABOVE_SECONDS] = wlstats.SecondsAbove(s.Points, threshold)
+		}
+		for name, value := range values {
+			(*pool)[name] = append((*pool)[name], metricdef.Metric{
+				Value:  value,
+				Labels: labelsMap,
+			})
+		}
+	}
+}
@@ -0,0 +1,418 @@
+// The awr package builds the workload report between two snapshots of the workload repository (WRM$/WRH$),
+// such as the load profile, the instance efficiency, the time model, the top wait events and the top SQL,
+// compares the workload of two periods, and breaks down the wait time by wait class.
+package awr
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"yhc/defs/timedef"
+	"yhc/utils/mathutil"
+)
+
+const (
+	// the stats of v$sysstat, the time stats are in milliseconds like the DB time of the history DB time metric
+	STAT_DB_TIME        = "DB TIME"
+	STAT_CPU_TIME       = "CPU TIME"
+	STAT_BUFFER_GETS    = "BUFFER GETS"
+	STAT_BUFFER_CR_GETS = "BUFFER CR GETS"
+	STAT_DISK_READS     = "DISK READS"
+	STAT_DISK_WRITES    = "DISK WRITES"
+	STAT_REDO_SIZE      = "REDO SIZE"
+	STAT_BLOCK_CHANGES  = "DB BLOCK CHANGES"
+	STAT_USER_CALLS     = "USER CALLS"
+	STAT_PARSE_TOTAL    = "PARSE COUNT (TOTAL)"
+	STAT_PARSE_HARD     = "PARSE COUNT (HARD)"
+	STAT_EXECUTE_COUNT  = "EXECUTE COUNT"
+	STAT_LOGONS         = "LOGONS CUMULATIVE"
+	STAT_USER_COMMITS   = "USER COMMITS"
+	STAT_USER_ROLLBACKS = "USER ROLLBACKS"
+
+	LOAD_LOGICAL_READS = "LOGICAL READS"
+	LOAD_TRANSACTIONS  = "TRANSACTIONS"
+
+	RATIO_BUFFER_HIT       = "Buffer Hit %"
+	RATIO_SOFT_PARSE       = "Soft Parse %"
+	RATIO_EXECUTE_TO_PARSE = "Execute to Parse %"
+	RATIO_ROLLBACK         = "Rollback %"
+
+	_time_suffix = "TIME"
+	_decimal     = 2
+)
+
+// the rows of the load profile, the value of a row is the sum of the stats
+var _loadProfile = []struct {
+	name  string
+	stats []string
+}{
+	{STAT_DB_TIME, []string{STAT_DB_TIME}},
+	{STAT_CPU_TIME, []string{STAT_CPU_TIME}},
+	{STAT_REDO_SIZE, []string{STAT_REDO_SIZE}},
+	{LOAD_LOGICAL_READS, []string{STAT_BUFFER_GETS, STAT_BUFFER_CR_GETS}},
+	{STAT_BLOCK_CHANGES, []string{STAT_BLOCK_CHANGES}},
+	{STAT_DISK_READS, []string{STAT_DISK_READS}},
+	{STAT_DISK_WRITES, []string{STAT_DISK_WRITES}},
+	{STAT_USER_CALLS, []string{STAT_USER_CALLS}},
+	{STAT_PARSE_TOTAL, []string{STAT_PARSE_TOTAL}},
+	{STAT_PARSE_HARD, []string{STAT_PARSE_HARD}},
+	{STAT_EXECUTE_COUNT, []string{STAT_EXECUTE_COUNT}},
+	{STAT_LOGONS, []string{STAT_LOGONS}},
+	{LOAD_TRANSACTIONS, []string{STAT_USER_COMMITS, STAT_USER_ROLLBACKS}},
+}
+
+// Snapshot is a snapshot of the workload repository.
+type Snapshot struct {
+	ID    int64
+	Begin time.Time
+	End   time.Time
+}
+
+// Period is the interval between the end of the begin snapshot and the end of the end snapshot.
+type Period struct {
+	Begin Snapshot
+	End   Snapshot
+}
+
+// Event is the counters of a wait event at a snapshot, or the increments between two snapshots.
+type Event struct {
+	Name            string
+	WaitClass       string
+	Waits           float64
+	TimeWaitedMicro float64
+}
+
+// SQLStat is the increments of the stats of a SQL between two snapshots, the times are in microseconds.
+type SQLStat struct {
+	SQLID       string
+	SQLText     string
+	Executions  float64
+	ElapsedTime float64
+	CPUTime     float64
+	BufferGets  float64
+	DiskReads   float64
+}
+
+// LoadItem is a row of the load profile.
+type LoadItem struct {
+	Name           string
+	Total          float64
+	PerSecond      float64
+	PerTransaction float64
+}
+
+// Ratio is an instance efficiency ratio in percent.
+type Ratio struct {
+	Name  string
+	Value float64
+}
+
+// TimeItem is a time stat and its percentage of the DB time.
+type TimeItem struct {
+	Name          string
+	Value         float64
+	DBTimePercent float64
+}
+
+// WaitEvent is a wait event between two snapshots.
+type WaitEvent struct {
+	Name          string
+	WaitClass     string
+	Waits         float64
+	TimeSeconds   float64
+	AvgWaitMs     float64
+	DBTimePercent float64
+}
+
+// Workload is the workload of a period.
+type Workload struct {
+	Period       Period
+	LoadProfile  []*LoadItem
+	Efficiency   []*Ratio
+	TimeModel    []*TimeItem
+	WaitEvents   []*WaitEvent
+	SQLByElapsed []*SQLStat
+	SQLByCPU     []*SQLStat
+	SQLByGets    []*SQLStat
+}
+
+// Diff is the change of a value from the base period to the compare period.
+type Diff struct {
+	Name    string
+	Base    float64
+	Compare float64
+	// Percent is the change in percent of the base value, 0 if the base value is 0
+	Percent float64
+}
+
+// Report is the workload of the base period, and the workload of the compare period with the changes if any.
+type Report struct {
+	Base       *Workload
+	Compare    *Workload
+	LoadDiffs  []*Diff
+	RatioDiffs []*Diff
+	EventDiffs []*Diff
+	// Errors is the errors of the parts failed to collect, such as the top SQL, the rest of the report is still shown
+	Errors []string
+}
+
+// Seconds returns the length of the period.
+func (p Period) Seconds() float64 {
+	return p.End.End.Sub(p.Begin.End).Seconds()
+}
+
+func (p Period) String() string {
+	return fmt.Sprintf("%d(%s) - %d(%s)", p.Begin.ID, p.Begin.End.Format(timedef.TIME_FORMAT), p.End.ID, p.End.End.Format(timedef.TIME_FORMAT))
+}
+
+// Find returns the period between the snapshots of the ids.
+func Find(snapshots []Snapshot, beginID, endID int64) (Period, error) {
+	if beginID >= endID {
+		return Period{}, fmt.Errorf("the begin snapshot %d should be less than the end snapshot %d", beginID, endID)
+	}
+	var period Period
+	var foundBegin, foundEnd bool
+	for _, s := range snapshots {
+		switch s.ID {
+		case beginID:
+			period.Begin, foundBegin = s, true
+		case endID:
+			period.End, foundEnd = s, true
+		}
+	}
+	if !foundBegin {
+		return period, fmt.Errorf("snapshot %d not found", beginID)
+	}
+	if !foundEnd {
+		return period, fmt.Errorf("snapshot %d not found", endID)
+	}
+	return period, nil
+}
+
+// Pick picks the period which covers the window best: the begin snapshot is the last one ending at or before
+// the start, or the first one in the window, and the end snapshot is the last one ending at or before the end.
+func Pick(snapshots []Snapshot, start, end time.Time) (Period, error) {
+	sorted := append([]Snapshot{}, snapshots...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].ID < sorted[j].ID })
+	begin, last := -1, -1
+	for i, s := range sorted {
+		if !s.End.After(start) {
+			begin = i
+		}
+		if !s.End.After(end) {
+			last = i
+		}
+	}
+	if begin < 0 {
+		// no snapshot before the window, begin with the first one in the window
+		for i, s := range sorted {
+			if s.End.After(start) && !s.End.After(end) {
+				begin = i
+				break
+			}
+		}
+	}
+	if begin < 0 || last <= begin {
+		return Period{}, fmt.Errorf("less than two snapshots between %s and %s", start.Format(timedef.TIME_FORMAT), end.Format(timedef.TIME_FORMAT))
+	}
+	return Period{Begin: sorted[begin], End: sorted[last]}, nil
+}
+
+// Delta returns the increments of the counters, the stats are keyed by the upper-case names,
+// and the counters reset by a restart are skipped.
+func Delta(begin, end map[string]float64) map[string]float64 {
+	res := make(map[string]float64, len(end))
+	for name, e := range end {
+		b := begin[name]
+		if e < b {
+			continue
+		}
+		res[strings.ToUpper(name)] = e - b
+	}
+	return res
+}
+
+// NewWorkload builds the workload of the period from the increments of the stats and wait events,
+// and keeps the top n wait events and the top n SQL by the elapsed time, the CPU time and the buffer gets.
+func NewWorkload(period Period, stats map[string]float64, events []*Event, sqls []*SQLStat, n int) *Workload {
+	w := &Workload{
+		Period:       period,
+		LoadProfile:  loadProfile(stats, period.Seconds()),
+		Efficiency:   efficiency(stats),
+		TimeModel:    timeModel(stats),
+		WaitEvents:   TopWaitEvents(events, stats[STAT_DB_TIME], n),
+		SQLByElapsed: TopSQL(sqls, func(s *SQLStat) float64 { return s.ElapsedTime }, n),
+		SQLByCPU:     TopSQL(sqls, func(s *SQLStat) float64 { return s.CPUTime }, n),
+		SQLByGets:    TopSQL(sqls, func(s *SQLStat) float64 { return s.BufferGets }, n),
+	}
+	return w
+}
+
+// EventDelta returns the increments of the wait events, the events not waited in the period are skipped.
+func EventDelta(begin, end []*Event) []*Event {
+	beginEvents := make(map[string]*Event, len(begin))
+	for _, e := range begin {
+		beginEvents[e.Name] = e
+	}
+	var res []*Event
+	for _, e := range end {
+		delta := &Event{Name: e.Name, WaitClass: e.WaitClass, Waits: e.Waits, TimeWaitedMicro: e.TimeWaitedMicro}
+		if b, ok := beginEvents[e.Name]; ok {
+			delta.Waits -= b.Waits
+			delta.TimeWaitedMicro -= b.TimeWaitedMicro
+		}
+		if delta.Waits <= 0 || delta.TimeWaitedMicro < 0 {
+			continue
+		}
+		res = append(res, delta)
+	}
+	return res
+}
+
+// TopSQL returns the top n SQL ordered by the key in descending order.
+func TopSQL(sqls []*SQLStat, key func(s *SQLStat) float64, n int) []*SQLStat {
+	sorted := append([]*SQLStat{}, sqls...)
+	sort.SliceStable(sorted, func(i, j int) bool { return key(sorted[i]) > key(sorted[j]) })
+	if len(sorted) > n {
+		sorted = sorted[:n]
+	}
+	return sorted
+}
+
+// NewReport builds the report of the base workload, and compares it with the compare workload if not nil.
+func NewReport(base, compare *Workload) *Report {
+	r := &Report{Base: base, Compare: compare}
+	if compare == nil {
+		return r
+	}
+	baseLoads, compareLoads := make(map[string]float64), make(map[string]float64)
+	var loadNames []string
+	for _, item := range base.LoadProfile {
+		baseLoads[item.Name] = item.PerSecond
+		loadNames = append(loadNames, item.Name)
+	}
+	for _, item := range compare.LoadProfile {
+		compareLoads[item.Name] = item.PerSecond
+	}
+	r.LoadDiffs = diffs(loadNames, baseLoads, compareLoads)
+
+	baseRatios, compareRatios := make(map[string]float64), make(map[string]float64)
+	var ratioNames []string
+	for _, ratio := range base.Efficiency {
+		baseRatios[ratio.Name] = ratio.Value
+		ratioNames = append(ratioNames, ratio.Name)
+	}
+	for _, ratio := range compare.Efficiency {
+		compareRatios[ratio.Name] = ratio.Value
+	}
+	r.RatioDiffs = diffs(ratioNames, baseRatios, compareRatios)
+
+	// the events of both periods are compared, so that an event only waited in one period is also found
+	baseEvents, compareEvents := make(map[string]float64), make(map[string]float64)
+	var eventNames []string
+	for _, e := range base.WaitEvents {
+		baseEvents[e.Name] = e.TimeSeconds
+		eventNames = append(eventNames, e.Name)
+	}
+	for _, e := range compare.WaitEvents {
+		if _, ok := baseEvents[e.Name]; !ok {
+			eventNames = append(eventNames, e.Name)
+		}
+		compareEvents[e.Name] = e.TimeSeconds
+	}
+	r.EventDiffs = diffs(eventNames, baseEvents, compareEvents)
+	return r
+}
+
+func diffs(names []string, base, compare map[string]float64) []*Diff {
+	res := make([]*Diff, 0, len(names))
+	for _, name := range names {
+		compareValue, ok := compare[name]
+		if !ok && base[name] == 0 {
+			continue
+		}
+		d := &Diff{Name: name, Base: base[name], Compare: compareValue}
+		if d.Base != 0 {
+			d.Percent = mathutil.Round((d.Compare-d.Base)/d.Base*100, _decimal)
+		}
+		res = append(res, d)
+	}
+	return res
+}
+
+func loadProfile(stats map[string]float64, seconds float64) []*LoadItem {
+	transactions := stats[STAT_USER_COMMITS] + stats[STAT_USER_ROLLBACKS]
+	var res []*LoadItem
+	for _, define := range _loadProfile {
+		var total float64
+		found := false
+		for _, stat := range define.stats {
+			if v, ok := stats[stat]; ok {
+				total += v
+				found = true
+			}
+		}
+		if !found {
+			continue
+		}
+		item := &LoadItem{Name: define.name, Total: total}
+		if seconds > 0 {
+			item.PerSecond = mathutil.Round(total/seconds, _decimal)
+		}
+		if transactions > 0 {
+			item.PerTransaction = mathutil.Round(total/transactions, _decimal)
+		}
+		res = append(res, item)
+	}
+	return res
+}
+
+func efficiency(stats map[string]float64) []*Ratio {
+	var res []*Ratio
+	add := func(name string, numerator, denominator float64, ok bool) {
+		if !ok || denominator <= 0 {
+			return
+		}
+		res = append(res, &Ratio{Name: name, Value: mathutil.Round(numerator/denominator*100, _decimal)})
+	}
+	has := func(names ...string) bool {
+		for _, name := range names {
+			if _, ok := stats[name]; !ok {
+				return false
+			}
+		}
+		return true
+	}
+	gets := stats[STAT_BUFFER_GETS] + stats[STAT_BUFFER_CR_GETS]
+	add(RATIO_BUFFER_HIT, gets-stats[STAT_DISK_READS], gets, has(STAT_BUFFER_GETS, STAT_DISK_READS))
+	add(RATIO_SOFT_PARSE, stats[STAT_PARSE_TOTAL]-stats[STAT_PARSE_HARD], stats[STAT_PARSE_TOTAL], has(STAT_PARSE_TOTAL, STAT_PARSE_HARD))
+	add(RATIO_EXECUTE_TO_PARSE, stats[STAT_EXECUTE_COUNT]-stats[STAT_PARSE_TOTAL], stats[STAT_EXECUTE_COUNT], has(STAT_EXECUTE_COUNT, STAT_PARSE_TOTAL))
+	transactions := stats[STAT_USER_COMMITS] + stats[STAT_USER_ROLLBACKS]
+	add(RATIO_ROLLBACK, stats[STAT_USER_ROLLBACKS], transactions, has(STAT_USER_COMMITS, STAT_USER_ROLLBACKS))
+	return res
+}
+
+// timeModel returns the time stats ordered by the value, the percentage of DB time is 0 if DB time is unknown
+func timeModel(stats map[string]float64) []*TimeItem {
+	dbTime := stats[STAT_DB_TIME]
+	var res []*TimeItem
+	for name, value := range stats {
+		if !strings.HasSuffix(name, _time_suffix) {
+			continue
+		}
+		item := &TimeItem{Name: name, Value: value}
+		if dbTime > 0 {
+			item.DBTimePercent = mathutil.Round(value/dbTime*100, _decimal)
+		}
+		res = append(res, item)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Value != res[j].Value {
+			return res[i].Value > res[j].Value
+		}
+		return res[i].Name < res[j].Name
+	})
+	return res
+}
@@ -0,0 +1,154 @@
+package awr_test
+
+import (
+	"testing"
+	"time"
+
+	"yhc/internal/modules/yhc/check/awr"
+)
+
+func snapshots() []awr.Snapshot {
+	base := time.Date(2024, 1, 2, 10, 0, 0, 0, time.Local)
+	var res []awr.Snapshot
+	for i := 0; i < 5; i++ {
+		end := base.Add(time.Duration(i) * time.Hour)
+		res = append(res, awr.Snapshot{ID: int64(i + 1), Begin: end.Add(-time.Hour), End: end})
+	}
+	return res
+}
+
+func TestPick(t *testing.T) {
+	snaps := snapshots()
+	start := time.Date(2024, 1, 2, 11, 30, 0, 0, time.Local)
+	end := time.Date(2024, 1, 2, 13, 30, 0, 0, time.Local)
+	period, err := awr.Pick(snaps, start, end)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if period.Begin.ID != 2 || period.End.ID != 4 || period.Seconds() != 7200 {
+		t.Fatalf("unexpected period: %s", period)
+	}
+	// no snapshot before the window
+	period, err = awr.Pick(snaps, start.Add(-24*time.Hour), end)
+	if err != nil || period.Begin.ID != 1 || period.End.ID != 4 {
+		t.Fatalf("unexpected period: %s, %v", period, err)
+	}
+	if _, err := awr.Pick(snaps, start, start.Add(10*time.Minute)); err == nil {
+		t.Fatal("expected error for a window without two snapshots")
+	}
+	if _, err := awr.Find(snaps, 3, 2); err == nil {
+		t.Fatal("expected error for reversed snapshots")
+	}
+	if _, err := awr.Find(snaps, 1, 9); err == nil {
+		t.Fatal("expected error for a missing snapshot")
+	}
+}
+
+func TestNewWorkload(t *testing.T) {
+	period, err := awr.Find(snapshots(), 1, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	begin := map[string]float64{awr.STAT_DB_TIME: 1000, awr.STAT_CPU_TIME: 100, awr.STAT_BUFFER_GETS: 0, awr.STAT_DISK_READS: 0, awr.STAT_USER_COMMITS: 10, awr.STAT_USER_ROLLBACKS: 0}
+	end := map[string]float64{awr.STAT_DB_TIME: 8200, awr.STAT_CPU_TIME: 3700, awr.STAT_BUFFER_GETS: 1000, awr.STAT_DISK_READS: 50, awr.STAT_USER_COMMITS: 82, awr.STAT_USER_ROLLBACKS: 0}
+	stats := awr.Delta(begin, end)
+	events := awr.EventDelta(
+		[]*awr.Event{{Name: "db file sequential read", Waits: 10, TimeWaitedMicro: 1000}},
+		[]*awr.Event{{Name: "db file sequential read", Waits: 110, TimeWaitedMicro: 3601000}, {Name: "idle", Waits: 0}},
+	)
+	sqls := []*awr.SQLStat{{SQLID: "a", ElapsedTime: 1, CPUTime: 3}, {SQLID: "b", ElapsedTime: 3, CPUTime: 1}, {SQLID: "c", ElapsedTime: 2, CPUTime: 2}}
+	w := awr.NewWorkload(period, stats, events, sqls, 2)
+
+	if len(w.LoadProfile) == 0 || w.LoadProfile[0].Name != awr.STAT_DB_TIME || w.LoadProfile[0].PerSecond != 2 || w.LoadProfile[0].PerTransaction != 100 {
+		t.Fatalf("unexpected load profile: %+v", w.LoadProfile[0])
+	}
+	if len(w.Efficiency) != 2 || w.Efficiency[0].Name != awr.RATIO_BUFFER_HIT || w.Efficiency[0].Value != 95 {
+		t.Fatalf("unexpected efficiency: %+v", w.Efficiency)
+	}
+	if len(w.TimeModel) != 2 || w.TimeModel[1].Name != awr.STAT_CPU_TIME || w.TimeModel[1].DBTimePercent != 50 {
+		t.Fatalf("unexpected time model: %+v", w.TimeModel)
+	}
+	if len(w.WaitEvents) != 1 || w.WaitEvents[0].TimeSeconds != 3.6 || w.WaitEvents[0].AvgWaitMs != 36 || w.WaitEvents[0].DBTimePercent != 50 {
+		t.Fatalf("unexpected wait events: %+v", w.WaitEvents)
+	}
+	if len(w.SQLByElapsed) != 2 || w.SQLByElapsed[0].SQLID != "b" || w.SQLByElapsed[1].SQLID != "c" {
+		t.Fatalf("unexpected top SQL by elapsed time: %+v", w.SQLByElapsed)
+	}
+	if len(w.SQLByCPU) != 2 || w.SQLByCPU[0].SQLID != "a" {
+		t.Fatalf("unexpected top SQL by CPU time: %+v", w.SQLByCPU)
+	}
+}
+
+func TestNewReport(t *testing.T) {
+	base := &awr.Workload{
+		LoadProfile: []*awr.LoadItem{{Name: awr.STAT_DB_TIME, PerSecond: 2}},
+		WaitEvents:  []*awr.WaitEvent{{Name: "a", TimeSeconds: 10}},
+	}
+	compare := &awr.Workload{
+		LoadProfile: []*awr.LoadItem{{Name: awr.STAT_DB_TIME, PerSecond: 1}},
+		WaitEvents:  []*awr.WaitEvent{{Name: "b", TimeSeconds: 5}},
+	}
+	r := awr.NewReport(base, compare)
+	if len(r.LoadDiffs) != 1 || r.LoadDiffs[0].Percent != -50 {
+		t.Fatalf("unexpected load diffs: %+v", r.LoadDiffs)
+	}
+	if len(r.EventDiffs) != 2 || r.EventDiffs[1].Name != "b" || r.EventDiffs[1].Base != 0 || r.EventDiffs[1].Compare != 5 {
+		t.Fatalf("unexpected event diffs: %+v", r.EventDiffs)
+	}
+	if r := awr.NewReport(base, nil); r.LoadDiffs != nil {
+		t.Fatal("unexpected diffs without compare period")
+	}
+}
+
+func TestWaitClasses(t *testing.T) {
+	events := []*awr.Event{
+		{Name: "db file sequential read", WaitClass: "User I/O", Waits: 100, TimeWaitedMicro: 2e6},
+		{Name: "enq: TX - row lock contention", WaitClass: "Other", Waits: 1, TimeWaitedMicro: 3e6},
+		{Name: "log file sync", WaitClass: "Commit", Waits: 10, TimeWaitedMicro: 1e5},
+		{Name: "db file scattered read", WaitClass: "User I/O", Waits: 50, TimeWaitedMicro: 2e6},
+		// the word block is not a lock, the class is split by the categories of its events
+		{Name: "buffer busy: block", WaitClass: "Other", Waits: 5, TimeWaitedMicro: 5e5},
+	}
+	classes := awr.WaitClasses(events, 10000)
+	if len(classes) != 4 {
+		t.Fatalf("unexpected classes: %+v", classes)
+	}
+	if classes[0].Name != "User I/O" || classes[0].Category != awr.CATEGORY_IO || classes[0].Waits != 150 || classes[0].TimeSeconds != 4 || classes[0].DBTimePercent != 40 {
+		t.Fatalf("unexpected class: %+v", classes[0])
+	}
+	if classes[1].Name != "Other" || classes[1].Category != awr.CATEGORY_LOCK || classes[1].Waits != 1 || classes[1].TimeSeconds != 3 {
+		t.Fatalf("the lock events should be lock: %+v", classes[1])
+	}
+	if classes[2].Name != "Other" || classes[2].Category != awr.CATEGORY_OTHER || classes[2].Waits != 5 || classes[2].DBTimePercent != 5 {
+		t.Fatalf("the block event should be other: %+v", classes[2])
+	}
+	if classes[3].Name != "Commit" || classes[3].Category != awr.CATEGORY_OTHER {
+		t.Fatalf("unexpected class: %+v", classes[3])
+	}
+	if top := awr.TopWaitEvents(events, 10000, 1); len(top) != 1 || top[0].Name != "enq: TX - row lock contention" || top[0].DBTimePercent != 30 {
+		t.Fatalf("unexpected top events: %+v", top)
+	}
+}
+
+func TestCategory(t *testing.T) {
+	cases := map[string][2]string{
+		awr.CATEGORY_IO:    {"System I/O", "control file parallel write"},
+		awr.CATEGORY_LOCK:  {"Application", "SQL*Net break/reset to client"},
+		awr.CATEGORY_OTHER: {"Other", "read by other session: block"},
+	}
+	for expected, c := range cases {
+		if category := awr.Category(c[0], c[1]); category != expected {
+			t.Fatalf("%v: expected %s, got %s", c, expected, category)
+		}
+	}
+	for _, event := range []string{"enq: TX - row lock contention", "latch: cache buffers chains", "Lock wait", "library cache lock"} {
+		if category := awr.Category("Other", event); category != awr.CATEGORY_LOCK {
+			t.Fatalf("%s: expected lock, got %s", event, category)
+		}
+	}
+	for _, event := range []string{"block change tracking buffer space", "db file parallel write: blocks", "enqueue free"} {
+		if category := awr.Category("Other", event); category != awr.CATEGORY_OTHER {
+			t.Fatalf("%s: expected other, got %s", event, category)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package awr
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"yhc/utils/mathutil"
+)
+
+const (
+	// the categories of the wait classes, the alerts are raised when the I/O or lock waits dominate DB time
+	CATEGORY_IO    = "io"
+	CATEGORY_LOCK  = "lock"
+	CATEGORY_OTHER = "other"
+
+	_class_io = "I/O"
+)
+
+var (
+	// the wait classes of the lock waits, such as the row lock of 'Application' and the latches of 'Concurrency'
+	_lockClasses = map[string]struct{}{
+		"APPLICATION": {},
+		"CONCURRENCY": {},
+	}
+	// the lock events of the other classes, such as 'enq: TX - row lock contention', 'latch: cache buffers chains' and
+	// 'library cache lock', the word lock is matched as a whole so that the events such as 'buffer busy: block' are not
+	_lockEventRegexp = regexp.MustCompile(`^(enq|latch):|\block\b`)
+)
+
+// WaitClass is the wait time of a wait class between two snapshots.
+type WaitClass struct {
+	Name          string
+	Category      string
+	Waits         float64
+	TimeSeconds   float64
+	DBTimePercent float64
+}
+
+// TopWaitEvents returns the top n wait events by the time waited, the DB time is in milliseconds.
+func TopWaitEvents(events []*Event, dbTime float64, n int) []*WaitEvent {
+	res := make([]*WaitEvent, 0, len(events))
+	for _, e := range events {
+		w := &WaitEvent{
+			Name:        e.Name,
+			WaitClass:   e.WaitClass,
+			Waits:       e.Waits,
+			TimeSeconds: mathutil.Round(e.TimeWaitedMicro/1e6, _decimal),
+		}
+		if e.Waits > 0 {
+			w.AvgWaitMs = mathutil.Round(e.TimeWaitedMicro/1e3/e.Waits, _decimal)
+		}
+		if dbTime > 0 {
+			w.DBTimePercent = mathutil.Round(e.TimeWaitedMicro/1e3/dbTime*100, _decimal)
+		}
+		res = append(res, w)
+	}
+	sort.SliceStable(res, func(i, j int) bool { return res[i].TimeSeconds > res[j].TimeSeconds })
+	if len(res) > n {
+		res = res[:n]
+	}
+	return res
+}
+
+// WaitClasses sums the wait events by wait class and category ordered by the time waited, the DB time is in milliseconds.
+// The events of a class are categorized one by one, so a class having both lock and other events is split into a row for each.
+func WaitClasses(events []*Event, dbTime float64) []*WaitClass {
+	type key struct{ class, category string }
+	classes := make(map[key]*WaitClass)
+	micros := make(map[*WaitClass]float64)
+	var res []*WaitClass
+	for _, e := range events {
+		k := key{class: e.WaitClass, category: Category(e.WaitClass, e.Name)}
+		c, ok := classes[k]
+		if !ok {
+			c = &WaitClass{Name: k.class, Category: k.category}
+			classes[k] = c
+			res = append(res, c)
+		}
+		c.Waits += e.Waits
+		micros[c] += e.TimeWaitedMicro
+	}
+	for _, c := range res {
+		c.TimeSeconds = mathutil.Round(micros[c]/1e6, _decimal)
+		if dbTime > 0 {
+			c.DBTimePercent = mathutil.Round(micros[c]/1e3/dbTime*100, _decimal)
+		}
+	}
+	sort.SliceStable(res, func(i, j int) bool { return res[i].TimeSeconds > res[j].TimeSeconds })
+	return res
+}
+
+// Category returns the category of the wait event by its class, such as 'User I/O', or by its name, such as 'enq: TX - row lock'.
+func Category(class, event string) string {
+	if strings.Contains(strings.ToUpper(class), _class_io) {
+		return CATEGORY_IO
+	}
+	if _, ok := _lockClasses[strings.ToUpper(class)]; ok {
+		return CATEGORY_LOCK
+	}
+	if _lockEventRegexp.MatchString(strings.ToLower(event)) {
+		return CATEGORY_LOCK
+	}
+	return CATEGORY_OTHER
+}
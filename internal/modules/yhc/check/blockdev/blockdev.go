@@ -0,0 +1,177 @@
+// The blockdev package resolves the physical disks under a mounted device, which may be a partition,
+// a LVM logical volume or a multipath device, and reads their queue settings from sysfs.
+package blockdev
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"yhc/utils/osutil"
+)
+
+const (
+	SYS_CLASS_BLOCK = "/sys/class/block"
+
+	TYPE_DISK = "disk"
+
+	WRITE_CACHE_BACK = "write back"
+
+	_dev_prefix      = "/dev/"
+	_partition       = "partition"
+	_queue           = "queue"
+	_scheduler       = "scheduler"
+	_rotational      = "rotational"
+	_nr_requests     = "nr_requests"
+	_read_ahead_kb   = "read_ahead_kb"
+	_write_cache     = "write_cache"
+	_scheduler_left  = "["
+	_scheduler_right = "]"
+
+	MOUNT_OPTION_NOATIME   = "noatime"
+	MOUNT_OPTION_NOBARRIER = "nobarrier"
+	MOUNT_OPTION_BARRIER_0 = "barrier=0"
+)
+
+type Queue struct {
+	Scheduler   string
+	Rotational  bool
+	NrRequests  int
+	ReadAheadKB int
+	WriteCache  string
+}
+
+// KName returns the kernel name of the device, such as 'dm-0' of '/dev/mapper/vg-lv',
+// an empty string is returned if the device is not a block device under /dev.
+func KName(device string) string {
+	if !strings.HasPrefix(device, _dev_prefix) {
+		return ""
+	}
+	if real, err := filepath.EvalSymlinks(device); err == nil {
+		device = real
+	}
+	return path.Base(device)
+}
+
+// Resolve returns the physical disks under the device with kernel name kname, the layers of device mapper
+// such as LVM and multipath are walked through by the parent names of lsblk, and partitions are mapped to
+// their disks by sysfs since lsblk output of partitions is ignored by osutil.Lsblk.
+func Resolve(sysClassBlock string, kname string, devices []*osutil.Device) []string {
+	res := make(map[string]struct{})
+	resolve(sysClassBlock, kname, devices, res, make(map[string]struct{}))
+	disks := make([]string, 0, len(res))
+	for disk := range res {
+		disks = append(disks, disk)
+	}
+	sort.Strings(disks)
+	return disks
+}
+
+func resolve(sysClassBlock string, kname string, devices []*osutil.Device, res map[string]struct{}, visited map[string]struct{}) {
+	if _, ok := visited[kname]; ok {
+		return
+	}
+	visited[kname] = struct{}{}
+	if parent, ok := PartitionParent(sysClassBlock, kname); ok {
+		resolve(sysClassBlock, parent, devices, res, visited)
+		return
+	}
+	found := false
+	for _, device := range devices {
+		if device.KName != kname {
+			continue
+		}
+		found = true
+		if device.Type == TYPE_DISK || len(device.Pkname) == 0 {
+			res[kname] = struct{}{}
+			continue
+		}
+		// a multipath device has one line for each path
+		resolve(sysClassBlock, device.Pkname, devices, res, visited)
+	}
+	if !found {
+		res[kname] = struct{}{}
+	}
+}
+
+// PartitionParent returns the disk of the partition, the sysfs directory of a partition is under its disk.
+func PartitionParent(sysClassBlock string, kname string) (string, bool) {
+	dir := path.Join(sysClassBlock, kname)
+	if _, err := os.Stat(path.Join(dir, _partition)); err != nil {
+		return "", false
+	}
+	real, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		return "", false
+	}
+	return path.Base(path.Dir(real)), true
+}
+
+// ReadQueue reads the queue settings of the device with kernel name kname.
+func ReadQueue(sysClassBlock string, kname string) (*Queue, error) {
+	dir := path.Join(sysClassBlock, kname, _queue)
+	queue := &Queue{}
+	scheduler, err := readTrim(path.Join(dir, _scheduler))
+	if err != nil {
+		return nil, err
+	}
+	queue.Scheduler = ParseScheduler(scheduler)
+	rotational, err := readInt(path.Join(dir, _rotational))
+	if err != nil {
+		return nil, err
+	}
+	queue.Rotational = rotational == 1
+	if queue.NrRequests, err = readInt(path.Join(dir, _nr_requests)); err != nil {
+		return nil, err
+	}
+	if queue.ReadAheadKB, err = readInt(path.Join(dir, _read_ahead_kb)); err != nil {
+		return nil, err
+	}
+	// write_cache does not exist in old kernels
+	queue.WriteCache, _ = readTrim(path.Join(dir, _write_cache))
+	return queue, nil
+}
+
+// ParseScheduler returns the selected scheduler, such as 'mq-deadline' of '[mq-deadline] kyber bfq none'.
+func ParseScheduler(scheduler string) string {
+	start := strings.Index(scheduler, _scheduler_left)
+	end := strings.Index(scheduler, _scheduler_right)
+	if start < 0 || end < start {
+		return strings.TrimSpace(scheduler)
+	}
+	return scheduler[start+1 : end]
+}
+
+// HasMountOption checks if the comma separated mount options contain the option.
+func HasMountOption(options string, option string) bool {
+	for _, o := range strings.Split(options, ",") {
+		if strings.TrimSpace(o) == option {
+			return true
+		}
+	}
+	return false
+}
+
+// BarrierEnabled returns false if write barriers are disabled by the mount options.
+func BarrierEnabled(options string) bool {
+	return !HasMountOption(options, MOUNT_OPTION_NOBARRIER) && !HasMountOption(options, MOUNT_OPTION_BARRIER_0)
+}
+
+func readTrim(fname string) (string, error) {
+	content, err := os.ReadFile(fname)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(content)), nil
+}
+
+func readInt(fname string) (int, error) {
+	content, err := readTrim(fname)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(content)
+}
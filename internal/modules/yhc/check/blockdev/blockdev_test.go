@@ -0,0 +1,91 @@
+package blockdev_test
+
+import (
+	"os"
+	"path"
+	"reflect"
+	"testing"
+
+	"yhc/internal/modules/yhc/check/blockdev"
+	"yhc/utils/osutil"
+)
+
+func writeFile(t *testing.T, fname, content string) {
+	if err := os.MkdirAll(path.Dir(fname), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(fname, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// newSysClassBlock links the devices like /sys/class/block, where partitions are under their disks.
+func newSysClassBlock(t *testing.T) string {
+	root := t.TempDir()
+	devices := path.Join(root, "devices")
+	classBlock := path.Join(root, "class")
+	for _, dir := range []string{"sda", "sda/sda2", "sdb", "sdc", "dm-0", "dm-1"} {
+		if err := os.MkdirAll(path.Join(devices, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeFile(t, path.Join(devices, "sda/sda2/partition"), "2\n")
+	writeFile(t, path.Join(devices, "sda/queue/scheduler"), "noop [deadline] cfq\n")
+	writeFile(t, path.Join(devices, "sda/queue/rotational"), "1\n")
+	writeFile(t, path.Join(devices, "sda/queue/nr_requests"), "128\n")
+	writeFile(t, path.Join(devices, "sda/queue/read_ahead_kb"), "4096\n")
+	if err := os.MkdirAll(classBlock, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, dir := range []string{"sda", "sda/sda2", "sdb", "sdc", "dm-0", "dm-1"} {
+		if err := os.Symlink(path.Join(devices, dir), path.Join(classBlock, path.Base(dir))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return classBlock
+}
+
+func TestResolve(t *testing.T) {
+	sysClassBlock := newSysClassBlock(t)
+	devices := []*osutil.Device{
+		{Name: "sda", KName: "sda", Type: "disk"},
+		{Name: "vg-lv", KName: "dm-0", Type: "lvm", Pkname: "sda2"},
+		{Name: "sdb", KName: "sdb", Type: "disk"},
+		{Name: "sdc", KName: "sdc", Type: "disk"},
+		{Name: "mpatha", KName: "dm-1", Type: "mpath", Pkname: "sdb"},
+		{Name: "mpatha", KName: "dm-1", Type: "mpath", Pkname: "sdc"},
+	}
+	cases := map[string][]string{
+		"sda2": {"sda"},
+		"dm-0": {"sda"},
+		"dm-1": {"sdb", "sdc"},
+		"sdb":  {"sdb"},
+	}
+	for kname, expected := range cases {
+		if disks := blockdev.Resolve(sysClassBlock, kname, devices); !reflect.DeepEqual(disks, expected) {
+			t.Errorf("resolve %s: expected %v, got %v", kname, expected, disks)
+		}
+	}
+}
+
+func TestReadQueue(t *testing.T) {
+	sysClassBlock := newSysClassBlock(t)
+	queue, err := blockdev.ReadQueue(sysClassBlock, "sda")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := &blockdev.Queue{Scheduler: "deadline", Rotational: true, NrRequests: 128, ReadAheadKB: 4096}
+	if !reflect.DeepEqual(queue, expected) {
+		t.Fatalf("expected %+v, got %+v", expected, queue)
+	}
+}
+
+func TestMountOptions(t *testing.T) {
+	options := "rw,noatime,attr2,inode64,nobarrier"
+	if !blockdev.HasMountOption(options, blockdev.MOUNT_OPTION_NOATIME) || blockdev.BarrierEnabled(options) {
+		t.Fatalf("unexpected result of %s", options)
+	}
+	if !blockdev.BarrierEnabled("rw,relatime,data=ordered") {
+		t.Fatal("barrier should be enabled by default")
+	}
+}
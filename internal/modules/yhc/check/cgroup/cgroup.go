@@ -0,0 +1,389 @@
+// The cgroup package reads the cgroup v1 and v2 limits and usages of a process,
+// and detects whether the process runs in a container.
+package cgroup
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	PROC          = "/proc"
+	SYS_FS_CGROUP = "/sys/fs/cgroup"
+
+	VERSION_V1 = "v1"
+	VERSION_V2 = "v2"
+
+	UNLIMITED int64 = -1
+
+	CONTROLLER_MEMORY  = "memory"
+	CONTROLLER_CPU     = "cpu"
+	CONTROLLER_CPUACCT = "cpuacct"
+	CONTROLLER_CPUSET  = "cpuset"
+	CONTROLLER_BLKIO   = "blkio"
+
+	RUNTIME_DOCKER     = "docker"
+	RUNTIME_PODMAN     = "podman"
+	RUNTIME_KUBERNETES = "kubernetes"
+	RUNTIME_CONTAINERD = "containerd"
+	RUNTIME_LXC        = "lxc"
+
+	_unified_controllers = "cgroup.controllers"
+	_env_container       = "container="
+	_v2_max              = "max"
+	// the memory limit of cgroup v1 is a page aligned max int64 when not set
+	_v1_unlimited_threshold = int64(1) << 62
+	// cpuacct.stat of cgroup v1 is in USER_HZ
+	_user_hz = 100
+)
+
+var ErrNotFound = errors.New("cgroup of process not found")
+
+// _runtimeKeywords detects the container runtime by the cgroup path, kubernetes goes first
+// because the pods are also started by docker or containerd.
+var _runtimeKeywords = []struct {
+	keyword string
+	runtime string
+}{
+	{"kubepods", RUNTIME_KUBERNETES},
+	{"libpod", RUNTIME_PODMAN},
+	{"docker", RUNTIME_DOCKER},
+	{"containerd", RUNTIME_CONTAINERD},
+	{"lxc", RUNTIME_LXC},
+}
+
+// Entry is a line of /proc/<pid>/cgroup, e.g. '4:memory:/system.slice/yasdb.service' or '0::/user.slice'.
+type Entry struct {
+	ID          string
+	Controllers []string
+	Path        string
+}
+
+type Limits struct {
+	Version     string
+	Path        string
+	MemoryLimit int64 // bytes, UNLIMITED if not set
+	MemoryUsage int64 // bytes
+	CPUQuota    int64 // microseconds, UNLIMITED if not set
+	CPUPeriod   int64 // microseconds
+	Cpuset      string
+	IOWeight    string
+	OOM         int64 // times of reaching the memory limit and OOM, cgroup v2 only
+	OOMKill     int64 // processes killed by OOM killer in the cgroup
+}
+
+// CPULimit returns the CPU cores limited by quota, 0 if not limited.
+func (l *Limits) CPULimit() float64 {
+	if l.CPUQuota <= 0 || l.CPUPeriod <= 0 {
+		return 0
+	}
+	return float64(l.CPUQuota) / float64(l.CPUPeriod)
+}
+
+// Usage is the accumulated usage of cgroup at the moment.
+type Usage struct {
+	Time        time.Time
+	MemoryUsage int64  // bytes, page cache included
+	WorkingSet  int64  // bytes, usage without inactive page cache
+	CPUUsec     uint64 // microseconds
+	UserUsec    uint64 // microseconds
+	SystemUsec  uint64 // microseconds
+}
+
+type Cgroup struct {
+	Version string
+	Path    string
+	// dirs is the directory of each controller, the key is empty for cgroup v2
+	dirs map[string]string
+}
+
+func ParseProcCgroup(r io.Reader) ([]Entry, error) {
+	var entries []Entry
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.SplitN(strings.TrimSpace(scanner.Text()), ":", 3)
+		if len(fields) != 3 {
+			continue
+		}
+		entry := Entry{ID: fields[0], Path: fields[2]}
+		if len(fields[1]) != 0 {
+			entry.Controllers = strings.Split(fields[1], ",")
+		}
+		entries = append(entries, entry)
+	}
+	return entries, scanner.Err()
+}
+
+// Open finds the cgroup of the process, procRoot and cgroupRoot are /proc and /sys/fs/cgroup except in tests.
+func Open(procRoot, cgroupRoot string, pid int) (*Cgroup, error) {
+	f, err := os.Open(path.Join(procRoot, strconv.Itoa(pid), "cgroup"))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	entries, err := ParseProcCgroup(f)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(path.Join(cgroupRoot, _unified_controllers)); err == nil {
+		for _, entry := range entries {
+			if entry.ID == "0" && len(entry.Controllers) == 0 {
+				return &Cgroup{
+					Version: VERSION_V2,
+					Path:    entry.Path,
+					dirs:    map[string]string{"": resolveDir(cgroupRoot, entry.Path)},
+				}, nil
+			}
+		}
+		return nil, ErrNotFound
+	}
+	cg := &Cgroup{Version: VERSION_V1, dirs: make(map[string]string)}
+	for _, entry := range entries {
+		for _, controller := range entry.Controllers {
+			cg.dirs[controller] = resolveDir(path.Join(cgroupRoot, controller), entry.Path)
+			if controller == CONTROLLER_MEMORY {
+				cg.Path = entry.Path
+			}
+		}
+	}
+	if len(cg.dirs) == 0 {
+		return nil, ErrNotFound
+	}
+	return cg, nil
+}
+
+// resolveDir returns the directory of the cgroup path under the mount point, in a container
+// with cgroup namespace the cgroup of process is mounted at the mount point itself.
+func resolveDir(mount, cgroupPath string) string {
+	dir := path.Join(mount, cgroupPath)
+	if _, err := os.Stat(dir); err == nil {
+		return dir
+	}
+	return mount
+}
+
+func (c *Cgroup) Limits() *Limits {
+	if c.Version == VERSION_V2 {
+		return c.limitsV2()
+	}
+	return c.limitsV1()
+}
+
+func (c *Cgroup) limitsV2() *Limits {
+	dir := c.dirs[""]
+	limits := &Limits{Version: c.Version, Path: c.Path, MemoryLimit: UNLIMITED, CPUQuota: UNLIMITED}
+	if value, err := readString(dir, "memory.max"); err == nil {
+		limits.MemoryLimit = parseV2Max(value)
+	}
+	if value, err := readInt(dir, "memory.current"); err == nil {
+		limits.MemoryUsage = value
+	}
+	// cpu.max: '$MAX $PERIOD', e.g. 'max 100000' or '200000 100000'
+	if value, err := readString(dir, "cpu.max"); err == nil {
+		fields := strings.Fields(value)
+		if len(fields) == 2 {
+			limits.CPUQuota = parseV2Max(fields[0])
+			limits.CPUPeriod, _ = strconv.ParseInt(fields[1], 10, 64)
+		}
+	}
+	limits.Cpuset, _ = readString(dir, "cpuset.cpus.effective")
+	limits.IOWeight = readIOWeight(dir, "io.weight", "io.bfq.weight")
+	if events, err := readKeyValues(dir, "memory.events"); err == nil {
+		limits.OOM, limits.OOMKill = events["oom"], events["oom_kill"]
+	}
+	return limits
+}
+
+func (c *Cgroup) limitsV1() *Limits {
+	limits := &Limits{Version: c.Version, Path: c.Path, MemoryLimit: UNLIMITED, CPUQuota: UNLIMITED}
+	if dir, ok := c.dirs[CONTROLLER_MEMORY]; ok {
+		if value, err := readInt(dir, "memory.limit_in_bytes"); err == nil && value < _v1_unlimited_threshold {
+			limits.MemoryLimit = value
+		}
+		if value, err := readInt(dir, "memory.usage_in_bytes"); err == nil {
+			limits.MemoryUsage = value
+		}
+		// oom_kill is provided since linux 4.13
+		if control, err := readKeyValues(dir, "memory.oom_control"); err == nil {
+			limits.OOMKill = control["oom_kill"]
+		}
+	}
+	if dir, ok := c.dirs[CONTROLLER_CPU]; ok {
+		if value, err := readInt(dir, "cpu.cfs_quota_us"); err == nil && value > 0 {
+			limits.CPUQuota = value
+		}
+		limits.CPUPeriod, _ = readInt(dir, "cpu.cfs_period_us")
+	}
+	if dir, ok := c.dirs[CONTROLLER_CPUSET]; ok {
+		if value, err := readString(dir, "cpuset.effective_cpus"); err == nil {
+			limits.Cpuset = value
+		} else {
+			limits.Cpuset, _ = readString(dir, "cpuset.cpus")
+		}
+	}
+	if dir, ok := c.dirs[CONTROLLER_BLKIO]; ok {
+		limits.IOWeight = readIOWeight(dir, "blkio.weight", "blkio.bfq.weight")
+	}
+	return limits
+}
+
+func (c *Cgroup) Usage() (*Usage, error) {
+	usage := &Usage{Time: time.Now()}
+	if c.Version == VERSION_V2 {
+		dir := c.dirs[""]
+		current, err := readInt(dir, "memory.current")
+		if err != nil {
+			return nil, err
+		}
+		usage.MemoryUsage = current
+		usage.WorkingSet = workingSet(current, dir, "inactive_file")
+		stat, err := readKeyValues(dir, "cpu.stat")
+		if err != nil {
+			return nil, err
+		}
+		usage.CPUUsec, usage.UserUsec, usage.SystemUsec = uint64(stat["usage_usec"]), uint64(stat["user_usec"]), uint64(stat["system_usec"])
+		return usage, nil
+	}
+	if dir, ok := c.dirs[CONTROLLER_MEMORY]; ok {
+		current, err := readInt(dir, "memory.usage_in_bytes")
+		if err != nil {
+			return nil, err
+		}
+		usage.MemoryUsage = current
+		usage.WorkingSet = workingSet(current, dir, "total_inactive_file")
+	}
+	if dir, ok := c.dirs[CONTROLLER_CPUACCT]; ok {
+		nanoseconds, err := readInt(dir, "cpuacct.usage")
+		if err != nil {
+			return nil, err
+		}
+		usage.CPUUsec = uint64(nanoseconds / 1000)
+		if stat, err := readKeyValues(dir, "cpuacct.stat"); err == nil {
+			usage.UserUsec = uint64(stat["user"]) * 1000000 / _user_hz
+			usage.SystemUsec = uint64(stat["system"]) * 1000000 / _user_hz
+		}
+	}
+	return usage, nil
+}
+
+// Runtime returns the container runtime by the cgroup path, empty if the path is not of a container.
+func Runtime(cgroupPath string) string {
+	for _, item := range _runtimeKeywords {
+		if strings.Contains(cgroupPath, item.keyword) {
+			return item.runtime
+		}
+	}
+	return ""
+}
+
+// DetectContainer returns the container runtime if the current process runs in a container, root is '/' except in tests.
+func DetectContainer(root string) string {
+	if _, err := os.Stat(path.Join(root, ".dockerenv")); err == nil {
+		return RUNTIME_DOCKER
+	}
+	if _, err := os.Stat(path.Join(root, "run/.containerenv")); err == nil {
+		return RUNTIME_PODMAN
+	}
+	if environ, err := os.ReadFile(path.Join(root, PROC, "1/environ")); err == nil {
+		for _, env := range strings.Split(string(environ), "\x00") {
+			if strings.HasPrefix(env, _env_container) && len(env) > len(_env_container) {
+				return strings.TrimPrefix(env, _env_container)
+			}
+		}
+	}
+	if f, err := os.Open(path.Join(root, PROC, "1/cgroup")); err == nil {
+		defer f.Close()
+		entries, _ := ParseProcCgroup(f)
+		for _, entry := range entries {
+			if runtime := Runtime(entry.Path); len(runtime) != 0 {
+				return runtime
+			}
+		}
+	}
+	return ""
+}
+
+func workingSet(usage int64, dir, inactiveKey string) int64 {
+	stat, err := readKeyValues(dir, "memory.stat")
+	if err != nil || stat[inactiveKey] > usage {
+		return usage
+	}
+	return usage - stat[inactiveKey]
+}
+
+func parseV2Max(value string) int64 {
+	if value == _v2_max {
+		return UNLIMITED
+	}
+	i, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return UNLIMITED
+	}
+	return i
+}
+
+// readIOWeight returns the default weight of the first existing file, e.g. 'default 100' or '500'.
+func readIOWeight(dir string, names ...string) string {
+	for _, name := range names {
+		value, err := readString(dir, name)
+		if err != nil || len(value) == 0 {
+			continue
+		}
+		for _, line := range strings.Split(value, "\n") {
+			fields := strings.Fields(line)
+			if len(fields) == 1 {
+				return fields[0]
+			}
+			if len(fields) == 2 && fields[0] == "default" {
+				return fields[1]
+			}
+		}
+	}
+	return ""
+}
+
+func readString(dir, name string) (string, error) {
+	bytes, err := os.ReadFile(path.Join(dir, name))
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(bytes)), nil
+}
+
+func readInt(dir, name string) (int64, error) {
+	value, err := readString(dir, name)
+	if err != nil {
+		return 0, err
+	}
+	i, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("parse %s of %s err: %s", name, dir, err.Error())
+	}
+	return i, nil
+}
+
+// readKeyValues reads the flat keyed file, e.g. memory.events, memory.stat and cpu.stat.
+func readKeyValues(dir, name string) (map[string]int64, error) {
+	value, err := readString(dir, name)
+	if err != nil {
+		return nil, err
+	}
+	res := make(map[string]int64)
+	for _, line := range strings.Split(value, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+		if i, err := strconv.ParseInt(fields[1], 10, 64); err == nil {
+			res[fields[0]] = i
+		}
+	}
+	return res, nil
+}
@@ -0,0 +1,117 @@
+package cgroup_test
+
+import (
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"yhc/internal/modules/yhc/check/cgroup"
+)
+
+func writeFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		p := path.Join(root, name)
+		if err := os.MkdirAll(path.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestParseProcCgroup(t *testing.T) {
+	entries, err := cgroup.ParseProcCgroup(strings.NewReader("12:cpu,cpuacct:/docker/abc\n1:name=systemd:/docker/abc\n0::/\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 3 || len(entries[0].Controllers) != 2 || entries[0].Path != "/docker/abc" || len(entries[2].Controllers) != 0 {
+		t.Fatalf("unexpected entries: %+v", entries)
+	}
+}
+
+func TestV2(t *testing.T) {
+	root := t.TempDir()
+	proc, sys := path.Join(root, "proc"), path.Join(root, "sys")
+	writeFiles(t, root, map[string]string{
+		"proc/100/cgroup":                                      "0::/system.slice/yasdb.service\n",
+		"sys/cgroup.controllers":                               "cpuset cpu io memory\n",
+		"sys/system.slice/yasdb.service/memory.max":            "8589934592\n",
+		"sys/system.slice/yasdb.service/memory.current":        "4294967296\n",
+		"sys/system.slice/yasdb.service/memory.stat":           "anon 3221225472\ninactive_file 1073741824\n",
+		"sys/system.slice/yasdb.service/memory.events":         "low 0\nhigh 0\nmax 12\noom 2\noom_kill 1\n",
+		"sys/system.slice/yasdb.service/cpu.max":               "200000 100000\n",
+		"sys/system.slice/yasdb.service/cpu.stat":              "usage_usec 5000000\nuser_usec 4000000\nsystem_usec 1000000\n",
+		"sys/system.slice/yasdb.service/cpuset.cpus.effective": "0-3\n",
+		"sys/system.slice/yasdb.service/io.weight":             "default 100\n8:0 200\n",
+	})
+	cg, err := cgroup.Open(proc, sys, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	limits := cg.Limits()
+	if limits.Version != cgroup.VERSION_V2 || limits.Path != "/system.slice/yasdb.service" || limits.MemoryLimit != 8589934592 ||
+		limits.CPULimit() != 2 || limits.Cpuset != "0-3" || limits.IOWeight != "100" || limits.OOM != 2 || limits.OOMKill != 1 {
+		t.Fatalf("unexpected limits: %+v", limits)
+	}
+	usage, err := cg.Usage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if usage.WorkingSet != 3221225472 || usage.CPUUsec != 5000000 || usage.UserUsec != 4000000 {
+		t.Fatalf("unexpected usage: %+v", usage)
+	}
+}
+
+func TestV1(t *testing.T) {
+	root := t.TempDir()
+	proc, sys := path.Join(root, "proc"), path.Join(root, "sys")
+	writeFiles(t, root, map[string]string{
+		"proc/100/cgroup": "4:memory:/docker/abc\n3:cpu,cpuacct:/docker/abc\n2:cpuset:/docker/abc\n1:name=systemd:/docker/abc\n",
+		"sys/memory/docker/abc/memory.limit_in_bytes": "9223372036854771712\n",
+		"sys/memory/docker/abc/memory.usage_in_bytes": "1048576\n",
+		"sys/memory/docker/abc/memory.oom_control":    "oom_kill_disable 0\nunder_oom 0\noom_kill 3\n",
+		"sys/cpu/docker/abc/cpu.cfs_quota_us":         "-1\n",
+		"sys/cpu/docker/abc/cpu.cfs_period_us":        "100000\n",
+		"sys/cpuacct/docker/abc/cpuacct.usage":        "2000000000\n",
+		"sys/cpuacct/docker/abc/cpuacct.stat":         "user 150\nsystem 50\n",
+		"sys/cpuset/docker/abc/cpuset.cpus":           "0,2\n",
+	})
+	cg, err := cgroup.Open(proc, sys, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	limits := cg.Limits()
+	if limits.Version != cgroup.VERSION_V1 || limits.MemoryLimit != cgroup.UNLIMITED || limits.MemoryUsage != 1048576 ||
+		limits.CPULimit() != 0 || limits.Cpuset != "0,2" || limits.OOMKill != 3 {
+		t.Fatalf("unexpected limits: %+v", limits)
+	}
+	usage, err := cg.Usage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if usage.CPUUsec != 2000000 || usage.UserUsec != 1500000 || usage.SystemUsec != 500000 {
+		t.Fatalf("unexpected usage: %+v", usage)
+	}
+	if runtime := cgroup.Runtime(limits.Path); runtime != cgroup.RUNTIME_DOCKER {
+		t.Fatalf("unexpected runtime: %s", runtime)
+	}
+}
+
+func TestDetectContainer(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{"proc/1/cgroup": "0::/init.scope\n"})
+	if runtime := cgroup.DetectContainer(root); runtime != "" {
+		t.Fatalf("unexpected runtime: %s", runtime)
+	}
+	writeFiles(t, root, map[string]string{"proc/1/cgroup": "0::/kubepods/burstable/pod1/abc\n"})
+	if runtime := cgroup.DetectContainer(root); runtime != cgroup.RUNTIME_KUBERNETES {
+		t.Fatalf("unexpected runtime: %s", runtime)
+	}
+	writeFiles(t, root, map[string]string{".dockerenv": ""})
+	if runtime := cgroup.DetectContainer(root); runtime != cgroup.RUNTIME_DOCKER {
+		t.Fatalf("unexpected runtime: %s", runtime)
+	}
+}
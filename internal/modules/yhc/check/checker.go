@@ -3,6 +3,7 @@ package check
 import (
 	"bufio"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path"
@@ -15,11 +16,15 @@ import (
 	"yhc/defs/confdef"
 	"yhc/defs/timedef"
 	"yhc/internal/modules/yhc/check/alertgenner"
+	"yhc/internal/modules/yhc/check/cgroup"
 	"yhc/internal/modules/yhc/check/define"
 	"yhc/internal/modules/yhc/check/evaluator"
 	"yhc/internal/modules/yhc/check/gopsutil"
 	"yhc/internal/modules/yhc/check/jsonparser"
+	"yhc/internal/modules/yhc/check/procstat"
+	"yhc/internal/modules/yhc/check/safile"
 	"yhc/internal/modules/yhc/check/sar"
+	"yhc/internal/modules/yhc/sampler"
 	"yhc/log"
 	"yhc/utils/stringutil"
 	"yhc/utils/yasdbutil"
@@ -43,14 +48,26 @@ var CheckMutipleNodes = false
 var _envs = []string{"LANG=en_US.UTF-8", "LC_TIME=en_US.UTF-8"}
 
 var MetricNameToWorkloadTypeMap = map[define.MetricName]define.WorkloadType{
-	define.METRIC_HOST_HISTORY_CPU_USAGE:    define.WT_CPU,
-	define.METRIC_HOST_CURRENT_CPU_USAGE:    define.WT_CPU,
-	define.METRIC_HOST_CURRENT_DISK_IO:      define.WT_DISK,
-	define.METRIC_HOST_HISTORY_DISK_IO:      define.WT_DISK,
-	define.METRIC_HOST_CURRENT_MEMORY_USAGE: define.WT_MEMORY,
-	define.METRIC_HOST_HISTORY_MEMORY_USAGE: define.WT_MEMORY,
-	define.METRIC_HOST_CURRENT_NETWORK_IO:   define.WT_NETWORK,
-	define.METRIC_HOST_HISTORY_NETWORK_IO:   define.WT_NETWORK,
+	define.METRIC_HOST_HISTORY_CPU_USAGE:      define.WT_CPU,
+	define.METRIC_HOST_CURRENT_CPU_USAGE:      define.WT_CPU,
+	define.METRIC_HOST_HISTORY_CPU_CORE_USAGE: define.WT_CPU_CORE,
+	define.METRIC_HOST_CURRENT_CPU_CORE_USAGE: define.WT_CPU_CORE,
+	define.METRIC_HOST_CURRENT_DISK_IO:        define.WT_DISK,
+	define.METRIC_HOST_HISTORY_DISK_IO:        define.WT_DISK,
+	define.METRIC_HOST_CURRENT_MEMORY_USAGE:   define.WT_MEMORY,
+	define.METRIC_HOST_HISTORY_MEMORY_USAGE:   define.WT_MEMORY,
+	define.METRIC_HOST_CURRENT_NETWORK_IO:     define.WT_NETWORK,
+	define.METRIC_HOST_HISTORY_NETWORK_IO:     define.WT_NETWORK,
+	define.METRIC_HOST_HISTORY_LOAD_AVERAGE:   define.WT_LOAD,
+	define.METRIC_HOST_CURRENT_LOAD_AVERAGE:   define.WT_LOAD,
+	define.METRIC_HOST_HISTORY_PAGING:         define.WT_PAGING,
+	define.METRIC_HOST_CURRENT_PAGING:         define.WT_PAGING,
+	define.METRIC_HOST_HISTORY_CONTEXT_SWITCH: define.WT_TASK,
+	define.METRIC_HOST_CURRENT_CONTEXT_SWITCH: define.WT_TASK,
+	define.METRIC_HOST_HISTORY_SWAPPING:       define.WT_SWAP,
+	define.METRIC_HOST_CURRENT_SWAPPING:       define.WT_SWAP,
+	define.METRIC_HOST_HISTORY_NETWORK_EDEV:   define.WT_NETWORK_ERROR,
+	define.METRIC_HOST_CURRENT_NETWORK_EDEV:   define.WT_NETWORK_ERROR,
 }
 
 var SQLMap = map[define.MetricName]string{
@@ -79,6 +96,9 @@ var SQLMap = map[define.MetricName]string{
 	define.METRIC_YASDB_LISTEN_ADDR:                                                            define.SQL_QUERY_LISTEN_ADDR,
 	define.METRIC_YASDB_TABLESPACE:                                                             define.SQL_QUERY_TABLESPACE,
 	define.METRIC_YASDB_WAIT_EVENT:                                                             define.SQL_QUERY_WAIT_EVENT,
+	define.METRIC_YASDB_CURRENT_WAIT_EVENT:                                                     define.SQL_QUERY_SYSTEM_EVENT,
+	define.METRIC_YASDB_CURRENT_WAIT_CLASS:                                                     define.SQL_QUERY_SYSTEM_EVENT,
+	define.METRIC_YASDB_SESSION_WAIT:                                                           define.SQL_QUERY_SESSION_WAIT,
 	define.METRIC_YASDB_ARCHIVE_DEST_STATUS:                                                    define.SQL_QUERY_ARCHIVE_DEST_STATUS,
 	define.METRIC_YASDB_ARCHIVE_LOG_SPACE:                                                      define.SQL_QUERY_ARCHIVE_LOG_SPACE,
 	define.METRIC_YASDB_ARCHIVE_LOG:                                                            define.SQL_QUERY_ARCHIVE_LOG,
@@ -101,6 +121,7 @@ var SQLMap = map[define.MetricName]string{
 	define.METRIC_YASDB_HIGH_FREQUENCY_SQL:                                                     define.SQL_QUERY_HIGH_FREQUENCY_SQL,
 	define.METRIC_YASDB_BUFFER_HIT_RATE:                                                        define.SQL_QUERY_BUFFER_HIT_RATE,
 	define.METRIC_YASDB_TABLE_LOCK_WAIT:                                                        define.SQL_QUERY_TABLE_LOCK_WAIT,
+	define.METRIC_YASDB_BLOCKING_CHAIN:                                                         define.SQL_QUERY_LOCK_WAITS,
 	define.METRIC_YASDB_ROW_LOCK_WAIT:                                                          define.SQL_QUERY_ROW_LOCK_WAIT,
 	define.METRIC_YASDB_LONG_RUNNING_TRANSACTION:                                               define.SQL_QUERY_LONG_RUNNING_TRANSACTION,
 	define.METRIC_YASDB_INVALID_OBJECT:                                                         define.SQL_QUERY_INVALID_OBJECT,
@@ -123,6 +144,7 @@ type logPredicate func(line string) bool
 type Checker interface {
 	CheckFuncs(metrics []*confdef.YHCMetric) map[string]func(string) error
 	GetResult(startCheck, endCheck time.Time) (map[define.MetricName][]*define.YHCItem, *define.PandoraReport, map[define.MetricName][]*define.YHCItem)
+	GetEvaluateResult() *define.EvaluateResult
 }
 
 type YHCChecker struct {
@@ -132,6 +154,22 @@ type YHCChecker struct {
 	Result         map[define.MetricName][]*define.YHCItem
 	evaluateResult *define.EvaluateResult
 	FailedItem     map[define.MetricName][]*define.YHCItem
+	// the network statistics are sampled once and shared by the network health metrics
+	networkSampleOnce sync.Once
+	networkSample     *networkSample
+	networkSampleErr  error
+	// the cgroup of yasdb process is found once and shared by the host metrics
+	yasdbCgroupOnce sync.Once
+	yasdbPid        int
+	yasdbCgroup     *cgroup.Cgroup
+	yasdbCgroupErr  error
+	// the processes are sampled once and shared by the process usage metrics
+	processSampleOnce sync.Once
+	processSamples    []*procstat.Sample
+	processSampleErr  error
+	// the wait events of the nodes are sampled once and shared by the current wait metrics
+	waitEventSampleOnce sync.Once
+	waitEventSamples    map[string]*waitEventSample
 }
 
 func NewYHCChecker(base *define.CheckerBase, metrics []*confdef.YHCMetric) *YHCChecker {
@@ -151,6 +189,11 @@ func (c *YHCChecker) GetResult(startCheck, endCheck time.Time) (map[define.Metri
 	return c.Result, c.genReportJson(startCheck, endCheck), c.FailedItem
 }
 
+// [Interface Func]
+func (c *YHCChecker) GetEvaluateResult() *define.EvaluateResult {
+	return c.evaluateResult
+}
+
 func (c *YHCChecker) genReportJson(startCheck, endCheck time.Time) *define.PandoraReport {
 	log := log.Module.M("gen-report-json")
 	parser := jsonparser.NewJsonParser(log, *c.base, startCheck, endCheck, c.metrics, c.Result, c.evaluateResult)
@@ -225,11 +268,23 @@ func (c *YHCChecker) funcMap() (res map[define.MetricName]func(string) error) {
 		define.METRIC_HOST_NETWORK_INFO:                                                            c.GetHostNetworkInfo,
 		define.METRIC_HOST_HISTORY_CPU_USAGE:                                                       c.GetHostHistoryCPUUsage,
 		define.METRIC_HOST_CURRENT_CPU_USAGE:                                                       c.GetHostCurrentCPUUsage,
+		define.METRIC_HOST_HISTORY_CPU_CORE_USAGE:                                                  c.GetHostHistoryCPUCoreUsage,
+		define.METRIC_HOST_CURRENT_CPU_CORE_USAGE:                                                  c.GetHostCurrentCPUCoreUsage,
 		define.METRIC_HOST_CURRENT_DISK_IO:                                                         c.GetHostCurrentDiskIO,
 		define.METRIC_HOST_HISTORY_DISK_IO:                                                         c.GetHostHistoryDiskIO,
 		define.METRIC_HOST_CURRENT_MEMORY_USAGE:                                                    c.GetHostCurrentMemoryUsage,
 		define.METRIC_HOST_HISTORY_MEMORY_USAGE:                                                    c.GetHostHistoryMemoryUsage,
 		define.METRIC_HOST_CURRENT_NETWORK_IO:                                                      c.GetHostCurrentNetworkIO,
+		define.METRIC_HOST_HISTORY_LOAD_AVERAGE:                                                    c.GetHostHistoryLoadAverage,
+		define.METRIC_HOST_CURRENT_LOAD_AVERAGE:                                                    c.GetHostCurrentLoadAverage,
+		define.METRIC_HOST_HISTORY_PAGING:                                                          c.GetHostHistoryPaging,
+		define.METRIC_HOST_CURRENT_PAGING:                                                          c.GetHostCurrentPaging,
+		define.METRIC_HOST_HISTORY_CONTEXT_SWITCH:                                                  c.GetHostHistoryContextSwitch,
+		define.METRIC_HOST_CURRENT_CONTEXT_SWITCH:                                                  c.GetHostCurrentContextSwitch,
+		define.METRIC_HOST_HISTORY_SWAPPING:                                                        c.GetHostHistorySwapping,
+		define.METRIC_HOST_CURRENT_SWAPPING:                                                        c.GetHostCurrentSwapping,
+		define.METRIC_HOST_HISTORY_NETWORK_EDEV:                                                    c.GetHostHistoryNetworkEdev,
+		define.METRIC_HOST_CURRENT_NETWORK_EDEV:                                                    c.GetHostCurrentNetworkEdev,
 		define.METRIC_HOST_HISTORY_NETWORK_IO:                                                      c.GetHostHistoryNetworkIO,
 		define.METRIC_YASDB_CONTROLFILE:                                                            c.GetNodesMultiRowData,
 		define.METRIC_YASDB_CONTROLFILE_COUNT:                                                      c.GetPrimarySingleRowData,
@@ -258,6 +313,11 @@ func (c *YHCChecker) funcMap() (res map[define.MetricName]func(string) error) {
 		define.METRIC_YASDB_SESSION:                                                                c.GetNodesSingleRowData,
 		define.METRIC_YASDB_TABLESPACE:                                                             c.GetNodesMultiRowData,
 		define.METRIC_YASDB_WAIT_EVENT:                                                             c.GetYasdbWaitEvent,
+		define.METRIC_YASDB_CURRENT_WAIT_EVENT:                                                     c.GetYasdbCurrentWaitEvent,
+		define.METRIC_YASDB_CURRENT_WAIT_CLASS:                                                     c.GetYasdbCurrentWaitClass,
+		define.METRIC_YASDB_HISTORY_WAIT_EVENT:                                                     c.GetYasdbHistoryWaitEvent,
+		define.METRIC_YASDB_HISTORY_WAIT_CLASS:                                                     c.GetYasdbHistoryWaitClass,
+		define.METRIC_YASDB_SESSION_WAIT:                                                           c.GetNodesMultiRowData,
 		define.METRIC_YASDB_INDEX_TABLE_INDEX_NOT_TOGETHER:                                         c.GetNodesMultiRowData,
 		define.METRIC_YASDB_INDEX_OVERSIZED:                                                        c.GetNodesMultiRowData,
 		define.METRIC_YASDB_SEQUENCE_NO_AVAILABLE:                                                  c.GetNodesMultiRowData,
@@ -306,11 +366,33 @@ func (c *YHCChecker) funcMap() (res map[define.MetricName]func(string) error) {
 		define.METRIC_YASDB_TOP_SQL_BY_PARSE_CALLS:                                                 c.GetNodesMultiRowData,
 		define.METRIC_YASDB_HIGH_FREQUENCY_SQL:                                                     c.GetNodesMultiRowData,
 		define.METRIC_YASDB_HISTORY_DB_TIME:                                                        c.GetYasdbHistoryDBTime,
+		define.METRIC_YASDB_AWR_REPORT:                                                             c.GetYasdbAWRReport,
 		define.METRIC_YASDB_HISTORY_BUFFER_HIT_RATE:                                                c.GetYasdbHistoryBufferHitRate,
 		define.METRIC_HOST_HUGE_PAGE:                                                               c.GetHugePageEnabled,
 		define.METRIC_HOST_SWAP_MEMORY:                                                             c.GetSwapMemoryEnabled,
+		define.METRIC_HOST_SYSCTL:                                                                  c.GetHostSysctl,
+		define.METRIC_YASDB_PROCESS_LIMITS:                                                         c.GetYasdbProcessLimits,
+		define.METRIC_HOST_TIME_SYNC:                                                               c.GetHostTimeSync,
+		define.METRIC_YASDB_NODE_TIME_SKEW:                                                         c.GetYasdbNodeTimeSkew,
+		define.METRIC_HOST_NUMA:                                                                    c.GetHostNuma,
+		define.METRIC_HOST_CPU_GOVERNOR:                                                            c.GetHostCpuGovernor,
+		define.METRIC_YASDB_NUMA_BINDING:                                                           c.GetYasdbNumaBinding,
+		define.METRIC_HOST_YASDB_BLOCK_DEVICE:                                                      c.GetHostYasdbBlockDevice,
+		define.METRIC_HOST_NETWORK_ERRORS:                                                          c.GetHostNetworkErrors,
+		define.METRIC_HOST_TCP_HEALTH:                                                              c.GetHostTcpHealth,
+		define.METRIC_HOST_KERNEL_EVENTS:                                                           c.GetHostKernelEvents,
+		define.METRIC_HOST_CGROUP:                                                                  c.GetHostCgroup,
+		define.METRIC_HOST_SECURITY_MAC:                                                            c.GetHostSecurityMac,
+		define.METRIC_HOST_SSHD_CONFIG:                                                             c.GetHostSshdConfig,
+		define.METRIC_HOST_YASDB_HOME_FILES:                                                        c.GetHostYasdbHomeFiles,
+		define.METRIC_HOST_CORE_DUMP:                                                               c.GetHostCoreDump,
+		define.METRIC_HOST_YASDB_USER_PASSWORD:                                                     c.GetHostYasdbUserPassword,
+		define.METRIC_HOST_YASDB_USER_SUDO:                                                         c.GetHostYasdbUserSudo,
+		define.METRIC_HOST_PROCESS_USAGE:                                                           c.GetHostProcessUsage,
+		define.METRIC_HOST_CURRENT_PROCESS_USAGE:                                                   c.GetHostCurrentProcessUsage,
 		define.METRIC_YASDB_BUFFER_HIT_RATE:                                                        c.GetNodesSingleRowData,
 		define.METRIC_YASDB_TABLE_LOCK_WAIT:                                                        c.GetNodesSingleRowData,
+		define.METRIC_YASDB_BLOCKING_CHAIN:                                                         c.GetYasdbBlockingChain,
 		define.METRIC_YASDB_ROW_LOCK_WAIT:                                                          c.GetNodesSingleRowData,
 		define.METRIC_YASDB_LONG_RUNNING_TRANSACTION:                                               c.GetNodesMultiRowData,
 	}
@@ -413,10 +495,24 @@ func (c *YHCChecker) hostHistoryWorkload(log yaslog.YasLog, name define.MetricNa
 	if stringutil.IsEmpty(sarDir) {
 		sarDir = sarCollector.GetSarDir()
 	}
+	// the sa files are decoded natively if sar is not installed or fails, e.g. the files are written by a newer sysstat
+	hasSar := c.CheckSarAccess() == nil
 	sarOutput := make(define.WorkloadOutput)
-	args := c.genHistoryWorkloadArgs(c.base.Start, c.base.End, sarDir)
-	for _, arg := range args {
-		output, e := sarCollector.Collect(workloadType, sarArg, arg)
+	for _, file := range c.genHistoryWorkloadFiles(c.base.Start, c.base.End, sarDir) {
+		var output define.WorkloadOutput
+		var e error
+		if hasSar {
+			if output, e = sarCollector.Collect(workloadType, sarArg, file.arg()); e != nil {
+				log.Warnf("failed to read %s by sar, decode it natively, err: %v", file.path, e)
+			}
+		}
+		if !hasSar || e != nil {
+			output, e = sarCollector.CollectFile(workloadType, file.path, file.start, file.end)
+		}
+		if errors.Is(e, safile.ErrUnsupportedVersion) {
+			log.Errorf("%s is written by a sysstat older than the native decoder supports, install the sysstat which wrote it to read it by sar, err: %v", file.path, e)
+			continue
+		}
 		if e != nil {
 			log.Error(e)
 			continue
@@ -425,11 +521,35 @@ func (c *YHCChecker) hostHistoryWorkload(log yaslog.YasLog, name define.MetricNa
 			sarOutput[timestamp] = output
 		}
 	}
+	if len(sarOutput) == 0 && sampler.IsSampled(workloadType) {
+		// the hosts without sysstat use the samples of yhcd
+		log.Infof("no sar data of %s, read the samples of yhcd", workloadType)
+		conf := confdef.GetYHCConf()
+		return sampler.NewStore(conf.GetSamplerDir(), conf.GetSamplerRetention()).Read(workloadType, c.base.Start, c.base.End)
+	}
 	resp = sarOutput
 	return
 }
 
-func (c *YHCChecker) genHistoryWorkloadArgs(start, end time.Time, sarDir string) (args []string) {
+// historyWorkloadFile is a daily sa file and the time range to read, the zero start or end means the whole day.
+type historyWorkloadFile struct {
+	path       string
+	start, end time.Time
+}
+
+// arg returns the args of sar to read the file.
+func (f historyWorkloadFile) arg() string {
+	var startArg, endArg string
+	if !f.start.IsZero() {
+		startArg = fmt.Sprintf("-s %s", f.start.Format(timedef.TIME_FORMAT_TIME))
+	}
+	if !f.end.IsZero() {
+		endArg = fmt.Sprintf("-e %s", f.end.Format(timedef.TIME_FORMAT_TIME))
+	}
+	return fmt.Sprintf("-f %s %s %s", f.path, startArg, endArg)
+}
+
+func (c *YHCChecker) genHistoryWorkloadFiles(start, end time.Time, sarDir string) (files []historyWorkloadFile) {
 	// get data between start and end
 	var dates []time.Time
 	begin := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, start.Location())
@@ -437,10 +557,10 @@ func (c *YHCChecker) genHistoryWorkloadArgs(start, end time.Time, sarDir string)
 		dates = append(dates, date)
 	}
 	for i, date := range dates {
-		var startArg, endArg, fileArg string
+		file := historyWorkloadFile{path: path.Join(sarDir, fmt.Sprintf("sa%s", date.Format(timedef.TIME_FORMAT_DAY)))}
 		// the frist
 		if i == 0 && !date.Equal(start) {
-			startArg = fmt.Sprintf("-s %s", start.Format(timedef.TIME_FORMAT_TIME))
+			file.start = start
 		}
 		// the last one
 		if i == len(dates)-1 {
@@ -448,10 +568,9 @@ func (c *YHCChecker) genHistoryWorkloadArgs(start, end time.Time, sarDir string)
 				// skip
 				continue
 			}
-			endArg = fmt.Sprintf("-e %s", end.Format(timedef.TIME_FORMAT_TIME))
+			file.end = end
 		}
-		fileArg = fmt.Sprintf("-f %s", path.Join(sarDir, fmt.Sprintf("sa%s", date.Format(timedef.TIME_FORMAT_DAY))))
-		args = append(args, fmt.Sprintf("%s %s %s", fileArg, startArg, endArg))
+		files = append(files, file)
 	}
 	return
 }
@@ -0,0 +1,139 @@
+// The cpucore package summarizes the per-core CPU usage sampled by sar -P ALL or gopsutil,
+// so that a single saturated core behind a single-threaded bottleneck can be told from the average.
+package cpucore
+
+import (
+	"encoding/json"
+	"sort"
+	"strconv"
+
+	"yhc/internal/modules/yhc/check/define"
+	"yhc/utils/mathutil"
+)
+
+const (
+	// CPU_ALL is the item name of all CPUs, which is skipped when summarizing the cores
+	CPU_ALL = "all"
+
+	_decimal = 2
+)
+
+// Usage is the usage of a core in percentage in a sample, the json tags are the same as the outputs of sar and gopsutil.
+type Usage struct {
+	CPU    string  `json:"cpu"`
+	User   float64 `json:"user"`
+	Nice   float64 `json:"nice"`
+	System float64 `json:"system"`
+	IOWait float64 `json:"iowait"`
+	Steal  float64 `json:"steal"`
+	Idle   float64 `json:"idle"`
+}
+
+// Busy returns the percentage of time the core is not idle, the I/O wait time is counted as idle.
+func (u Usage) Busy() float64 {
+	busy := 100 - u.Idle - u.IOWait
+	if busy < 0 {
+		return 0
+	}
+	return busy
+}
+
+// Summary is the usage of a core over all samples.
+type Summary struct {
+	CPU       string
+	Samples   int
+	BusyAvg   float64
+	BusyMax   float64
+	UserAvg   float64
+	SysAvg    float64
+	IOWaitAvg float64
+	// AboveAverage is how much the average busy percentage of the core exceeds the average of all cores
+	AboveAverage float64
+}
+
+// FromWorkload converts the workload output of per-core CPU usage to the usages keyed by time, the item of all CPUs is skipped.
+func FromWorkload(output define.WorkloadOutput) (map[int64][]Usage, error) {
+	res := make(map[int64][]Usage, len(output))
+	for timestamp, item := range output {
+		for name, value := range item {
+			if name == CPU_ALL {
+				continue
+			}
+			bytes, err := json.Marshal(value)
+			if err != nil {
+				return nil, err
+			}
+			var usage Usage
+			if err := json.Unmarshal(bytes, &usage); err != nil {
+				return nil, err
+			}
+			usage.CPU = name
+			res[timestamp] = append(res[timestamp], usage)
+		}
+	}
+	return res, nil
+}
+
+// Summarize summarizes the usage of every core over the samples, ordered by the average busy percentage.
+func Summarize(samples map[int64][]Usage) []*Summary {
+	summaries := make(map[string]*Summary)
+	for _, usages := range samples {
+		for _, usage := range usages {
+			summary, ok := summaries[usage.CPU]
+			if !ok {
+				summary = &Summary{CPU: usage.CPU}
+				summaries[usage.CPU] = summary
+			}
+			busy := usage.Busy()
+			summary.Samples++
+			summary.BusyAvg += busy
+			summary.UserAvg += usage.User
+			summary.SysAvg += usage.System
+			summary.IOWaitAvg += usage.IOWait
+			if busy > summary.BusyMax {
+				summary.BusyMax = busy
+			}
+		}
+	}
+	res := make([]*Summary, 0, len(summaries))
+	var total float64
+	for _, summary := range summaries {
+		n := float64(summary.Samples)
+		summary.BusyAvg = mathutil.Round(summary.BusyAvg/n, _decimal)
+		summary.UserAvg = mathutil.Round(summary.UserAvg/n, _decimal)
+		summary.SysAvg = mathutil.Round(summary.SysAvg/n, _decimal)
+		summary.IOWaitAvg = mathutil.Round(summary.IOWaitAvg/n, _decimal)
+		total += summary.BusyAvg
+		res = append(res, summary)
+	}
+	if len(res) == 0 {
+		return res
+	}
+	average := total / float64(len(res))
+	for _, summary := range res {
+		summary.AboveAverage = mathutil.Round(summary.BusyAvg-average, _decimal)
+	}
+	sort.SliceStable(res, func(i, j int) bool {
+		if res[i].BusyAvg != res[j].BusyAvg {
+			return res[i].BusyAvg > res[j].BusyAvg
+		}
+		return coreNumber(res[i].CPU) < coreNumber(res[j].CPU)
+	})
+	return res
+}
+
+// Top returns the n busiest cores of the summaries ordered by Summarize.
+func Top(summaries []*Summary, n int) []*Summary {
+	if len(summaries) <= n {
+		return summaries
+	}
+	return summaries[:n]
+}
+
+func coreNumber(name string) int {
+	n, err := strconv.Atoi(name)
+	if err != nil {
+		return -1
+	}
+	return n
+}
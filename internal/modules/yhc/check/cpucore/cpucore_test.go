@@ -0,0 +1,44 @@
+package cpucore_test
+
+import (
+	"testing"
+
+	"yhc/internal/modules/yhc/check/cpucore"
+	"yhc/internal/modules/yhc/check/define"
+	"yhc/internal/modules/yhc/check/sar"
+)
+
+func TestSummarize(t *testing.T) {
+	output := define.WorkloadOutput{
+		1: {
+			"all": sar.CPUUsage{CPU: "all", User: 30, Idle: 70},
+			"0":   sar.CPUUsage{CPU: "0", User: 90, System: 8, Idle: 2},
+			"1":   sar.CPUUsage{CPU: "1", User: 10, IOWait: 10, Idle: 80},
+			"2":   sar.CPUUsage{CPU: "2", User: 10, Idle: 90},
+		},
+		2: {
+			"0": sar.CPUUsage{CPU: "0", User: 94, System: 4, Idle: 2},
+			"1": sar.CPUUsage{CPU: "1", User: 10, Idle: 90},
+			"2": sar.CPUUsage{CPU: "2", User: 10, Idle: 90},
+		},
+	}
+	samples, err := cpucore.FromWorkload(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	summaries := cpucore.Summarize(samples)
+	if len(summaries) != 3 {
+		t.Fatalf("unexpected summaries: %+v", summaries)
+	}
+	// the iowait time is not busy, core 1 and 2 are ordered by the number
+	hot := summaries[0]
+	if hot.CPU != "0" || hot.Samples != 2 || hot.BusyAvg != 98 || hot.BusyMax != 98 || hot.UserAvg != 92 || hot.SysAvg != 6 {
+		t.Fatalf("unexpected hot core: %+v", hot)
+	}
+	if hot.AboveAverage != 58.67 || summaries[1].CPU != "1" || summaries[1].IOWaitAvg != 5 || summaries[2].AboveAverage != -29.33 {
+		t.Fatalf("unexpected summaries: %+v %+v", summaries[1], summaries[2])
+	}
+	if top := cpucore.Top(summaries, 1); len(top) != 1 || top[0] != hot {
+		t.Fatalf("unexpected top cores: %+v", top)
+	}
+}
@@ -13,10 +13,21 @@ const (
 )
 
 const (
-	WT_CPU     WorkloadType = "cpu"
-	WT_NETWORK WorkloadType = "network"
-	WT_MEMORY  WorkloadType = "memory"
-	WT_DISK    WorkloadType = "disk"
+	PROFILE_CHECK         = "check"
+	PROFILE_AFTER_INSTALL = "after-install"
+)
+
+const (
+	WT_CPU           WorkloadType = "cpu"
+	WT_NETWORK       WorkloadType = "network"
+	WT_MEMORY        WorkloadType = "memory"
+	WT_DISK          WorkloadType = "disk"
+	WT_LOAD          WorkloadType = "load"
+	WT_PAGING        WorkloadType = "paging"
+	WT_TASK          WorkloadType = "task"
+	WT_SWAP          WorkloadType = "swap"
+	WT_NETWORK_ERROR WorkloadType = "network_error"
+	WT_CPU_CORE      WorkloadType = "cpu_core"
 )
 
 type WorkloadType string
@@ -63,4 +74,18 @@ type CheckerBase struct {
 	// TODO: add other struct which checker needed
 	NodeInfos     []*yasdb.NodeInfo
 	MultipleNodes bool
+	// Profile is the kind of the check, such as 'check' and 'after-install'
+	Profile string
+	// Snapshots is the snapshots of the workload report chosen by the user
+	Snapshots SnapshotOptions
+}
+
+// SnapshotOptions is the snapshot ids of the workload report, the snapshots are picked by the check window if the ids are 0.
+// The compare period is given by the ids, or by shifting the base period back by CompareOffset.
+type SnapshotOptions struct {
+	BeginSnap        int64
+	EndSnap          int64
+	CompareBeginSnap int64
+	CompareEndSnap   int64
+	CompareOffset    time.Duration
 }
@@ -17,6 +17,7 @@ const (
 
 	// parent module: MN_HOST
 	MODULE_HOST_WORKLOAD ModuleName = "host_workload_check"
+	MODULE_HOST_CONFIG   ModuleName = "host_config_check"
 
 	// parent module: MN_YASDB
 	MODULE_YASDB_STANDBY     ModuleName = "yasdb_standby_check"
@@ -42,6 +43,7 @@ const (
 	MODULE_SECURITY_LOGIN      ModuleName = "security_login_config"
 	MODULE_SECURITY_PERMISSION ModuleName = "security_permission_check"
 	MODULE_SECURITY_AUDIT      ModuleName = "security_audit_check"
+	MODULE_SECURITY_HOST       ModuleName = "security_host_check"
 
 	// parent module: MN_LOG
 	MODULE_LOG_RUN   ModuleName = "log_run_analysis"
@@ -63,6 +65,9 @@ const (
 	METRIC_YASDB_FILE_PERMISSION                                                        MetricName = "yasdb_file_permission"
 	METRIC_YASDB_LISTEN_ADDR                                                            MetricName = "yasdb_listen_address"
 	METRIC_YASDB_OS_AUTH                                                                MetricName = "yasdb_os_auth"
+	METRIC_YASDB_PROCESS_LIMITS                                                         MetricName = "yasdb_process_limits"
+	METRIC_YASDB_NODE_TIME_SKEW                                                         MetricName = "yasdb_node_time_skew"
+	METRIC_YASDB_NUMA_BINDING                                                           MetricName = "yasdb_numa_binding"
 	METRIC_HOST_INFO                                                                    MetricName = "host_info"
 	METRIC_HOST_FIREWALLD                                                               MetricName = "host_firewalld"
 	METRIC_HOST_IPTABLES                                                                MetricName = "host_iptables"
@@ -74,12 +79,24 @@ const (
 	METRIC_HOST_NETWORK_INFO                                                            MetricName = "host_network_info"
 	METRIC_HOST_HISTORY_CPU_USAGE                                                       MetricName = "host_history_cpu_usage"
 	METRIC_HOST_CURRENT_CPU_USAGE                                                       MetricName = "host_current_cpu_usage"
+	METRIC_HOST_HISTORY_CPU_CORE_USAGE                                                  MetricName = "host_history_cpu_core_usage"
+	METRIC_HOST_CURRENT_CPU_CORE_USAGE                                                  MetricName = "host_current_cpu_core_usage"
 	METRIC_HOST_HISTORY_DISK_IO                                                         MetricName = "host_history_disk_io"
 	METRIC_HOST_CURRENT_DISK_IO                                                         MetricName = "host_current_disk_io"
 	METRIC_HOST_HISTORY_MEMORY_USAGE                                                    MetricName = "host_history_memory_usage"
 	METRIC_HOST_CURRENT_MEMORY_USAGE                                                    MetricName = "host_current_memory_usage"
 	METRIC_HOST_HISTORY_NETWORK_IO                                                      MetricName = "host_history_network_io"
 	METRIC_HOST_CURRENT_NETWORK_IO                                                      MetricName = "host_current_network_io"
+	METRIC_HOST_HISTORY_LOAD_AVERAGE                                                    MetricName = "host_history_load_average"
+	METRIC_HOST_CURRENT_LOAD_AVERAGE                                                    MetricName = "host_current_load_average"
+	METRIC_HOST_HISTORY_PAGING                                                          MetricName = "host_history_paging"
+	METRIC_HOST_CURRENT_PAGING                                                          MetricName = "host_current_paging"
+	METRIC_HOST_HISTORY_CONTEXT_SWITCH                                                  MetricName = "host_history_context_switch"
+	METRIC_HOST_CURRENT_CONTEXT_SWITCH                                                  MetricName = "host_current_context_switch"
+	METRIC_HOST_HISTORY_SWAPPING                                                        MetricName = "host_history_swapping"
+	METRIC_HOST_CURRENT_SWAPPING                                                        MetricName = "host_current_swapping"
+	METRIC_HOST_HISTORY_NETWORK_EDEV                                                    MetricName = "host_history_network_edev"
+	METRIC_HOST_CURRENT_NETWORK_EDEV                                                    MetricName = "host_current_network_edev"
 	METRIC_YASDB_ARCHIVE_DEST_STATUS                                                    MetricName = "yasdb_archive_dest_status"
 	METRIC_YASDB_ARCHIVE_LOG                                                            MetricName = "yasdb_archive_log"
 	METRIC_YASDB_ARCHIVE_LOG_SPACE                                                      MetricName = "yasdb_archive_log_space"
@@ -90,6 +107,11 @@ const (
 	METRIC_YASDB_DATAFILE                                                               MetricName = "yasdb_datafile"
 	METRIC_YASDB_SESSION                                                                MetricName = "yasdb_session"
 	METRIC_YASDB_WAIT_EVENT                                                             MetricName = "yasdb_wait_event"
+	METRIC_YASDB_CURRENT_WAIT_EVENT                                                     MetricName = "yasdb_current_wait_event"
+	METRIC_YASDB_CURRENT_WAIT_CLASS                                                     MetricName = "yasdb_current_wait_class"
+	METRIC_YASDB_HISTORY_WAIT_EVENT                                                     MetricName = "yasdb_history_wait_event"
+	METRIC_YASDB_HISTORY_WAIT_CLASS                                                     MetricName = "yasdb_history_wait_class"
+	METRIC_YASDB_SESSION_WAIT                                                           MetricName = "yasdb_session_wait"
 	METRIC_YASDB_INDEX_OVERSIZED                                                        MetricName = "yasdb_index_oversized"
 	METRIC_YASDB_INDEX_TABLE_INDEX_NOT_TOGETHER                                         MetricName = "yasdb_index_table_index_not_together"
 	METRIC_YASDB_SEQUENCE_NO_AVAILABLE                                                  MetricName = "yasdb_sequence_no_available"
@@ -127,11 +149,30 @@ const (
 	METRIC_YASDB_HIGH_FREQUENCY_SQL                                                     MetricName = "yasdb_high_frequency_sql"
 	METRIC_YASDB_HISTORY_DB_TIME                                                        MetricName = "yasdb_history_db_time"
 	METRIC_YASDB_HISTORY_BUFFER_HIT_RATE                                                MetricName = "yasdb_history_buffer_hit_rate"
+	METRIC_YASDB_AWR_REPORT                                                             MetricName = "yasdb_awr_report"
 	METRIC_HOST_HUGE_PAGE                                                               MetricName = "host_huge_page"
 	METRIC_HOST_SWAP_MEMORY                                                             MetricName = "host_swap_memory"
+	METRIC_HOST_SYSCTL                                                                  MetricName = "host_sysctl"
+	METRIC_HOST_TIME_SYNC                                                               MetricName = "host_time_sync"
+	METRIC_HOST_NUMA                                                                    MetricName = "host_numa"
+	METRIC_HOST_CPU_GOVERNOR                                                            MetricName = "host_cpu_governor"
+	METRIC_HOST_YASDB_BLOCK_DEVICE                                                      MetricName = "host_yasdb_block_device"
+	METRIC_HOST_NETWORK_ERRORS                                                          MetricName = "host_network_errors"
+	METRIC_HOST_TCP_HEALTH                                                              MetricName = "host_tcp_health"
+	METRIC_HOST_KERNEL_EVENTS                                                           MetricName = "host_kernel_events"
+	METRIC_HOST_CGROUP                                                                  MetricName = "host_cgroup"
+	METRIC_HOST_SECURITY_MAC                                                            MetricName = "host_security_mac"
+	METRIC_HOST_SSHD_CONFIG                                                             MetricName = "host_sshd_config"
+	METRIC_HOST_YASDB_HOME_FILES                                                        MetricName = "host_yasdb_home_files"
+	METRIC_HOST_CORE_DUMP                                                               MetricName = "host_core_dump"
+	METRIC_HOST_YASDB_USER_PASSWORD                                                     MetricName = "host_yasdb_user_password"
+	METRIC_HOST_YASDB_USER_SUDO                                                         MetricName = "host_yasdb_user_sudo"
+	METRIC_HOST_PROCESS_USAGE                                                           MetricName = "host_process_usage"
+	METRIC_HOST_CURRENT_PROCESS_USAGE                                                   MetricName = "host_current_process_usage"
 	METRIC_YASDB_BUFFER_HIT_RATE                                                        MetricName = "yasdb_buffer_hit_rate"
 	METRIC_YASDB_TABLE_LOCK_WAIT                                                        MetricName = "yasdb_table_lock_wait"
 	METRIC_YASDB_ROW_LOCK_WAIT                                                          MetricName = "yasdb_row_lock_wait"
+	METRIC_YASDB_BLOCKING_CHAIN                                                         MetricName = "yasdb_blocking_chain"
 	METRIC_YASDB_LONG_RUNNING_TRANSACTION                                               MetricName = "yasdb_long_running_transaction"
 	METRIC_YASDB_OBJECT_COUNT                                                           MetricName = "yasdb_object_count"
 	METRIC_YASDB_OBJECT_SUMMARY                                                         MetricName = "yasdb_object_summary"
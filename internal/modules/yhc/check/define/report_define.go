@@ -32,6 +32,12 @@ const (
 	TABLE_LAYOUT_FIXED = "fixed"
 )
 
+const (
+	// the keys of a table row rendered as a tree, the children are the nested rows and the key identifies a row
+	TABLE_ROW_KEY      = "key"
+	TABLE_ROW_CHILDREN = "children"
+)
+
 // GetAlertTypeAlias 获取告警类型的本地化名称
 func GetAlertTypeAlias(alertType AlertType) string {
 	switch alertType {
@@ -145,7 +151,9 @@ type ChartCustomOptions struct {
 }
 
 type ChartAttributes struct {
-	CustomOptions ChartCustomOptions `json:"customOptions,omitempty"`
+	// Options is merged to the echarts options generated from the custom options, such as the mark areas of the series
+	Options       map[string]interface{} `json:"options,omitempty"`
+	CustomOptions ChartCustomOptions     `json:"customOptions,omitempty"`
 }
 
 type ChartSeries struct {
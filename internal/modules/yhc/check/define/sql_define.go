@@ -44,6 +44,29 @@ const (
     'rdbms ipc message',
     'rdbms ipc reply',
     'smon timer');`
+	SQL_QUERY_SYSTEM_EVENT = `SELECT EVENT AS EVENT_NAME, WAIT_CLASS, TOTAL_WAITS, TIME_WAITED_MICRO FROM v$system_event WHERE WAIT_CLASS != 'Idle';`
+	SQL_QUERY_DB_TIME      = `SELECT VALUE FROM v$sysstat WHERE NAME = 'DB TIME';`
+	SQL_QUERY_SESSION_WAIT = `SELECT s.SID, s.USERNAME, s.STATUS, s.WAIT_EVENT, se.WAIT_CLASS, s.SQL_ID, s.BLOCKING_SESSION
+    FROM v$session s
+        LEFT JOIN v$system_event se ON se.EVENT = s.WAIT_EVENT
+    WHERE s.TYPE <> 'BACKGROUND'
+        AND s.WAIT_EVENT IS NOT NULL
+        AND nvl(se.WAIT_CLASS, ' ') != 'Idle'
+    ORDER BY s.BLOCKING_SESSION, s.SID;`
+	SQL_QUERY_LOCK_WAITS = `SELECT w.SID AS WAITER, b.SID AS BLOCKER, max(w.CTIME) AS WAIT_SECONDS
+    FROM v$lock w
+        JOIN v$lock b ON b.TYPE = w.TYPE AND b.ID1 = w.ID1 AND b.ID2 = w.ID2
+    WHERE w.REQUEST IS NOT NULL
+        AND w.REQUEST != 'NONE'
+        AND b.BLOCK = 1
+        AND b.SID != w.SID
+    GROUP BY w.SID, b.SID;`
+	SQL_QUERY_LOCK_SESSIONS = `SELECT s.SID, s.USERNAME, s.PROGRAM, s.SQL_ID, s.STATUS, s.LAST_CALL_ET AS IDLE_SECONDS
+        , to_char(min(t.START_DATE), 'yyyy-mm-dd hh24:mi:ss') AS TX_START
+    FROM v$session s
+        LEFT JOIN v$transaction t ON t.SID = s.SID
+    WHERE s.SID IN (SELECT SID FROM v$lock)
+    GROUP BY s.SID, s.USERNAME, s.PROGRAM, s.SQL_ID, s.STATUS, s.LAST_CALL_ET;`
 	SQL_QUERY_VM_SWAP_RATE              = `SELECT t1.SWAPPED_OUT_BLOCKS / t2.value AS RATE FROM ( SELECT SWAPPED_OUT_BLOCKS FROM v$vm ) t1, ( SELECT value FROM V$SYSSTAT WHERE NAME = 'VM ALLOC' ) t2;`
 	SQL_QUERY_YASDB_TOP_SQL_BY_CPU_TIME = `SELECT round(CPU_TIME / 1000, 2) AS CPU_TIME, EXECUTIONS
         , round(ELAPSED_TIME / 1000, 2) AS ALL_ELAPSED_TIME
@@ -143,6 +166,65 @@ const (
         JOIN t5 ON t4.snap_id = t5.snap_id
     where t5.snap_time >= TIMESTAMP('%s') AND t5.snap_time <= TIMESTAMP('%s')
     ORDER BY t5.snap_time;`
+	SQL_QUERY_AWR_SNAPSHOTS = `
+    WITH dbinfo AS (
+        SELECT DISTINCT dbid
+        FROM SYS.wrm$_database_instance
+        LIMIT 1
+    )
+    SELECT snap_id
+        , to_char(begin_interval_time, 'YYYY-MM-DD HH24:MI:SS') AS begin_time
+        , to_char(end_interval_time, 'YYYY-MM-DD HH24:MI:SS') AS end_time
+    FROM SYS.wrm$_snapshot, dbinfo
+    WHERE SYS.wrm$_snapshot.dbid = dbinfo.dbid
+    ORDER BY snap_id;`
+	SQL_QUERY_AWR_SYSSTAT_FORMATER = `
+    WITH dbinfo AS (
+        SELECT DISTINCT dbid
+        FROM SYS.wrm$_database_instance
+        LIMIT 1
+    )
+    SELECT s.snap_id, n.name AS stat_name, s.value
+    FROM SYS.wrh$_sysstat s, v$sysstat n, dbinfo
+    WHERE s.dbid = dbinfo.dbid
+        AND s.stat_id = n.statistic#
+        AND s.snap_id IN (%d, %d);`
+	SQL_QUERY_AWR_SYSTEM_EVENT_FORMATER = `
+    WITH dbinfo AS (
+        SELECT DISTINCT dbid
+        FROM SYS.wrm$_database_instance
+        LIMIT 1
+    )
+    SELECT e.snap_id, n.event_name, n.wait_class, e.total_waits, e.time_waited_micro
+    FROM SYS.wrh$_system_event e, SYS.wrh$_event_name n, dbinfo
+    WHERE e.dbid = dbinfo.dbid
+        AND n.dbid = e.dbid
+        AND n.event_id = e.event_id
+        AND n.wait_class != 'Idle'
+        AND e.snap_id IN (%d, %d);`
+	SQL_QUERY_AWR_SQLSTAT_FORMATER = `
+    WITH dbinfo AS (
+        SELECT DISTINCT dbid
+        FROM SYS.wrm$_database_instance
+        LIMIT 1
+    ), 
+    stat AS (
+        SELECT sql_id
+            , sum(executions_delta) AS executions
+            , sum(elapsed_time_delta) AS elapsed_time
+            , sum(cpu_time_delta) AS cpu_time
+            , sum(buffer_gets_delta) AS buffer_gets
+            , sum(disk_reads_delta) AS disk_reads
+        FROM SYS.wrh$_sqlstat, dbinfo
+        WHERE SYS.wrh$_sqlstat.dbid = dbinfo.dbid
+            AND snap_id > %d
+            AND snap_id <= %d
+        GROUP BY sql_id
+    )
+    SELECT stat.sql_id, stat.executions, stat.elapsed_time, stat.cpu_time, stat.buffer_gets, stat.disk_reads, t.sql_text
+    FROM stat
+        LEFT JOIN SYS.wrh$_sqltext t ON t.sql_id = stat.sql_id
+    ORDER BY stat.elapsed_time DESC;`
 	SQL_QUERY_BUFFER_HIT_RATE          = `select (sum(decode(NAME, 'BUFFER GETS', VALUE, 0)) + sum(decode(NAME, 'BUFFER CR GETS', VALUE, 0)) - sum(decode(NAME, 'DISK READS', VALUE, 0))) / (sum(decode(NAME, 'BUFFER GETS', VALUE, 0)) + sum(decode(NAME, 'BUFFER CR GETS', VALUE, 0))) * 100 AS HIT_RATE FROM v$sysstat;`
 	SQL_QUERY_TABLE_LOCK_WAIT          = `select count(*) as TOTAL from v$lock lo where REQUEST in ('TS','TX');`
 	SQL_QUERY_ROW_LOCK_WAIT            = `select count(*) as TOTAL from v$lock lo where REQUEST in ('ROW');`
@@ -328,4 +410,9 @@ const (
 	SQL_QUERY_UNDO_LOG_SIZE                 = `SELECT round(a.USED_UBLK * b.value /1024/1024,3)  AS SIZE_MB, XID from V$TRANSACTION as a , ( SELECT to_number(decode(value, '8K','8192','16K','16384','32K','32768',value)) as VALUE FROM v$parameter WHERE NAME = 'DB_BLOCK_SIZE') as b;`
 	SQL_QUERY_UNDO_LOG_TOTAL_BLOCK          = `SELECT  SUM(USED_UBLK) as TOTAL_BLOCK from V$TRANSACTION ;`
 	SQL_QUERY_UNDO_LOG_RUNNING_TRANSACTIONS = `SELECT XID, SID,XRMID,XEXT, XNODE,XSN,STATUS,RESIDUAL, USED_UBLK, FIRST_UBAFIL,FIRST_UBABLK,FIRST_UBAVER ,FIRST_UBAREC,LAST_UBAFIL,LAST_UBABLK, PTX_XID, START_DATE,ISOLATION_LEVEL from V$TRANSACTION ;`
+	/**数据库文件所在块设备**/
+	SQL_QUERY_LOGFILE_NAME       = "select NAME from v$logfile;"
+	SQL_QUERY_ARCHIVE_LOCAL_DEST = "select value as ARCHIVE_DEST from v$parameter where name = 'ARCHIVE_LOCAL_DEST';"
+	/**时钟检查**/
+	SQL_QUERY_SYSTIMESTAMP = "select to_char(systimestamp, 'YYYY-MM-DD HH24:MI:SS.FF3') as NODE_TIME from dual;"
 )
@@ -0,0 +1,363 @@
+// The exporter package exports the table-type metrics of a check to spreadsheets,
+// so that the results can be post-processed by DBAs.
+package exporter
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"yhc/defs/confdef"
+	"yhc/defs/timedef"
+	"yhc/i18n"
+	"yhc/internal/modules/yhc/check/define"
+	"yhc/utils/fileutil"
+	"yhc/utils/stringutil"
+	"yhc/utils/xlsxutil"
+
+	"git.yasdb.com/go/yaslog"
+)
+
+const (
+	FORMAT_XLSX = "xlsx"
+	FORMAT_CSV  = "csv"
+
+	SUMMARY_NAME = "summary"
+
+	_csv_ext = ".csv"
+	// UTF-8 BOM makes spreadsheet softwares recognize the encoding of csv files
+	_utf8_bom = "\xef\xbb\xbf"
+)
+
+// Table is a two-dimensional view of a metric which can be written to a worksheet or a csv file.
+type Table struct {
+	Name   string // used as the csv file name
+	Title  string // used as the worksheet name
+	Header []string
+	Rows   [][]interface{}
+}
+
+type Exporter struct {
+	log            yaslog.YasLog
+	base           *define.CheckerBase
+	beginTime      time.Time
+	endTime        time.Time
+	metrics        []*confdef.YHCMetric
+	items          map[define.MetricName][]*define.YHCItem
+	evaluateResult *define.EvaluateResult
+}
+
+func NewExporter(log yaslog.YasLog, base *define.CheckerBase, beginTime, endTime time.Time, metrics []*confdef.YHCMetric, items map[define.MetricName][]*define.YHCItem, evaluateResult *define.EvaluateResult) *Exporter {
+	return &Exporter{
+		log:            log,
+		base:           base,
+		beginTime:      beginTime,
+		endTime:        endTime,
+		metrics:        metrics,
+		items:          items,
+		evaluateResult: evaluateResult,
+	}
+}
+
+// IsValidFormat checks whether the export format is supported.
+func IsValidFormat(format string) bool {
+	return format == FORMAT_XLSX || format == FORMAT_CSV
+}
+
+// ExportXlsx writes the summary and every table-type metric to its own worksheet of the xlsx file.
+func (e *Exporter) ExportXlsx(fname string) error {
+	wb := xlsxutil.NewWorkbook()
+	for _, table := range e.GenTables() {
+		wb.AddSheet(table.Title, table.Header, table.Rows)
+	}
+	return wb.SaveAs(fname)
+}
+
+// ExportCsv writes the summary and every table-type metric to its own csv file in the dir.
+func (e *Exporter) ExportCsv(dir string) error {
+	for _, table := range e.GenTables() {
+		if err := e.writeCsv(path.Join(dir, table.Name+_csv_ext), table); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GenTables returns the summary table followed by the tables of metrics in report order.
+func (e *Exporter) GenTables() []*Table {
+	tables := []*Table{e.genSummaryTable()}
+	for _, metric := range e.orderedMetrics() {
+		table := e.genMetricTable(metric)
+		if table == nil {
+			continue
+		}
+		tables = append(tables, table)
+	}
+	return tables
+}
+
+func (e *Exporter) writeCsv(fname string, table *Table) error {
+	var buf bytes.Buffer
+	buf.WriteString(_utf8_bom)
+	writer := csv.NewWriter(&buf)
+	if len(table.Header) != 0 {
+		if err := writer.Write(table.Header); err != nil {
+			return err
+		}
+	}
+	for _, row := range table.Rows {
+		record := make([]string, 0, len(row))
+		for _, cell := range row {
+			record = append(record, xlsxutil.ToString(cell))
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return err
+	}
+	return fileutil.WriteFile(fname, buf.Bytes())
+}
+
+func (e *Exporter) orderedMetrics() []*confdef.YHCMetric {
+	metricMap := make(map[string]*confdef.YHCMetric)
+	for _, metric := range e.metrics {
+		metricMap[metric.Name] = metric
+	}
+	res := []*confdef.YHCMetric{}
+	for _, name := range confdef.GetMetricOrder() {
+		metric, ok := metricMap[name]
+		if !ok {
+			continue
+		}
+		res = append(res, metric)
+		delete(metricMap, name)
+	}
+	// metrics which are not in the report modules are appended in configuration order
+	for _, metric := range e.metrics {
+		if _, ok := metricMap[metric.Name]; ok {
+			res = append(res, metric)
+		}
+	}
+	return res
+}
+
+func (e *Exporter) genMetricTable(metric *confdef.YHCMetric) *Table {
+	items, ok := e.items[define.MetricName(metric.Name)]
+	if !ok || len(items) == 0 {
+		return nil
+	}
+	type nodeRow struct {
+		nodeID string
+		data   map[string]interface{}
+	}
+	var rows []nodeRow
+	columns := make(map[string]struct{})
+	for _, item := range items {
+		datas, ok := e.toRows(item.Details)
+		if !ok {
+			e.log.Debugf("skip exporting metric %s, data type %T is not a table", metric.Name, item.Details)
+			return nil
+		}
+		for _, data := range e.flattenRows(datas) {
+			for key := range data {
+				columns[key] = struct{}{}
+			}
+			rows = append(rows, nodeRow{nodeID: item.NodeID, data: data})
+		}
+	}
+	if len(columns) == 0 {
+		return nil
+	}
+	keys := e.sortColumns(metric, columns)
+	withNodeID := e.base != nil && e.base.MultipleNodes
+	table := &Table{
+		Name:  metric.Name,
+		Title: metric.GetMetricAlias(),
+	}
+	if stringutil.IsEmpty(table.Title) {
+		table.Title = metric.Name
+	}
+	if withNodeID {
+		table.Header = append(table.Header, i18n.T("table.node_id"))
+	}
+	for _, key := range keys {
+		table.Header = append(table.Header, metric.GetColumnAlias(key))
+	}
+	for _, row := range rows {
+		var cells []interface{}
+		if withNodeID {
+			cells = append(cells, row.nodeID)
+		}
+		for _, key := range keys {
+			cells = append(cells, row.data[key])
+		}
+		table.Rows = append(table.Rows, cells)
+	}
+	return table
+}
+
+// toRows converts details of table-type metrics to rows, other details are not exported.
+func (e *Exporter) toRows(details interface{}) ([]map[string]interface{}, bool) {
+	switch detail := details.(type) {
+	case []map[string]interface{}:
+		return detail, true
+	case []map[string]string:
+		res := make([]map[string]interface{}, 0, len(detail))
+		for _, data := range detail {
+			row := make(map[string]interface{}, len(data))
+			for k, v := range data {
+				row[k] = v
+			}
+			res = append(res, row)
+		}
+		return res, true
+	case []interface{}:
+		// details loaded from data json or redacted are generic json values
+		res := make([]map[string]interface{}, 0, len(detail))
+		for _, data := range detail {
+			row, ok := data.(map[string]interface{})
+			if !ok {
+				return nil, false
+			}
+			res = append(res, row)
+		}
+		return res, true
+	default:
+		return nil, false
+	}
+}
+
+// flattenRows flattens the tree tables, the row of a node is followed by the rows of its children, and the key and
+// children columns of the tree are dropped.
+func (e *Exporter) flattenRows(datas []map[string]interface{}) []map[string]interface{} {
+	res := make([]map[string]interface{}, 0, len(datas))
+	for _, data := range datas {
+		children, isTree := data[define.TABLE_ROW_CHILDREN]
+		if _, ok := data[define.TABLE_ROW_KEY]; !ok && !isTree {
+			res = append(res, data)
+			continue
+		}
+		row := make(map[string]interface{}, len(data))
+		for k, v := range data {
+			if k != define.TABLE_ROW_KEY && k != define.TABLE_ROW_CHILDREN {
+				row[k] = v
+			}
+		}
+		res = append(res, row)
+		if childRows, ok := e.toRows(children); ok {
+			res = append(res, e.flattenRows(childRows)...)
+		}
+	}
+	return res
+}
+
+// sortColumns puts the columns in column_order first, the others are sorted by name, hidden columns are dropped.
+func (e *Exporter) sortColumns(metric *confdef.YHCMetric, columns map[string]struct{}) []string {
+	for _, hidden := range metric.HiddenColumns {
+		delete(columns, hidden)
+	}
+	var order, unorder []string
+	for _, column := range metric.ColumnOrder {
+		if _, ok := columns[column]; ok {
+			order = append(order, column)
+			delete(columns, column)
+		}
+	}
+	for column := range columns {
+		unorder = append(unorder, column)
+	}
+	sort.Strings(unorder)
+	return append(order, unorder...)
+}
+
+func (e *Exporter) genSummaryTable() *Table {
+	table := &Table{
+		Name:  SUMMARY_NAME,
+		Title: i18n.T("export.summary"),
+	}
+	table.Rows = append(table.Rows,
+		[]interface{}{i18n.T("report.check_start_time"), e.beginTime.Format(timedef.TIME_FORMAT)},
+		[]interface{}{i18n.T("export.check_end_time"), e.endTime.Format(timedef.TIME_FORMAT)},
+	)
+	if e.base != nil && e.base.DBInfo != nil {
+		table.Rows = append(table.Rows,
+			[]interface{}{i18n.T("report.database_name"), e.base.DBInfo.DatabaseName},
+			[]interface{}{i18n.T("report.yasdb_home"), e.base.DBInfo.YasdbHome},
+			[]interface{}{i18n.T("report.yasdb_data"), e.base.DBInfo.YasdbData},
+		)
+	}
+	if e.evaluateResult != nil {
+		table.Rows = append(table.Rows, []interface{}{i18n.T("score.current_score"), fmt.Sprintf("%.2f", e.evaluateResult.Score)})
+		if e.evaluateResult.EvaluateModel != nil {
+			table.Rows = append(table.Rows, []interface{}{i18n.T("score.total_score"), fmt.Sprintf("%.2f", e.evaluateResult.EvaluateModel.TotalScore)})
+		}
+		table.Rows = append(table.Rows, []interface{}{i18n.T("score.health_status"), e.evaluateResult.HealthStatus})
+		if summary := e.evaluateResult.AlertSummary; summary != nil {
+			table.Rows = append(table.Rows, []interface{}{i18n.T("score.alert_summary"), fmt.Sprintf(i18n.T("export.alert_count"), summary.CriticalCount, summary.WarningCount, summary.InfoCount)})
+		}
+	}
+	// an empty row separates the overview and the alert details
+	table.Rows = append(table.Rows, []interface{}{}, e.genAlertHeader())
+	table.Rows = append(table.Rows, e.genAlertRows()...)
+	return table
+}
+
+func (e *Exporter) genAlertHeader() []interface{} {
+	header := []interface{}{}
+	if e.base != nil && e.base.MultipleNodes {
+		header = append(header, i18n.T("table.node_id"))
+	}
+	return append(header,
+		i18n.T("table.metric_name"),
+		i18n.T("table.alert_level"),
+		i18n.T("table.alert_description"),
+		i18n.T("table.expression"),
+		i18n.T("table.value"),
+		i18n.T("table.alert_suggestion"),
+		i18n.T("table.alert_labels"),
+	)
+}
+
+func (e *Exporter) genAlertRows() [][]interface{} {
+	rows := [][]interface{}{}
+	levels := []string{confdef.AL_CRITICAL, confdef.AL_WARNING, confdef.AL_INFO}
+	for _, metric := range e.orderedMetrics() {
+		for _, item := range e.items[define.MetricName(metric.Name)] {
+			for _, level := range levels {
+				for _, alert := range item.Alerts[level] {
+					row := []interface{}{}
+					if e.base != nil && e.base.MultipleNodes {
+						row = append(row, item.NodeID)
+					}
+					row = append(row,
+						metric.GetMetricAlias(),
+						confdef.GetAlertLevelText(level),
+						alert.AlertDetails.GetAlertDescription(),
+						alert.Expression,
+						alert.Value,
+						alert.AlertDetails.GetAlertSuggestion(),
+						e.genAlertLabels(metric, alert),
+					)
+					rows = append(rows, row)
+				}
+			}
+		}
+	}
+	return rows
+}
+
+func (e *Exporter) genAlertLabels(metric *confdef.YHCMetric, alert *define.YHCAlert) string {
+	labels := []string{}
+	for key, value := range alert.Labels {
+		labels = append(labels, fmt.Sprintf("%s: %s", metric.GetColumnAlias(key), value))
+	}
+	sort.Strings(labels)
+	return strings.Join(labels, stringutil.STR_NEWLINE)
+}
@@ -3,14 +3,9 @@ package gopsutil
 import (
 	"yhc/internal/modules/yhc/check/define"
 
-	"github.com/shirou/gopsutil/cpu"
 	"github.com/shirou/gopsutil/mem"
 )
 
-type CpuUsage struct {
-	cpu.TimesStat
-}
-
 type DiskIO struct {
 	Dev                string  `json:"dev"`                          // dev name.
 	Iops               uint64  `json:"iops,omitempty"`               // iops in process.
@@ -42,4 +37,56 @@ type NetworkIO struct {
 	Fifoout float64 `json:"fifoout,omitempty"` // FIFO buffers errors per second while sending
 }
 
+type LoadAverage struct {
+	RunqSz        float64 `json:"runqSz"`        // number of tasks running
+	PlistSz       float64 `json:"plistSz"`       // number of tasks in the task list
+	Ldavg1        float64 `json:"ldavg1"`        // load average for the last minute
+	Ldavg5        float64 `json:"ldavg5"`        // load average for the past 5 minutes
+	Ldavg15       float64 `json:"ldavg15"`       // load average for the past 15 minutes
+	Blocked       float64 `json:"blocked"`       // number of tasks blocked waiting for I/O
+	Ldavg1PerCore float64 `json:"ldavg1PerCore"` // load average for the last minute divided by the number of CPUs
+}
+
+type Paging struct {
+	Pgpgin  float64 `json:"pgpgin"`  // kilobytes paged in from disk per second
+	Pgpgout float64 `json:"pgpgout"` // kilobytes paged out to disk per second
+	Fault   float64 `json:"fault"`   // page faults (major + minor) per second
+	Majflt  float64 `json:"majflt"`  // major faults per second
+}
+
+type TaskSwitch struct {
+	Proc  float64 `json:"proc"`  // tasks created per second
+	Cswch float64 `json:"cswch"` // context switches per second
+}
+
+type Swapping struct {
+	Pswpin  float64 `json:"pswpin"`  // swap pages brought in per second
+	Pswpout float64 `json:"pswpout"` // swap pages brought out per second
+}
+
+type NetworkError struct {
+	Iface  string  `json:"iface"`  // interface name
+	Rxerr  float64 `json:"rxerr"`  // bad packets received per second
+	Txerr  float64 `json:"txerr"`  // errors while transmitting packets per second
+	Rxdrop float64 `json:"rxdrop"` // received packets dropped per second
+	Txdrop float64 `json:"txdrop"` // transmitted packets dropped per second
+	Rxfifo float64 `json:"rxfifo"` // FIFO overrun errors on received packets per second
+	Txfifo float64 `json:"txfifo"` // FIFO overrun errors on transmitted packets per second
+}
+
+// CPUCoreUsage is the usage of all CPUs or a core in percentage over a scrape interval, as sar -P ALL -u.
+type CPUCoreUsage struct {
+	CPU    string  `json:"cpu"`    // 'all' or the number of the core
+	User   float64 `json:"user"`   // percentage of CPU time spent in user space
+	Nice   float64 `json:"nice"`   // percentage of CPU time spent on low priority tasks
+	System float64 `json:"system"` // percentage of CPU time spent in kernel space, including the irq time
+	IOWait float64 `json:"iowait"` // percentage of CPU time waiting for I/O operations to complete
+	Steal  float64 `json:"steal"`  // percentage of CPU time stolen by the hypervisor
+	Idle   float64 `json:"idle"`   // percentage of CPU time idle
+	Usage  float64 `json:"usage"`  // percentage of CPU time not idle
+}
+
+// counterSample maps the item name to the counters of the item at a moment.
+type counterSample map[string][]uint64
+
 type collectWorkloadFunc func(scrapeInterval, scrapeTimes int) (define.WorkloadOutput, error)
@@ -2,12 +2,15 @@ package gopsutil
 
 import (
 	"errors"
+	"strings"
 	"time"
 
 	"yhc/internal/modules/yhc/check/define"
+	"yhc/utils/mathutil"
 
 	"github.com/shirou/gopsutil/cpu"
 	"github.com/shirou/gopsutil/disk"
+	"github.com/shirou/gopsutil/load"
 	"github.com/shirou/gopsutil/mem"
 	"github.com/shirou/gopsutil/net"
 )
@@ -17,8 +20,23 @@ var _typeToFuncMap = map[define.WorkloadType]collectWorkloadFunc{
 	define.WT_DISK:    collectDiskIO,
 	define.WT_MEMORY:  collectMemoryUsage,
 	define.WT_NETWORK: collectNetworkIO,
+	// TODO: pgfree/pgscan/pgsteal and collisions are not provided by gopsutil
+	define.WT_LOAD:          collectLoadAverage,
+	define.WT_PAGING:        collectPaging,
+	define.WT_TASK:          collectTaskSwitch,
+	define.WT_SWAP:          collectSwapping,
+	define.WT_NETWORK_ERROR: collectNetworkError,
+	define.WT_CPU_CORE:      collectCPUCoreUsage,
 }
 
+const (
+	_page_size = 4096
+	_decimal   = 2
+
+	_cpu_all         = "all"
+	_cpu_name_prefix = "cpu"
+)
+
 func Collect(t define.WorkloadType, scrapeInterval, scrapeTimes int) (define.WorkloadOutput, error) {
 	collectFunc, ok := _typeToFuncMap[t]
 	if !ok {
@@ -27,20 +45,14 @@ func Collect(t define.WorkloadType, scrapeInterval, scrapeTimes int) (define.Wor
 	return collectFunc(scrapeInterval, scrapeTimes)
 }
 
+// collectCPUUasge calculates the usage of all CPUs in percentage as sar -u instead of the accumulated times.
 func collectCPUUasge(scrapeInterval, scrapeTimes int) (define.WorkloadOutput, error) {
-	res := make(define.WorkloadOutput)
-	for i := 0; i < scrapeTimes; i++ {
-		m := make(define.WorkloadItem, 0)
-		cpuInfos, err := cpu.Times(false)
-		if err != nil {
-			return res, err
-		}
-		for _, cpuInfo := range cpuInfos {
-			m[cpuInfo.CPU] = CpuUsage{cpuInfo}
-		}
-		now := time.Now().Unix()
-		res[now] = m
-		time.Sleep(time.Second * time.Duration(scrapeInterval))
+	res, err := collectCPUCoreUsage(scrapeInterval, scrapeTimes)
+	if err != nil {
+		return res, err
+	}
+	for timestamp, item := range res {
+		res[timestamp] = define.WorkloadItem{_cpu_all: item[_cpu_all]}
 	}
 	return res, nil
 }
@@ -162,3 +174,169 @@ func collectMemoryUsage(scrapeInterval, scrapeTimes int) (define.WorkloadOutput,
 	}
 	return res, nil
 }
+
+func collectLoadAverage(scrapeInterval, scrapeTimes int) (define.WorkloadOutput, error) {
+	res := make(define.WorkloadOutput)
+	cpus, err := cpu.Counts(true)
+	if err != nil {
+		return res, err
+	}
+	for i := 0; i < scrapeTimes; i++ {
+		avg, err := load.Avg()
+		if err != nil {
+			return res, err
+		}
+		misc, err := load.Misc()
+		if err != nil {
+			return res, err
+		}
+		loadAverage := LoadAverage{
+			RunqSz:  float64(misc.ProcsRunning),
+			PlistSz: float64(misc.ProcsTotal),
+			Ldavg1:  avg.Load1,
+			Ldavg5:  avg.Load5,
+			Ldavg15: avg.Load15,
+			Blocked: float64(misc.ProcsBlocked),
+		}
+		if cpus > 0 {
+			loadAverage.Ldavg1PerCore = mathutil.Round(avg.Load1/float64(cpus), _decimal)
+		}
+		res[time.Now().Unix()] = define.WorkloadItem{string(define.WT_LOAD): loadAverage}
+		time.Sleep(time.Second * time.Duration(scrapeInterval))
+	}
+	return res, nil
+}
+
+func collectPaging(scrapeInterval, scrapeTimes int) (define.WorkloadOutput, error) {
+	// gopsutil multiplies the counters of /proc/vmstat by the page size
+	return collectRates(scrapeInterval, scrapeTimes, func() (counterSample, error) {
+		swap, err := mem.SwapMemory()
+		if err != nil {
+			return nil, err
+		}
+		return counterSample{string(define.WT_PAGING): {swap.PgIn / _page_size, swap.PgOut / _page_size,
+			swap.PgFault / _page_size, swap.PgMajFault / _page_size}}, nil
+	}, func(_ string, rates []float64) interface{} {
+		return Paging{Pgpgin: rates[0], Pgpgout: rates[1], Fault: rates[2], Majflt: rates[3]}
+	})
+}
+
+func collectTaskSwitch(scrapeInterval, scrapeTimes int) (define.WorkloadOutput, error) {
+	return collectRates(scrapeInterval, scrapeTimes, func() (counterSample, error) {
+		misc, err := load.Misc()
+		if err != nil {
+			return nil, err
+		}
+		return counterSample{string(define.WT_TASK): {uint64(misc.ProcsCreated), uint64(misc.Ctxt)}}, nil
+	}, func(_ string, rates []float64) interface{} {
+		return TaskSwitch{Proc: rates[0], Cswch: rates[1]}
+	})
+}
+
+func collectSwapping(scrapeInterval, scrapeTimes int) (define.WorkloadOutput, error) {
+	return collectRates(scrapeInterval, scrapeTimes, func() (counterSample, error) {
+		swap, err := mem.SwapMemory()
+		if err != nil {
+			return nil, err
+		}
+		return counterSample{string(define.WT_SWAP): {swap.Sin / _page_size, swap.Sout / _page_size}}, nil
+	}, func(_ string, rates []float64) interface{} {
+		return Swapping{Pswpin: rates[0], Pswpout: rates[1]}
+	})
+}
+
+func collectNetworkError(scrapeInterval, scrapeTimes int) (define.WorkloadOutput, error) {
+	return collectRates(scrapeInterval, scrapeTimes, func() (counterSample, error) {
+		ioCounters, err := net.IOCounters(true)
+		if err != nil {
+			return nil, err
+		}
+		sample := make(counterSample)
+		for _, io := range ioCounters {
+			sample[io.Name] = []uint64{io.Errin, io.Errout, io.Dropin, io.Dropout, io.Fifoin, io.Fifoout}
+		}
+		return sample, nil
+	}, func(iface string, rates []float64) interface{} {
+		return NetworkError{Iface: iface, Rxerr: rates[0], Txerr: rates[1], Rxdrop: rates[2], Txdrop: rates[3],
+			Rxfifo: rates[4], Txfifo: rates[5]}
+	})
+}
+
+// collectRates samples the counters scrapeTimes+1 times and converts the differences of them to the rates per second.
+func collectRates(scrapeInterval, scrapeTimes int, sample func() (counterSample, error),
+	build func(name string, rates []float64) interface{}) (define.WorkloadOutput, error) {
+	res := make(define.WorkloadOutput)
+	var last counterSample
+	for i := 0; i < scrapeTimes+1; i++ {
+		current, err := sample()
+		if err != nil {
+			return res, err
+		}
+		now := time.Now().Unix()
+		if last != nil {
+			m := make(define.WorkloadItem)
+			for name, newCounters := range current {
+				oldCounters, ok := last[name]
+				if !ok {
+					continue
+				}
+				rates := make([]float64, len(newCounters))
+				for j := range newCounters {
+					// the counter is reset when it goes backwards
+					if newCounters[j] >= oldCounters[j] && scrapeInterval > 0 {
+						rates[j] = mathutil.Round(float64(newCounters[j]-oldCounters[j])/float64(scrapeInterval), _decimal)
+					}
+				}
+				m[name] = build(name, rates)
+			}
+			res[now] = m
+		}
+		last = current
+		if i < scrapeTimes {
+			time.Sleep(time.Second * time.Duration(scrapeInterval))
+		}
+	}
+	return res, nil
+}
+
+func collectCPUCoreUsage(scrapeInterval, scrapeTimes int) (define.WorkloadOutput, error) {
+	return collectRates(scrapeInterval, scrapeTimes, func() (counterSample, error) {
+		sample := make(counterSample)
+		for _, percpu := range []bool{false, true} {
+			times, err := cpu.Times(percpu)
+			if err != nil {
+				return nil, err
+			}
+			for _, t := range times {
+				name := strings.TrimPrefix(t.CPU, _cpu_name_prefix)
+				if !percpu {
+					name = _cpu_all
+				}
+				// the ticks are in centiseconds, which keeps the precision of the rates
+				sample[name] = []uint64{centiseconds(t.User - t.Guest), centiseconds(t.Nice - t.GuestNice),
+					centiseconds(t.System + t.Irq + t.Softirq), centiseconds(t.Iowait), centiseconds(t.Steal), centiseconds(t.Idle)}
+			}
+		}
+		return sample, nil
+	}, func(name string, rates []float64) interface{} {
+		var total float64
+		for _, rate := range rates {
+			total += rate
+		}
+		percent := func(v float64) float64 {
+			if total == 0 {
+				return 0
+			}
+			return mathutil.Round(v*100/total, _decimal)
+		}
+		return CPUCoreUsage{CPU: name, User: percent(rates[0]), Nice: percent(rates[1]), System: percent(rates[2]),
+			IOWait: percent(rates[3]), Steal: percent(rates[4]), Idle: percent(rates[5]), Usage: percent(total - rates[5])}
+	})
+}
+
+func centiseconds(seconds float64) uint64 {
+	if seconds <= 0 {
+		return 0
+	}
+	return uint64(seconds * 100)
+}
@@ -0,0 +1,246 @@
+package check
+
+import (
+	"errors"
+	"sort"
+	"time"
+
+	"yhc/defs/confdef"
+	"yhc/internal/modules/yhc/check/cgroup"
+	"yhc/internal/modules/yhc/check/define"
+	"yhc/internal/modules/yhc/check/numa"
+	"yhc/internal/modules/yhc/check/sar"
+	"yhc/log"
+	"yhc/utils/mathutil"
+	"yhc/utils/processutil"
+
+	"git.yasdb.com/go/yaserr"
+	"git.yasdb.com/go/yaslog"
+	"git.yasdb.com/go/yasutil/size"
+	"github.com/shirou/gopsutil/cpu"
+)
+
+const (
+	KEY_CGROUP_PID                 = "pid"
+	KEY_CGROUP_VERSION             = "version"
+	KEY_CGROUP_PATH                = "cgroupPath"
+	KEY_CGROUP_CONTAINERIZED       = "containerized"
+	KEY_CGROUP_CONTAINER_RUNTIME   = "containerRuntime"
+	KEY_CGROUP_MEMORY_LIMIT        = "memoryLimit"
+	KEY_CGROUP_MEMORY_USAGE        = "memoryUsage"
+	KEY_CGROUP_MEMORY_USED_PERCENT = "memoryUsedPercent"
+	KEY_CGROUP_CPU_QUOTA           = "cpuQuota"
+	KEY_CGROUP_CPU_PERIOD          = "cpuPeriod"
+	KEY_CGROUP_CPU_LIMIT           = "cpuLimit"
+	KEY_CGROUP_CPUSET              = "cpuset"
+	KEY_CGROUP_IO_WEIGHT           = "ioWeight"
+	KEY_CGROUP_OOM_EVENTS          = "oomEvents"
+	KEY_CGROUP_OOM_KILLS           = "oomKills"
+
+	CGROUP_UNLIMITED = "unlimited"
+
+	// WORKLOAD_ITEM_CGROUP is the item of the yasdb cgroup in the current workload, the usages are relative to the cgroup limits
+	WORKLOAD_ITEM_CGROUP = "cgroup"
+)
+
+// cgroupMemoryUsage is the memory usage of the yasdb cgroup, the inactive page cache is not counted as used.
+type cgroupMemoryUsage struct {
+	// Type is the label telling the cgroup item from the host item in the alerts
+	Type        string  `json:"type"`
+	Limit       int64   `json:"limit"`
+	Used        int64   `json:"used"`
+	RealMemUsed float64 `json:"realMemUsed"`
+}
+
+// GetHostCgroup reports the cgroup limits of the yasdb process, and whether it runs in a container.
+func (c *YHCChecker) GetHostCgroup(name string) (err error) {
+	data := &define.YHCItem{Name: define.METRIC_HOST_CGROUP}
+	defer c.fillResults(data)
+
+	logger := log.Module.M(string(define.METRIC_HOST_CGROUP))
+	pid, cg, err := c.getYasdbCgroup()
+	if err != nil {
+		err = yaserr.Wrap(err)
+		logger.Error(err)
+		data.Error = err.Error()
+		return
+	}
+	limits := cg.Limits()
+	runtime := c.getContainerRuntime(cg)
+	res := map[string]interface{}{
+		KEY_CGROUP_PID:               pid,
+		KEY_CGROUP_VERSION:           limits.Version,
+		KEY_CGROUP_PATH:              limits.Path,
+		KEY_CGROUP_CONTAINERIZED:     boolToString(len(runtime) != 0),
+		KEY_CGROUP_CONTAINER_RUNTIME: runtime,
+		KEY_CGROUP_MEMORY_LIMIT:      CGROUP_UNLIMITED,
+		KEY_CGROUP_MEMORY_USAGE:      size.GenHumanReadableSize(float64(limits.MemoryUsage), decimal),
+		KEY_CGROUP_CPU_QUOTA:         CGROUP_UNLIMITED,
+		KEY_CGROUP_CPU_PERIOD:        limits.CPUPeriod,
+		KEY_CGROUP_CPU_LIMIT:         CGROUP_UNLIMITED,
+		KEY_CGROUP_CPUSET:            limits.Cpuset,
+		KEY_CGROUP_IO_WEIGHT:         limits.IOWeight,
+		KEY_CGROUP_OOM_EVENTS:        limits.OOM,
+		KEY_CGROUP_OOM_KILLS:         limits.OOMKill,
+	}
+	if limits.MemoryLimit != cgroup.UNLIMITED {
+		res[KEY_CGROUP_MEMORY_LIMIT] = size.GenHumanReadableSize(float64(limits.MemoryLimit), decimal)
+		if limits.MemoryLimit > 0 {
+			res[KEY_CGROUP_MEMORY_USED_PERCENT] = mathutil.Round(float64(limits.MemoryUsage)*100/float64(limits.MemoryLimit), decimal)
+		}
+	}
+	if limits.CPUQuota != cgroup.UNLIMITED {
+		res[KEY_CGROUP_CPU_QUOTA] = limits.CPUQuota
+	}
+	if cores := cgroupCPULimit(logger, limits); cores > 0 {
+		res[KEY_CGROUP_CPU_LIMIT] = mathutil.Round(cores, decimal)
+	}
+	data.Details = res
+	return
+}
+
+// getYasdbCgroup finds the cgroup of the yasdb process once, the metrics of host share the result.
+func (c *YHCChecker) getYasdbCgroup() (int, *cgroup.Cgroup, error) {
+	c.yasdbCgroupOnce.Do(func() {
+		processes, err := processutil.GetYasdbProcess(c.base.DBInfo.YasdbData)
+		if err != nil {
+			c.yasdbCgroupErr = err
+			return
+		}
+		if len(processes) == 0 {
+			c.yasdbCgroupErr = errors.New("yasdb process not found")
+			return
+		}
+		c.yasdbPid = processes[0].Pid
+		c.yasdbCgroup, c.yasdbCgroupErr = cgroup.Open(cgroup.PROC, cgroup.SYS_FS_CGROUP, c.yasdbPid)
+	})
+	return c.yasdbPid, c.yasdbCgroup, c.yasdbCgroupErr
+}
+
+// getContainerRuntime returns the container runtime of yhc itself or the yasdb process, empty on bare metal.
+func (c *YHCChecker) getContainerRuntime(cg *cgroup.Cgroup) string {
+	if runtime := cgroup.DetectContainer("/"); len(runtime) != 0 {
+		return runtime
+	}
+	if cg != nil {
+		return cgroup.Runtime(cg.Path)
+	}
+	return ""
+}
+
+// cgroupCPULimit returns the CPU cores the cgroup can use, limited by quota or cpuset, 0 if not limited.
+func cgroupCPULimit(logger yaslog.YasLog, limits *cgroup.Limits) float64 {
+	cores := limits.CPULimit()
+	if len(limits.Cpuset) == 0 {
+		return cores
+	}
+	cpus, err := numa.ParseList(limits.Cpuset)
+	if err != nil {
+		logger.Warnf("parse cpuset %s err: %s", limits.Cpuset, err.Error())
+		return cores
+	}
+	online, err := cpu.Counts(true)
+	if err != nil || len(cpus) == 0 || len(cpus) >= online {
+		return cores
+	}
+	if cores == 0 || float64(len(cpus)) < cores {
+		return float64(len(cpus))
+	}
+	return cores
+}
+
+// sampleYasdbCgroup samples the usage of the limited yasdb cgroup while the current workload is collected,
+// the returned function merges the samples into the workload, nil is returned if the cgroup is not limited.
+func (c *YHCChecker) sampleYasdbCgroup(logger yaslog.YasLog, workloadType define.WorkloadType) func(define.WorkloadOutput) {
+	_, cg, err := c.getYasdbCgroup()
+	if err != nil {
+		logger.Infof("skip sampling yasdb cgroup: %s", err.Error())
+		return nil
+	}
+	limits := cg.Limits()
+	cores := cgroupCPULimit(logger, limits)
+	switch {
+	case workloadType == define.WT_CPU && cores > 0:
+	case workloadType == define.WT_MEMORY && limits.MemoryLimit > 0:
+	default:
+		return nil
+	}
+	conf := confdef.GetYHCConf()
+	interval, times := time.Duration(conf.GetScrapeInterval())*time.Second, conf.GetScrapeTimes()
+	done := make(chan []*cgroup.Usage, 1)
+	go func() {
+		var usages []*cgroup.Usage
+		for i := 0; i <= times; i++ {
+			if i != 0 {
+				time.Sleep(interval)
+			}
+			usage, err := cg.Usage()
+			if err != nil {
+				logger.Warnf("sample yasdb cgroup usage err: %s", err.Error())
+				break
+			}
+			usages = append(usages, usage)
+		}
+		done <- usages
+	}()
+	return func(output define.WorkloadOutput) {
+		usages := <-done
+		for i := 1; i < len(usages); i++ {
+			var item interface{}
+			if workloadType == define.WT_CPU {
+				item = cgroupCPUUsage(usages[i-1], usages[i], cores)
+			} else {
+				item = cgroupMemoryUsage{
+					Type:        WORKLOAD_ITEM_CGROUP,
+					Limit:       limits.MemoryLimit,
+					Used:        usages[i].WorkingSet,
+					RealMemUsed: mathutil.Round(float64(usages[i].WorkingSet)*100/float64(limits.MemoryLimit), decimal),
+				}
+			}
+			mergeWorkloadItem(output, usages[i].Time.Unix(), WORKLOAD_ITEM_CGROUP, item)
+		}
+	}
+}
+
+// cgroupCPUUsage calculates the CPU usage percentages relative to the CPU cores of the cgroup.
+func cgroupCPUUsage(old, new *cgroup.Usage, cores float64) sar.CPUUsage {
+	usage := sar.CPUUsage{CPU: WORKLOAD_ITEM_CGROUP}
+	total := float64(new.Time.Sub(old.Time).Microseconds()) * cores
+	if total <= 0 {
+		return usage
+	}
+	user, system := float64(new.UserUsec-old.UserUsec), float64(new.SystemUsec-old.SystemUsec)
+	if user+system == 0 {
+		// the user and system time are not provided
+		user = float64(new.CPUUsec - old.CPUUsec)
+	}
+	usage.User = mathutil.Round(user*100/total, decimal)
+	usage.System = mathutil.Round(system*100/total, decimal)
+	// the usage of the quota, it may exceed 100 a little since the quota is enforced per period
+	usage.Usage = mathutil.Round((user+system)*100/total, decimal)
+	if idle := 100 - usage.Usage; idle > 0 {
+		usage.Idle = mathutil.Round(idle, decimal)
+	}
+	return usage
+}
+
+// mergeWorkloadItem puts the item at the nearest time of the workload, so that it is drawn with the host items.
+func mergeWorkloadItem(output define.WorkloadOutput, t int64, name string, item interface{}) {
+	if len(output) == 0 {
+		output[t] = define.WorkloadItem{name: item}
+		return
+	}
+	times := make([]int64, 0, len(output))
+	for key := range output {
+		times = append(times, key)
+	}
+	sort.Slice(times, func(i, j int) bool { return abs64(times[i]-t) < abs64(times[j]-t) })
+	output[times[0]][name] = item
+}
+
+func abs64(i int64) int64 {
+	if i < 0 {
+		return -i
+	}
+	return i
+}
@@ -0,0 +1,49 @@
+package check
+
+import (
+	"yhc/internal/modules/yhc/check/define"
+	"yhc/log"
+
+	"git.yasdb.com/go/yaserr"
+)
+
+func (c *YHCChecker) GetHostHistoryContextSwitch(name string) (err error) {
+	data := &define.YHCItem{
+		Name: define.METRIC_HOST_HISTORY_CONTEXT_SWITCH,
+	}
+	defer c.fillResults(data)
+
+	log := log.Module.M(string(define.METRIC_HOST_HISTORY_CONTEXT_SWITCH))
+	resp, err := c.hostHistoryWorkload(log, define.METRIC_HOST_HISTORY_CONTEXT_SWITCH)
+	if err != nil {
+		err = yaserr.Wrap(err)
+		log.Error(err)
+		data.Error = err.Error()
+		return
+	}
+	data.Details = resp
+	return
+}
+
+func (c *YHCChecker) GetHostCurrentContextSwitch(name string) (err error) {
+	data := &define.YHCItem{
+		Name:     define.METRIC_HOST_CURRENT_CONTEXT_SWITCH,
+		DataType: define.DATATYPE_SAR,
+	}
+	defer c.fillResults(data)
+
+	log := log.Module.M(string(define.METRIC_HOST_CURRENT_CONTEXT_SWITCH))
+	hasSar := c.CheckSarAccess() == nil
+	if !hasSar {
+		data.DataType = define.DATATYPE_GOPSUTIL
+	}
+	resp, err := c.hostCurrentWorkload(log, define.METRIC_HOST_CURRENT_CONTEXT_SWITCH, hasSar)
+	if err != nil {
+		err = yaserr.Wrap(err)
+		log.Error(err)
+		data.Error = err.Error()
+		return
+	}
+	data.Details = resp
+	return
+}
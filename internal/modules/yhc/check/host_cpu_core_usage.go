@@ -0,0 +1,94 @@
+package check
+
+import (
+	"yhc/internal/modules/yhc/check/cpucore"
+	"yhc/internal/modules/yhc/check/define"
+	"yhc/log"
+
+	"git.yasdb.com/go/yaserr"
+)
+
+const (
+	KEY_CPU_CORE            = "cpu"
+	KEY_CPU_CORE_BUSY_AVG   = "busyAvg"
+	KEY_CPU_CORE_BUSY_MAX   = "busyMax"
+	KEY_CPU_CORE_USER_AVG   = "userAvg"
+	KEY_CPU_CORE_SYS_AVG    = "sysAvg"
+	KEY_CPU_CORE_IOWAIT_AVG = "iowaitAvg"
+	KEY_CPU_CORE_ABOVE_AVG  = "aboveAverage"
+
+	// CPU_CORE_USAGE_TOP_N is the number of the busiest cores reported
+	CPU_CORE_USAGE_TOP_N = 10
+)
+
+// GetHostHistoryCPUCoreUsage reports the busiest cores in the history workload.
+func (c *YHCChecker) GetHostHistoryCPUCoreUsage(name string) (err error) {
+	data := &define.YHCItem{
+		Name: define.METRIC_HOST_HISTORY_CPU_CORE_USAGE,
+	}
+	defer c.fillResults(data)
+
+	log := log.Module.M(string(define.METRIC_HOST_HISTORY_CPU_CORE_USAGE))
+	resp, err := c.hostHistoryWorkload(log, define.METRIC_HOST_HISTORY_CPU_CORE_USAGE)
+	if err != nil {
+		err = yaserr.Wrap(err)
+		log.Error(err)
+		data.Error = err.Error()
+		return
+	}
+	if data.Details, err = c.topCPUCores(resp); err != nil {
+		err = yaserr.Wrap(err)
+		log.Error(err)
+		data.Error = err.Error()
+	}
+	return
+}
+
+// GetHostCurrentCPUCoreUsage reports the busiest cores during the check, a core much busier than the others
+// usually means a single-threaded bottleneck or the interrupts bound to it.
+func (c *YHCChecker) GetHostCurrentCPUCoreUsage(name string) (err error) {
+	data := &define.YHCItem{
+		Name:     define.METRIC_HOST_CURRENT_CPU_CORE_USAGE,
+		DataType: define.DATATYPE_SAR,
+	}
+	defer c.fillResults(data)
+
+	log := log.Module.M(string(define.METRIC_HOST_CURRENT_CPU_CORE_USAGE))
+	hasSar := c.CheckSarAccess() == nil
+	if !hasSar {
+		data.DataType = define.DATATYPE_GOPSUTIL
+	}
+	resp, err := c.hostCurrentWorkload(log, define.METRIC_HOST_CURRENT_CPU_CORE_USAGE, hasSar)
+	if err != nil {
+		err = yaserr.Wrap(err)
+		log.Error(err)
+		data.Error = err.Error()
+		return
+	}
+	if data.Details, err = c.topCPUCores(resp); err != nil {
+		err = yaserr.Wrap(err)
+		log.Error(err)
+		data.Error = err.Error()
+	}
+	return
+}
+
+func (c *YHCChecker) topCPUCores(output define.WorkloadOutput) ([]map[string]interface{}, error) {
+	samples, err := cpucore.FromWorkload(output)
+	if err != nil {
+		return nil, err
+	}
+	res := []map[string]interface{}{}
+	for _, summary := range cpucore.Top(cpucore.Summarize(samples), CPU_CORE_USAGE_TOP_N) {
+		res = append(res, map[string]interface{}{
+			KEY_CPU_CORE:            summary.CPU,
+			KEY_CPU_CORE_BUSY_AVG:   summary.BusyAvg,
+			KEY_CPU_CORE_BUSY_MAX:   summary.BusyMax,
+			KEY_CPU_CORE_USER_AVG:   summary.UserAvg,
+			KEY_CPU_CORE_SYS_AVG:    summary.SysAvg,
+			KEY_CPU_CORE_IOWAIT_AVG: summary.IOWaitAvg,
+			KEY_CPU_CORE_ABOVE_AVG:  summary.AboveAverage,
+		})
+	}
+	return res, nil
+}
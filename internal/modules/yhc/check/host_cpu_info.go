@@ -26,6 +26,7 @@ const (
 	KEY_CPU_VERDOR_ID      = "vendorId"
 	KEY_CPU_FLAGS          = "flags"
 	KEY_CPU_GHZ            = "GHz"
+	KEY_CPU_CGROUP_LIMIT   = "cgroupCpuLimit"
 
 	KY_PRODUCT_INFO  = "/etc/.productinfo"
 	KEY_KY_MAX_SPEED = "Max Speed:"
@@ -45,7 +46,14 @@ func (c *YHCChecker) GetHostCPUInfo(name string) (err error) {
 		data.Error = err.Error()
 		return
 	}
-	data.Details = c.countCPUInfo(log, cpuInfos)
+	res := c.countCPUInfo(log, cpuInfos)
+	// the cores the yasdb cgroup can use, if limited by quota or cpuset
+	if _, cg, e := c.getYasdbCgroup(); e == nil {
+		if cores := cgroupCPULimit(log, cg.Limits()); cores > 0 {
+			res[KEY_CPU_CGROUP_LIMIT] = mathutil.Round(cores, decimal)
+		}
+	}
+	data.Details = res
 	return
 }
 
@@ -26,6 +26,8 @@ const (
 	KEY_VIRTUALIZATION_ROLE   = "virtualizationRole"
 	KEY_PLATFORM_FAMILY       = "platformFamily"
 	KEY_PLATFORM_VERSION      = "platformVersion"
+	KEY_CONTAINERIZED         = "containerized"
+	KEY_CONTAINER_RUNTIME     = "containerRuntime"
 )
 
 func (c *YHCChecker) GetHostInfo(name string) (err error) {
@@ -71,6 +73,11 @@ func (c *YHCChecker) dealHostInfo(log yaslog.YasLog, res map[string]interface{})
 		return fmt.Sprintf(i18n.T("time.format_without_days"), hours, minutes, seconds)
 	}
 	res[KEY_UP_TIME] = formatDuration(time.Second * time.Duration(upTime))
+	// the yasdb cgroup may be not found, e.g. the database is not running, then only yhc itself is detected
+	_, cg, _ := c.getYasdbCgroup()
+	runtime := c.getContainerRuntime(cg)
+	res[KEY_CONTAINERIZED] = boolToString(len(runtime) != 0)
+	res[KEY_CONTAINER_RUNTIME] = runtime
 	if runtimedef.GetOSRelease().Id == osutil.KYLIN_ID {
 		delete(res, KEY_PLATFORM_FAMILY)
 		platformVersion, err := c.getKyPlatformVersion(log)
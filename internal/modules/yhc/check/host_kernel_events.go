@@ -0,0 +1,144 @@
+package check
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"yhc/defs/bashdef"
+	"yhc/defs/timedef"
+	"yhc/internal/modules/yhc/check/define"
+	"yhc/internal/modules/yhc/check/kernelevent"
+	"yhc/log"
+	"yhc/utils/execerutil"
+	"yhc/utils/stringutil"
+
+	"git.yasdb.com/go/yaserr"
+	"git.yasdb.com/go/yaslog"
+	"git.yasdb.com/go/yasutil/size"
+	"github.com/google/uuid"
+)
+
+const (
+	KEY_KERNEL_EVENT_TIME    = "time"
+	KEY_KERNEL_EVENT_TYPE    = "type"
+	KEY_KERNEL_EVENT_SOURCE  = "source"
+	KEY_KERNEL_EVENT_DEVICE  = "device"
+	KEY_KERNEL_EVENT_PROCESS = "process"
+	KEY_KERNEL_EVENT_PID     = "pid"
+	KEY_KERNEL_EVENT_RSS     = "rss"
+	KEY_KERNEL_EVENT_COUNT   = "count"
+	KEY_KERNEL_EVENT_MESSAGE = "message"
+
+	// the same event read from dmesg, system log and journal, or repeated in the window, is reported once
+	KERNEL_EVENT_MERGE_WINDOW = time.Minute
+
+	JOURNAL_TIME_FORMAT = "2006-01-02T15:04:05-0700"
+)
+
+// GetHostKernelEvents detects the OOM kills, kernel panics, hung tasks, lockups, hardware errors,
+// I/O errors and read-only file systems from dmesg, system log and the kernel messages of journal.
+func (c *YHCChecker) GetHostKernelEvents(name string) (err error) {
+	data := &define.YHCItem{Name: define.METRIC_HOST_KERNEL_EVENTS}
+	defer c.fillResults(data)
+	logger := log.Module.M(string(define.METRIC_HOST_KERNEL_EVENTS))
+
+	sources := []struct {
+		name    string
+		collect func(yaslog.YasLog) ([]string, logTimeParseFunc, error)
+	}{
+		{kernelevent.SOURCE_DMESG, c.collectDmesgKernelLog},
+		{kernelevent.SOURCE_SYSLOG, c.collectSystemKernelLog},
+		{kernelevent.SOURCE_JOURNAL, c.collectJournalKernelLog},
+	}
+	var errs []string
+	collector := kernelevent.NewCollector(KERNEL_EVENT_MERGE_WINDOW)
+	for _, source := range sources {
+		lines, timeParse, e := source.collect(logger)
+		if e != nil {
+			logger.Warnf("collect kernel events from %s err: %s", source.name, e.Error())
+			errs = append(errs, fmt.Sprintf("%s: %s", source.name, e.Error()))
+			continue
+		}
+		for _, line := range lines {
+			event, ok := kernelevent.Classify(line)
+			if !ok {
+				continue
+			}
+			if event.Time, e = timeParse(time.Now(), stringutil.RemoveExtraSpaces(strings.TrimSpace(line))); e != nil {
+				logger.Warnf("skip line: %s, err: %s", line, e.Error())
+				continue
+			}
+			event.Source = source.name
+			collector.Add(event)
+		}
+	}
+	if len(errs) == len(sources) {
+		err = yaserr.Wrap(fmt.Errorf("no kernel log available: %s", strings.Join(errs, "; ")))
+		logger.Error(err)
+		data.Error = err.Error()
+		return
+	}
+	res := []map[string]interface{}{}
+	for _, event := range collector.Events() {
+		res = append(res, map[string]interface{}{
+			KEY_KERNEL_EVENT_TIME:    event.Time.Format(timedef.TIME_FORMAT),
+			KEY_KERNEL_EVENT_TYPE:    event.Type,
+			KEY_KERNEL_EVENT_SOURCE:  event.Source,
+			KEY_KERNEL_EVENT_DEVICE:  event.Device,
+			KEY_KERNEL_EVENT_PROCESS: event.Process,
+			KEY_KERNEL_EVENT_PID:     event.Pid,
+			KEY_KERNEL_EVENT_RSS:     size.GenHumanReadableSize(float64(event.RSSKB*1024), decimal),
+			KEY_KERNEL_EVENT_COUNT:   event.Count,
+			KEY_KERNEL_EVENT_MESSAGE: event.Message,
+		})
+	}
+	data.Details = res
+	return
+}
+
+func (c *YHCChecker) collectDmesgKernelLog(logger yaslog.YasLog) ([]string, logTimeParseFunc, error) {
+	timeParse := c.genDmesgLogTimeParseFunc()
+	lines, err := c.collectKernelLogByCommand(logger, bashdef.CMD_DMESG, timeParse)
+	return lines, timeParse, err
+}
+
+// collectJournalKernelLog reads the kernel messages of all boots in the journal, so that the events
+// before a panic or reboot can also be found when the journal is persistent.
+func (c *YHCChecker) collectJournalKernelLog(logger yaslog.YasLog) ([]string, logTimeParseFunc, error) {
+	cmd := fmt.Sprintf("%s _TRANSPORT=kernel --no-pager -o short-iso --since '%s' --until '%s'",
+		bashdef.CMD_JOURNALCTL, c.base.Start.Format(timedef.TIME_FORMAT), c.base.End.Format(timedef.TIME_FORMAT))
+	lines, err := c.collectKernelLogByCommand(logger, cmd, journalLogTimeParse)
+	return lines, journalLogTimeParse, err
+}
+
+func (c *YHCChecker) collectSystemKernelLog(logger yaslog.YasLog) ([]string, logTimeParseFunc, error) {
+	logName, err := getSystemLogName()
+	if err != nil {
+		return nil, nil, err
+	}
+	lines, err := c.collectLog(logger, logName, time.Now(), kernelevent.Match, c.hostLogTimeParse)
+	return lines, c.hostLogTimeParse, err
+}
+
+func (c *YHCChecker) collectKernelLogByCommand(logger yaslog.YasLog, cmd string, timeParse logTimeParseFunc) ([]string, error) {
+	tmpFileName := path.Join("/tmp", uuid.NewString()[0:6]+".log")
+	defer os.Remove(tmpFileName)
+	ret, _, stderr := execerutil.NewExecer(logger).Exec(bashdef.CMD_BASH, "-c", fmt.Sprintf("%s > %s", cmd, tmpFileName))
+	if ret != 0 {
+		return nil, fmt.Errorf("exec %s err: %s", cmd, stderr)
+	}
+	return c.collectLog(logger, tmpFileName, time.Now(), kernelevent.Match, timeParse)
+}
+
+// journalLogTimeParse parses the time of the journal line in short-iso format,
+// e.g. '2024-01-02T15:04:05+0800 host kernel: message'.
+func journalLogTimeParse(date time.Time, line string) (time.Time, error) {
+	fields := strings.SplitN(line, stringutil.STR_BLANK_SPACE, 2)
+	if t, err := time.Parse(JOURNAL_TIME_FORMAT, fields[0]); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.RFC3339, fields[0])
+}
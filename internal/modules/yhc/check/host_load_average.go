@@ -0,0 +1,49 @@
+package check
+
+import (
+	"yhc/internal/modules/yhc/check/define"
+	"yhc/log"
+
+	"git.yasdb.com/go/yaserr"
+)
+
+func (c *YHCChecker) GetHostHistoryLoadAverage(name string) (err error) {
+	data := &define.YHCItem{
+		Name: define.METRIC_HOST_HISTORY_LOAD_AVERAGE,
+	}
+	defer c.fillResults(data)
+
+	log := log.Module.M(string(define.METRIC_HOST_HISTORY_LOAD_AVERAGE))
+	resp, err := c.hostHistoryWorkload(log, define.METRIC_HOST_HISTORY_LOAD_AVERAGE)
+	if err != nil {
+		err = yaserr.Wrap(err)
+		log.Error(err)
+		data.Error = err.Error()
+		return
+	}
+	data.Details = resp
+	return
+}
+
+func (c *YHCChecker) GetHostCurrentLoadAverage(name string) (err error) {
+	data := &define.YHCItem{
+		Name:     define.METRIC_HOST_CURRENT_LOAD_AVERAGE,
+		DataType: define.DATATYPE_SAR,
+	}
+	defer c.fillResults(data)
+
+	log := log.Module.M(string(define.METRIC_HOST_CURRENT_LOAD_AVERAGE))
+	hasSar := c.CheckSarAccess() == nil
+	if !hasSar {
+		data.DataType = define.DATATYPE_GOPSUTIL
+	}
+	resp, err := c.hostCurrentWorkload(log, define.METRIC_HOST_CURRENT_LOAD_AVERAGE, hasSar)
+	if err != nil {
+		err = yaserr.Wrap(err)
+		log.Error(err)
+		data.Error = err.Error()
+		return
+	}
+	data.Details = resp
+	return
+}
@@ -5,6 +5,7 @@ import (
 	"yhc/log"
 
 	"git.yasdb.com/go/yaserr"
+	"git.yasdb.com/go/yaslog"
 	"git.yasdb.com/go/yasutil/size"
 	"github.com/shirou/gopsutil/mem"
 )
@@ -20,6 +21,7 @@ const (
 
 	SYSTEM_MEMORY_TYPE = "system"
 	SWAP_MEMORY_TYPE   = "swap"
+	CGROUP_MEMORY_TYPE = "cgroup"
 )
 
 func (c *YHCChecker) GetHostMemoryInfo(name string) (err error) {
@@ -29,17 +31,55 @@ func (c *YHCChecker) GetHostMemoryInfo(name string) (err error) {
 	defer c.fillResults(data)
 
 	log := log.Module.M(string(define.METRIC_HOST_MEMORY_INFO))
-	memInfo, err := mem.VirtualMemory()
+	memInfo, err := c.getHostMemory(log)
 	if err != nil {
-		err = yaserr.Wrap(err)
-		log.Error(err)
 		data.Error = err.Error()
 		return err
 	}
-	data.Details = c.dealMemoryData(memInfo)
+	res := c.dealMemoryData(memInfo)
+	if row := c.cgroupMemoryData(); row != nil {
+		res = append(res, row)
+	}
+	data.Details = res
 	return
 }
 
+// getHostMemory returns the memory of the host, it is shared by host_memory_info and the metrics scaled by the host memory.
+func (c *YHCChecker) getHostMemory(log yaslog.YasLog) (*mem.VirtualMemoryStat, error) {
+	memInfo, err := mem.VirtualMemory()
+	if err != nil {
+		err = yaserr.Wrap(err)
+		log.Error(err)
+		return nil, err
+	}
+	return memInfo, nil
+}
+
+// cgroupMemoryData returns the memory limit and usage of the yasdb cgroup, nil if the memory is not limited.
+func (c *YHCChecker) cgroupMemoryData() map[string]any {
+	_, cg, err := c.getYasdbCgroup()
+	if err != nil {
+		return nil
+	}
+	limits := cg.Limits()
+	if limits.MemoryLimit <= 0 {
+		return nil
+	}
+	used := limits.MemoryUsage
+	if usage, err := cg.Usage(); err == nil {
+		used = usage.WorkingSet
+	}
+	return map[string]any{
+		KEY_MEMORY_TYPE:               CGROUP_MEMORY_TYPE,
+		KEY_MEMORY_TOTAL:              size.GenHumanReadableSize(float64(limits.MemoryLimit), decimal),
+		KEY_MEMORY_USED:               size.GenHumanReadableSize(float64(used), decimal),
+		KEY_MEMORY_FREE:               size.GenHumanReadableSize(float64(limits.MemoryLimit-limits.MemoryUsage), decimal),
+		KEY_MEMORY_SHARED:             "/",
+		KEY_MEMORY_BUFFERS_AND_CACHED: size.GenHumanReadableSize(float64(limits.MemoryUsage-used), decimal),
+		KEY_MEMORY_AVAILABLE:          size.GenHumanReadableSize(float64(limits.MemoryLimit-used), decimal),
+	}
+}
+
 func (c *YHCChecker) dealMemoryData(memory *mem.VirtualMemoryStat) (res []map[string]any) {
 	res = append(res,
 		map[string]any{
@@ -37,6 +37,7 @@ func (c *YHCChecker) GetHostCurrentMemoryUsage(name string) (err error) {
 	if !hasSar {
 		data.DataType = define.DATATYPE_GOPSUTIL
 	}
+	mergeCgroup := c.sampleYasdbCgroup(log, define.WT_MEMORY)
 	resp, err := c.hostCurrentWorkload(log, define.METRIC_HOST_CURRENT_MEMORY_USAGE, hasSar)
 	if err != nil {
 		err = yaserr.Wrap(err)
@@ -44,6 +45,9 @@ func (c *YHCChecker) GetHostCurrentMemoryUsage(name string) (err error) {
 		data.Error = err.Error()
 		return
 	}
+	if mergeCgroup != nil {
+		mergeCgroup(resp)
+	}
 	data.Details = resp
 	return
 }
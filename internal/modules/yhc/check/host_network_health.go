@@ -0,0 +1,195 @@
+package check
+
+import (
+	"net"
+	"sort"
+	"strconv"
+	"time"
+
+	"yhc/defs/confdef"
+	"yhc/internal/modules/yhc/check/define"
+	"yhc/internal/modules/yhc/check/netstat"
+	"yhc/log"
+	"yhc/utils/mathutil"
+	"yhc/utils/yasdbutil"
+
+	"git.yasdb.com/go/yaserr"
+	"git.yasdb.com/go/yaslog"
+)
+
+const (
+	KEY_NETWORK_IFACE       = "iface"
+	KEY_NETWORK_RX_PACKETS  = "rxPackets"
+	KEY_NETWORK_RX_ERRORS   = "rxErrors"
+	KEY_NETWORK_RX_DROPPED  = "rxDropped"
+	KEY_NETWORK_RX_OVERRUNS = "rxOverruns"
+	KEY_NETWORK_RX_FRAME    = "rxFrame"
+	KEY_NETWORK_TX_PACKETS  = "txPackets"
+	KEY_NETWORK_TX_ERRORS   = "txErrors"
+	KEY_NETWORK_TX_DROPPED  = "txDropped"
+	KEY_NETWORK_TX_OVERRUNS = "txOverruns"
+	KEY_NETWORK_TX_CARRIER  = "txCarrier"
+	KEY_NETWORK_COLLISIONS  = "collisions"
+	KEY_NETWORK_SECONDS     = "seconds"
+
+	KEY_LISTEN_ADDR = "LISTEN_ADDR"
+
+	KEY_TCP_LISTEN_PORT      = "listenPort"
+	KEY_TCP_OUT_SEGS         = "outSegs"
+	KEY_TCP_RETRANS_SEGS     = "retransSegs"
+	KEY_TCP_RETRANS_RATE     = "retransRate"
+	KEY_TCP_LISTEN_OVERFLOWS = "listenOverflows"
+	KEY_TCP_LISTEN_DROPS     = "listenDrops"
+	KEY_TCP_ESTABLISHED      = "established"
+	KEY_TCP_TIME_WAIT        = "timeWait"
+	KEY_TCP_CLOSE_WAIT       = "closeWait"
+	KEY_TCP_SECONDS          = "seconds"
+)
+
+// networkSample is the increase of the network statistics over scrape_interval * scrape_times.
+type networkSample struct {
+	old *netstat.Snapshot
+	new *netstat.Snapshot
+}
+
+func (s *networkSample) seconds() float64 {
+	return mathutil.Round(s.new.Time.Sub(s.old.Time).Seconds(), decimal)
+}
+
+// getNetworkSample samples the network statistics once, the metrics of network health share the sample.
+func (c *YHCChecker) getNetworkSample(logger yaslog.YasLog) (*networkSample, error) {
+	c.networkSampleOnce.Do(func() {
+		old, err := netstat.Sample(netstat.PROC_NET)
+		if err != nil {
+			c.networkSampleErr = err
+			return
+		}
+		conf := confdef.GetYHCConf()
+		duration := time.Duration(conf.GetScrapeInterval()*conf.GetScrapeTimes()) * time.Second
+		logger.Infof("sampling network statistics for %s", duration)
+		time.Sleep(duration)
+		new, err := netstat.Sample(netstat.PROC_NET)
+		if err != nil {
+			c.networkSampleErr = err
+			return
+		}
+		c.networkSample = &networkSample{old: old, new: new}
+	})
+	return c.networkSample, c.networkSampleErr
+}
+
+// GetHostNetworkErrors reports the increase of errors, drops and overruns of each interface,
+// the interfaces matching network_io_discard are ignored.
+func (c *YHCChecker) GetHostNetworkErrors(name string) (err error) {
+	data := &define.YHCItem{Name: define.METRIC_HOST_NETWORK_ERRORS}
+	defer c.fillResults(data)
+
+	logger := log.Module.M(string(define.METRIC_HOST_NETWORK_ERRORS))
+	sample, err := c.getNetworkSample(logger)
+	if err != nil {
+		err = yaserr.Wrap(err)
+		logger.Error(err)
+		data.Error = err.Error()
+		return
+	}
+	ifaces := []string{}
+	for iface := range sample.new.Dev {
+		if confdef.IsDiscardNetwork(iface) {
+			continue
+		}
+		if _, ok := sample.old.Dev[iface]; !ok {
+			// the interface is added during sampling
+			continue
+		}
+		ifaces = append(ifaces, iface)
+	}
+	sort.Strings(ifaces)
+	res := []map[string]any{}
+	for _, iface := range ifaces {
+		delta := sample.new.Dev[iface].Sub(sample.old.Dev[iface])
+		res = append(res, map[string]any{
+			KEY_NETWORK_IFACE:       iface,
+			KEY_NETWORK_RX_PACKETS:  delta.RxPackets,
+			KEY_NETWORK_RX_ERRORS:   delta.RxErrors,
+			KEY_NETWORK_RX_DROPPED:  delta.RxDropped,
+			KEY_NETWORK_RX_OVERRUNS: delta.RxOverruns,
+			KEY_NETWORK_RX_FRAME:    delta.RxFrame,
+			KEY_NETWORK_TX_PACKETS:  delta.TxPackets,
+			KEY_NETWORK_TX_ERRORS:   delta.TxErrors,
+			KEY_NETWORK_TX_DROPPED:  delta.TxDropped,
+			KEY_NETWORK_TX_OVERRUNS: delta.TxOverruns,
+			KEY_NETWORK_TX_CARRIER:  delta.TxCarrier,
+			KEY_NETWORK_COLLISIONS:  delta.Collisions,
+			KEY_NETWORK_SECONDS:     sample.seconds(),
+		})
+	}
+	data.Details = res
+	return
+}
+
+// GetHostTcpHealth reports the TCP retransmission rate and listen queue overflows during sampling,
+// and the current connections of the yasdb listen port by state.
+func (c *YHCChecker) GetHostTcpHealth(name string) (err error) {
+	data := &define.YHCItem{Name: define.METRIC_HOST_TCP_HEALTH}
+	defer c.fillResults(data)
+
+	logger := log.Module.M(string(define.METRIC_HOST_TCP_HEALTH))
+	sample, err := c.getNetworkSample(logger)
+	if err != nil {
+		err = yaserr.Wrap(err)
+		logger.Error(err)
+		data.Error = err.Error()
+		return
+	}
+	outSegs := sample.new.Proto.Delta(sample.old.Proto, netstat.PROTO_TCP, netstat.TCP_OUT_SEGS)
+	retransSegs := sample.new.Proto.Delta(sample.old.Proto, netstat.PROTO_TCP, netstat.TCP_RETRANS_SEGS)
+	var retransRate float64
+	if outSegs > 0 {
+		retransRate = float64(retransSegs) * 100 / float64(outSegs)
+	}
+	details := map[string]any{
+		KEY_TCP_OUT_SEGS:         outSegs,
+		KEY_TCP_RETRANS_SEGS:     retransSegs,
+		KEY_TCP_RETRANS_RATE:     mathutil.Round(retransRate, decimal),
+		KEY_TCP_LISTEN_OVERFLOWS: sample.new.Proto.Delta(sample.old.Proto, netstat.PROTO_TCPEXT, netstat.TCPEXT_LISTEN_OVERFL),
+		KEY_TCP_LISTEN_DROPS:     sample.new.Proto.Delta(sample.old.Proto, netstat.PROTO_TCPEXT, netstat.TCPEXT_LISTEN_DROPS),
+		KEY_TCP_SECONDS:          sample.seconds(),
+	}
+	data.Details = details
+	port, err := c.getYasdbListenPort(logger)
+	if err != nil {
+		// the statistics of the host are still reported
+		logger.Warnf("get yasdb listen port err: %s", err.Error())
+		err = nil
+		return
+	}
+	states, err := netstat.CountTCPStates(netstat.PROC_NET, port)
+	if err != nil {
+		logger.Warnf("count tcp states of port %d err: %s", port, err.Error())
+		err = nil
+		return
+	}
+	details[KEY_TCP_LISTEN_PORT] = strconv.Itoa(port)
+	details[KEY_TCP_ESTABLISHED] = states[netstat.STATE_ESTABLISHED]
+	details[KEY_TCP_TIME_WAIT] = states[netstat.STATE_TIME_WAIT]
+	details[KEY_TCP_CLOSE_WAIT] = states[netstat.STATE_CLOSE_WAIT]
+	return
+}
+
+// getYasdbListenPort returns the port of the listen address queried as yasdb_listen_address,
+// the listen address of the check is used if the query fails.
+func (c *YHCChecker) getYasdbListenPort(logger yaslog.YasLog) (int, error) {
+	listenAddr := c.base.DBInfo.ListenAddr
+	yasdb := yasdbutil.NewYashanDB(logger, c.base.DBInfo)
+	res, err := yasdb.QueryMultiRows(define.SQL_QUERY_LISTEN_ADDR, confdef.GetYHCConf().SqlTimeout)
+	if err != nil {
+		logger.Warnf("query listen address err: %s", err.Error())
+	} else if len(res) > 0 && len(res[0][KEY_LISTEN_ADDR]) != 0 {
+		listenAddr = res[0][KEY_LISTEN_ADDR]
+	}
+	_, port, err := net.SplitHostPort(listenAddr)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(port)
+}
@@ -0,0 +1,142 @@
+package check
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"yhc/defs/confdef"
+	"yhc/internal/modules/yhc/check/define"
+	"yhc/internal/modules/yhc/check/numa"
+	"yhc/log"
+	"yhc/utils/mathutil"
+
+	"git.yasdb.com/go/yaserr"
+	"git.yasdb.com/go/yasutil/size"
+)
+
+const (
+	KEY_NUMA_NODE         = "node"
+	KEY_NUMA_CPUS         = "cpus"
+	KEY_NUMA_MEM_TOTAL    = "memTotal"
+	KEY_NUMA_MEM_FREE     = "memFree"
+	KEY_NUMA_DISTANCES    = "distances"
+	KEY_NUMA_HIT          = "numaHit"
+	KEY_NUMA_MISS         = "numaMiss"
+	KEY_NUMA_FOREIGN      = "numaForeign"
+	KEY_NUMA_MISS_PERCENT = "missPercent"
+	KEY_NUMA_SECONDS      = "seconds"
+
+	KEY_GOVERNOR           = "governor"
+	KEY_GOVERNOR_CPUS      = "cpus"
+	KEY_GOVERNOR_CPU_COUNT = "cpuCount"
+	KEY_GOVERNOR_COMPLIANT = "compliant"
+
+	GOVERNOR_PERFORMANCE = "performance"
+
+	_numa_node_prefix = "node"
+	_kb               = 1024
+)
+
+// GetHostNuma reports the memory and distances of each NUMA node, and the allocation statistics
+// increased over scrape_interval * scrape_times, since the counters of numastat are cumulative since boot.
+func (c *YHCChecker) GetHostNuma(name string) (err error) {
+	data := &define.YHCItem{Name: define.METRIC_HOST_NUMA}
+	defer c.fillResults(data)
+
+	logger := log.Module.M(string(define.METRIC_HOST_NUMA))
+	olds, err := numa.ReadNodes(numa.SYS_NODE)
+	if err != nil {
+		err = yaserr.Wrap(err)
+		logger.Error(err)
+		data.Error = err.Error()
+		return
+	}
+	start := time.Now()
+	conf := confdef.GetYHCConf()
+	duration := time.Duration(conf.GetScrapeInterval()*conf.GetScrapeTimes()) * time.Second
+	logger.Infof("sampling numa statistics for %s", duration)
+	time.Sleep(duration)
+	nodes, err := numa.ReadNodes(numa.SYS_NODE)
+	if err != nil {
+		err = yaserr.Wrap(err)
+		logger.Error(err)
+		data.Error = err.Error()
+		return
+	}
+	seconds := mathutil.Round(time.Since(start).Seconds(), decimal)
+	oldNodes := make(map[int]*numa.Node, len(olds))
+	for _, node := range olds {
+		oldNodes[node.ID] = node
+	}
+	res := []map[string]any{}
+	for _, node := range nodes {
+		old, ok := oldNodes[node.ID]
+		if !ok {
+			// the node is onlined during sampling
+			continue
+		}
+		node = node.StatSince(old)
+		distances := []string{}
+		for _, d := range node.Distances {
+			distances = append(distances, fmt.Sprint(d))
+		}
+		res = append(res, map[string]any{
+			KEY_NUMA_NODE:         fmt.Sprintf("%s%d", _numa_node_prefix, node.ID),
+			KEY_NUMA_CPUS:         node.CPUList,
+			KEY_NUMA_MEM_TOTAL:    size.GenHumanReadableSize(float64(node.MemTotalKB*_kb), decimal),
+			KEY_NUMA_MEM_FREE:     size.GenHumanReadableSize(float64(node.MemFreeKB*_kb), decimal),
+			KEY_NUMA_DISTANCES:    strings.Join(distances, " "),
+			KEY_NUMA_HIT:          node.NumaHit(),
+			KEY_NUMA_MISS:         node.NumaMiss(),
+			KEY_NUMA_FOREIGN:      node.NumaForeign(),
+			KEY_NUMA_MISS_PERCENT: mathutil.Round(node.MissPercent(), decimal),
+			KEY_NUMA_SECONDS:      seconds,
+		})
+	}
+	data.Details = res
+	return
+}
+
+// GetHostCpuGovernor groups the CPUs by the frequency scaling governor.
+func (c *YHCChecker) GetHostCpuGovernor(name string) (err error) {
+	data := &define.YHCItem{Name: define.METRIC_HOST_CPU_GOVERNOR}
+	defer c.fillResults(data)
+
+	logger := log.Module.M(string(define.METRIC_HOST_CPU_GOVERNOR))
+	governors, err := numa.ReadGovernors(numa.SYS_CPU)
+	if err != nil {
+		err = yaserr.Wrap(err)
+		logger.Error(err)
+		data.Error = err.Error()
+		return
+	}
+	if len(governors) == 0 {
+		logger.Infof("cpufreq is not available")
+	}
+	cpus := make(map[string][]int)
+	for cpu, governor := range governors {
+		cpus[governor] = append(cpus[governor], cpu)
+	}
+	names := make([]string, 0, len(cpus))
+	for governor := range cpus {
+		names = append(names, governor)
+	}
+	sort.Strings(names)
+	res := []map[string]any{}
+	for _, governor := range names {
+		compliant := STR_TRUE
+		if governor != GOVERNOR_PERFORMANCE {
+			compliant = STR_FALSE
+		}
+		res = append(res, map[string]any{
+			KEY_GOVERNOR:           governor,
+			KEY_GOVERNOR_CPUS:      numa.FormatList(cpus[governor]),
+			KEY_GOVERNOR_CPU_COUNT: len(cpus[governor]),
+			KEY_GOVERNOR_COMPLIANT: compliant,
+		})
+	}
+	data.Details = res
+	return
+}
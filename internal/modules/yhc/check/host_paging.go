@@ -0,0 +1,49 @@
+package check
+
+import (
+	"yhc/internal/modules/yhc/check/define"
+	"yhc/log"
+
+	"git.yasdb.com/go/yaserr"
+)
+
+func (c *YHCChecker) GetHostHistoryPaging(name string) (err error) {
+	data := &define.YHCItem{
+		Name: define.METRIC_HOST_HISTORY_PAGING,
+	}
+	defer c.fillResults(data)
+
+	log := log.Module.M(string(define.METRIC_HOST_HISTORY_PAGING))
+	resp, err := c.hostHistoryWorkload(log, define.METRIC_HOST_HISTORY_PAGING)
+	if err != nil {
+		err = yaserr.Wrap(err)
+		log.Error(err)
+		data.Error = err.Error()
+		return
+	}
+	data.Details = resp
+	return
+}
+
+func (c *YHCChecker) GetHostCurrentPaging(name string) (err error) {
+	data := &define.YHCItem{
+		Name:     define.METRIC_HOST_CURRENT_PAGING,
+		DataType: define.DATATYPE_SAR,
+	}
+	defer c.fillResults(data)
+
+	log := log.Module.M(string(define.METRIC_HOST_CURRENT_PAGING))
+	hasSar := c.CheckSarAccess() == nil
+	if !hasSar {
+		data.DataType = define.DATATYPE_GOPSUTIL
+	}
+	resp, err := c.hostCurrentWorkload(log, define.METRIC_HOST_CURRENT_PAGING, hasSar)
+	if err != nil {
+		err = yaserr.Wrap(err)
+		log.Error(err)
+		data.Error = err.Error()
+		return
+	}
+	data.Details = resp
+	return
+}
@@ -0,0 +1,154 @@
+package check
+
+import (
+	"fmt"
+	"time"
+
+	"yhc/defs/confdef"
+	"yhc/internal/modules/yhc/check/define"
+	"yhc/internal/modules/yhc/check/procstat"
+	"yhc/log"
+	"yhc/utils/mathutil"
+
+	"git.yasdb.com/go/yaserr"
+	"git.yasdb.com/go/yaslog"
+	"git.yasdb.com/go/yasutil/size"
+	"github.com/shirou/gopsutil/cpu"
+)
+
+const (
+	KEY_PROCESS_PID              = "pid"
+	KEY_PROCESS_NAME             = "name"
+	KEY_PROCESS_CATEGORY         = "category"
+	KEY_PROCESS_USER             = "user"
+	KEY_PROCESS_CPU_AVG          = "cpuAvg"
+	KEY_PROCESS_CPU_MAX          = "cpuMax"
+	KEY_PROCESS_HOST_CPU_PERCENT = "hostCpuPercent"
+	KEY_PROCESS_RSS_MAX          = "rssMax"
+	KEY_PROCESS_READ_BYTES       = "readBytes"
+	KEY_PROCESS_WRITE_BYTES      = "writeBytes"
+	KEY_PROCESS_CTX_SWITCHES     = "ctxSwitches"
+	KEY_PROCESS_THREADS          = "threads"
+	KEY_PROCESS_EXE              = "exe"
+
+	// PROCESS_USAGE_TOP_N is the number of other processes reported besides the YashanDB processes
+	PROCESS_USAGE_TOP_N = 10
+)
+
+// processChartUsage is the usage of a process drawn in the workload charts.
+type processChartUsage struct {
+	CPUPercent        float64 `json:"cpuPercent"`
+	RSSMB             float64 `json:"rssMB"`
+	ReadKBPerSec      float64 `json:"readKBPerSec"`
+	WriteKBPerSec     float64 `json:"writeKBPerSec"`
+	CtxSwitchesPerSec float64 `json:"ctxSwitchesPerSec"`
+	Threads           int32   `json:"threads"`
+}
+
+// getProcessSamples samples all processes scrape_times times, the metrics of process usage share the samples.
+func (c *YHCChecker) getProcessSamples(logger yaslog.YasLog) ([]*procstat.Sample, error) {
+	c.processSampleOnce.Do(func() {
+		conf := confdef.GetYHCConf()
+		interval, times := time.Duration(conf.GetScrapeInterval())*time.Second, conf.GetScrapeTimes()
+		logger.Infof("sampling process usage %d times every %s", times, interval)
+		for i := 0; i <= times; i++ {
+			if i != 0 {
+				time.Sleep(interval)
+			}
+			sample, err := procstat.Collect()
+			if err != nil {
+				c.processSampleErr = err
+				return
+			}
+			c.processSamples = append(c.processSamples, sample)
+		}
+	})
+	return c.processSamples, c.processSampleErr
+}
+
+// getTopProcesses returns the YashanDB processes and the top other processes over the check window.
+func (c *YHCChecker) getTopProcesses(logger yaslog.YasLog) ([]*procstat.Sample, []*procstat.Summary, error) {
+	samples, err := c.getProcessSamples(logger)
+	if err != nil {
+		return nil, nil, err
+	}
+	return samples, procstat.Top(procstat.Summarize(samples), PROCESS_USAGE_TOP_N), nil
+}
+
+// GetHostProcessUsage reports the CPU, memory, I/O, context switches and threads of the YashanDB processes
+// and the top other processes, so that it can be told which process consumes the host resources.
+func (c *YHCChecker) GetHostProcessUsage(name string) (err error) {
+	data := &define.YHCItem{Name: define.METRIC_HOST_PROCESS_USAGE}
+	defer c.fillResults(data)
+
+	logger := log.Module.M(string(define.METRIC_HOST_PROCESS_USAGE))
+	_, summaries, err := c.getTopProcesses(logger)
+	if err != nil {
+		err = yaserr.Wrap(err)
+		logger.Error(err)
+		data.Error = err.Error()
+		return
+	}
+	cpus, err := cpu.Counts(true)
+	if err != nil || cpus == 0 {
+		logger.Warnf("failed to get logical cpu count, err: %v", err)
+		cpus, err = 1, nil
+	}
+	res := []map[string]interface{}{}
+	for _, summary := range summaries {
+		res = append(res, map[string]interface{}{
+			KEY_PROCESS_PID:              summary.Pid,
+			KEY_PROCESS_NAME:             summary.Name,
+			KEY_PROCESS_CATEGORY:         summary.Category,
+			KEY_PROCESS_USER:             summary.User,
+			KEY_PROCESS_CPU_AVG:          mathutil.Round(summary.CPUAvg, decimal),
+			KEY_PROCESS_CPU_MAX:          mathutil.Round(summary.CPUMax, decimal),
+			KEY_PROCESS_HOST_CPU_PERCENT: mathutil.Round(summary.CPUAvg/float64(cpus), decimal),
+			KEY_PROCESS_RSS_MAX:          size.GenHumanReadableSize(float64(summary.RSSMax), decimal),
+			KEY_PROCESS_READ_BYTES:       size.GenHumanReadableSize(float64(summary.ReadBytes), decimal),
+			KEY_PROCESS_WRITE_BYTES:      size.GenHumanReadableSize(float64(summary.WriteBytes), decimal),
+			KEY_PROCESS_CTX_SWITCHES:     summary.CtxSwitches,
+			KEY_PROCESS_THREADS:          summary.ThreadsMax,
+			KEY_PROCESS_EXE:              summary.Exe,
+		})
+	}
+	data.Details = res
+	return
+}
+
+// GetHostCurrentProcessUsage reports the usage of the YashanDB processes and the top other processes at every sample,
+// which is drawn as the workload charts.
+func (c *YHCChecker) GetHostCurrentProcessUsage(name string) (err error) {
+	data := &define.YHCItem{Name: define.METRIC_HOST_CURRENT_PROCESS_USAGE}
+	defer c.fillResults(data)
+
+	logger := log.Module.M(string(define.METRIC_HOST_CURRENT_PROCESS_USAGE))
+	samples, summaries, err := c.getTopProcesses(logger)
+	if err != nil {
+		err = yaserr.Wrap(err)
+		logger.Error(err)
+		data.Error = err.Error()
+		return
+	}
+	res := make(define.WorkloadOutput)
+	for t, usages := range procstat.Usages(samples) {
+		item := make(define.WorkloadItem)
+		for _, summary := range summaries {
+			usage, ok := usages[summary.Pid]
+			if !ok {
+				continue
+			}
+			item[fmt.Sprintf("%s(%d)", summary.Name, summary.Pid)] = processChartUsage{
+				CPUPercent:        mathutil.Round(usage.CPUPercent, decimal),
+				RSSMB:             mathutil.Round(float64(usage.RSS)/1024/1024, decimal),
+				ReadKBPerSec:      mathutil.Round(usage.ReadBytesPerSec/1024, decimal),
+				WriteKBPerSec:     mathutil.Round(usage.WriteBytesPerSec/1024, decimal),
+				CtxSwitchesPerSec: mathutil.Round(usage.CtxSwitchesPerSec, decimal),
+				Threads:           usage.Threads,
+			}
+		}
+		res[t.Unix()] = item
+	}
+	data.Details = res
+	return
+}
@@ -0,0 +1,346 @@
+package check
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"os/user"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"yhc/defs/timedef"
+	"yhc/internal/modules/yhc/check/define"
+	"yhc/internal/modules/yhc/check/hostsec"
+	"yhc/internal/modules/yhc/check/sysctl"
+	"yhc/log"
+	"yhc/utils/fileutil"
+	"yhc/utils/processutil"
+	"yhc/utils/userutil"
+
+	"git.yasdb.com/go/yaserr"
+)
+
+const (
+	KEY_SECURITY_SELINUX           = "selinux"
+	KEY_SECURITY_SELINUX_CONFIG    = "selinuxConfig"
+	KEY_SECURITY_APPARMOR          = "apparmor"
+	KEY_SECURITY_APPARMOR_PROFILES = "apparmorProfiles"
+	KEY_SECURITY_APPARMOR_ENFORCE  = "apparmorEnforce"
+	KEY_SECURITY_APPARMOR_COMPLAIN = "apparmorComplain"
+
+	KEY_SSHD_NAME        = "name"
+	KEY_SSHD_VALUE       = "value"
+	KEY_SSHD_SOURCE      = "source"
+	KEY_SSHD_RECOMMENDED = "recommended"
+	KEY_SSHD_COMPLIANT   = "compliant"
+
+	KEY_HOME_FILE_PATH       = "filePath"
+	KEY_HOME_FILE_PERMISSION = "permission"
+	KEY_HOME_FILE_OWNER      = "owner"
+	KEY_HOME_FILE_ISSUE      = "issue"
+
+	KEY_CORE_PATTERN       = "corePattern"
+	KEY_CORE_LOCATION      = "coreLocation"
+	KEY_CORE_SUID_DUMPABLE = "suidDumpable"
+
+	KEY_PASSWORD_USER            = "user"
+	KEY_PASSWORD_LOCKED          = "locked"
+	KEY_PASSWORD_LAST_CHANGED    = "lastChanged"
+	KEY_PASSWORD_MIN_DAYS        = "minDays"
+	KEY_PASSWORD_MAX_DAYS        = "maxDays"
+	KEY_PASSWORD_WARN_DAYS       = "warnDays"
+	KEY_PASSWORD_INACTIVE_DAYS   = "inactiveDays"
+	KEY_PASSWORD_EXPIRES         = "passwordExpires"
+	KEY_PASSWORD_DAYS_TO_EXPIRE  = "daysToExpire"
+	KEY_PASSWORD_NEVER_EXPIRES   = "neverExpires"
+	KEY_PASSWORD_ACCOUNT_EXPIRES = "accountExpires"
+
+	KEY_SUDO_FILE         = "file"
+	KEY_SUDO_RULE         = "rule"
+	KEY_SUDO_RUN_AS       = "runAs"
+	KEY_SUDO_NOPASSWD     = "nopasswd"
+	KEY_SUDO_COMMANDS     = "commands"
+	KEY_SUDO_ALL_COMMANDS = "allCommands"
+
+	SSHD_SOURCE_CONFIG  = "config"
+	SSHD_SOURCE_DEFAULT = "default"
+
+	HOME_FILE_WORLD_WRITABLE = "world_writable"
+	HOME_FILE_SETUID         = "setuid"
+	HOME_FILE_SETGID         = "setgid"
+
+	CORE_LOCATION_PIPE     = "pipe"
+	CORE_LOCATION_ABSOLUTE = "absolute"
+	CORE_LOCATION_RELATIVE = "relative"
+
+	SYSCTL_CORE_PATTERN  = "kernel.core_pattern"
+	SYSCTL_SUID_DUMPABLE = "fs.suid_dumpable"
+
+	NOT_SET = "-"
+
+	// _specialPermMask is the permission bits with the setuid, setgid and sticky bits
+	_specialPermMask = os.ModePerm | os.ModeSetuid | os.ModeSetgid | os.ModeSticky
+)
+
+// _sshdRecommended are the recommended sshd settings, in the order of the report
+var _sshdRecommended = []struct {
+	name  string
+	value string
+}{
+	{hostsec.SSHD_PERMIT_ROOT_LOGIN, "no"},
+	{hostsec.SSHD_PASSWORD_AUTHENTICATION, "no"},
+	{hostsec.SSHD_PERMIT_EMPTY_PASSWORDS, "no"},
+}
+
+// GetHostSecurityMac reports the mode of mandatory access control, SELinux and AppArmor.
+func (c *YHCChecker) GetHostSecurityMac(name string) (err error) {
+	data := &define.YHCItem{Name: define.METRIC_HOST_SECURITY_MAC}
+	defer c.fillResults(data)
+
+	selinux, selinuxConfig := hostsec.ReadSELinux("/")
+	apparmor := hostsec.ReadAppArmor("/")
+	data.Details = map[string]interface{}{
+		KEY_SECURITY_SELINUX:           selinux,
+		KEY_SECURITY_SELINUX_CONFIG:    selinuxConfig,
+		KEY_SECURITY_APPARMOR:          apparmor.Mode,
+		KEY_SECURITY_APPARMOR_PROFILES: apparmor.Profiles,
+		KEY_SECURITY_APPARMOR_ENFORCE:  apparmor.Enforce,
+		KEY_SECURITY_APPARMOR_COMPLAIN: apparmor.Complain,
+	}
+	return
+}
+
+// GetHostSshdConfig reports the sshd settings about root and password login.
+func (c *YHCChecker) GetHostSshdConfig(name string) (err error) {
+	data := &define.YHCItem{Name: define.METRIC_HOST_SSHD_CONFIG}
+	defer c.fillResults(data)
+
+	logger := log.Module.M(string(define.METRIC_HOST_SSHD_CONFIG))
+	conf, err := hostsec.ParseSshdConfig(hostsec.SSHD_CONFIG)
+	if err != nil {
+		err = yaserr.Wrap(err)
+		logger.Error(err)
+		data.Error = err.Error()
+		return
+	}
+	res := []map[string]interface{}{}
+	for _, item := range _sshdRecommended {
+		value, source := conf[strings.ToLower(item.name)], SSHD_SOURCE_CONFIG
+		if len(value) == 0 {
+			value, source = hostsec.SshdDefaults[item.name], SSHD_SOURCE_DEFAULT
+		}
+		res = append(res, map[string]interface{}{
+			KEY_SSHD_NAME:        item.name,
+			KEY_SSHD_VALUE:       value,
+			KEY_SSHD_SOURCE:      source,
+			KEY_SSHD_RECOMMENDED: item.value,
+			KEY_SSHD_COMPLIANT:   boolToString(strings.EqualFold(value, item.value)),
+		})
+	}
+	data.Details = res
+	return
+}
+
+// GetHostYasdbHomeFiles reports the world-writable, setuid and setgid files under YASDB_HOME.
+func (c *YHCChecker) GetHostYasdbHomeFiles(name string) (err error) {
+	data := &define.YHCItem{Name: define.METRIC_HOST_YASDB_HOME_FILES}
+	defer c.fillResults(data)
+
+	logger := log.Module.M(string(define.METRIC_HOST_YASDB_HOME_FILES))
+	permissionMap, errs := fileutil.GetFilesMode(c.base.DBInfo.YasdbHome)
+	for filePath, e := range errs {
+		logger.Warnf("failed to get permission of %s, err: %v", filePath, e)
+	}
+	res := []map[string]interface{}{}
+	for filePath, fileMode := range permissionMap {
+		var issues []string
+		// the permissions of symlinks are always 0777 and never used
+		if fileutil.CheckOtherWrite(fileMode) && fileMode&os.ModeSymlink == 0 {
+			issues = append(issues, HOME_FILE_WORLD_WRITABLE)
+		}
+		if fileMode&os.ModeSetuid != 0 {
+			issues = append(issues, HOME_FILE_SETUID)
+		}
+		if fileMode&os.ModeSetgid != 0 {
+			issues = append(issues, HOME_FILE_SETGID)
+		}
+		if len(issues) == 0 {
+			continue
+		}
+		var owner string
+		if o, e := fileutil.GetOwner(filePath); e == nil {
+			owner = o.Username
+		}
+		for _, issue := range issues {
+			res = append(res, map[string]interface{}{
+				KEY_HOME_FILE_PATH:       filePath,
+				KEY_HOME_FILE_PERMISSION: (fileMode & _specialPermMask).String(),
+				KEY_HOME_FILE_OWNER:      owner,
+				KEY_HOME_FILE_ISSUE:      issue,
+			})
+		}
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i][KEY_HOME_FILE_PATH].(string) < res[j][KEY_HOME_FILE_PATH].(string)
+	})
+	data.Details = res
+	return
+}
+
+// GetHostCoreDump reports where the core files are written and whether setuid programs dump core.
+func (c *YHCChecker) GetHostCoreDump(name string) (err error) {
+	data := &define.YHCItem{Name: define.METRIC_HOST_CORE_DUMP}
+	defer c.fillResults(data)
+
+	logger := log.Module.M(string(define.METRIC_HOST_CORE_DUMP))
+	pattern, err := sysctl.Read(sysctl.PROC_SYS, SYSCTL_CORE_PATTERN)
+	if err != nil {
+		err = yaserr.Wrap(err)
+		logger.Error(err)
+		data.Error = err.Error()
+		return
+	}
+	suidDumpable, err := sysctl.Read(sysctl.PROC_SYS, SYSCTL_SUID_DUMPABLE)
+	if err != nil {
+		err = yaserr.Wrap(err)
+		logger.Error(err)
+		data.Error = err.Error()
+		return
+	}
+	location := CORE_LOCATION_RELATIVE
+	if strings.HasPrefix(pattern, "|") {
+		location = CORE_LOCATION_PIPE
+	} else if path.IsAbs(pattern) {
+		location = CORE_LOCATION_ABSOLUTE
+	}
+	data.Details = map[string]interface{}{
+		KEY_CORE_PATTERN:       pattern,
+		KEY_CORE_LOCATION:      location,
+		KEY_CORE_SUID_DUMPABLE: suidDumpable,
+	}
+	return
+}
+
+// GetHostYasdbUserPassword reports the password aging of the OS user running yasdb.
+func (c *YHCChecker) GetHostYasdbUserPassword(name string) (err error) {
+	data := &define.YHCItem{Name: define.METRIC_HOST_YASDB_USER_PASSWORD}
+	defer c.fillResults(data)
+
+	logger := log.Module.M(string(define.METRIC_HOST_YASDB_USER_PASSWORD))
+	username, err := c.getYasdbOSUser()
+	if err != nil {
+		err = yaserr.Wrap(err)
+		logger.Error(err)
+		data.Error = err.Error()
+		return
+	}
+	f, err := os.Open(hostsec.SHADOW)
+	if err != nil {
+		err = yaserr.Wrap(err)
+		logger.Error(err)
+		data.Error = err.Error()
+		return
+	}
+	defer f.Close()
+	shadow, err := hostsec.ParseShadow(f, username)
+	if err == nil && shadow == nil {
+		err = fmt.Errorf("user %s not found in %s", username, hostsec.SHADOW)
+	}
+	if err != nil {
+		err = yaserr.Wrap(err)
+		logger.Error(err)
+		data.Error = err.Error()
+		return
+	}
+	res := map[string]interface{}{
+		KEY_PASSWORD_USER:            username,
+		KEY_PASSWORD_LOCKED:          boolToString(shadow.Locked),
+		KEY_PASSWORD_LAST_CHANGED:    formatShadowDate(shadow.LastChange),
+		KEY_PASSWORD_MIN_DAYS:        formatShadowDays(shadow.MinDays),
+		KEY_PASSWORD_MAX_DAYS:        formatShadowDays(shadow.MaxDays),
+		KEY_PASSWORD_WARN_DAYS:       formatShadowDays(shadow.WarnDays),
+		KEY_PASSWORD_INACTIVE_DAYS:   formatShadowDays(shadow.InactiveDays),
+		KEY_PASSWORD_ACCOUNT_EXPIRES: formatShadowDate(shadow.Expire),
+		KEY_PASSWORD_EXPIRES:         NOT_SET,
+		KEY_PASSWORD_NEVER_EXPIRES:   STR_TRUE,
+	}
+	if expires, ok := shadow.PasswordExpires(); ok {
+		res[KEY_PASSWORD_EXPIRES] = expires.Format(timedef.TIME_FORMAT_DATE)
+		res[KEY_PASSWORD_NEVER_EXPIRES] = STR_FALSE
+		res[KEY_PASSWORD_DAYS_TO_EXPIRE] = int(math.Floor(time.Until(expires).Hours() / 24))
+	}
+	data.Details = res
+	return
+}
+
+// GetHostYasdbUserSudo reports the sudo rules which allow the OS user running yasdb to run commands as root.
+func (c *YHCChecker) GetHostYasdbUserSudo(name string) (err error) {
+	data := &define.YHCItem{Name: define.METRIC_HOST_YASDB_USER_SUDO}
+	defer c.fillResults(data)
+
+	logger := log.Module.M(string(define.METRIC_HOST_YASDB_USER_SUDO))
+	username, err := c.getYasdbOSUser()
+	if err != nil {
+		err = yaserr.Wrap(err)
+		logger.Error(err)
+		data.Error = err.Error()
+		return
+	}
+	var groups []string
+	if u, e := user.Lookup(username); e == nil {
+		groups = userutil.GetUserGroups(u)
+	}
+	rules, err := hostsec.ParseSudoers(hostsec.SUDOERS, username, groups)
+	if err != nil {
+		err = yaserr.Wrap(err)
+		logger.Error(err)
+		data.Error = err.Error()
+		return
+	}
+	res := []map[string]interface{}{}
+	for _, rule := range rules {
+		res = append(res, map[string]interface{}{
+			KEY_SUDO_FILE:         rule.File,
+			KEY_SUDO_RULE:         rule.Rule,
+			KEY_SUDO_RUN_AS:       rule.RunAs,
+			KEY_SUDO_NOPASSWD:     boolToString(rule.NoPasswd),
+			KEY_SUDO_COMMANDS:     rule.Commands,
+			KEY_SUDO_ALL_COMMANDS: boolToString(rule.AllCommands),
+		})
+	}
+	data.Details = res
+	return
+}
+
+// getYasdbOSUser returns the OS user running yasdb, or the owner of YASDB_HOME if yasdb is not running.
+func (c *YHCChecker) getYasdbOSUser() (string, error) {
+	processes, err := processutil.GetYasdbProcess(c.base.DBInfo.YasdbData)
+	if err == nil && len(processes) != 0 && len(processes[0].User) != 0 {
+		return processes[0].User, nil
+	}
+	owner, err := fileutil.GetOwner(c.base.DBInfo.YasdbHome)
+	if err != nil {
+		return "", err
+	}
+	if len(owner.Username) == 0 {
+		return "", errors.New("failed to get the OS user of yasdb")
+	}
+	return owner.Username, nil
+}
+
+func formatShadowDays(days int) interface{} {
+	if days == hostsec.NEVER {
+		return NOT_SET
+	}
+	return days
+}
+
+func formatShadowDate(days int) string {
+	if days == hostsec.NEVER || days == 0 {
+		return NOT_SET
+	}
+	return hostsec.DaysToTime(days).Format(timedef.TIME_FORMAT_DATE)
+}
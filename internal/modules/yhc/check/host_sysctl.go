@@ -0,0 +1,67 @@
+package check
+
+import (
+	"errors"
+	"os"
+
+	"yhc/defs/confdef"
+	"yhc/internal/modules/yhc/check/define"
+	"yhc/internal/modules/yhc/check/sysctl"
+	"yhc/log"
+)
+
+const (
+	KEY_SYSCTL_NAME        = "name"
+	KEY_SYSCTL_CURRENT     = "current"
+	KEY_SYSCTL_RECOMMENDED = "recommended"
+	KEY_SYSCTL_COMPLIANT   = "compliant"
+	KEY_SYSCTL_DESCRIPTION = "description"
+)
+
+// GetHostSysctl compares the kernel parameters in /proc/sys to the baseline scaled by the host memory.
+func (c *YHCChecker) GetHostSysctl(name string) (err error) {
+	data := &define.YHCItem{Name: define.METRIC_HOST_SYSCTL}
+	defer c.fillResults(data)
+
+	logger := log.Module.M(string(define.METRIC_HOST_SYSCTL))
+	baseline := confdef.GetSysctlBaseline()
+	if baseline == nil || len(baseline.Parameters) == 0 {
+		err = errors.New("sysctl baseline is not configured")
+		logger.Error(err)
+		data.Error = err.Error()
+		return
+	}
+	memInfo, err := c.getHostMemory(logger)
+	if err != nil {
+		data.Error = err.Error()
+		return
+	}
+	pageSize := os.Getpagesize()
+	res := []map[string]string{}
+	for _, param := range baseline.Parameters {
+		current, readErr := sysctl.Read(sysctl.PROC_SYS, param.Name)
+		if readErr != nil {
+			// the parameter may not exist in the kernel version
+			logger.Warnf("read %s err: %s", param.Name, readErr.Error())
+			continue
+		}
+		recommended := sysctl.Recommend(param, memInfo.Total, pageSize)
+		ok, compareErr := sysctl.Compare(param.Compare, current, recommended)
+		if compareErr != nil {
+			logger.Warnf("compare %s err: %s", param.Name, compareErr.Error())
+		}
+		compliant := STR_FALSE
+		if ok {
+			compliant = STR_TRUE
+		}
+		res = append(res, map[string]string{
+			KEY_SYSCTL_NAME:        param.Name,
+			KEY_SYSCTL_CURRENT:     current,
+			KEY_SYSCTL_RECOMMENDED: sysctl.Format(param.Compare, recommended),
+			KEY_SYSCTL_COMPLIANT:   compliant,
+			KEY_SYSCTL_DESCRIPTION: param.GetDescription(),
+		})
+	}
+	data.Details = res
+	return
+}
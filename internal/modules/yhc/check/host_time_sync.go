@@ -0,0 +1,78 @@
+package check
+
+import (
+	"errors"
+	"math"
+
+	"yhc/defs/bashdef"
+	"yhc/internal/modules/yhc/check/define"
+	"yhc/internal/modules/yhc/check/timesync"
+	"yhc/log"
+	"yhc/utils/execerutil"
+	"yhc/utils/mathutil"
+
+	"git.yasdb.com/go/yaserr"
+	"git.yasdb.com/go/yaslog"
+)
+
+const (
+	KEY_TIME_SYNC_SERVICE      = "service"
+	KEY_TIME_SYNC_SYNCHRONIZED = "synchronized"
+	KEY_TIME_SYNC_SOURCE       = "source"
+	KEY_TIME_SYNC_OFFSET_MS    = "offsetMs"
+	KEY_TIME_SYNC_ABS_OFFSET   = "absOffsetMs"
+	KEY_TIME_SYNC_STRATUM      = "stratum"
+)
+
+// GetHostTimeSync detects chronyd or ntpd and reports the clock synchronization status of the host,
+// timedatectl is used when neither of them is running.
+func (c *YHCChecker) GetHostTimeSync(name string) (err error) {
+	data := &define.YHCItem{Name: define.METRIC_HOST_TIME_SYNC}
+	defer c.fillResults(data)
+
+	logger := log.Module.M(string(define.METRIC_HOST_TIME_SYNC))
+	status, err := getTimeSyncStatus(logger)
+	if err != nil {
+		err = yaserr.Wrap(err)
+		logger.Error(err)
+		data.Error = err.Error()
+		return
+	}
+	synchronized := STR_FALSE
+	if status.Synchronized {
+		synchronized = STR_TRUE
+	}
+	data.Details = map[string]any{
+		KEY_TIME_SYNC_SERVICE:      status.Service,
+		KEY_TIME_SYNC_SYNCHRONIZED: synchronized,
+		KEY_TIME_SYNC_SOURCE:       status.Source,
+		KEY_TIME_SYNC_OFFSET_MS:    mathutil.Round(status.OffsetMs, decimal),
+		KEY_TIME_SYNC_ABS_OFFSET:   mathutil.Round(math.Abs(status.OffsetMs), decimal),
+		KEY_TIME_SYNC_STRATUM:      status.Stratum,
+	}
+	return
+}
+
+func getTimeSyncStatus(logger yaslog.YasLog) (*timesync.Status, error) {
+	execer := execerutil.NewExecer(logger)
+	ret, stdout, stderr := execer.EnvExec(_envs, bashdef.CMD_CHRONYC, "tracking")
+	if ret == 0 {
+		return timesync.ParseChronyTracking(stdout)
+	}
+	logger.Infof("chronyd is not available, stderr: %s", stderr)
+	ret, stdout, stderr = execer.EnvExec(_envs, bashdef.CMD_NTPQ, "-pn")
+	if ret == 0 {
+		status, err := timesync.ParseNtpq(stdout)
+		if err == timesync.ErrNoSyncPeer {
+			// ntpd is running but not synchronized yet
+			return status, nil
+		}
+		return status, err
+	}
+	logger.Infof("ntpd is not available, stderr: %s", stderr)
+	ret, stdout, stderr = execer.EnvExec(_envs, bashdef.CMD_TIMEDATECTL, "status")
+	if ret != 0 {
+		return nil, errors.New(stderr)
+	}
+	return timesync.ParseTimedatectl(stdout), nil
+}
@@ -0,0 +1,221 @@
+package check
+
+import (
+	"path"
+	"sort"
+	"strings"
+
+	"yhc/defs/confdef"
+	"yhc/internal/modules/yhc/check/blockdev"
+	"yhc/internal/modules/yhc/check/define"
+	"yhc/log"
+	"yhc/utils/osutil"
+	"yhc/utils/yasdbutil"
+
+	"git.yasdb.com/go/yaserr"
+	"git.yasdb.com/go/yaslog"
+	"github.com/shirou/gopsutil/disk"
+)
+
+const (
+	KEY_ARCHIVE_DEST = "ARCHIVE_DEST"
+
+	KEY_BLOCK_DEVICE_ROLES         = "roles"
+	KEY_BLOCK_DEVICE_MOUNT_POINT   = "mountPoint"
+	KEY_BLOCK_DEVICE_FSTYPE        = "fstype"
+	KEY_BLOCK_DEVICE_MOUNT_OPTIONS = "mountOptions"
+	KEY_BLOCK_DEVICE_DEVICE        = "device"
+	KEY_BLOCK_DEVICE_DISK          = "disk"
+	KEY_BLOCK_DEVICE_SCHEDULER     = "scheduler"
+	KEY_BLOCK_DEVICE_SCHEDULER_OK  = "schedulerCompliant"
+	KEY_BLOCK_DEVICE_ROTATIONAL    = "rotational"
+	KEY_BLOCK_DEVICE_NR_REQUESTS   = "nrRequests"
+	KEY_BLOCK_DEVICE_READ_AHEAD_KB = "readAheadKb"
+	KEY_BLOCK_DEVICE_WRITE_CACHE   = "writeCache"
+	KEY_BLOCK_DEVICE_NOATIME       = "noatime"
+	KEY_BLOCK_DEVICE_BARRIER       = "barrier"
+
+	ROLE_DATA    = "data"
+	ROLE_REDO    = "redo"
+	ROLE_ARCHIVE = "archive"
+
+	// the path of database files may start with '?', which means the data directory
+	_yasdb_data_placeholder = "?"
+	// the path of database files in a YFS disk group starts with '+'
+	_yfs_prefix = "+"
+)
+
+var (
+	// schedulers fit for database workloads, the schedulers of old single queue kernels are included
+	_ssdSchedulers = map[string]struct{}{"none": {}, "noop": {}, "mq-deadline": {}, "deadline": {}, "kyber": {}}
+	_hddSchedulers = map[string]struct{}{"mq-deadline": {}, "deadline": {}}
+)
+
+type mountedPaths struct {
+	partition disk.PartitionStat
+	roles     map[string]struct{}
+}
+
+// GetHostYasdbBlockDevice resolves the block devices hosting the data files, redo logs and archive logs,
+// and reports the queue settings of the disks and the mount options of the file systems.
+func (c *YHCChecker) GetHostYasdbBlockDevice(name string) (err error) {
+	data := &define.YHCItem{Name: define.METRIC_HOST_YASDB_BLOCK_DEVICE}
+	defer c.fillResults(data)
+
+	logger := log.Module.M(string(define.METRIC_HOST_YASDB_BLOCK_DEVICE))
+	partitions, err := disk.Partitions(false)
+	if err != nil {
+		err = yaserr.Wrap(err)
+		logger.Error(err)
+		data.Error = err.Error()
+		return
+	}
+	devices, err := osutil.Lsblk(logger)
+	if err != nil {
+		err = yaserr.Wrap(err)
+		logger.Error(err)
+		data.Error = err.Error()
+		return
+	}
+	mounts := make(map[string]*mountedPaths)
+	for role, paths := range c.getYasdbFilePaths(logger) {
+		for _, p := range paths {
+			partition, ok := findPartition(partitions, p)
+			if !ok {
+				logger.Warnf("mount point of %s not found", p)
+				continue
+			}
+			mount, ok := mounts[partition.Mountpoint]
+			if !ok {
+				mount = &mountedPaths{partition: partition, roles: make(map[string]struct{})}
+				mounts[partition.Mountpoint] = mount
+			}
+			mount.roles[role] = struct{}{}
+		}
+	}
+	mountPoints := make([]string, 0, len(mounts))
+	for mountPoint := range mounts {
+		mountPoints = append(mountPoints, mountPoint)
+	}
+	sort.Strings(mountPoints)
+	res := []map[string]any{}
+	for _, mountPoint := range mountPoints {
+		mount := mounts[mountPoint]
+		kname := blockdev.KName(mount.partition.Device)
+		if len(kname) == 0 {
+			logger.Infof("skip %s mounted on %s which is not a block device", mount.partition.Device, mountPoint)
+			continue
+		}
+		for _, diskName := range blockdev.Resolve(blockdev.SYS_CLASS_BLOCK, kname, devices) {
+			queue, e := blockdev.ReadQueue(blockdev.SYS_CLASS_BLOCK, diskName)
+			if e != nil {
+				logger.Warnf("read queue of %s err: %s", diskName, e.Error())
+				continue
+			}
+			res = append(res, genBlockDeviceRow(mount, diskName, queue))
+		}
+	}
+	data.Details = res
+	return
+}
+
+// getYasdbFilePaths returns the paths of the database files by role, the data directory is always included as data,
+// the paths which can not be queried are skipped.
+func (c *YHCChecker) getYasdbFilePaths(logger yaslog.YasLog) map[string][]string {
+	res := map[string][]string{ROLE_DATA: {c.base.DBInfo.YasdbData}}
+	queries := []struct {
+		role   string
+		sql    string
+		column string
+	}{
+		{role: ROLE_DATA, sql: define.SQL_QUERY_DATAFILE, column: KEY_FILE_NAME},
+		{role: ROLE_REDO, sql: define.SQL_QUERY_LOGFILE_NAME, column: COLUMN_NAME},
+		{role: ROLE_ARCHIVE, sql: define.SQL_QUERY_ARCHIVE_LOCAL_DEST, column: KEY_ARCHIVE_DEST},
+	}
+	yasdb := yasdbutil.NewYashanDB(logger, c.base.DBInfo)
+	for _, query := range queries {
+		rows, err := yasdb.QueryMultiRows(query.sql, confdef.GetYHCConf().SqlTimeout)
+		if err != nil {
+			logger.Warnf("query %s paths err: %s", query.role, err.Error())
+			continue
+		}
+		for _, row := range rows {
+			p := c.resolveYasdbPath(row[query.column])
+			if len(p) == 0 {
+				continue
+			}
+			res[query.role] = append(res[query.role], p)
+		}
+	}
+	return res
+}
+
+func (c *YHCChecker) resolveYasdbPath(p string) string {
+	p = strings.TrimSpace(p)
+	if len(p) == 0 || strings.HasPrefix(p, _yfs_prefix) {
+		return ""
+	}
+	if strings.HasPrefix(p, _yasdb_data_placeholder) {
+		return path.Join(c.base.DBInfo.YasdbData, strings.TrimPrefix(p, _yasdb_data_placeholder))
+	}
+	if !path.IsAbs(p) {
+		return path.Join(c.base.DBInfo.YasdbData, p)
+	}
+	return p
+}
+
+// findPartition returns the partition with the longest mount point containing the path.
+func findPartition(partitions []disk.PartitionStat, p string) (disk.PartitionStat, bool) {
+	var res disk.PartitionStat
+	found := false
+	for _, partition := range partitions {
+		mountPoint := partition.Mountpoint
+		if p != mountPoint && mountPoint != "/" && !strings.HasPrefix(p, mountPoint+"/") {
+			continue
+		}
+		if !found || len(mountPoint) > len(res.Mountpoint) {
+			res, found = partition, true
+		}
+	}
+	return res, found
+}
+
+func genBlockDeviceRow(mount *mountedPaths, diskName string, queue *blockdev.Queue) map[string]any {
+	roles := make([]string, 0, len(mount.roles))
+	for role := range mount.roles {
+		roles = append(roles, role)
+	}
+	sort.Strings(roles)
+	schedulers := _hddSchedulers
+	if !queue.Rotational {
+		schedulers = _ssdSchedulers
+	}
+	schedulerCompliant := STR_FALSE
+	if _, ok := schedulers[queue.Scheduler]; ok {
+		schedulerCompliant = STR_TRUE
+	}
+	options := mount.partition.Opts
+	return map[string]any{
+		KEY_BLOCK_DEVICE_ROLES:         strings.Join(roles, ","),
+		KEY_BLOCK_DEVICE_MOUNT_POINT:   mount.partition.Mountpoint,
+		KEY_BLOCK_DEVICE_FSTYPE:        mount.partition.Fstype,
+		KEY_BLOCK_DEVICE_MOUNT_OPTIONS: options,
+		KEY_BLOCK_DEVICE_DEVICE:        mount.partition.Device,
+		KEY_BLOCK_DEVICE_DISK:          diskName,
+		KEY_BLOCK_DEVICE_SCHEDULER:     queue.Scheduler,
+		KEY_BLOCK_DEVICE_SCHEDULER_OK:  schedulerCompliant,
+		KEY_BLOCK_DEVICE_ROTATIONAL:    boolToString(queue.Rotational),
+		KEY_BLOCK_DEVICE_NR_REQUESTS:   queue.NrRequests,
+		KEY_BLOCK_DEVICE_READ_AHEAD_KB: queue.ReadAheadKB,
+		KEY_BLOCK_DEVICE_WRITE_CACHE:   queue.WriteCache,
+		KEY_BLOCK_DEVICE_NOATIME:       boolToString(blockdev.HasMountOption(options, blockdev.MOUNT_OPTION_NOATIME)),
+		KEY_BLOCK_DEVICE_BARRIER:       boolToString(blockdev.BarrierEnabled(options)),
+	}
+}
+
+func boolToString(b bool) string {
+	if b {
+		return STR_TRUE
+	}
+	return STR_FALSE
+}
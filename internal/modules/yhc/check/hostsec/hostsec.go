@@ -0,0 +1,450 @@
+// The hostsec package reads the security settings of the host, such as SELinux, AppArmor, sshd,
+// password aging and sudo rules.
+package hostsec
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	SELINUX_FS     = "/sys/fs/selinux"
+	SELINUX_CONFIG = "/etc/selinux/config"
+	APPARMOR_PARAM = "/sys/module/apparmor/parameters/enabled"
+	APPARMOR_FS    = "/sys/kernel/security/apparmor/profiles"
+	SSHD_CONFIG    = "/etc/ssh/sshd_config"
+	SHADOW         = "/etc/shadow"
+	SUDOERS        = "/etc/sudoers"
+
+	MODE_ENFORCING     = "enforcing"
+	MODE_PERMISSIVE    = "permissive"
+	MODE_DISABLED      = "disabled"
+	MODE_ENABLED       = "enabled"
+	MODE_NOT_INSTALLED = "not_installed"
+
+	SSHD_PERMIT_ROOT_LOGIN       = "PermitRootLogin"
+	SSHD_PASSWORD_AUTHENTICATION = "PasswordAuthentication"
+	SSHD_PERMIT_EMPTY_PASSWORDS  = "PermitEmptyPasswords"
+
+	// NEVER is the days of the shadow field that is empty or too large to take effect
+	NEVER = -1
+
+	_sudo_all  = "ALL"
+	_sudo_root = "root"
+	// the max days of password is treated as never expires from 99999 days
+	_never_expire_days = 99999
+	_apparmor_enforce  = "(enforce)"
+	_apparmor_complain = "(complain)"
+)
+
+// SshdDefaults are the default values of the sshd settings in OpenSSH 7.0 and later.
+var SshdDefaults = map[string]string{
+	SSHD_PERMIT_ROOT_LOGIN:       "prohibit-password",
+	SSHD_PASSWORD_AUTHENTICATION: "yes",
+	SSHD_PERMIT_EMPTY_PASSWORDS:  "no",
+}
+
+type AppArmor struct {
+	Mode     string
+	Profiles int
+	Enforce  int
+	Complain int
+}
+
+// Shadow is the entry of /etc/shadow, the days are NEVER if the field is empty.
+type Shadow struct {
+	User         string
+	Locked       bool
+	NoPassword   bool
+	LastChange   int // days since 1970-01-01
+	MinDays      int
+	MaxDays      int
+	WarnDays     int
+	InactiveDays int
+	Expire       int // days since 1970-01-01
+}
+
+// SudoRule is a sudo rule which allows the user to run commands as root.
+type SudoRule struct {
+	File        string
+	Rule        string
+	RunAs       string
+	NoPasswd    bool
+	Commands    string
+	AllCommands bool
+}
+
+// ReadSELinux returns the current mode of SELinux and the mode configured in /etc/selinux/config, root is '/' except in tests.
+func ReadSELinux(root string) (current, configured string) {
+	current, configured = MODE_NOT_INSTALLED, MODE_NOT_INSTALLED
+	if _, err := os.Stat(path.Join(root, SELINUX_CONFIG)); err == nil {
+		configured = readKeyValue(path.Join(root, SELINUX_CONFIG), "SELINUX", "=")
+	}
+	if _, err := os.Stat(path.Join(root, SELINUX_FS)); err != nil {
+		if configured != MODE_NOT_INSTALLED {
+			current = MODE_DISABLED
+		}
+		return
+	}
+	enforce, err := os.ReadFile(path.Join(root, SELINUX_FS, "enforce"))
+	if err != nil {
+		current = MODE_DISABLED
+		return
+	}
+	current = MODE_PERMISSIVE
+	if strings.TrimSpace(string(enforce)) == "1" {
+		current = MODE_ENFORCING
+	}
+	return
+}
+
+// ReadAppArmor returns the mode of AppArmor and the count of loaded profiles, the profiles can only be read by root.
+func ReadAppArmor(root string) AppArmor {
+	res := AppArmor{Mode: MODE_NOT_INSTALLED}
+	enabled, err := os.ReadFile(path.Join(root, APPARMOR_PARAM))
+	if err != nil {
+		return res
+	}
+	res.Mode = MODE_DISABLED
+	if strings.TrimSpace(string(enabled)) == "Y" {
+		res.Mode = MODE_ENABLED
+	}
+	f, err := os.Open(path.Join(root, APPARMOR_FS))
+	if err != nil {
+		return res
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) == 0 {
+			continue
+		}
+		res.Profiles++
+		if strings.HasSuffix(line, _apparmor_enforce) {
+			res.Enforce++
+		} else if strings.HasSuffix(line, _apparmor_complain) {
+			res.Complain++
+		}
+	}
+	return res
+}
+
+// ParseSshdConfig returns the global settings of sshd_config, the keys are lower case.
+// The first value of a keyword takes effect, and the settings in Match blocks are ignored.
+func ParseSshdConfig(file string) (map[string]string, error) {
+	res := make(map[string]string)
+	if err := parseSshdConfig(file, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func parseSshdConfig(file string, res map[string]string) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(strings.Replace(line, "=", " ", 1))
+		if len(fields) < 2 {
+			continue
+		}
+		keyword := strings.ToLower(fields[0])
+		switch keyword {
+		case "match":
+			return nil
+		case "include":
+			for _, pattern := range fields[1:] {
+				if !path.IsAbs(pattern) {
+					pattern = path.Join(path.Dir(SSHD_CONFIG), pattern)
+				}
+				files, _ := filepath.Glob(pattern)
+				for _, include := range files {
+					if err := parseSshdConfig(include, res); err != nil {
+						return err
+					}
+				}
+			}
+		default:
+			if _, ok := res[keyword]; !ok {
+				res[keyword] = fields[1]
+			}
+		}
+	}
+	return scanner.Err()
+}
+
+// ParseShadow returns the shadow entry of the user, nil if not found.
+func ParseShadow(r io.Reader, user string) (*Shadow, error) {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Split(strings.TrimSpace(scanner.Text()), ":")
+		if len(fields) < 8 || fields[0] != user {
+			continue
+		}
+		password := fields[1]
+		shadow := &Shadow{
+			User:         user,
+			Locked:       strings.HasPrefix(password, "!") || strings.HasPrefix(password, "*"),
+			NoPassword:   len(password) == 0,
+			LastChange:   parseDays(fields[2]),
+			MinDays:      parseDays(fields[3]),
+			MaxDays:      parseDays(fields[4]),
+			WarnDays:     parseDays(fields[5]),
+			InactiveDays: parseDays(fields[6]),
+			Expire:       parseDays(fields[7]),
+		}
+		if shadow.MaxDays >= _never_expire_days {
+			shadow.MaxDays = NEVER
+		}
+		return shadow, scanner.Err()
+	}
+	return nil, scanner.Err()
+}
+
+// PasswordExpires returns the time when the password expires, false if it never expires.
+func (s *Shadow) PasswordExpires() (time.Time, bool) {
+	if s.MaxDays == NEVER || s.LastChange == NEVER || s.LastChange == 0 {
+		return time.Time{}, false
+	}
+	return DaysToTime(s.LastChange + s.MaxDays), true
+}
+
+// DaysToTime converts the days since 1970-01-01 of shadow to time.
+func DaysToTime(days int) time.Time {
+	return time.Unix(int64(days)*24*60*60, 0).UTC()
+}
+
+// ParseSudoers returns the sudo rules that allow the user or the groups of the user to run commands as root,
+// the files included by #include, #includedir, @include and @includedir are also parsed.
+func ParseSudoers(file, user string, groups []string) ([]SudoRule, error) {
+	p := &sudoersParser{
+		principals: map[string]struct{}{user: {}, _sudo_all: {}},
+		aliases:    make(map[string][]string),
+	}
+	for _, group := range groups {
+		p.principals["%"+group] = struct{}{}
+	}
+	if err := p.parse(file); err != nil {
+		return nil, err
+	}
+	return p.rules, nil
+}
+
+type sudoersParser struct {
+	principals map[string]struct{}
+	aliases    map[string][]string
+	rules      []SudoRule
+}
+
+func (p *sudoersParser) parse(file string) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	var line string
+	for scanner.Scan() {
+		text := strings.TrimSpace(scanner.Text())
+		if strings.HasSuffix(text, "\\") {
+			line += strings.TrimSuffix(text, "\\") + " "
+			continue
+		}
+		line += text
+		if err := p.parseLine(file, line); err != nil {
+			return err
+		}
+		line = ""
+	}
+	return scanner.Err()
+}
+
+func (p *sudoersParser) parseLine(file, line string) error {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return nil
+	}
+	switch fields[0] {
+	case "#include", "@include":
+		if len(fields) > 1 {
+			return p.parse(p.includePath(file, fields[1]))
+		}
+		return nil
+	case "#includedir", "@includedir":
+		if len(fields) > 1 {
+			return p.parseDir(p.includePath(file, fields[1]))
+		}
+		return nil
+	case "User_Alias", "Runas_Alias", "Cmnd_Alias", "Host_Alias":
+		p.parseAlias(strings.TrimSpace(strings.TrimPrefix(line, fields[0])))
+		return nil
+	}
+	if strings.HasPrefix(line, "#") || strings.HasPrefix(fields[0], "Defaults") {
+		return nil
+	}
+	p.parseRule(file, line)
+	return nil
+}
+
+func (p *sudoersParser) includePath(file, include string) string {
+	if path.IsAbs(include) {
+		return include
+	}
+	return path.Join(path.Dir(file), include)
+}
+
+// parseDir parses the files in the directory, the files end with '~' or contain '.' are skipped as sudo does.
+func (p *sudoersParser) parseDir(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || strings.HasSuffix(name, "~") || strings.Contains(name, ".") {
+			continue
+		}
+		if err := p.parse(path.Join(dir, name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseAlias parses 'NAME = item1, item2 : NAME2 = item3'.
+func (p *sudoersParser) parseAlias(definition string) {
+	for _, part := range strings.Split(definition, ":") {
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		p.aliases[strings.TrimSpace(kv[0])] = splitList(kv[1])
+	}
+}
+
+// parseRule parses 'users hosts = (runas) TAG: commands', only the first host and command spec is parsed.
+func (p *sudoersParser) parseRule(file, line string) {
+	kv := strings.SplitN(line, "=", 2)
+	if len(kv) != 2 {
+		return
+	}
+	left := strings.Fields(kv[0])
+	if len(left) < 2 {
+		return
+	}
+	users := strings.Join(left[:len(left)-1], " ")
+	if !p.matchAny(splitList(users), p.principals) {
+		return
+	}
+	spec := strings.TrimSpace(kv[1])
+	runAs := _sudo_root
+	if strings.HasPrefix(spec, "(") {
+		end := strings.Index(spec, ")")
+		if end < 0 {
+			return
+		}
+		runAs = strings.TrimSpace(strings.SplitN(spec[1:end], ":", 2)[0])
+		spec = strings.TrimSpace(spec[end+1:])
+		if len(runAs) == 0 {
+			// '(:group)' runs as the user itself
+			return
+		}
+	}
+	if !p.matchAny(splitList(runAs), map[string]struct{}{_sudo_root: {}, _sudo_all: {}}) {
+		return
+	}
+	rule := SudoRule{File: file, Rule: line, RunAs: runAs}
+	// tags such as NOPASSWD: and SETENV: are before the commands
+	for {
+		index := strings.Index(spec, ":")
+		if index < 0 || !isTag(spec[:index]) {
+			break
+		}
+		if strings.TrimSpace(spec[:index]) == "NOPASSWD" {
+			rule.NoPasswd = true
+		}
+		spec = strings.TrimSpace(spec[index+1:])
+	}
+	commands := splitList(spec)
+	rule.Commands = strings.Join(commands, ", ")
+	rule.AllCommands = p.matchAny(commands, map[string]struct{}{_sudo_all: {}})
+	p.rules = append(p.rules, rule)
+}
+
+// matchAny reports whether any of the items or the items of alias is in targets.
+func (p *sudoersParser) matchAny(items []string, targets map[string]struct{}) bool {
+	for _, item := range items {
+		if _, ok := targets[item]; ok {
+			return true
+		}
+		if members, ok := p.aliases[item]; ok && p.matchAny(members, targets) {
+			return true
+		}
+	}
+	return false
+}
+
+func isTag(s string) bool {
+	s = strings.TrimSpace(s)
+	if len(s) == 0 {
+		return false
+	}
+	for _, r := range s {
+		if (r < 'A' || r > 'Z') && r != '_' {
+			return false
+		}
+	}
+	return true
+}
+
+func splitList(s string) []string {
+	var res []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); len(item) != 0 {
+			res = append(res, item)
+		}
+	}
+	return res
+}
+
+func parseDays(s string) int {
+	days, err := strconv.Atoi(strings.TrimSpace(s))
+	if err != nil {
+		return NEVER
+	}
+	return days
+}
+
+// readKeyValue returns the value of the key in file with lines like 'KEY=VALUE', empty if not found.
+func readKeyValue(file, key, sep string) string {
+	f, err := os.Open(file)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		kv := strings.SplitN(strings.TrimSpace(scanner.Text()), sep, 2)
+		if len(kv) == 2 && strings.TrimSpace(kv[0]) == key {
+			return strings.Trim(strings.TrimSpace(kv[1]), `"'`)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,117 @@
+package hostsec_test
+
+import (
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"yhc/internal/modules/yhc/check/hostsec"
+)
+
+func writeFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		p := path.Join(root, name)
+		if err := os.MkdirAll(path.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestReadSELinuxAndAppArmor(t *testing.T) {
+	root := t.TempDir()
+	if current, configured := hostsec.ReadSELinux(root); current != hostsec.MODE_NOT_INSTALLED || configured != hostsec.MODE_NOT_INSTALLED {
+		t.Fatalf("unexpected selinux: %s %s", current, configured)
+	}
+	writeFiles(t, root, map[string]string{
+		"etc/selinux/config":                     "# comment\nSELINUX=enforcing\nSELINUXTYPE=targeted\n",
+		"sys/fs/selinux/enforce":                 "0",
+		"sys/module/apparmor/parameters/enabled": "Y\n",
+		"sys/kernel/security/apparmor/profiles":  "/usr/sbin/mysqld (enforce)\n/usr/bin/man (complain)\nnvidia_modprobe (enforce)\n",
+	})
+	if current, configured := hostsec.ReadSELinux(root); current != hostsec.MODE_PERMISSIVE || configured != hostsec.MODE_ENFORCING {
+		t.Fatalf("unexpected selinux: %s %s", current, configured)
+	}
+	apparmor := hostsec.ReadAppArmor(root)
+	if apparmor.Mode != hostsec.MODE_ENABLED || apparmor.Profiles != 3 || apparmor.Enforce != 2 || apparmor.Complain != 1 {
+		t.Fatalf("unexpected apparmor: %+v", apparmor)
+	}
+}
+
+func TestParseSshdConfig(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"sshd_config":          "Include " + path.Join(root, "conf.d") + "/*.conf\n#PermitRootLogin yes\nPermitRootLogin yes\nPasswordAuthentication yes\nMatch User backup\n  PermitEmptyPasswords yes\n",
+		"conf.d/50-cloud.conf": "PasswordAuthentication=no\n",
+	})
+	conf, err := hostsec.ParseSshdConfig(path.Join(root, "sshd_config"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if conf["permitrootlogin"] != "yes" || conf["passwordauthentication"] != "no" {
+		t.Fatalf("unexpected config: %v", conf)
+	}
+	if _, ok := conf["permitemptypasswords"]; ok {
+		t.Fatalf("settings of match block should be ignored: %v", conf)
+	}
+}
+
+func TestParseShadow(t *testing.T) {
+	content := "root:!:19000:0:99999:7:::\nyashan:$6$abc:19000:1:90:7:30::\n"
+	shadow, err := hostsec.ParseShadow(strings.NewReader(content), "yashan")
+	if err != nil || shadow == nil {
+		t.Fatalf("parse shadow err: %v", err)
+	}
+	if shadow.Locked || shadow.MaxDays != 90 || shadow.InactiveDays != 30 || shadow.Expire != hostsec.NEVER {
+		t.Fatalf("unexpected shadow: %+v", shadow)
+	}
+	expires, ok := shadow.PasswordExpires()
+	if !ok || !expires.Equal(hostsec.DaysToTime(19090)) {
+		t.Fatalf("unexpected expires: %v", expires)
+	}
+	root, _ := hostsec.ParseShadow(strings.NewReader(content), "root")
+	if _, ok := root.PasswordExpires(); ok || !root.Locked {
+		t.Fatalf("unexpected root shadow: %+v", root)
+	}
+	if none, _ := hostsec.ParseShadow(strings.NewReader(content), "nobody"); none != nil {
+		t.Fatalf("unexpected shadow: %+v", none)
+	}
+}
+
+func TestParseSudoers(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"sudoers": strings.Join([]string{
+			"Defaults env_reset",
+			"User_Alias DBA = yashan, oracle",
+			"root ALL=(ALL:ALL) ALL",
+			"%wheel ALL=(ALL) ALL",
+			"DBA ALL=(root) NOPASSWD: /usr/bin/systemctl restart yasdb, \\",
+			"    /usr/bin/systemctl stop yasdb",
+			"yashan ALL=(postgres) ALL",
+			"#includedir " + path.Join(root, "sudoers.d"),
+		}, "\n"),
+		"sudoers.d/yashan":     "yashan ALL=(ALL) NOPASSWD:SETENV: ALL\n",
+		"sudoers.d/README.txt": "yashan ALL=(ALL) ALL\n",
+	})
+	rules, err := hostsec.ParseSudoers(path.Join(root, "sudoers"), "yashan", []string{"yashan", "wheel"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rules) != 3 {
+		t.Fatalf("unexpected rules: %+v", rules)
+	}
+	if !rules[0].AllCommands || rules[0].NoPasswd {
+		t.Fatalf("unexpected wheel rule: %+v", rules[0])
+	}
+	if rules[1].AllCommands || !rules[1].NoPasswd || rules[1].Commands != "/usr/bin/systemctl restart yasdb, /usr/bin/systemctl stop yasdb" {
+		t.Fatalf("unexpected alias rule: %+v", rules[1])
+	}
+	if !rules[2].AllCommands || !rules[2].NoPasswd || rules[2].File != path.Join(root, "sudoers.d/yashan") {
+		t.Fatalf("unexpected included rule: %+v", rules[2])
+	}
+}
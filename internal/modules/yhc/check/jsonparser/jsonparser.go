@@ -13,8 +13,12 @@ import (
 	"yhc/defs/confdef"
 	"yhc/defs/timedef"
 	"yhc/i18n"
+	"yhc/internal/modules/yhc/check/awr"
 	"yhc/internal/modules/yhc/check/define"
+	"yhc/internal/modules/yhc/check/timeline"
+	"yhc/internal/modules/yhc/check/wlstats"
 	"yhc/log"
+	"yhc/utils/mathutil"
 	"yhc/utils/stringutil"
 
 	"git.yasdb.com/go/yaslog"
@@ -37,8 +41,71 @@ const (
 	_node_role     = "nodeRole"
 	_database_name = "databaseName"
 	_yasdb_user    = "yasdbUser"
+
+	_workload_item    = "item"
+	_workload_field   = "field"
+	_workload_min     = "min"
+	_workload_avg     = "avg"
+	_workload_p95     = "p95"
+	_workload_max     = "max"
+	_workload_above   = "aboveSeconds"
+	_anomaly_start    = "start"
+	_anomaly_end      = "end"
+	_anomaly_peak     = "peak"
+	_anomaly_baseline = "baseline"
+	_anomaly_score    = "score"
+	_anomaly_color    = "rgba(255, 77, 79, 0.15)"
+
+	_event_time    = "time"
+	_event_source  = "source"
+	_event_message = "message"
+	_missing_value = "-"
+	_timeline_menu = "timeline"
+
+	// the keys of the kernel events and the slow SQL
+	_kernel_event_time    = "time"
+	_kernel_event_type    = "type"
+	_kernel_event_message = "message"
+	_slow_sql_record_time = "RECORD_TIME"
+	_slow_sql_query_time  = "QUERY_TIME"
+	_slow_sql_id          = "SQL_ID"
+
+	// the keys of the awr report
+	_awr_name            = "name"
+	_awr_per_second      = "perSecond"
+	_awr_per_transaction = "perTransaction"
+	_awr_value           = "value"
+	_awr_compare         = "compare"
+	_awr_diff            = "diff"
+	_awr_db_time_percent = "dbTimePercent"
+	_awr_wait_class      = "waitClass"
+	_awr_waits           = "waits"
+	_awr_time            = "time"
+	_awr_avg_wait        = "avgWait"
+	_awr_sql_id          = "sqlID"
+	_awr_executions      = "executions"
+	_awr_elapsed         = "elapsed"
+	_awr_cpu             = "cpu"
+	_awr_gets            = "gets"
+	_awr_reads           = "reads"
+	_awr_sql_text        = "sqlText"
+	_awr_decimal         = 2
 )
 
+// the lines of the timeline, the series of all items of a field are merged to one line
+var _timelineLines = []struct {
+	metric define.MetricName
+	field  string
+	unit   string
+}{
+	{define.METRIC_HOST_HISTORY_CPU_USAGE, "usage", timeline.UNIT_PERCENT},
+	{define.METRIC_HOST_HISTORY_CPU_USAGE, "iowait", timeline.UNIT_PERCENT},
+	{define.METRIC_HOST_HISTORY_MEMORY_USAGE, "realMemUsed", timeline.UNIT_PERCENT},
+	{define.METRIC_HOST_HISTORY_DISK_IO, "util", timeline.UNIT_PERCENT},
+	{define.METRIC_YASDB_HISTORY_BUFFER_HIT_RATE, "HIT_RATE", timeline.UNIT_PERCENT},
+	{define.METRIC_YASDB_HISTORY_DB_TIME, "DB_TIMES", timeline.UNIT_MILLISECOND},
+}
+
 // 将不同指标的数据合并到一个map中，只支持map之间的合并
 var _mergeMetricMap = map[define.MetricName][]define.MetricName{
 	define.METRIC_HOST_INFO: {
@@ -75,6 +142,8 @@ func getMergeOldMenuToNew() []merge {
 		originMetrics: []string{
 			string(define.METRIC_HOST_CURRENT_CPU_USAGE),
 			string(define.METRIC_HOST_HISTORY_CPU_USAGE),
+			string(define.METRIC_HOST_CURRENT_CPU_CORE_USAGE),
+			string(define.METRIC_HOST_HISTORY_CPU_CORE_USAGE),
 		},
 	},
 	{
@@ -93,6 +162,46 @@ func getMergeOldMenuToNew() []merge {
 			string(define.METRIC_HOST_HISTORY_NETWORK_IO),
 		},
 	},
+	{
+		parentModule: string(define.MODULE_HOST_WORKLOAD),
+		targetTitle:  i18n.T("merge.load_average"),
+		originMetrics: []string{
+			string(define.METRIC_HOST_CURRENT_LOAD_AVERAGE),
+			string(define.METRIC_HOST_HISTORY_LOAD_AVERAGE),
+		},
+	},
+	{
+		parentModule: string(define.MODULE_HOST_WORKLOAD),
+		targetTitle:  i18n.T("merge.paging"),
+		originMetrics: []string{
+			string(define.METRIC_HOST_CURRENT_PAGING),
+			string(define.METRIC_HOST_HISTORY_PAGING),
+		},
+	},
+	{
+		parentModule: string(define.MODULE_HOST_WORKLOAD),
+		targetTitle:  i18n.T("merge.context_switch"),
+		originMetrics: []string{
+			string(define.METRIC_HOST_CURRENT_CONTEXT_SWITCH),
+			string(define.METRIC_HOST_HISTORY_CONTEXT_SWITCH),
+		},
+	},
+	{
+		parentModule: string(define.MODULE_HOST_WORKLOAD),
+		targetTitle:  i18n.T("merge.swapping"),
+		originMetrics: []string{
+			string(define.METRIC_HOST_CURRENT_SWAPPING),
+			string(define.METRIC_HOST_HISTORY_SWAPPING),
+		},
+	},
+	{
+		parentModule: string(define.MODULE_HOST_WORKLOAD),
+		targetTitle:  i18n.T("merge.network_edev"),
+		originMetrics: []string{
+			string(define.METRIC_HOST_CURRENT_NETWORK_EDEV),
+			string(define.METRIC_HOST_HISTORY_NETWORK_EDEV),
+		},
+	},
 	{
 		parentModule: string(define.MODULE_HOST_WORKLOAD),
 		targetTitle:  i18n.T("merge.disk_usage"),
@@ -101,6 +210,14 @@ func getMergeOldMenuToNew() []merge {
 			string(define.METRIC_HOST_HISTORY_DISK_IO),
 		},
 	},
+	{
+		parentModule: string(define.MODULE_HOST_WORKLOAD),
+		targetTitle:  i18n.T("merge.process_usage"),
+		originMetrics: []string{
+			string(define.METRIC_HOST_PROCESS_USAGE),
+			string(define.METRIC_HOST_CURRENT_PROCESS_USAGE),
+		},
+	},
 	{
 		parentModule: string(define.MODULE_OVERVIEW_HOST),
 		targetTitle:  i18n.T("merge.host_info"),
@@ -254,6 +371,7 @@ func (j *JsonParser) Parse() *define.PandoraReport {
 		report.ReportData = append(report.ReportData, menu)
 		j.dealYHCModule(module, menu)
 	}
+	j.addTimeline(report, len(confdef.GetModuleConf().Modules))
 	j.mergeElements(report)
 	j.filterSingleElementTitle(report)
 	j.addElementToEmptyMenus(report)
@@ -663,12 +781,24 @@ func (j *JsonParser) genDefaultMetricParseFunc(metric *confdef.YHCMetric) (Metri
 		define.METRIC_HOST_NETWORK_INFO:                                                            j.parseTable,
 		define.METRIC_HOST_HISTORY_CPU_USAGE:                                                       j.parseHostWorkload,
 		define.METRIC_HOST_CURRENT_CPU_USAGE:                                                       j.parseHostWorkload,
+		define.METRIC_HOST_HISTORY_CPU_CORE_USAGE:                                                  j.parseTable,
+		define.METRIC_HOST_CURRENT_CPU_CORE_USAGE:                                                  j.parseTable,
 		define.METRIC_HOST_CURRENT_DISK_IO:                                                         j.parseHostWorkload,
 		define.METRIC_HOST_HISTORY_DISK_IO:                                                         j.parseHostWorkload,
 		define.METRIC_HOST_CURRENT_MEMORY_USAGE:                                                    j.parseHostWorkload,
 		define.METRIC_HOST_HISTORY_MEMORY_USAGE:                                                    j.parseHostWorkload,
 		define.METRIC_HOST_CURRENT_NETWORK_IO:                                                      j.parseHostWorkload,
 		define.METRIC_HOST_HISTORY_NETWORK_IO:                                                      j.parseHostWorkload,
+		define.METRIC_HOST_HISTORY_LOAD_AVERAGE:                                                    j.parseHostWorkload,
+		define.METRIC_HOST_CURRENT_LOAD_AVERAGE:                                                    j.parseHostWorkload,
+		define.METRIC_HOST_HISTORY_PAGING:                                                          j.parseHostWorkload,
+		define.METRIC_HOST_CURRENT_PAGING:                                                          j.parseHostWorkload,
+		define.METRIC_HOST_HISTORY_CONTEXT_SWITCH:                                                  j.parseHostWorkload,
+		define.METRIC_HOST_CURRENT_CONTEXT_SWITCH:                                                  j.parseHostWorkload,
+		define.METRIC_HOST_HISTORY_SWAPPING:                                                        j.parseHostWorkload,
+		define.METRIC_HOST_CURRENT_SWAPPING:                                                        j.parseHostWorkload,
+		define.METRIC_HOST_HISTORY_NETWORK_EDEV:                                                    j.parseHostWorkload,
+		define.METRIC_HOST_CURRENT_NETWORK_EDEV:                                                    j.parseHostWorkload,
 		define.METRIC_YASDB_ARCHIVE_DEST_STATUS:                                                    j.parseTable,
 		define.METRIC_YASDB_ARCHIVE_LOG:                                                            j.parseTable,
 		define.METRIC_YASDB_ARCHIVE_LOG_SPACE:                                                      j.parseMap,
@@ -680,6 +810,11 @@ func (j *JsonParser) genDefaultMetricParseFunc(metric *confdef.YHCMetric) (Metri
 		define.METRIC_YASDB_DATAFILE:                                                               j.parseTable,
 		define.METRIC_YASDB_SESSION:                                                                j.parseMap,
 		define.METRIC_YASDB_WAIT_EVENT:                                                             j.parseTable,
+		define.METRIC_YASDB_CURRENT_WAIT_EVENT:                                                     j.parseTable,
+		define.METRIC_YASDB_CURRENT_WAIT_CLASS:                                                     j.parseTable,
+		define.METRIC_YASDB_HISTORY_WAIT_EVENT:                                                     j.parseTable,
+		define.METRIC_YASDB_HISTORY_WAIT_CLASS:                                                     j.parseTable,
+		define.METRIC_YASDB_SESSION_WAIT:                                                           j.parseTable,
 		define.METRIC_YASDB_OBJECT_COUNT:                                                           j.parseMap,
 		define.METRIC_YASDB_OBJECT_SUMMARY:                                                         j.parseTable,
 		define.METRIC_YASDB_SEGMENTS_COUNT:                                                         j.parseMap,
@@ -726,12 +861,34 @@ func (j *JsonParser) genDefaultMetricParseFunc(metric *confdef.YHCMetric) (Metri
 		define.METRIC_YASDB_TOP_SQL_BY_PARSE_CALLS:                                                 j.parseTable,
 		define.METRIC_YASDB_HIGH_FREQUENCY_SQL:                                                     j.parseTable,
 		define.METRIC_YASDB_HISTORY_DB_TIME:                                                        j.parseHostWorkload,
+		define.METRIC_YASDB_AWR_REPORT:                                                             j.parseAWRReport,
 		define.METRIC_YASDB_HISTORY_BUFFER_HIT_RATE:                                                j.parseHostWorkload,
 		define.METRIC_HOST_HUGE_PAGE:                                                               j.parseMap,
 		define.METRIC_HOST_SWAP_MEMORY:                                                             j.parseMap,
+		define.METRIC_HOST_SYSCTL:                                                                  j.parseTable,
+		define.METRIC_YASDB_PROCESS_LIMITS:                                                         j.parseTable,
+		define.METRIC_HOST_TIME_SYNC:                                                               j.parseMap,
+		define.METRIC_YASDB_NODE_TIME_SKEW:                                                         j.parseMap,
+		define.METRIC_HOST_NUMA:                                                                    j.parseTable,
+		define.METRIC_HOST_CPU_GOVERNOR:                                                            j.parseTable,
+		define.METRIC_YASDB_NUMA_BINDING:                                                           j.parseMap,
+		define.METRIC_HOST_YASDB_BLOCK_DEVICE:                                                      j.parseTable,
+		define.METRIC_HOST_NETWORK_ERRORS:                                                          j.parseTable,
+		define.METRIC_HOST_TCP_HEALTH:                                                              j.parseMap,
+		define.METRIC_HOST_KERNEL_EVENTS:                                                           j.parseTable,
+		define.METRIC_HOST_CGROUP:                                                                  j.parseMap,
+		define.METRIC_HOST_SECURITY_MAC:                                                            j.parseMap,
+		define.METRIC_HOST_SSHD_CONFIG:                                                             j.parseTable,
+		define.METRIC_HOST_YASDB_HOME_FILES:                                                        j.parseTable,
+		define.METRIC_HOST_CORE_DUMP:                                                               j.parseMap,
+		define.METRIC_HOST_YASDB_USER_PASSWORD:                                                     j.parseMap,
+		define.METRIC_HOST_YASDB_USER_SUDO:                                                         j.parseTable,
+		define.METRIC_HOST_PROCESS_USAGE:                                                           j.parseTable,
+		define.METRIC_HOST_CURRENT_PROCESS_USAGE:                                                   j.parseHostWorkload,
 		define.METRIC_YASDB_BUFFER_HIT_RATE:                                                        j.parseMap,
 		define.METRIC_YASDB_TABLE_LOCK_WAIT:                                                        j.parseMap,
 		define.METRIC_YASDB_ROW_LOCK_WAIT:                                                          j.parseMap,
+		define.METRIC_YASDB_BLOCKING_CHAIN:                                                         j.parseTable,
 		define.METRIC_YASDB_LONG_RUNNING_TRANSACTION:                                               j.parseTable,
 		define.METRIC_YASDB_INVALID_OBJECT:                                                         j.parseTable,
 		define.METRIC_YASDB_INVISIBLE_INDEX:                                                        j.parseTable,
@@ -868,6 +1025,9 @@ func (j *JsonParser) dealTableAnyRow(attributes *define.TableAttributes, metric
 	if len(attributes.TableColumns) == 0 {
 		columnsMap := make(map[string]*define.TableColumn)
 		for key := range data {
+			if key == define.TABLE_ROW_KEY || key == define.TABLE_ROW_CHILDREN {
+				continue
+			}
 			title := j.getColumnAlias(metric, key)
 			column := &define.TableColumn{
 				Title:     title,
@@ -1252,7 +1412,7 @@ func (j *JsonParser) genCustomSqlParseFunc(metric *confdef.YHCMetric) (MetricPar
 	return fn, nil
 }
 
-func (j *JsonParser) parseHostOtherWorkload(menu *define.PandoraMenu, item *define.YHCItem, metric *confdef.YHCMetric, includeFields map[string]struct{}) error {
+func (j *JsonParser) parseHostOtherWorkload(menu *define.PandoraMenu, item *define.YHCItem, metric *confdef.YHCMetric, includeFields map[string]struct{}, anomalies map[string][]wlstats.Anomaly) error {
 	if len(item.Error) != 0 {
 		return fmt.Errorf("failed to gen parse func because the metric %s check failed, err: %v", metric.Name, item.Error)
 	}
@@ -1328,7 +1488,8 @@ func (j *JsonParser) parseHostOtherWorkload(menu *define.PandoraMenu, item *defi
 			}
 		}
 	}
-	for _, attribute := range attributes {
+	for field, attribute := range attributes {
+		attribute.Options = j.anomalyOptions(anomalies[field], timeArray)
 		menu.Elements = append(menu.Elements, &define.PandoraElement{
 			MetricName:  metric.Name,
 			ElementType: define.ET_CHART,
@@ -1343,15 +1504,178 @@ func (j *JsonParser) parseHostWorkload(menu *define.PandoraMenu, item *define.YH
 	for column := range metric.ColumnAlias {
 		includeFields[column] = struct{}{}
 	}
+	stats := j.workloadStats(item, metric, includeFields)
+	byItem := func(s *workloadStats) string { return s.series.Item }
+	byField := func(s *workloadStats) string { return s.series.Field }
+	var err error
 	switch item.Name {
 	case define.METRIC_HOST_CURRENT_CPU_USAGE, define.METRIC_HOST_HISTORY_CPU_USAGE:
-		return j.parseHostCPUUsage(menu, item, metric, includeFields)
+		err = j.parseHostCPUUsage(menu, item, metric, includeFields, groupAnomalies(stats, byItem))
+	// the fields of load average and swapping share the same scale, draw them in one chart,
+	// paging and context switch fall back to one chart per field
+	case define.METRIC_HOST_CURRENT_LOAD_AVERAGE, define.METRIC_HOST_HISTORY_LOAD_AVERAGE,
+		define.METRIC_HOST_CURRENT_SWAPPING, define.METRIC_HOST_HISTORY_SWAPPING:
+		err = j.parseHostCPUUsage(menu, item, metric, includeFields, groupAnomalies(stats, byItem))
 	default:
-		return j.parseHostOtherWorkload(menu, item, metric, includeFields)
+		err = j.parseHostOtherWorkload(menu, item, metric, includeFields, groupAnomalies(stats, byField))
+	}
+	if err != nil {
+		return err
 	}
+	j.parseWorkloadStats(menu, item, metric, stats)
+	return nil
 }
 
-func (j *JsonParser) parseHostCPUUsage(menu *define.PandoraMenu, item *define.YHCItem, metric *confdef.YHCMetric, includeFields map[string]struct{}) error {
+// workloadStats is the statistics and the spikes of a series of the workload
+type workloadStats struct {
+	series    *wlstats.Series
+	stats     wlstats.Stats
+	anomalies []wlstats.Anomaly
+}
+
+func (j *JsonParser) workloadStats(item *define.YHCItem, metric *confdef.YHCMetric, includeFields map[string]struct{}) []*workloadStats {
+	if len(item.Error) != 0 {
+		return nil
+	}
+	output, ok := item.Details.(define.WorkloadOutput)
+	if !ok {
+		return nil
+	}
+	series, err := wlstats.FromWorkload(output, metric.Labels)
+	if err != nil {
+		j.log.Errorf("failed to parse workload series of %s, err: %v", metric.Name, err)
+		return nil
+	}
+	var res []*workloadStats
+	for _, s := range series {
+		if _, ok := includeFields[s.Field]; !ok {
+			continue
+		}
+		res = append(res, &workloadStats{
+			series:    s,
+			stats:     wlstats.Summarize(s.Points),
+			anomalies: wlstats.DetectAnomalies(s.Points, wlstats.ANOMALY_WINDOW, wlstats.ANOMALY_THRESHOLD),
+		})
+	}
+	return res
+}
+
+// groupAnomalies groups the spikes by the key of the chart which the series is drawn in
+func groupAnomalies(stats []*workloadStats, key func(s *workloadStats) string) map[string][]wlstats.Anomaly {
+	res := make(map[string][]wlstats.Anomaly)
+	for _, s := range stats {
+		res[key(s)] = append(res[key(s)], s.anomalies...)
+	}
+	return res
+}
+
+// anomalyOptions marks the spike periods on the line chart, a spike of a single point is extended to the next point
+func (j *JsonParser) anomalyOptions(anomalies []wlstats.Anomaly, timeArray []int64) map[string]interface{} {
+	if len(anomalies) == 0 {
+		return nil
+	}
+	areas := make([]interface{}, 0, len(anomalies))
+	for _, anomaly := range anomalies {
+		end := anomaly.End
+		if anomaly.Start == anomaly.End {
+			if i := sort.Search(len(timeArray), func(i int) bool { return timeArray[i] > end }); i < len(timeArray) {
+				end = timeArray[i]
+			}
+		}
+		areas = append(areas, []map[string]interface{}{
+			{"xAxis": time.Unix(anomaly.Start, 0).Format(timedef.TIME_FORMAT)},
+			{"xAxis": time.Unix(end, 0).Format(timedef.TIME_FORMAT)},
+		})
+	}
+	return map[string]interface{}{
+		"series": []interface{}{
+			map[string]interface{}{
+				"markArea": map[string]interface{}{
+					"silent":    true,
+					"itemStyle": map[string]interface{}{"color": _anomaly_color},
+					"data":      areas,
+				},
+			},
+		},
+	}
+}
+
+// parseWorkloadStats appends the statistics of every series, and the spikes if any, after the charts
+func (j *JsonParser) parseWorkloadStats(menu *define.PandoraMenu, item *define.YHCItem, metric *confdef.YHCMetric, stats []*workloadStats) {
+	if len(stats) == 0 {
+		return
+	}
+	statColumns := []*define.TableColumn{
+		{Title: i18n.T("table.workload_item"), DataIndex: _workload_item},
+		{Title: i18n.T("table.workload_field"), DataIndex: _workload_field},
+		{Title: i18n.T("table.workload_min"), DataIndex: _workload_min},
+		{Title: i18n.T("table.workload_avg"), DataIndex: _workload_avg},
+		{Title: i18n.T("table.workload_p95"), DataIndex: _workload_p95},
+		{Title: i18n.T("table.workload_max"), DataIndex: _workload_max},
+	}
+	if len(metric.Thresholds) != 0 {
+		statColumns = append(statColumns, &define.TableColumn{Title: i18n.T("table.workload_above_seconds"), DataIndex: _workload_above})
+	}
+	var statRows, anomalyRows []map[string]interface{}
+	for _, s := range stats {
+		field := j.getColumnAlias(metric, s.series.Field)
+		row := map[string]interface{}{
+			_workload_item:  s.series.Item,
+			_workload_field: field,
+			_workload_min:   s.stats.Min,
+			_workload_avg:   s.stats.Avg,
+			_workload_p95:   s.stats.P95,
+			_workload_max:   s.stats.Max,
+		}
+		if threshold, ok := metric.Thresholds[s.series.Field]; ok {
+			row[_workload_above] = wlstats.SecondsAbove(s.series.Points, threshold)
+		}
+		statRows = append(statRows, row)
+		for _, anomaly := range s.anomalies {
+			anomalyRows = append(anomalyRows, map[string]interface{}{
+				_workload_item:    s.series.Item,
+				_workload_field:   field,
+				_anomaly_start:    time.Unix(anomaly.Start, 0).Format(timedef.TIME_FORMAT),
+				_anomaly_end:      time.Unix(anomaly.End, 0).Format(timedef.TIME_FORMAT),
+				_anomaly_peak:     anomaly.Peak,
+				_anomaly_baseline: anomaly.Baseline,
+				_anomaly_score:    anomaly.Score,
+			})
+		}
+	}
+	title := j.genElementTitle(metric, item)
+	menu.Elements = append(menu.Elements, &define.PandoraElement{
+		MetricName:   metric.Name,
+		ElementTitle: fmt.Sprintf(i18n.T("report.workload_statistics"), title),
+		ElementType:  define.ET_TABLE,
+		Attributes:   define.TableAttributes{TableColumns: statColumns, DataSource: statRows},
+	})
+	if len(anomalyRows) == 0 {
+		return
+	}
+	sort.SliceStable(anomalyRows, func(i, j int) bool {
+		return anomalyRows[i][_anomaly_start].(string) < anomalyRows[j][_anomaly_start].(string)
+	})
+	menu.Elements = append(menu.Elements, &define.PandoraElement{
+		MetricName:   metric.Name,
+		ElementTitle: fmt.Sprintf(i18n.T("report.workload_anomalies"), title),
+		ElementType:  define.ET_TABLE,
+		Attributes: define.TableAttributes{
+			TableColumns: []*define.TableColumn{
+				{Title: i18n.T("table.workload_item"), DataIndex: _workload_item},
+				{Title: i18n.T("table.workload_field"), DataIndex: _workload_field},
+				{Title: i18n.T("table.anomaly_start"), DataIndex: _anomaly_start},
+				{Title: i18n.T("table.anomaly_end"), DataIndex: _anomaly_end},
+				{Title: i18n.T("table.anomaly_peak"), DataIndex: _anomaly_peak},
+				{Title: i18n.T("table.anomaly_baseline"), DataIndex: _anomaly_baseline},
+				{Title: i18n.T("table.anomaly_score"), DataIndex: _anomaly_score},
+			},
+			DataSource: anomalyRows,
+		},
+	})
+}
+
+func (j *JsonParser) parseHostCPUUsage(menu *define.PandoraMenu, item *define.YHCItem, metric *confdef.YHCMetric, includeFields map[string]struct{}, anomalies map[string][]wlstats.Anomaly) error {
 	if len(item.Error) != 0 {
 		return fmt.Errorf("failed to gen parse func because the metric %s check failed. err: %v", metric.Name, item.Error)
 	}
@@ -1420,11 +1744,12 @@ func (j *JsonParser) parseHostCPUUsage(menu *define.PandoraMenu, item *define.YH
 			attributes[name] = attribute
 		}
 	}
-	for _, attribute := range attributes {
+	for name, attribute := range attributes {
 		datas := attribute.CustomOptions.Data
 		for _, data := range datas {
 			data.Name = j.getColumnAlias(metric, data.Name)
 		}
+		attribute.Options = j.anomalyOptions(anomalies[name], timeArray)
 		menu.Elements = append(menu.Elements, &define.PandoraElement{
 			ElementType: define.ET_CHART,
 			Attributes:  attribute,
@@ -1444,3 +1769,394 @@ func (j *JsonParser) convertObjectToMap(object interface{}) (map[string]interfac
 	}
 	return m, nil
 }
+
+// addTimeline adds the chapter which overlays the workload of the host and the database on a shared time axis,
+// and marks the log events on the axis, so that the events can be correlated with the workload.
+func (j *JsonParser) addTimeline(report *define.PandoraReport, menuIndex int) {
+	tl := timeline.New(j.timelineLines(), j.timelineEvents())
+	if tl.Empty() {
+		return
+	}
+	menu := &define.PandoraMenu{IsMenu: true, Title: i18n.T("report.timeline"), TitleEn: _timeline_menu, MenuIndex: menuIndex}
+	if len(tl.Lines) != 0 {
+		menu.Elements = append(menu.Elements, &define.PandoraElement{
+			ElementType:  define.ET_CHART,
+			ElementTitle: i18n.T("report.timeline_chart"),
+			Attributes:   j.timelineChart(tl),
+		})
+	}
+	if len(tl.Events) != 0 {
+		var rows []map[string]interface{}
+		for _, event := range tl.Events {
+			rows = append(rows, map[string]interface{}{
+				_event_time:    time.Unix(event.Time, 0).Format(timedef.TIME_FORMAT),
+				_event_source:  j.timelineSourceAlias(event.Source),
+				_event_message: event.Message,
+			})
+		}
+		menu.Elements = append(menu.Elements, &define.PandoraElement{
+			ElementType:  define.ET_TABLE,
+			ElementTitle: i18n.T("report.timeline_events"),
+			Attributes: define.TableAttributes{
+				TableColumns: []*define.TableColumn{
+					{Title: i18n.T("table.event_time"), DataIndex: _event_time},
+					{Title: i18n.T("table.event_source"), DataIndex: _event_source},
+					{Title: i18n.T("table.event_message"), DataIndex: _event_message},
+				},
+				DataSource:  rows,
+				TableLayout: define.TABLE_LAYOUT_FIXED,
+			},
+		})
+	}
+	report.ReportData = append(report.ReportData, menu)
+}
+
+func (j *JsonParser) timelineLines() []*timeline.Line {
+	var lines []*timeline.Line
+	for _, l := range _timelineLines {
+		metric, err := j.getMetric(string(l.metric))
+		if err != nil {
+			continue
+		}
+		for _, item := range j.results[l.metric] {
+			output, ok := item.Details.(define.WorkloadOutput)
+			if len(item.Error) != 0 || !ok {
+				continue
+			}
+			series, err := wlstats.FromWorkload(output, metric.Labels)
+			if err != nil {
+				j.log.Errorf("failed to parse workload series of %s, err: %v", metric.Name, err)
+				continue
+			}
+			var fieldSeries []*wlstats.Series
+			for _, s := range series {
+				if s.Field == l.field {
+					fieldSeries = append(fieldSeries, s)
+				}
+			}
+			name := j.getColumnAlias(metric, l.field)
+			if len(item.NodeID) != 0 {
+				name = fmt.Sprintf("%s(%s)", name, item.NodeID)
+			}
+			lines = append(lines, timeline.NewLine(name, l.unit, fieldSeries...))
+		}
+	}
+	return lines
+}
+
+func (j *JsonParser) timelineEvents() []timeline.Event {
+	var events []timeline.Event
+	logs := []struct {
+		metric define.MetricName
+		source string
+	}{
+		{define.METRIC_YASDB_RUN_LOG_ERROR, timeline.SOURCE_RUN_LOG},
+		{define.METRIC_YASDB_ALERT_LOG_ERROR, timeline.SOURCE_ALERT_LOG},
+	}
+	for _, l := range logs {
+		for _, item := range j.results[l.metric] {
+			if lines, ok := item.Details.([]string); ok {
+				events = append(events, timeline.ParseYasdbLog(l.source, lines)...)
+			}
+		}
+	}
+	for _, item := range j.results[define.METRIC_HOST_KERNEL_EVENTS] {
+		kernelEvents, _ := item.Details.([]map[string]interface{})
+		for _, e := range kernelEvents {
+			t, err := time.ParseInLocation(timedef.TIME_FORMAT, fmt.Sprint(e[_kernel_event_time]), time.Local)
+			if err != nil {
+				continue
+			}
+			events = append(events, timeline.Event{
+				Time:    t.Unix(),
+				Source:  timeline.SOURCE_KERNEL,
+				Message: fmt.Sprintf("%v: %v", e[_kernel_event_type], e[_kernel_event_message]),
+			})
+		}
+	}
+	for _, item := range j.results[define.METRIC_YASDB_SLOW_LOG] {
+		slowSQLs, _ := item.Details.([]map[string]string)
+		for _, sql := range slowSQLs {
+			t, err := time.ParseInLocation(timedef.TIME_FORMAT, sql[_slow_sql_record_time], time.Local)
+			if err != nil {
+				continue
+			}
+			events = append(events, timeline.Event{
+				Time:    t.Unix(),
+				Source:  timeline.SOURCE_SLOW_SQL,
+				Message: fmt.Sprintf(i18n.T("report.timeline_slow_sql"), sql[_slow_sql_id], sql[_slow_sql_query_time]),
+			})
+		}
+	}
+	return events
+}
+
+// timelineChart draws the lines on one chart with an y axis per unit, the missing samples are connected,
+// and the aligned events are marked as the vertical lines labeled with the sources and counts of the events.
+func (j *JsonParser) timelineChart(tl *timeline.Timeline) define.ChartAttributes {
+	units := tl.Units()
+	unitIndex := make(map[string]int, len(units))
+	yAxis := make([]interface{}, 0, len(units))
+	for i, unit := range units {
+		unitIndex[unit] = i
+		yAxis = append(yAxis, map[string]interface{}{"type": "value", "name": unit})
+	}
+	datas := make([]*define.ChartData, 0, len(tl.Lines))
+	series := make([]interface{}, 0, len(tl.Lines))
+	for _, line := range tl.Lines {
+		data := &define.ChartData{Name: line.Name}
+		for _, t := range tl.Times {
+			var y interface{} = _missing_value
+			if v, ok := line.Value(t); ok {
+				y = v
+			}
+			data.Value = append(data.Value, &define.ChartCoordinate{X: time.Unix(t, 0).Format(timedef.TIME_FORMAT), Y: y})
+		}
+		datas = append(datas, data)
+		series = append(series, map[string]interface{}{"connectNulls": true, "yAxisIndex": unitIndex[line.Unit]})
+	}
+	marks := tl.Align()
+	markTimes := make([]int64, 0, len(marks))
+	for t := range marks {
+		markTimes = append(markTimes, t)
+	}
+	sort.Slice(markTimes, func(i, j int) bool { return markTimes[i] < markTimes[j] })
+	markData := make([]interface{}, 0, len(markTimes))
+	for _, t := range markTimes {
+		markData = append(markData, map[string]interface{}{
+			"name":  j.timelineMarkName(marks[t]),
+			"xAxis": time.Unix(t, 0).Format(timedef.TIME_FORMAT),
+		})
+	}
+	if len(markData) != 0 {
+		series[0].(map[string]interface{})["markLine"] = map[string]interface{}{
+			"symbol": "none",
+			"label":  map[string]interface{}{"formatter": "{b}"},
+			"data":   markData,
+		}
+	}
+	return define.ChartAttributes{
+		Options: map[string]interface{}{"yAxis": yAxis, "series": series},
+		CustomOptions: define.ChartCustomOptions{
+			ChartType: define.CT_LINE,
+			Title:     define.CustomOptionTitle{Text: i18n.T("report.timeline_chart")},
+			Data:      datas,
+		},
+	}
+}
+
+// timelineMarkName counts the events by source, e.g. 'run.log x2, slow SQL x5'
+func (j *JsonParser) timelineMarkName(events []timeline.Event) string {
+	var sources []string
+	counts := make(map[string]int)
+	for _, event := range events {
+		if counts[event.Source] == 0 {
+			sources = append(sources, event.Source)
+		}
+		counts[event.Source]++
+	}
+	names := make([]string, 0, len(sources))
+	for _, source := range sources {
+		names = append(names, fmt.Sprintf("%s x%d", j.timelineSourceAlias(source), counts[source]))
+	}
+	return strings.Join(names, ", ")
+}
+
+func (j *JsonParser) timelineSourceAlias(source string) string {
+	switch source {
+	case timeline.SOURCE_KERNEL:
+		return i18n.T("report.timeline_kernel")
+	case timeline.SOURCE_SLOW_SQL:
+		return i18n.T("report.timeline_slow_sql_source")
+	default:
+		return source
+	}
+}
+
+func (j *JsonParser) parseAWRReport(menu *define.PandoraMenu, item *define.YHCItem, metric *confdef.YHCMetric) error {
+	report, ok := item.Details.(*awr.Report)
+	if !ok || report.Base == nil {
+		return fmt.Errorf("failed to parse awr report, unsupport data type %T", item.Details)
+	}
+	title := j.genElementTitle(metric, item)
+	base, compare := report.Base, report.Compare
+	periods := []*define.DescriptionData{
+		{Label: i18n.T("report.awr_period"), Value: fmt.Sprintf(i18n.T("report.awr_period_value"), base.Period, base.Period.Seconds())},
+	}
+	if compare != nil {
+		periods = append(periods, &define.DescriptionData{
+			Label: i18n.T("report.awr_compare_period"),
+			Value: fmt.Sprintf(i18n.T("report.awr_period_value"), compare.Period, compare.Period.Seconds()),
+		})
+	}
+	if len(report.Errors) != 0 {
+		periods = append(periods, &define.DescriptionData{Label: i18n.T("report.awr_errors"), Value: strings.Join(report.Errors, stringutil.STR_NEWLINE)})
+	}
+	menu.Elements = append(menu.Elements, &define.PandoraElement{
+		MetricName:   metric.Name,
+		ElementTitle: fmt.Sprintf(i18n.T("report.awr_summary"), title),
+		ElementType:  define.ET_DESCRIPTION,
+		Attributes:   define.DescriptionAttributes{Data: periods},
+	})
+	j.awrLoadProfile(menu, metric, title, report)
+	j.awrEfficiency(menu, metric, title, report)
+	j.awrTimeModel(menu, metric, title, base)
+	j.awrWaitEvents(menu, metric, title, report)
+	j.awrTopSQL(menu, metric, fmt.Sprintf(i18n.T("report.awr_sql_by_elapsed"), title), base.SQLByElapsed)
+	j.awrTopSQL(menu, metric, fmt.Sprintf(i18n.T("report.awr_sql_by_cpu"), title), base.SQLByCPU)
+	j.awrTopSQL(menu, metric, fmt.Sprintf(i18n.T("report.awr_sql_by_gets"), title), base.SQLByGets)
+	return nil
+}
+
+// awrTable appends the table if there are rows, the tables without rows are skipped
+func (j *JsonParser) awrTable(menu *define.PandoraMenu, metric *confdef.YHCMetric, title string, columns []*define.TableColumn, rows []map[string]interface{}, layout string) {
+	if len(rows) == 0 {
+		return
+	}
+	menu.Elements = append(menu.Elements, &define.PandoraElement{
+		MetricName:   metric.Name,
+		ElementTitle: title,
+		ElementType:  define.ET_TABLE,
+		Attributes:   define.TableAttributes{TableColumns: columns, DataSource: rows, TableLayout: layout},
+	})
+}
+
+// awrDiffs keys the changes by name, the compare columns are shown only if the report has a compare period
+func (j *JsonParser) awrDiffs(diffs []*awr.Diff) map[string]*awr.Diff {
+	res := make(map[string]*awr.Diff, len(diffs))
+	for _, d := range diffs {
+		res[d.Name] = d
+	}
+	return res
+}
+
+func (j *JsonParser) awrLoadProfile(menu *define.PandoraMenu, metric *confdef.YHCMetric, title string, report *awr.Report) {
+	columns := []*define.TableColumn{
+		{Title: i18n.T("table.awr_name"), DataIndex: _awr_name},
+		{Title: i18n.T("table.awr_per_second"), DataIndex: _awr_per_second},
+		{Title: i18n.T("table.awr_per_transaction"), DataIndex: _awr_per_transaction},
+	}
+	if report.Compare != nil {
+		columns = append(columns,
+			&define.TableColumn{Title: i18n.T("table.awr_compare_per_second"), DataIndex: _awr_compare},
+			&define.TableColumn{Title: i18n.T("table.awr_diff"), DataIndex: _awr_diff})
+	}
+	diffs := j.awrDiffs(report.LoadDiffs)
+	var rows []map[string]interface{}
+	for _, load := range report.Base.LoadProfile {
+		row := map[string]interface{}{
+			_awr_name:            load.Name,
+			_awr_per_second:      load.PerSecond,
+			_awr_per_transaction: load.PerTransaction,
+		}
+		if d, ok := diffs[load.Name]; ok {
+			row[_awr_compare], row[_awr_diff] = d.Compare, d.Percent
+		}
+		rows = append(rows, row)
+	}
+	j.awrTable(menu, metric, fmt.Sprintf(i18n.T("report.awr_load_profile"), title), columns, rows, "")
+}
+
+func (j *JsonParser) awrEfficiency(menu *define.PandoraMenu, metric *confdef.YHCMetric, title string, report *awr.Report) {
+	columns := []*define.TableColumn{
+		{Title: i18n.T("table.awr_name"), DataIndex: _awr_name},
+		{Title: i18n.T("table.awr_value"), DataIndex: _awr_value},
+	}
+	if report.Compare != nil {
+		columns = append(columns,
+			&define.TableColumn{Title: i18n.T("table.awr_compare_value"), DataIndex: _awr_compare},
+			&define.TableColumn{Title: i18n.T("table.awr_diff"), DataIndex: _awr_diff})
+	}
+	diffs := j.awrDiffs(report.RatioDiffs)
+	var rows []map[string]interface{}
+	for _, ratio := range report.Base.Efficiency {
+		row := map[string]interface{}{_awr_name: ratio.Name, _awr_value: ratio.Value}
+		if d, ok := diffs[ratio.Name]; ok {
+			row[_awr_compare], row[_awr_diff] = d.Compare, d.Percent
+		}
+		rows = append(rows, row)
+	}
+	j.awrTable(menu, metric, fmt.Sprintf(i18n.T("report.awr_efficiency"), title), columns, rows, "")
+}
+
+func (j *JsonParser) awrTimeModel(menu *define.PandoraMenu, metric *confdef.YHCMetric, title string, workload *awr.Workload) {
+	columns := []*define.TableColumn{
+		{Title: i18n.T("table.awr_name"), DataIndex: _awr_name},
+		{Title: i18n.T("table.awr_value"), DataIndex: _awr_value},
+		{Title: i18n.T("table.awr_db_time_percent"), DataIndex: _awr_db_time_percent},
+	}
+	var rows []map[string]interface{}
+	for _, t := range workload.TimeModel {
+		rows = append(rows, map[string]interface{}{_awr_name: t.Name, _awr_value: t.Value, _awr_db_time_percent: t.DBTimePercent})
+	}
+	j.awrTable(menu, metric, fmt.Sprintf(i18n.T("report.awr_time_model"), title), columns, rows, "")
+}
+
+func (j *JsonParser) awrWaitEvents(menu *define.PandoraMenu, metric *confdef.YHCMetric, title string, report *awr.Report) {
+	columns := []*define.TableColumn{
+		{Title: i18n.T("table.awr_event"), DataIndex: _awr_name},
+		{Title: i18n.T("table.awr_wait_class"), DataIndex: _awr_wait_class},
+		{Title: i18n.T("table.awr_waits"), DataIndex: _awr_waits},
+		{Title: i18n.T("table.awr_time_seconds"), DataIndex: _awr_time},
+		{Title: i18n.T("table.awr_avg_wait"), DataIndex: _awr_avg_wait},
+		{Title: i18n.T("table.awr_db_time_percent"), DataIndex: _awr_db_time_percent},
+	}
+	var rows []map[string]interface{}
+	for _, e := range report.Base.WaitEvents {
+		rows = append(rows, map[string]interface{}{
+			_awr_name:            e.Name,
+			_awr_wait_class:      e.WaitClass,
+			_awr_waits:           e.Waits,
+			_awr_time:            e.TimeSeconds,
+			_awr_avg_wait:        e.AvgWaitMs,
+			_awr_db_time_percent: e.DBTimePercent,
+		})
+	}
+	j.awrTable(menu, metric, fmt.Sprintf(i18n.T("report.awr_wait_events"), title), columns, rows, "")
+	if report.Compare == nil {
+		return
+	}
+	columns = []*define.TableColumn{
+		{Title: i18n.T("table.awr_event"), DataIndex: _awr_name},
+		{Title: i18n.T("table.awr_base_time"), DataIndex: _awr_value},
+		{Title: i18n.T("table.awr_compare_time"), DataIndex: _awr_compare},
+		{Title: i18n.T("table.awr_diff"), DataIndex: _awr_diff},
+	}
+	rows = nil
+	for _, d := range report.EventDiffs {
+		row := map[string]interface{}{_awr_name: d.Name, _awr_value: d.Base, _awr_compare: d.Compare}
+		// the event not waited in the base period has no change in percent
+		if d.Base != 0 {
+			row[_awr_diff] = d.Percent
+		} else {
+			row[_awr_diff] = _missing_value
+		}
+		rows = append(rows, row)
+	}
+	j.awrTable(menu, metric, fmt.Sprintf(i18n.T("report.awr_wait_event_changes"), title), columns, rows, "")
+}
+
+func (j *JsonParser) awrTopSQL(menu *define.PandoraMenu, metric *confdef.YHCMetric, title string, sqls []*awr.SQLStat) {
+	columns := []*define.TableColumn{
+		{Title: i18n.T("table.awr_sql_id"), DataIndex: _awr_sql_id},
+		{Title: i18n.T("table.awr_executions"), DataIndex: _awr_executions},
+		{Title: i18n.T("table.awr_elapsed"), DataIndex: _awr_elapsed},
+		{Title: i18n.T("table.awr_cpu"), DataIndex: _awr_cpu},
+		{Title: i18n.T("table.awr_gets"), DataIndex: _awr_gets},
+		{Title: i18n.T("table.awr_reads"), DataIndex: _awr_reads},
+		{Title: i18n.T("table.awr_sql_text"), DataIndex: _awr_sql_text},
+	}
+	var rows []map[string]interface{}
+	for _, s := range sqls {
+		rows = append(rows, map[string]interface{}{
+			_awr_sql_id:     s.SQLID,
+			_awr_executions: s.Executions,
+			_awr_elapsed:    mathutil.Round(s.ElapsedTime/1e6, _awr_decimal),
+			_awr_cpu:        mathutil.Round(s.CPUTime/1e6, _awr_decimal),
+			_awr_gets:       s.BufferGets,
+			_awr_reads:      s.DiskReads,
+			_awr_sql_text:   s.SQLText,
+		})
+	}
+	j.awrTable(menu, metric, title, columns, rows, define.TABLE_LAYOUT_FIXED)
+}
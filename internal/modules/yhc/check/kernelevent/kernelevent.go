@@ -0,0 +1,185 @@
+// The kernelevent package classifies the kernel messages of dmesg, system logs and journal into typed events,
+// such as OOM kills, hung tasks, lockups, hardware errors, I/O errors and file systems remounted read-only.
+package kernelevent
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	TYPE_OOM_KILL    = "oom_kill"
+	TYPE_PANIC       = "panic"
+	TYPE_HUNG_TASK   = "hung_task"
+	TYPE_SOFT_LOCKUP = "soft_lockup"
+	TYPE_HARD_LOCKUP = "hard_lockup"
+	TYPE_MCE         = "mce"
+	TYPE_EDAC_CE     = "edac_ce"
+	TYPE_EDAC_UE     = "edac_ue"
+	TYPE_IO_ERROR    = "io_error"
+	TYPE_FS_READONLY = "fs_readonly"
+	TYPE_FS_SHUTDOWN = "fs_shutdown"
+
+	SOURCE_DMESG   = "dmesg"
+	SOURCE_JOURNAL = "journal"
+	SOURCE_SYSLOG  = "syslog"
+
+	_kernel_prefix = "kernel: "
+	_edac_ue       = "UE"
+)
+
+var (
+	// Killed process 1234 (yasdb) total-vm:123kB, anon-rss:456kB, file-rss:0kB, shmem-rss:0kB
+	_oomRegexp      = regexp.MustCompile(`Killed process (\d+) \(([^)]+)\)(?:.*?anon-rss:(\d+)kB)?(?:, file-rss:(\d+)kB)?(?:, shmem-rss:(\d+)kB)?`)
+	_panicRegexp    = regexp.MustCompile(`Kernel panic - not syncing`)
+	_hungTaskRegexp = regexp.MustCompile(`task (\S+):(\d+) blocked for more than \d+ seconds`)
+	// watchdog: BUG: soft lockup - CPU#3 stuck for 22s! [yasdb:1234]
+	_softLockupRegexp = regexp.MustCompile(`soft lockup - (CPU#\d+) stuck for \d+s! \[(.+):(\d+)\]`)
+	_hardLockupRegexp = regexp.MustCompile(`(?i)hard LOCKUP on cpu (\d+)`)
+	// EDAC MC0: 1 CE memory read error on CPU_SrcID#0_Ha#0_Chan#1_DIMM#0
+	_edacRegexp = regexp.MustCompile(`EDAC (MC\d+): \d+ (CE|UE)`)
+	_mceRegexp  = regexp.MustCompile(`\[Hardware Error\]|Machine check events logged|mce: .*error`)
+	_mceCPU     = regexp.MustCompile(`CPU (\d+)`)
+	// blk_update_request: I/O error, dev sda, sector 123 / Buffer I/O error on dev sda1, logical block 0
+	_ioErrorRegexp = regexp.MustCompile(`I/O error,? (?:on )?dev ([\w.-]+)`)
+	// EXT4-fs (sda1): Remounting filesystem read-only
+	_fsReadonlyRegexp = regexp.MustCompile(`\(([^)]+)\): [Rr]emounting filesystem read-only`)
+	// XFS (dm-0): Corruption of in-memory data detected.  Shutting down filesystem
+	_fsShutdownRegexp = regexp.MustCompile(`XFS \(([^)]+)\): .*(?:Shutting down filesystem|shut down)`)
+)
+
+type Event struct {
+	Time    time.Time
+	Type    string
+	Source  string
+	Device  string
+	Process string
+	Pid     string
+	RSSKB   uint64
+	Message string
+	Count   int
+}
+
+func (e *Event) key() string {
+	return strings.Join([]string{e.Type, e.Device, e.Process, e.Pid}, "|")
+}
+
+// Classify returns the event of the kernel message, false is returned if the message is not a known event.
+func Classify(line string) (*Event, bool) {
+	event := &Event{Message: Message(line), Count: 1}
+	if m := _oomRegexp.FindStringSubmatch(line); m != nil {
+		event.Type, event.Pid, event.Process = TYPE_OOM_KILL, m[1], m[2]
+		for _, rss := range m[3:] {
+			kb, _ := strconv.ParseUint(rss, 10, 64)
+			event.RSSKB += kb
+		}
+		return event, true
+	}
+	if _panicRegexp.MatchString(line) {
+		event.Type = TYPE_PANIC
+		return event, true
+	}
+	if m := _hungTaskRegexp.FindStringSubmatch(line); m != nil {
+		event.Type, event.Process, event.Pid = TYPE_HUNG_TASK, m[1], m[2]
+		return event, true
+	}
+	if m := _softLockupRegexp.FindStringSubmatch(line); m != nil {
+		event.Type, event.Device, event.Process, event.Pid = TYPE_SOFT_LOCKUP, m[1], m[2], m[3]
+		return event, true
+	}
+	if m := _hardLockupRegexp.FindStringSubmatch(line); m != nil {
+		event.Type, event.Device = TYPE_HARD_LOCKUP, "CPU#"+m[1]
+		return event, true
+	}
+	if m := _edacRegexp.FindStringSubmatch(line); m != nil {
+		event.Type, event.Device = TYPE_EDAC_CE, m[1]
+		if m[2] == _edac_ue {
+			event.Type = TYPE_EDAC_UE
+		}
+		return event, true
+	}
+	if _mceRegexp.MatchString(line) {
+		event.Type = TYPE_MCE
+		if m := _mceCPU.FindStringSubmatch(line); m != nil {
+			event.Device = "CPU#" + m[1]
+		}
+		return event, true
+	}
+	if m := _ioErrorRegexp.FindStringSubmatch(line); m != nil {
+		event.Type, event.Device = TYPE_IO_ERROR, m[1]
+		return event, true
+	}
+	if m := _fsReadonlyRegexp.FindStringSubmatch(line); m != nil {
+		event.Type, event.Device = TYPE_FS_READONLY, m[1]
+		return event, true
+	}
+	if m := _fsShutdownRegexp.FindStringSubmatch(line); m != nil {
+		event.Type, event.Device = TYPE_FS_SHUTDOWN, m[1]
+		return event, true
+	}
+	return nil, false
+}
+
+// Match reports whether the kernel message is a known event.
+func Match(line string) bool {
+	_, ok := Classify(line)
+	return ok
+}
+
+// Message returns the kernel message without the prefix of syslog, journal or dmesg.
+func Message(line string) string {
+	if index := strings.Index(line, _kernel_prefix); index >= 0 {
+		line = line[index+len(_kernel_prefix):]
+	}
+	line = strings.TrimSpace(line)
+	// [  123.456789] message
+	if strings.HasPrefix(line, "[") {
+		if index := strings.Index(line, "]"); index > 0 {
+			line = line[index+1:]
+		}
+	}
+	return strings.TrimSpace(line)
+}
+
+// Collector merges the same events read from different sources, or repeated in a short time,
+// such as the I/O errors of a device.
+type Collector struct {
+	window time.Duration
+	events []*Event
+	last   map[string]*Event
+}
+
+func NewCollector(window time.Duration) *Collector {
+	return &Collector{window: window, last: make(map[string]*Event)}
+}
+
+func (c *Collector) Add(event *Event) {
+	key := event.key()
+	if last, ok := c.last[key]; ok && absDuration(event.Time.Sub(last.Time)) <= c.window {
+		last.Count++
+		if event.Time.Before(last.Time) {
+			last.Time = event.Time
+		}
+		return
+	}
+	c.events = append(c.events, event)
+	c.last[key] = event
+}
+
+// Events returns the events sorted by time.
+func (c *Collector) Events() []*Event {
+	sort.SliceStable(c.events, func(i, j int) bool {
+		return c.events[i].Time.Before(c.events[j].Time)
+	})
+	return c.events
+}
+
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
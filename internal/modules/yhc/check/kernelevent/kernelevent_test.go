@@ -0,0 +1,66 @@
+package kernelevent_test
+
+import (
+	"testing"
+	"time"
+
+	"yhc/internal/modules/yhc/check/kernelevent"
+)
+
+func TestClassify(t *testing.T) {
+	cases := []struct {
+		line    string
+		typ     string
+		device  string
+		process string
+		pid     string
+		rss     uint64
+	}{
+		{"Oct 19 16:00:00 host kernel: Out of memory: Killed process 1234 (yasdb) total-vm:8000kB, anon-rss:4000kB, file-rss:100kB, shmem-rss:20kB", kernelevent.TYPE_OOM_KILL, "", "yasdb", "1234", 4120},
+		{"[ 100.5] Killed process 99 (java) total-vm:100kB, anon-rss:50kB, file-rss:0kB", kernelevent.TYPE_OOM_KILL, "", "java", "99", 50},
+		{"[ 100.5] INFO: task yasdb:2345 blocked for more than 120 seconds.", kernelevent.TYPE_HUNG_TASK, "", "yasdb", "2345", 0},
+		{"[ 100.5] watchdog: BUG: soft lockup - CPU#3 stuck for 22s! [kworker/3:1:77]", kernelevent.TYPE_SOFT_LOCKUP, "CPU#3", "kworker/3:1", "77", 0},
+		{"[ 100.5] Watchdog detected hard LOCKUP on cpu 5", kernelevent.TYPE_HARD_LOCKUP, "CPU#5", "", "", 0},
+		{"[ 100.5] EDAC MC0: 1 CE memory read error on CPU_SrcID#0_Ha#0_Chan#1_DIMM#0", kernelevent.TYPE_EDAC_CE, "MC0", "", "", 0},
+		{"[ 100.5] EDAC MC1: 1 UE memory read error on CPU_SrcID#1", kernelevent.TYPE_EDAC_UE, "MC1", "", "", 0},
+		{"[ 100.5] mce: [Hardware Error]: CPU 2: Machine Check: 0 Bank 5: be00000000800400", kernelevent.TYPE_MCE, "CPU#2", "", "", 0},
+		{"[ 100.5] blk_update_request: I/O error, dev sdb, sector 2048 op 0x1:(WRITE)", kernelevent.TYPE_IO_ERROR, "sdb", "", "", 0},
+		{"[ 100.5] Buffer I/O error on dev dm-0, logical block 0, async page read", kernelevent.TYPE_IO_ERROR, "dm-0", "", "", 0},
+		{"[ 100.5] EXT4-fs (sda1): Remounting filesystem read-only", kernelevent.TYPE_FS_READONLY, "sda1", "", "", 0},
+		{"[ 100.5] XFS (dm-1): Corruption of in-memory data detected.  Shutting down filesystem", kernelevent.TYPE_FS_SHUTDOWN, "dm-1", "", "", 0},
+		{"[ 100.5] Kernel panic - not syncing: Fatal exception", kernelevent.TYPE_PANIC, "", "", "", 0},
+	}
+	for _, c := range cases {
+		event, ok := kernelevent.Classify(c.line)
+		if !ok {
+			t.Errorf("line not classified: %s", c.line)
+			continue
+		}
+		if event.Type != c.typ || event.Device != c.device || event.Process != c.process || event.Pid != c.pid || event.RSSKB != c.rss {
+			t.Errorf("unexpected event of %s: %+v", c.line, event)
+		}
+	}
+	if _, ok := kernelevent.Classify("[ 100.5] e1000e: eth0 NIC Link is Up 1000 Mbps Full Duplex"); ok {
+		t.Error("unexpected event")
+	}
+	if msg := kernelevent.Message("Oct 19 16:00:00 host kernel: [ 100.5] EXT4-fs (sda1): error"); msg != "EXT4-fs (sda1): error" {
+		t.Errorf("unexpected message: %s", msg)
+	}
+}
+
+func TestCollector(t *testing.T) {
+	now := time.Now()
+	collector := kernelevent.NewCollector(time.Minute)
+	for i, source := range []string{kernelevent.SOURCE_DMESG, kernelevent.SOURCE_SYSLOG, kernelevent.SOURCE_DMESG} {
+		event, _ := kernelevent.Classify("blk_update_request: I/O error, dev sdb, sector 2048")
+		event.Source, event.Time = source, now.Add(time.Duration(i)*time.Second)
+		collector.Add(event)
+	}
+	event, _ := kernelevent.Classify("blk_update_request: I/O error, dev sdb, sector 4096")
+	event.Time = now.Add(-time.Hour)
+	collector.Add(event)
+	events := collector.Events()
+	if len(events) != 2 || events[1].Count != 3 || !events[0].Time.Equal(now.Add(-time.Hour)) {
+		t.Fatalf("unexpected events: %+v", events)
+	}
+}
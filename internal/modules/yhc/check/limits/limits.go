@@ -0,0 +1,303 @@
+// The limits package parses the resource limits of processes from /proc/<pid>/limits
+// and the limits configured by pam_limits in /etc/security/limits.conf and limits.d.
+package limits
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	LIMIT_NOFILE  = "nofile"
+	LIMIT_NPROC   = "nproc"
+	LIMIT_MEMLOCK = "memlock"
+	LIMIT_CORE    = "core"
+	LIMIT_STACK   = "stack"
+
+	LIMITS_CONF = "/etc/security/limits.conf"
+	LIMITS_DIR  = "/etc/security/limits.d"
+
+	// UNLIMITED is the value of 'unlimited' and 'infinity'
+	UNLIMITED int64 = -1
+	// NOT_SET means the limit is not configured
+	NOT_SET int64 = -2
+
+	_type_soft = "soft"
+	_type_hard = "hard"
+	_type_both = "-"
+	_kb        = 1024
+
+	_proc_status    = "status"
+	_status_uid     = "Uid"
+	_status_threads = "Threads"
+)
+
+// _procLimitNames maps the names in /proc/<pid>/limits to the items of limits.conf,
+// memlock, core and stack are converted from bytes to KB which is the unit of limits.conf.
+var _procLimitNames = map[string]struct {
+	item      string
+	kiloBytes bool
+}{
+	"Max open files":     {LIMIT_NOFILE, false},
+	"Max processes":      {LIMIT_NPROC, false},
+	"Max locked memory":  {LIMIT_MEMLOCK, true},
+	"Max core file size": {LIMIT_CORE, true},
+	"Max stack size":     {LIMIT_STACK, true},
+}
+
+// Limit is the soft and hard value of a resource limit.
+type Limit struct {
+	Soft int64
+	Hard int64
+}
+
+// ParseProcLimits parses the content of /proc/<pid>/limits.
+func ParseProcLimits(reader io.Reader) (map[string]Limit, error) {
+	res := make(map[string]Limit)
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		line := scanner.Text()
+		for name, limit := range _procLimitNames {
+			if !strings.HasPrefix(line, name) {
+				continue
+			}
+			fields := strings.Fields(strings.TrimPrefix(line, name))
+			if len(fields) < 2 {
+				continue
+			}
+			soft, hard := parseValue(fields[0]), parseValue(fields[1])
+			if limit.kiloBytes {
+				soft, hard = toKiloBytes(soft), toKiloBytes(hard)
+			}
+			res[limit.item] = Limit{Soft: soft, Hard: hard}
+		}
+	}
+	return res, scanner.Err()
+}
+
+// ParseLimitsConf returns the limits of the user configured in limits.conf and the files in limits.d.
+// The files in limits.d are read after limits.conf in lexical order, the entry of the user overrides
+// the entry of its groups, which overrides the wildcard entry, and the later entry overrides the former
+// one of the same domain type. Limits which are not configured are NOT_SET.
+func ParseLimitsConf(conf, dir, user string, groups []string) (map[string]Limit, error) {
+	files := []string{conf}
+	matches, err := filepath.Glob(filepath.Join(dir, "*.conf"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(matches)
+	files = append(files, matches...)
+	p := &confParser{
+		user:   user,
+		groups: groups,
+		values: make(map[string]map[string]entry),
+	}
+	for _, fname := range files {
+		f, err := os.Open(fname)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+		err = p.parse(f)
+		f.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return p.limits(), nil
+}
+
+type entry struct {
+	value    int64
+	priority int
+}
+
+type confParser struct {
+	user   string
+	groups []string
+	// values is keyed by item and type
+	values map[string]map[string]entry
+}
+
+func (p *confParser) parse(reader io.Reader) error {
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if index := strings.Index(line, "#"); index >= 0 {
+			line = line[:index]
+		}
+		fields := strings.Fields(line)
+		if len(fields) < 4 {
+			continue
+		}
+		priority := p.priority(fields[0])
+		if priority == 0 {
+			continue
+		}
+		item, value := fields[2], parseValue(fields[3])
+		types := []string{fields[1]}
+		if fields[1] == _type_both {
+			types = []string{_type_soft, _type_hard}
+		}
+		for _, t := range types {
+			if t != _type_soft && t != _type_hard {
+				continue
+			}
+			if _, ok := p.values[item]; !ok {
+				p.values[item] = make(map[string]entry)
+			}
+			if old, ok := p.values[item][t]; ok && old.priority > priority {
+				continue
+			}
+			p.values[item][t] = entry{value: value, priority: priority}
+		}
+	}
+	return scanner.Err()
+}
+
+// priority returns 0 if the domain does not match the user.
+func (p *confParser) priority(domain string) int {
+	switch {
+	case domain == p.user:
+		return 3
+	case strings.HasPrefix(domain, "@"):
+		for _, group := range p.groups {
+			if group == domain[1:] {
+				return 2
+			}
+		}
+		return 0
+	case domain == "*":
+		return 1
+	default:
+		return 0
+	}
+}
+
+func (p *confParser) limits() map[string]Limit {
+	res := make(map[string]Limit)
+	for item, values := range p.values {
+		limit := Limit{Soft: NOT_SET, Hard: NOT_SET}
+		if e, ok := values[_type_soft]; ok {
+			limit.Soft = e.value
+		}
+		if e, ok := values[_type_hard]; ok {
+			limit.Hard = e.value
+		}
+		res[item] = limit
+	}
+	return res
+}
+
+// IsLess returns true if the value is less than the recommended value, UNLIMITED is greater than any value.
+func IsLess(value, recommended int64) bool {
+	if recommended == UNLIMITED {
+		return value != UNLIMITED
+	}
+	return value != UNLIMITED && value < recommended
+}
+
+// Format returns the readable value.
+func Format(value int64) string {
+	switch value {
+	case UNLIMITED:
+		return "unlimited"
+	case NOT_SET:
+		return "-"
+	default:
+		return strconv.FormatInt(value, 10)
+	}
+}
+
+func parseValue(s string) int64 {
+	switch strings.ToLower(s) {
+	case "unlimited", "infinity":
+		return UNLIMITED
+	}
+	value, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return NOT_SET
+	}
+	return value
+}
+
+func toKiloBytes(value int64) int64 {
+	if value < 0 {
+		return value
+	}
+	return value / _kb
+}
+
+// CountUserTasks counts the tasks of all processes whose real uid is the uid, which is what RLIMIT_NPROC limits,
+// procDir is usually /proc, the processes exiting while counting are skipped.
+func CountUserTasks(procDir string, uid int) (int, error) {
+	entries, err := os.ReadDir(procDir)
+	if err != nil {
+		return 0, err
+	}
+	var count int
+	for _, entry := range entries {
+		if _, err := strconv.Atoi(entry.Name()); err != nil {
+			continue
+		}
+		status, err := ParseProcStatus(filepath.Join(procDir, entry.Name(), _proc_status))
+		if err != nil || status.RealUID != uid {
+			continue
+		}
+		count += status.Threads
+	}
+	return count, nil
+}
+
+// ProcStatus is the owner and thread count of a process from /proc/<pid>/status.
+type ProcStatus struct {
+	RealUID int
+	Threads int
+}
+
+// ParseProcStatus parses the real uid and the thread count from the status file of a process.
+func ParseProcStatus(path string) (ProcStatus, error) {
+	status := ProcStatus{RealUID: -1}
+	f, err := os.Open(path)
+	if err != nil {
+		return status, err
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), ":")
+		if !ok {
+			continue
+		}
+		fields := strings.Fields(value)
+		if len(fields) == 0 {
+			continue
+		}
+		switch key {
+		case _status_uid:
+			// real, effective, saved set and filesystem uids
+			if status.RealUID, err = strconv.Atoi(fields[0]); err != nil {
+				return status, err
+			}
+		case _status_threads:
+			if status.Threads, err = strconv.Atoi(fields[0]); err != nil {
+				return status, err
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return status, err
+	}
+	if status.RealUID < 0 {
+		return status, fmt.Errorf("uid not found in %s", path)
+	}
+	return status, nil
+}
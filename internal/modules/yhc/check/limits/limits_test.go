@@ -0,0 +1,103 @@
+package limits_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"yhc/internal/modules/yhc/check/limits"
+)
+
+const _procLimits = `Limit                     Soft Limit           Hard Limit           Units
+Max cpu time              unlimited            unlimited            seconds
+Max file size             unlimited            unlimited            bytes
+Max stack size            8388608              unlimited            bytes
+Max core file size        0                    unlimited            bytes
+Max processes             4096                 63422                processes
+Max open files            1024                 524288               files
+Max locked memory         65536                65536                bytes
+`
+
+func TestParseProcLimits(t *testing.T) {
+	res, err := limits.ParseProcLimits(strings.NewReader(_procLimits))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]limits.Limit{
+		limits.LIMIT_NOFILE:  {Soft: 1024, Hard: 524288},
+		limits.LIMIT_NPROC:   {Soft: 4096, Hard: 63422},
+		limits.LIMIT_MEMLOCK: {Soft: 64, Hard: 64},
+		limits.LIMIT_CORE:    {Soft: 0, Hard: limits.UNLIMITED},
+		limits.LIMIT_STACK:   {Soft: 8192, Hard: limits.UNLIMITED},
+	}
+	for item, limit := range expected {
+		if res[item] != limit {
+			t.Errorf("%s: expected %v, got %v", item, limit, res[item])
+		}
+	}
+}
+
+func TestParseLimitsConf(t *testing.T) {
+	tmp := t.TempDir()
+	conf := filepath.Join(tmp, "limits.conf")
+	dir := filepath.Join(tmp, "limits.d")
+	os.MkdirAll(dir, 0755)
+	os.WriteFile(conf, []byte(`# comment
+*        soft   nofile   4096
+@yashan  -      nofile   65536
+yashan   soft   nproc    65536
+other    -      memlock  unlimited
+`), 0644)
+	os.WriteFile(filepath.Join(dir, "90-nproc.conf"), []byte(`*  soft  nproc  4096
+*  -      memlock unlimited # trailing comment
+`), 0644)
+	res, err := limits.ParseLimitsConf(conf, dir, "yashan", []string{"yashan"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]limits.Limit{
+		limits.LIMIT_NOFILE:  {Soft: 65536, Hard: 65536},
+		limits.LIMIT_NPROC:   {Soft: 65536, Hard: limits.NOT_SET},
+		limits.LIMIT_MEMLOCK: {Soft: limits.UNLIMITED, Hard: limits.UNLIMITED},
+	}
+	for item, limit := range expected {
+		if res[item] != limit {
+			t.Errorf("%s: expected %v, got %v", item, limit, res[item])
+		}
+	}
+	if _, ok := res[limits.LIMIT_CORE]; ok {
+		t.Error("core is not configured")
+	}
+}
+
+func TestIsLess(t *testing.T) {
+	if !limits.IsLess(1024, 65536) || limits.IsLess(limits.UNLIMITED, 65536) || !limits.IsLess(65536, limits.UNLIMITED) {
+		t.Error("unexpected compare result")
+	}
+}
+
+func TestCountUserTasks(t *testing.T) {
+	proc := t.TempDir()
+	statuses := map[string]string{
+		"1":   "Name:\tsystemd\nUid:\t0\t0\t0\t0\nThreads:\t1\n",
+		"100": "Name:\tyasdb\nUid:\t1000\t1000\t1000\t1000\nThreads:\t120\n",
+		"200": "Name:\tyasom\nUid:\t1000\t1000\t1000\t1000\nThreads:\t8\n",
+		// the effective uid is the user, but nproc counts the real uid
+		"300": "Name:\tsu\nUid:\t0\t1000\t1000\t1000\nThreads:\t1\n",
+	}
+	for pid, status := range statuses {
+		os.MkdirAll(filepath.Join(proc, pid), 0755)
+		os.WriteFile(filepath.Join(proc, pid, "status"), []byte(status), 0644)
+	}
+	// the entries which are not processes and the processes exited are skipped
+	os.MkdirAll(filepath.Join(proc, "sys"), 0755)
+	os.MkdirAll(filepath.Join(proc, "400"), 0755)
+	count, err := limits.CountUserTasks(proc, 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 128 {
+		t.Errorf("expected 128 tasks, got %d", count)
+	}
+}
@@ -0,0 +1,175 @@
+// The locktree package builds the blocking chains from the lock waits, a chain is a tree of which the root is a session
+// blocking the others without waiting itself, and the children of a session are the sessions waiting for its locks.
+package locktree
+
+import (
+	"sort"
+	"strconv"
+	"time"
+
+	"yhc/commons/constants"
+)
+
+// Session is a session taking part in the lock waits.
+type Session struct {
+	SID     string
+	User    string
+	Program string
+	SQLID   string
+	Status  string
+	// TxStart is the start time of the transaction of the session, zero if there is no transaction
+	TxStart time.Time
+	// IdleSeconds is the seconds since the last call of the session, 0 if the session is active
+	IdleSeconds float64
+}
+
+// Wait is a session waiting for a lock held by another session.
+type Wait struct {
+	Waiter  string
+	Blocker string
+	Seconds float64
+}
+
+// Node is a session in a blocking chain.
+type Node struct {
+	Session *Session
+	// WaitSeconds is the seconds the session has waited for its blocker, 0 for the root
+	WaitSeconds float64
+	// Blockers is all the sessions the session waits for ordered by SID, the node is only put under the first one
+	Blockers []string
+	// Blocked is the number of the sessions blocked by the session directly or indirectly
+	Blocked  int
+	Children []*Node
+}
+
+// Chain is a tree of the sessions blocked by the root.
+type Chain struct {
+	Root *Node
+	// Depth is the number of the levels of the tree, 2 if the root only blocks the sessions not blocking others
+	Depth int
+	// AgeSeconds is the longest wait in the chain, i.e. how long the chain has lasted
+	AgeSeconds float64
+}
+
+// Build builds the blocking chains ordered by the number of the blocked sessions and the age. A waiter blocked by
+// several sessions is put under the blocker with the smallest SID by number with all its blockers listed, and the sessions waiting
+// for each other, i.e. a deadlock, are rooted at the session with the smallest SID.
+func Build(sessions map[string]*Session, waits []Wait) []*Chain {
+	blockers := make(map[string]Wait)
+	allBlockers := make(map[string][]string)
+	for _, w := range waits {
+		if w.Waiter == w.Blocker {
+			continue
+		}
+		allBlockers[w.Waiter] = append(allBlockers[w.Waiter], w.Blocker)
+		if old, ok := blockers[w.Waiter]; !ok || lessSID(w.Blocker, old.Blocker) || (w.Blocker == old.Blocker && w.Seconds > old.Seconds) {
+			blockers[w.Waiter] = w
+		}
+	}
+	for waiter, sids := range allBlockers {
+		allBlockers[waiter] = sortSIDs(dedup(sids))
+	}
+	children := make(map[string][]Wait)
+	sids := make(map[string]struct{})
+	for waiter, w := range blockers {
+		children[w.Blocker] = append(children[w.Blocker], w)
+		sids[waiter], sids[w.Blocker] = struct{}{}, struct{}{}
+	}
+	for _, c := range children {
+		sort.Slice(c, func(i, j int) bool { return lessSID(c[i].Waiter, c[j].Waiter) })
+	}
+	var roots []string
+	for sid := range sids {
+		if _, waiting := blockers[sid]; !waiting {
+			roots = append(roots, sid)
+		}
+	}
+	sortSIDs(roots)
+	visited := make(map[string]bool)
+	var res []*Chain
+	build := func(root string) {
+		chain := &Chain{}
+		chain.Root = chain.grow(sessions, children, allBlockers, visited, root, 0, 1)
+		res = append(res, chain)
+	}
+	for _, root := range roots {
+		build(root)
+	}
+	// the sessions left are in the cycles
+	var left []string
+	for sid := range sids {
+		if !visited[sid] {
+			left = append(left, sid)
+		}
+	}
+	sortSIDs(left)
+	for _, sid := range left {
+		if !visited[sid] {
+			build(sid)
+		}
+	}
+	sort.SliceStable(res, func(i, j int) bool {
+		if res[i].Root.Blocked != res[j].Root.Blocked {
+			return res[i].Root.Blocked > res[j].Root.Blocked
+		}
+		return res[i].AgeSeconds > res[j].AgeSeconds
+	})
+	return res
+}
+
+func (c *Chain) grow(sessions map[string]*Session, children map[string][]Wait, blockers map[string][]string, visited map[string]bool,
+	sid string, seconds float64, depth int) *Node {
+	visited[sid] = true
+	session, ok := sessions[sid]
+	if !ok {
+		// the session ended after the locks were queried
+		session = &Session{SID: sid}
+	}
+	node := &Node{Session: session, WaitSeconds: seconds, Blockers: blockers[sid]}
+	if depth > c.Depth {
+		c.Depth = depth
+	}
+	if seconds > c.AgeSeconds {
+		c.AgeSeconds = seconds
+	}
+	for _, w := range children[sid] {
+		if visited[w.Waiter] {
+			continue
+		}
+		child := c.grow(sessions, children, blockers, visited, w.Waiter, w.Seconds, depth+1)
+		node.Blocked += child.Blocked + 1
+		node.Children = append(node.Children, child)
+	}
+	return node
+}
+
+// lessSID compares the SIDs by number, the SIDs which are not numbers are compared as strings after the numbers.
+func lessSID(a, b string) bool {
+	x, errA := strconv.ParseUint(a, constants.BASE_DECIMAL, constants.BIT_SIZE_64)
+	y, errB := strconv.ParseUint(b, constants.BASE_DECIMAL, constants.BIT_SIZE_64)
+	switch {
+	case errA == nil && errB == nil:
+		return x < y
+	case errA == nil || errB == nil:
+		return errA == nil
+	default:
+		return a < b
+	}
+}
+
+func sortSIDs(sids []string) []string {
+	sort.Slice(sids, func(i, j int) bool { return lessSID(sids[i], sids[j]) })
+	return sids
+}
+
+func dedup(sids []string) []string {
+	seen := make(map[string]struct{}, len(sids))
+	res := sids[:0]
+	for _, sid := range sids {
+		if _, ok := seen[sid]; !ok {
+			seen[sid] = struct{}{}
+			res = append(res, sid)
+		}
+	}
+	return res
+}
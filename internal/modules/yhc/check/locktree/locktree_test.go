@@ -0,0 +1,53 @@
+package locktree_test
+
+import (
+	"testing"
+
+	"yhc/internal/modules/yhc/check/locktree"
+)
+
+func TestBuild(t *testing.T) {
+	sessions := map[string]*locktree.Session{
+		"1":  {SID: "1", User: "APP"},
+		"2":  {SID: "2"},
+		"3":  {SID: "3"},
+		"10": {SID: "10"},
+		"7":  {SID: "7"},
+		"8":  {SID: "8"},
+	}
+	waits := []locktree.Wait{
+		{Waiter: "2", Blocker: "1", Seconds: 10},
+		{Waiter: "3", Blocker: "2", Seconds: 30},
+		{Waiter: "10", Blocker: "9", Seconds: 5},
+		// 10 also waits for 1, it is put under the blocker with the smallest SID by number with both blockers listed
+		{Waiter: "10", Blocker: "1", Seconds: 5},
+		// a deadlock of 7 and 8
+		{Waiter: "7", Blocker: "8", Seconds: 1},
+		{Waiter: "8", Blocker: "7", Seconds: 2},
+	}
+	chains := locktree.Build(sessions, waits)
+	if len(chains) != 2 {
+		t.Fatalf("unexpected chains: %d", len(chains))
+	}
+	root := chains[0].Root
+	if root.Session.SID != "1" || root.Session.User != "APP" || root.Blocked != 3 || chains[0].Depth != 3 || chains[0].AgeSeconds != 30 {
+		t.Fatalf("unexpected chain: %+v", chains[0])
+	}
+	// the children are ordered by SID by number
+	if len(root.Children) != 2 || root.Children[0].Session.SID != "2" || root.Children[0].Blocked != 1 || root.Children[1].Session.SID != "10" || root.Children[1].WaitSeconds != 5 {
+		t.Fatalf("unexpected children: %+v", root.Children)
+	}
+	if blockers := root.Children[1].Blockers; len(blockers) != 2 || blockers[0] != "1" || blockers[1] != "9" {
+		t.Fatalf("unexpected blockers: %v", blockers)
+	}
+	if len(root.Blockers) != 0 || len(root.Children[0].Blockers) != 1 {
+		t.Fatalf("unexpected blockers: %v, %v", root.Blockers, root.Children[0].Blockers)
+	}
+	if root.Children[0].Children[0].Session.SID != "3" {
+		t.Fatalf("unexpected grandchildren: %+v", root.Children[0].Children)
+	}
+	// the deadlock is rooted at the smallest SID
+	if chains[1].Root.Session.SID != "7" || chains[1].Root.Blocked != 1 || chains[1].AgeSeconds != 2 {
+		t.Fatalf("unexpected deadlock chain: %+v", chains[1].Root)
+	}
+}
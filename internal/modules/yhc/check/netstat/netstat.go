@@ -0,0 +1,266 @@
+// The netstat package parses the network statistics in /proc/net, and counts the TCP connections of a port.
+package netstat
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	PROC_NET = "/proc/net"
+
+	PROTO_TCP    = "Tcp"
+	PROTO_TCPEXT = "TcpExt"
+
+	TCP_RETRANS_SEGS     = "RetransSegs"
+	TCP_OUT_SEGS         = "OutSegs"
+	TCPEXT_LISTEN_OVERFL = "ListenOverflows"
+	TCPEXT_LISTEN_DROPS  = "ListenDrops"
+
+	STATE_ESTABLISHED = "ESTABLISHED"
+	STATE_TIME_WAIT   = "TIME_WAIT"
+	STATE_CLOSE_WAIT  = "CLOSE_WAIT"
+	STATE_LISTEN      = "LISTEN"
+
+	_dev     = "dev"
+	_snmp    = "snmp"
+	_netstat = "netstat"
+	_tcp     = "tcp"
+	_tcp6    = "tcp6"
+
+	_dev_field_count = 16
+)
+
+// the states in /proc/net/tcp, see include/net/tcp_states.h
+var _tcpStates = map[string]string{
+	"01": STATE_ESTABLISHED,
+	"06": STATE_TIME_WAIT,
+	"08": STATE_CLOSE_WAIT,
+	"0A": STATE_LISTEN,
+}
+
+// DevStat is the counters of an interface in /proc/net/dev.
+type DevStat struct {
+	RxPackets  uint64
+	RxErrors   uint64
+	RxDropped  uint64
+	RxOverruns uint64
+	RxFrame    uint64
+	TxPackets  uint64
+	TxErrors   uint64
+	TxDropped  uint64
+	TxOverruns uint64
+	TxCarrier  uint64
+	Collisions uint64
+}
+
+// Sub returns the increase of the counters since old, counters which have been reset are taken as 0.
+func (d *DevStat) Sub(old *DevStat) *DevStat {
+	return &DevStat{
+		RxPackets:  sub(d.RxPackets, old.RxPackets),
+		RxErrors:   sub(d.RxErrors, old.RxErrors),
+		RxDropped:  sub(d.RxDropped, old.RxDropped),
+		RxOverruns: sub(d.RxOverruns, old.RxOverruns),
+		RxFrame:    sub(d.RxFrame, old.RxFrame),
+		TxPackets:  sub(d.TxPackets, old.TxPackets),
+		TxErrors:   sub(d.TxErrors, old.TxErrors),
+		TxDropped:  sub(d.TxDropped, old.TxDropped),
+		TxOverruns: sub(d.TxOverruns, old.TxOverruns),
+		TxCarrier:  sub(d.TxCarrier, old.TxCarrier),
+		Collisions: sub(d.Collisions, old.Collisions),
+	}
+}
+
+// ProtoStats is the counters in /proc/net/snmp and /proc/net/netstat, such as stats["Tcp"]["RetransSegs"].
+type ProtoStats map[string]map[string]int64
+
+// Delta returns the increase of the counter since old.
+func (p ProtoStats) Delta(old ProtoStats, proto, name string) int64 {
+	delta := p[proto][name] - old[proto][name]
+	if delta < 0 {
+		return 0
+	}
+	return delta
+}
+
+// Snapshot is the network statistics at a time.
+type Snapshot struct {
+	Time  time.Time
+	Dev   map[string]*DevStat
+	Proto ProtoStats
+}
+
+// Sample reads the statistics in root, such as /proc/net.
+func Sample(root string) (*Snapshot, error) {
+	snapshot := &Snapshot{Time: time.Now(), Proto: make(ProtoStats)}
+	devFile, err := os.Open(path.Join(root, _dev))
+	if err != nil {
+		return nil, err
+	}
+	defer devFile.Close()
+	if snapshot.Dev, err = ParseNetDev(devFile); err != nil {
+		return nil, err
+	}
+	for _, name := range []string{_snmp, _netstat} {
+		stats, err := parseProtoStatsFile(path.Join(root, name))
+		if err != nil {
+			return nil, err
+		}
+		for proto, counters := range stats {
+			snapshot.Proto[proto] = counters
+		}
+	}
+	return snapshot, nil
+}
+
+func parseProtoStatsFile(fname string) (ProtoStats, error) {
+	f, err := os.Open(fname)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ParseProtoStats(f)
+}
+
+// ParseNetDev parses /proc/net/dev.
+func ParseNetDev(reader io.Reader) (map[string]*DevStat, error) {
+	res := make(map[string]*DevStat)
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		line := scanner.Text()
+		index := strings.Index(line, ":")
+		if index < 0 {
+			// the headers
+			continue
+		}
+		fields := strings.Fields(line[index+1:])
+		if len(fields) < _dev_field_count {
+			return nil, fmt.Errorf("invalid line of net dev: %s", line)
+		}
+		values := make([]uint64, _dev_field_count)
+		for i := 0; i < _dev_field_count; i++ {
+			value, err := strconv.ParseUint(fields[i], 10, 64)
+			if err != nil {
+				return nil, err
+			}
+			values[i] = value
+		}
+		// receive: bytes packets errs drop fifo frame compressed multicast
+		// transmit: bytes packets errs drop fifo colls carrier compressed
+		res[strings.TrimSpace(line[:index])] = &DevStat{
+			RxPackets:  values[1],
+			RxErrors:   values[2],
+			RxDropped:  values[3],
+			RxOverruns: values[4],
+			RxFrame:    values[5],
+			TxPackets:  values[9],
+			TxErrors:   values[10],
+			TxDropped:  values[11],
+			TxOverruns: values[12],
+			Collisions: values[13],
+			TxCarrier:  values[14],
+		}
+	}
+	return res, scanner.Err()
+}
+
+// ParseProtoStats parses /proc/net/snmp or /proc/net/netstat, in which each protocol has a line of names
+// followed by a line of values.
+func ParseProtoStats(reader io.Reader) (ProtoStats, error) {
+	res := make(ProtoStats)
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		names := strings.Fields(scanner.Text())
+		if len(names) == 0 {
+			continue
+		}
+		if !scanner.Scan() {
+			return nil, errors.New("unexpected end of proto stats")
+		}
+		values := strings.Fields(scanner.Text())
+		if len(values) != len(names) || values[0] != names[0] {
+			return nil, fmt.Errorf("mismatched proto stats of %s", names[0])
+		}
+		proto := strings.TrimSuffix(names[0], ":")
+		counters := make(map[string]int64)
+		for i := 1; i < len(names); i++ {
+			value, err := strconv.ParseInt(values[i], 10, 64)
+			if err != nil {
+				return nil, err
+			}
+			counters[names[i]] = value
+		}
+		res[proto] = counters
+	}
+	return res, scanner.Err()
+}
+
+// CountTCPStates counts the TCP connections of the local port by state in /proc/net/tcp and /proc/net/tcp6 under root.
+func CountTCPStates(root string, port int) (map[string]int, error) {
+	res := make(map[string]int)
+	for _, name := range []string{_tcp, _tcp6} {
+		f, err := os.Open(path.Join(root, name))
+		if err != nil {
+			if os.IsNotExist(err) {
+				// ipv6 is disabled
+				continue
+			}
+			return nil, err
+		}
+		err = countTCPStates(f, port, res)
+		f.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+// ParseTCPStates counts the TCP connections of the local port by state in the content of /proc/net/tcp.
+func ParseTCPStates(reader io.Reader, port int) (map[string]int, error) {
+	res := make(map[string]int)
+	err := countTCPStates(reader, port, res)
+	return res, err
+}
+
+func countTCPStates(reader io.Reader, port int, res map[string]int) error {
+	scanner := bufio.NewScanner(reader)
+	// skip the header
+	scanner.Scan()
+	for scanner.Scan() {
+		// sl local_address rem_address st ...
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 4 {
+			continue
+		}
+		index := strings.LastIndex(fields[1], ":")
+		if index < 0 {
+			continue
+		}
+		localPort, err := strconv.ParseInt(fields[1][index+1:], 16, 32)
+		if err != nil {
+			return err
+		}
+		if int(localPort) != port {
+			continue
+		}
+		if state, ok := _tcpStates[fields[3]]; ok {
+			res[state]++
+		}
+	}
+	return scanner.Err()
+}
+
+func sub(new, old uint64) uint64 {
+	if new < old {
+		return 0
+	}
+	return new - old
+}
@@ -0,0 +1,70 @@
+package netstat_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"yhc/internal/modules/yhc/check/netstat"
+)
+
+func TestParseNetDev(t *testing.T) {
+	dev := `Inter-|   Receive                                                |  Transmit
+ face |bytes    packets errs drop fifo frame compressed multicast|bytes    packets errs drop fifo colls carrier compressed
+    lo: 1000      10    0    0    0     0          0         0     1000      10    0    0    0     0       0          0
+  eth0: 5000000  4000    3    7    2     1          0         0  3000000    2500    4    5    6     0       8          0
+`
+	stats, err := netstat.ParseNetDev(strings.NewReader(dev))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := &netstat.DevStat{RxPackets: 4000, RxErrors: 3, RxDropped: 7, RxOverruns: 2, RxFrame: 1,
+		TxPackets: 2500, TxErrors: 4, TxDropped: 5, TxOverruns: 6, TxCarrier: 8}
+	if len(stats) != 2 || !reflect.DeepEqual(stats["eth0"], expected) {
+		t.Fatalf("unexpected stats: %+v", stats["eth0"])
+	}
+	delta := expected.Sub(&netstat.DevStat{RxPackets: 1000, RxErrors: 5})
+	if delta.RxPackets != 3000 || delta.RxErrors != 0 {
+		t.Fatalf("unexpected delta: %+v", delta)
+	}
+}
+
+func TestParseProtoStats(t *testing.T) {
+	snmp := `Tcp: RtoAlgorithm RtoMin RtoMax MaxConn ActiveOpens RetransSegs OutSegs
+Tcp: 1 200 120000 -1 100 30 10000
+TcpExt: ListenOverflows ListenDrops
+TcpExt: 2 3
+`
+	stats, err := netstat.ParseProtoStats(strings.NewReader(snmp))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats[netstat.PROTO_TCP]["MaxConn"] != -1 || stats[netstat.PROTO_TCPEXT][netstat.TCPEXT_LISTEN_DROPS] != 3 {
+		t.Fatalf("unexpected stats: %v", stats)
+	}
+	old := netstat.ProtoStats{netstat.PROTO_TCP: {netstat.TCP_RETRANS_SEGS: 10}}
+	if delta := stats.Delta(old, netstat.PROTO_TCP, netstat.TCP_RETRANS_SEGS); delta != 20 {
+		t.Fatalf("unexpected delta: %d", delta)
+	}
+	if _, err := netstat.ParseProtoStats(strings.NewReader("Tcp: RtoAlgorithm\n")); err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+func TestParseTCPStates(t *testing.T) {
+	tcp := `  sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode
+   0: 00000000:0698 00000000:0000 0A 00000000:00000000 00:00000000 00000000  1000        0 1 1 0 100 0 0 10 0
+   1: 0100007F:0698 0100007F:D2F0 01 00000000:00000000 00:00000000 00000000  1000        0 2 1 0 20 4 30 10 -1
+   2: 0100007F:0698 0100007F:D2F2 06 00000000:00000000 03:00001000 00000000     0        0 0 3 0
+   3: 0100007F:0698 0100007F:D2F4 08 00000000:00000000 00:00000000 00000000  1000        0 3 1 0 20 4 30 10 -1
+   4: 0100007F:D2F6 0100007F:0698 06 00000000:00000000 03:00001000 00000000     0        0 0 3 0
+`
+	states, err := netstat.ParseTCPStates(strings.NewReader(tcp), 1688)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]int{netstat.STATE_LISTEN: 1, netstat.STATE_ESTABLISHED: 1, netstat.STATE_TIME_WAIT: 1, netstat.STATE_CLOSE_WAIT: 1}
+	if !reflect.DeepEqual(states, expected) {
+		t.Fatalf("expected %v, got %v", expected, states)
+	}
+}
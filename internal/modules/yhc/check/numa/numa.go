@@ -0,0 +1,310 @@
+// The numa package reads the NUMA topology and the CPU frequency governors from sysfs,
+// and the memory policy of a process from procfs.
+package numa
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	SYS_NODE = "/sys/devices/system/node"
+	SYS_CPU  = "/sys/devices/system/cpu"
+
+	POLICY_DEFAULT    = "default"
+	POLICY_BIND       = "bind"
+	POLICY_INTERLEAVE = "interleave"
+	POLICY_PREFER     = "prefer"
+	POLICY_LOCAL      = "local"
+
+	_node_prefix = "node"
+	_cpu_list    = "cpulist"
+	_meminfo     = "meminfo"
+	_distance    = "distance"
+	_numastat    = "numastat"
+	_online      = "online"
+	_governor    = "cpufreq/scaling_governor"
+
+	_numa_hit     = "numa_hit"
+	_numa_miss    = "numa_miss"
+	_numa_foreign = "numa_foreign"
+
+	_cpus_allowed_list = "Cpus_allowed_list:"
+	_mems_allowed_list = "Mems_allowed_list:"
+
+	_mem_total = "MemTotal"
+	_mem_free  = "MemFree"
+)
+
+var (
+	_nodeDirRegexp = regexp.MustCompile(`^node\d+$`)
+	_cpuDirRegexp  = regexp.MustCompile(`^cpu\d+$`)
+)
+
+type Node struct {
+	ID         int
+	CPUList    string
+	MemTotalKB uint64
+	MemFreeKB  uint64
+	Distances  []int
+	Stat       map[string]uint64
+}
+
+// MissPercent returns the percentage of numa_miss in the allocations intended for the node.
+func (n *Node) MissPercent() float64 {
+	hit, miss := n.Stat[_numa_hit], n.Stat[_numa_miss]
+	if hit+miss == 0 {
+		return 0
+	}
+	return float64(miss) * 100 / float64(hit+miss)
+}
+
+// StatSince returns a copy of the node whose statistics are the increments since the old node, the counters in numastat
+// are cumulative since boot, so the increments reflect the allocations of the sampling window only.
+func (n *Node) StatSince(old *Node) *Node {
+	res := *n
+	res.Stat = make(map[string]uint64, len(n.Stat))
+	for key, value := range n.Stat {
+		// a counter less than before is never expected, the increment is 0 then
+		if oldValue := old.Stat[key]; value > oldValue {
+			res.Stat[key] = value - oldValue
+		} else {
+			res.Stat[key] = 0
+		}
+	}
+	return &res
+}
+
+func (n *Node) NumaHit() uint64 {
+	return n.Stat[_numa_hit]
+}
+
+func (n *Node) NumaMiss() uint64 {
+	return n.Stat[_numa_miss]
+}
+
+func (n *Node) NumaForeign() uint64 {
+	return n.Stat[_numa_foreign]
+}
+
+// ReadNodes reads the NUMA nodes under root, such as /sys/devices/system/node, sorted by node id.
+func ReadNodes(root string) ([]*Node, error) {
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return nil, err
+	}
+	nodes := []*Node{}
+	for _, entry := range entries {
+		if !_nodeDirRegexp.MatchString(entry.Name()) {
+			continue
+		}
+		id, _ := strconv.Atoi(strings.TrimPrefix(entry.Name(), _node_prefix))
+		node, err := readNode(path.Join(root, entry.Name()), id)
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, node)
+	}
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].ID < nodes[j].ID
+	})
+	return nodes, nil
+}
+
+func readNode(dir string, id int) (*Node, error) {
+	node := &Node{ID: id, Stat: make(map[string]uint64)}
+	cpuList, err := readTrim(path.Join(dir, _cpu_list))
+	if err != nil {
+		return nil, err
+	}
+	node.CPUList = cpuList
+	distance, err := readTrim(path.Join(dir, _distance))
+	if err != nil {
+		return nil, err
+	}
+	for _, field := range strings.Fields(distance) {
+		d, err := strconv.Atoi(field)
+		if err != nil {
+			return nil, err
+		}
+		node.Distances = append(node.Distances, d)
+	}
+	meminfo, err := os.ReadFile(path.Join(dir, _meminfo))
+	if err != nil {
+		return nil, err
+	}
+	// such as 'Node 0 MemTotal:       65701384 kB'
+	for _, line := range strings.Split(string(meminfo), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 4 {
+			continue
+		}
+		value, err := strconv.ParseUint(fields[3], 10, 64)
+		if err != nil {
+			continue
+		}
+		switch strings.TrimSuffix(fields[2], ":") {
+		case _mem_total:
+			node.MemTotalKB = value
+		case _mem_free:
+			node.MemFreeKB = value
+		}
+	}
+	numastat, err := os.ReadFile(path.Join(dir, _numastat))
+	if err != nil {
+		return nil, err
+	}
+	for _, line := range strings.Split(string(numastat), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+		value, err := strconv.ParseUint(fields[1], 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		node.Stat[fields[0]] = value
+	}
+	return node, nil
+}
+
+// ReadGovernors returns the scaling governor of each CPU under root, such as /sys/devices/system/cpu,
+// CPUs without cpufreq, which is common in virtual machines, are not returned.
+func ReadGovernors(root string) (map[int]string, error) {
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return nil, err
+	}
+	res := make(map[int]string)
+	for _, entry := range entries {
+		if !_cpuDirRegexp.MatchString(entry.Name()) {
+			continue
+		}
+		governor, err := readTrim(path.Join(root, entry.Name(), _governor))
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+		id, _ := strconv.Atoi(strings.TrimPrefix(entry.Name(), "cpu"))
+		res[id] = governor
+	}
+	return res, nil
+}
+
+// ReadOnlineCPUs returns the online CPU list under root, such as /sys/devices/system/cpu.
+func ReadOnlineCPUs(root string) (string, error) {
+	return readTrim(path.Join(root, _online))
+}
+
+// ParseList parses a list such as '0-3,8,10-11' to the sorted ids.
+func ParseList(list string) ([]int, error) {
+	res := []int{}
+	list = strings.TrimSpace(list)
+	if len(list) == 0 {
+		return res, nil
+	}
+	for _, part := range strings.Split(list, ",") {
+		bounds := strings.SplitN(part, "-", 2)
+		start, err := strconv.Atoi(bounds[0])
+		if err != nil {
+			return nil, err
+		}
+		end := start
+		if len(bounds) == 2 {
+			if end, err = strconv.Atoi(bounds[1]); err != nil {
+				return nil, err
+			}
+		}
+		if end < start {
+			return nil, fmt.Errorf("invalid list %s", list)
+		}
+		for i := start; i <= end; i++ {
+			res = append(res, i)
+		}
+	}
+	sort.Ints(res)
+	return res, nil
+}
+
+// FormatList formats the ids to a list such as '0-3,8,10-11'.
+func FormatList(ids []int) string {
+	sorted := append([]int{}, ids...)
+	sort.Ints(sorted)
+	parts := []string{}
+	for i := 0; i < len(sorted); {
+		j := i
+		for j+1 < len(sorted) && sorted[j+1] == sorted[j]+1 {
+			j++
+		}
+		if i == j {
+			parts = append(parts, strconv.Itoa(sorted[i]))
+		} else {
+			parts = append(parts, fmt.Sprintf("%d-%d", sorted[i], sorted[j]))
+		}
+		i = j + 1
+	}
+	return strings.Join(parts, ",")
+}
+
+// ParseNumaMaps parses /proc/<pid>/numa_maps and returns the memory policy covering the most mappings,
+// such as 'default', 'bind:0' or 'interleave:0-1'.
+func ParseNumaMaps(reader io.Reader) (string, error) {
+	counts := make(map[string]int)
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 {
+			continue
+		}
+		counts[fields[1]]++
+	}
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+	policy, max := POLICY_DEFAULT, 0
+	for p, count := range counts {
+		if count > max || (count == max && p < policy) {
+			policy, max = p, count
+		}
+	}
+	return policy, nil
+}
+
+// ParseAllowedLists parses /proc/<pid>/status and returns the CPUs and memory nodes the process is allowed to use.
+func ParseAllowedLists(reader io.Reader) (cpus string, mems string, err error) {
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, _cpus_allowed_list):
+			cpus = strings.TrimSpace(strings.TrimPrefix(line, _cpus_allowed_list))
+		case strings.HasPrefix(line, _mems_allowed_list):
+			mems = strings.TrimSpace(strings.TrimPrefix(line, _mems_allowed_list))
+		}
+	}
+	err = scanner.Err()
+	return
+}
+
+// PolicyMode returns the mode of the policy, such as 'bind' of 'bind:0'.
+func PolicyMode(policy string) string {
+	return strings.SplitN(policy, ":", 2)[0]
+}
+
+func readTrim(fname string) (string, error) {
+	content, err := os.ReadFile(fname)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(content)), nil
+}
@@ -0,0 +1,109 @@
+package numa_test
+
+import (
+	"os"
+	"path"
+	"reflect"
+	"strings"
+	"testing"
+
+	"yhc/internal/modules/yhc/check/numa"
+)
+
+func writeFile(t *testing.T, fname, content string) {
+	if err := os.MkdirAll(path.Dir(fname), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(fname, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReadNodes(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, path.Join(root, "node1/cpulist"), "4-7\n")
+	writeFile(t, path.Join(root, "node1/distance"), "21 10\n")
+	writeFile(t, path.Join(root, "node1/meminfo"), "Node 1 MemTotal:       8000000 kB\nNode 1 MemFree:        2000000 kB\n")
+	writeFile(t, path.Join(root, "node1/numastat"), "numa_hit 900\nnuma_miss 100\nnuma_foreign 5\n")
+	writeFile(t, path.Join(root, "node0/cpulist"), "0-3\n")
+	writeFile(t, path.Join(root, "node0/distance"), "10 21\n")
+	writeFile(t, path.Join(root, "node0/meminfo"), "Node 0 MemTotal:       8000000 kB\nNode 0 MemFree:        1000000 kB\n")
+	writeFile(t, path.Join(root, "node0/numastat"), "numa_hit 1000\nnuma_miss 0\nnuma_foreign 100\n")
+	writeFile(t, path.Join(root, "possible"), "0-1\n")
+
+	nodes, err := numa.ReadNodes(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(nodes) != 2 || nodes[0].ID != 0 || nodes[1].ID != 1 {
+		t.Fatalf("unexpected nodes: %+v", nodes)
+	}
+	if nodes[1].CPUList != "4-7" || nodes[1].MemFreeKB != 2000000 || !reflect.DeepEqual(nodes[1].Distances, []int{21, 10}) {
+		t.Fatalf("unexpected node: %+v", nodes[1])
+	}
+	if nodes[1].MissPercent() != 10 || nodes[0].MissPercent() != 0 {
+		t.Fatalf("unexpected miss percent: %v %v", nodes[0].MissPercent(), nodes[1].MissPercent())
+	}
+}
+
+func TestReadGovernors(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, path.Join(root, "cpu0/cpufreq/scaling_governor"), "performance\n")
+	writeFile(t, path.Join(root, "cpu1/cpufreq/scaling_governor"), "powersave\n")
+	writeFile(t, path.Join(root, "cpu2/topology/core_id"), "2\n")
+	writeFile(t, path.Join(root, "online"), "0-2\n")
+	governors, err := numa.ReadGovernors(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(governors, map[int]string{0: "performance", 1: "powersave"}) {
+		t.Fatalf("unexpected governors: %v", governors)
+	}
+}
+
+func TestList(t *testing.T) {
+	ids, err := numa.ParseList("8,0-3,10-11")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(ids, []int{0, 1, 2, 3, 8, 10, 11}) {
+		t.Fatalf("unexpected ids: %v", ids)
+	}
+	if list := numa.FormatList(ids); list != "0-3,8,10-11" {
+		t.Fatalf("unexpected list: %s", list)
+	}
+	if _, err := numa.ParseList("3-1"); err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+func TestParseNumaMaps(t *testing.T) {
+	maps := `00400000 interleave:0-1 file=/opt/yasdb/bin/yasdb mapped=10
+00600000 interleave:0-1 anon=20 dirty=20
+7f0000000000 default anon=1
+`
+	policy, err := numa.ParseNumaMaps(strings.NewReader(maps))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if policy != "interleave:0-1" || numa.PolicyMode(policy) != numa.POLICY_INTERLEAVE {
+		t.Fatalf("unexpected policy: %s", policy)
+	}
+	cpus, mems, err := numa.ParseAllowedLists(strings.NewReader("Name:\tyasdb\nCpus_allowed_list:\t0-3\nMems_allowed_list:\t0\n"))
+	if err != nil || cpus != "0-3" || mems != "0" {
+		t.Fatalf("unexpected allowed lists: %s %s %v", cpus, mems, err)
+	}
+}
+
+func TestStatSince(t *testing.T) {
+	old := &numa.Node{ID: 0, Stat: map[string]uint64{"numa_hit": 1000000, "numa_miss": 500000, "numa_foreign": 10}}
+	node := &numa.Node{ID: 0, Stat: map[string]uint64{"numa_hit": 1000900, "numa_miss": 500100, "numa_foreign": 5}}
+	delta := node.StatSince(old)
+	// the lifetime miss percent is 33%, while the misses of the window are 10%
+	if delta.NumaHit() != 900 || delta.NumaMiss() != 100 || delta.NumaForeign() != 0 || delta.MissPercent() != 10 {
+		t.Fatalf("unexpected delta: %+v", delta.Stat)
+	}
+	if node.NumaHit() != 1000900 {
+		t.Fatal("the node is modified")
+	}
+}
@@ -59,11 +59,14 @@ var (
 		define.METRIC_YASDB_SECURITY_AUDIT_FILE_SIZE:                         {},
 		define.METRIC_YASDB_TABLESPACE:                                       {},
 
-		define.METRIC_HOST_BIOS_INFO:   {},
-		define.METRIC_HOST_FIREWALLD:   {},
-		define.METRIC_HOST_IPTABLES:    {},
-		define.METRIC_YASDB_DATAFILE:   {},
-		define.METRIC_YASDB_WAIT_EVENT: {},
+		define.METRIC_HOST_BIOS_INFO:           {},
+		define.METRIC_HOST_FIREWALLD:           {},
+		define.METRIC_HOST_IPTABLES:            {},
+		define.METRIC_HOST_SSHD_CONFIG:         {},
+		define.METRIC_HOST_YASDB_USER_PASSWORD: {},
+		define.METRIC_HOST_YASDB_USER_SUDO:     {},
+		define.METRIC_YASDB_DATAFILE:           {},
+		define.METRIC_YASDB_WAIT_EVENT:         {},
 
 		define.METRIC_YASDB_RUN_LOG_DATABASE_CHANGES: {},
 		define.METRIC_YASDB_SLOW_LOG_PARAMETER:       {},
@@ -75,6 +78,13 @@ var (
 
 		define.METRIC_YASDB_BACKUP_SET:                                                             {},
 		define.METRIC_YASDB_HISTORY_DB_TIME:                                                        {},
+		define.METRIC_YASDB_AWR_REPORT:                                                             {},
+		define.METRIC_YASDB_CURRENT_WAIT_EVENT:                                                     {},
+		define.METRIC_YASDB_CURRENT_WAIT_CLASS:                                                     {},
+		define.METRIC_YASDB_HISTORY_WAIT_EVENT:                                                     {},
+		define.METRIC_YASDB_HISTORY_WAIT_CLASS:                                                     {},
+		define.METRIC_YASDB_SESSION_WAIT:                                                           {},
+		define.METRIC_YASDB_BLOCKING_CHAIN:                                                         {},
 		define.METRIC_YASDB_HISTORY_BUFFER_HIT_RATE:                                                {},
 		define.METRIC_YASDB_INVALID_OBJECT:                                                         {},
 		define.METRIC_YASDB_INVISIBLE_INDEX:                                                        {},
@@ -123,6 +133,13 @@ var (
 		define.METRIC_HOST_DMESG_LOG_ERROR:                                                         checkDmesg,
 		define.METRIC_YASDB_BACKUP_SET:                                                             checkDBAPrivileges,
 		define.METRIC_YASDB_HISTORY_DB_TIME:                                                        checkSysWrmAndWrh,
+		define.METRIC_YASDB_AWR_REPORT:                                                             checkSysWrmAndWrh,
+		define.METRIC_YASDB_CURRENT_WAIT_EVENT:                                                     checkDBAPrivileges,
+		define.METRIC_YASDB_CURRENT_WAIT_CLASS:                                                     checkDBAPrivileges,
+		define.METRIC_YASDB_HISTORY_WAIT_EVENT:                                                     checkSysWrmAndWrh,
+		define.METRIC_YASDB_HISTORY_WAIT_CLASS:                                                     checkSysWrmAndWrh,
+		define.METRIC_YASDB_SESSION_WAIT:                                                           checkDBAPrivileges,
+		define.METRIC_YASDB_BLOCKING_CHAIN:                                                         checkDBAPrivileges,
 		define.METRIC_YASDB_HISTORY_BUFFER_HIT_RATE:                                                checkSysWrmAndWrh,
 		define.METRIC_YASDB_INVALID_OBJECT:                                                         checkDBAPrivileges,
 		define.METRIC_YASDB_INVISIBLE_INDEX:                                                        checkDBAPrivileges,
@@ -140,6 +157,9 @@ var (
 		define.METRIC_HOST_FIREWALLD:                                                               checkFirewalld,
 		define.METRIC_HOST_BIOS_INFO:                                                               checkRootPermission,
 		define.METRIC_HOST_IPTABLES:                                                                checkRootPermission,
+		define.METRIC_HOST_SSHD_CONFIG:                                                             checkRootPermission,
+		define.METRIC_HOST_YASDB_USER_PASSWORD:                                                     checkRootPermission,
+		define.METRIC_HOST_YASDB_USER_SUDO:                                                         checkRootPermission,
 	}
 
 	metricPermissionPathMap = map[string]getPathFunc{
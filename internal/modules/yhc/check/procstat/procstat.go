@@ -0,0 +1,235 @@
+// The procstat package samples the resource usage of the processes, and summarizes the samples per process.
+package procstat
+
+import (
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"yhc/utils/mathutil"
+
+	"github.com/shirou/gopsutil/process"
+)
+
+const (
+	CATEGORY_YASDB    = "yasdb"
+	CATEGORY_YASOM    = "yasom"
+	CATEGORY_YASAGENT = "yasagent"
+	CATEGORY_OTHER    = "other"
+)
+
+// Snapshot is the counters of a process at a point of time.
+type Snapshot struct {
+	Pid         int32
+	CreateTime  int64
+	Name        string
+	Cmdline     string
+	User        string
+	CPUSeconds  float64
+	RSS         uint64
+	ReadBytes   uint64
+	WriteBytes  uint64
+	CtxSwitches int64
+	Threads     int32
+}
+
+// Sample is the snapshots of all processes taken at the same time.
+type Sample struct {
+	Time      time.Time
+	Processes map[int32]*Snapshot
+}
+
+// Usage is the usage of a process between two samples, the rates are per second.
+type Usage struct {
+	CPUPercent        float64 `json:"cpuPercent"`
+	RSS               uint64  `json:"rss"`
+	ReadBytesPerSec   float64 `json:"readBytesPerSec"`
+	WriteBytesPerSec  float64 `json:"writeBytesPerSec"`
+	CtxSwitchesPerSec float64 `json:"ctxSwitchesPerSec"`
+	Threads           int32   `json:"threads"`
+}
+
+// Summary is the usage of a process over all samples.
+type Summary struct {
+	Pid      int32
+	Name     string
+	Category string
+	User     string
+	// Exe is the executable of the process, the arguments are not kept since they may contain the passwords
+	Exe         string
+	Samples     int
+	CPUAvg      float64
+	CPUMax      float64
+	RSSMax      uint64
+	ReadBytes   uint64
+	WriteBytes  uint64
+	CtxSwitches int64
+	ThreadsMax  int32
+}
+
+// Collect takes the snapshots of all processes, the processes exiting or not readable are skipped.
+func Collect() (*Sample, error) {
+	processes, err := process.Processes()
+	if err != nil {
+		return nil, err
+	}
+	sample := &Sample{Time: time.Now(), Processes: make(map[int32]*Snapshot, len(processes))}
+	for _, p := range processes {
+		if snapshot := snapshot(p); snapshot != nil {
+			sample.Processes[p.Pid] = snapshot
+		}
+	}
+	return sample, nil
+}
+
+func snapshot(p *process.Process) *Snapshot {
+	times, err := p.Times()
+	if err != nil {
+		return nil
+	}
+	s := &Snapshot{Pid: p.Pid, CPUSeconds: times.User + times.System}
+	s.CreateTime, _ = p.CreateTime()
+	s.Name, _ = p.Name()
+	s.Cmdline, _ = p.Cmdline()
+	s.User, _ = p.Username()
+	if mem, err := p.MemoryInfo(); err == nil {
+		s.RSS = mem.RSS
+	}
+	// the io counters of the processes of other users are only readable by root
+	if io, err := p.IOCounters(); err == nil {
+		s.ReadBytes, s.WriteBytes = io.ReadBytes, io.WriteBytes
+	}
+	if ctx, err := p.NumCtxSwitches(); err == nil {
+		s.CtxSwitches = ctx.Voluntary + ctx.Involuntary
+	}
+	s.Threads, _ = p.NumThreads()
+	return s
+}
+
+// Executable returns the executable of the process, which is the first field of the command line or the name.
+func Executable(name, cmdline string) string {
+	if fields := strings.Fields(cmdline); len(fields) != 0 {
+		return fields[0]
+	}
+	return name
+}
+
+// Category returns which YashanDB component the process is, or CATEGORY_OTHER.
+func Category(name, cmdline string) string {
+	bin := path.Base(Executable(name, cmdline))
+	for _, category := range []string{CATEGORY_YASDB, CATEGORY_YASOM, CATEGORY_YASAGENT} {
+		if bin == category || name == category {
+			return category
+		}
+	}
+	return CATEGORY_OTHER
+}
+
+// Same reports whether the two snapshots are of the same process, the pid may be reused.
+func Same(old, new *Snapshot) bool {
+	return old.Pid == new.Pid && old.CreateTime == new.CreateTime
+}
+
+// Rate calculates the usage between two snapshots of the same process.
+func Rate(old, new *Snapshot, seconds float64) Usage {
+	usage := Usage{RSS: new.RSS, Threads: new.Threads}
+	if seconds <= 0 {
+		return usage
+	}
+	usage.CPUPercent = mathutil.CounterDelta(old.CPUSeconds, new.CPUSeconds) * 100 / seconds
+	usage.ReadBytesPerSec = mathutil.CounterDelta(float64(old.ReadBytes), float64(new.ReadBytes)) / seconds
+	usage.WriteBytesPerSec = mathutil.CounterDelta(float64(old.WriteBytes), float64(new.WriteBytes)) / seconds
+	usage.CtxSwitchesPerSec = mathutil.CounterDelta(float64(old.CtxSwitches), float64(new.CtxSwitches)) / seconds
+	return usage
+}
+
+// Usages calculates the usage of every process between the adjacent samples, keyed by the time of the later sample.
+func Usages(samples []*Sample) map[time.Time]map[int32]Usage {
+	res := make(map[time.Time]map[int32]Usage)
+	for i := 1; i < len(samples); i++ {
+		old, new := samples[i-1], samples[i]
+		seconds := new.Time.Sub(old.Time).Seconds()
+		usages := make(map[int32]Usage)
+		for pid, n := range new.Processes {
+			if o, ok := old.Processes[pid]; ok && Same(o, n) {
+				usages[pid] = Rate(o, n, seconds)
+			}
+		}
+		res[new.Time] = usages
+	}
+	return res
+}
+
+// Summarize summarizes the usage of every process over the samples, the processes seen in only one sample are skipped.
+func Summarize(samples []*Sample) []*Summary {
+	summaries := make(map[int32]*Summary)
+	for i := 1; i < len(samples); i++ {
+		old, new := samples[i-1], samples[i]
+		seconds := new.Time.Sub(old.Time).Seconds()
+		for pid, n := range new.Processes {
+			o, ok := old.Processes[pid]
+			if !ok || !Same(o, n) {
+				continue
+			}
+			summary, ok := summaries[pid]
+			if !ok {
+				summary = &Summary{Pid: pid, Name: n.Name, Category: Category(n.Name, n.Cmdline), User: n.User,
+					Exe: Executable(n.Name, n.Cmdline)}
+				summaries[pid] = summary
+			}
+			usage := Rate(o, n, seconds)
+			summary.Samples++
+			summary.CPUAvg += usage.CPUPercent
+			if usage.CPUPercent > summary.CPUMax {
+				summary.CPUMax = usage.CPUPercent
+			}
+			if n.RSS > summary.RSSMax {
+				summary.RSSMax = n.RSS
+			}
+			if n.Threads > summary.ThreadsMax {
+				summary.ThreadsMax = n.Threads
+			}
+			summary.ReadBytes += uint64(mathutil.CounterDelta(float64(o.ReadBytes), float64(n.ReadBytes)))
+			summary.WriteBytes += uint64(mathutil.CounterDelta(float64(o.WriteBytes), float64(n.WriteBytes)))
+			summary.CtxSwitches += int64(mathutil.CounterDelta(float64(o.CtxSwitches), float64(n.CtxSwitches)))
+		}
+	}
+	res := make([]*Summary, 0, len(summaries))
+	for _, summary := range summaries {
+		summary.CPUAvg /= float64(summary.Samples)
+		res = append(res, summary)
+	}
+	sortSummaries(res)
+	return res
+}
+
+// Top returns the YashanDB processes and the top n other processes ordered by CPU and memory.
+func Top(summaries []*Summary, n int) []*Summary {
+	var res []*Summary
+	others := 0
+	for _, summary := range summaries {
+		if summary.Category != CATEGORY_OTHER {
+			res = append(res, summary)
+			continue
+		}
+		if others < n {
+			res = append(res, summary)
+			others++
+		}
+	}
+	sortSummaries(res)
+	return res
+}
+
+func sortSummaries(summaries []*Summary) {
+	sort.SliceStable(summaries, func(i, j int) bool {
+		if summaries[i].CPUAvg != summaries[j].CPUAvg {
+			return summaries[i].CPUAvg > summaries[j].CPUAvg
+		}
+		if summaries[i].RSSMax != summaries[j].RSSMax {
+			return summaries[i].RSSMax > summaries[j].RSSMax
+		}
+		return summaries[i].Pid < summaries[j].Pid
+	})
+}
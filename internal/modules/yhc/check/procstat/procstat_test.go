@@ -0,0 +1,76 @@
+package procstat_test
+
+import (
+	"testing"
+	"time"
+
+	"yhc/internal/modules/yhc/check/procstat"
+)
+
+func TestCategory(t *testing.T) {
+	cases := map[string][2]string{
+		procstat.CATEGORY_YASDB:    {"yasdb", "/home/yashan/bin/yasdb open -D /data/yasdb/db-1-1"},
+		procstat.CATEGORY_YASOM:    {"yasom", "/home/yashan/bin/yasom -M -c /home/yashan/conf/yasom.toml"},
+		procstat.CATEGORY_YASAGENT: {"yasagent", "yasagent -c /home/yashan/conf/yasagent.toml"},
+		procstat.CATEGORY_OTHER:    {"java", "/usr/bin/java -jar yasdb.jar"},
+	}
+	for expected, c := range cases {
+		if category := procstat.Category(c[0], c[1]); category != expected {
+			t.Fatalf("expected %s of %s, got %s", expected, c[1], category)
+		}
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	start := time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC)
+	sample := func(seconds int, snapshots ...*procstat.Snapshot) *procstat.Sample {
+		s := &procstat.Sample{Time: start.Add(time.Duration(seconds) * time.Second), Processes: map[int32]*procstat.Snapshot{}}
+		for _, snapshot := range snapshots {
+			s.Processes[snapshot.Pid] = snapshot
+		}
+		return s
+	}
+	yasdb := func(cpu float64, rss, read uint64, ctx int64) *procstat.Snapshot {
+		return &procstat.Snapshot{Pid: 100, CreateTime: 1, Name: "yasdb", Cmdline: "/home/yashan/bin/yasdb open -D /data -p secret",
+			CPUSeconds: cpu, RSS: rss, ReadBytes: read, CtxSwitches: ctx, Threads: 50}
+	}
+	other := func(pid int32, createTime int64, cpu float64) *procstat.Snapshot {
+		return &procstat.Snapshot{Pid: pid, CreateTime: createTime, Name: "stress", CPUSeconds: cpu}
+	}
+	samples := []*procstat.Sample{
+		sample(0, yasdb(10, 100, 0, 0), other(200, 1, 0), other(300, 1, 0)),
+		sample(10, yasdb(15, 300, 1000, 100), other(200, 1, 20), other(300, 1, 1)),
+		// pid 300 is reused by a new process
+		sample(20, yasdb(25, 200, 3000, 50), other(200, 1, 30), other(300, 2, 0)),
+	}
+	summaries := procstat.Summarize(samples)
+	if len(summaries) != 3 {
+		t.Fatalf("unexpected summaries: %d", len(summaries))
+	}
+	first, db := summaries[0], summaries[1]
+	if first.Pid != 200 || first.CPUAvg != 150 || first.CPUMax != 200 {
+		t.Fatalf("unexpected top process: %+v", first)
+	}
+	if db.Category != procstat.CATEGORY_YASDB || db.CPUAvg != 75 || db.RSSMax != 300 ||
+		db.ReadBytes != 3000 || db.CtxSwitches != 100 || db.ThreadsMax != 50 || db.Samples != 2 {
+		t.Fatalf("unexpected yasdb summary: %+v", db)
+	}
+	// only the executable is kept, the arguments may contain the passwords
+	if db.Exe != "/home/yashan/bin/yasdb" || first.Exe != "stress" {
+		t.Fatalf("unexpected executables: %s, %s", db.Exe, first.Exe)
+	}
+	if summaries[2].Pid != 300 || summaries[2].Samples != 1 {
+		t.Fatalf("unexpected reused pid summary: %+v", summaries[2])
+	}
+	top := procstat.Top(summaries, 1)
+	if len(top) != 2 || top[0].Pid != 200 || top[1].Pid != 100 {
+		t.Fatalf("unexpected top: %+v", top)
+	}
+	usages := procstat.Usages(samples)
+	if usage := usages[samples[2].Time][100]; usage.CPUPercent != 100 || usage.ReadBytesPerSec != 200 || usage.RSS != 200 {
+		t.Fatalf("unexpected usage: %+v", usage)
+	}
+	if _, ok := usages[samples[2].Time][300]; ok {
+		t.Fatal("the usage of the reused pid should be skipped")
+	}
+}
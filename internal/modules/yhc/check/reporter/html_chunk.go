@@ -0,0 +1,199 @@
+package reporter
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"yhc/internal/modules/yhc/check/define"
+)
+
+const (
+	_MODULE_SCRIPT_TAG          = `<script type="module" crossorigin>`
+	_DEFERRED_MODULE_SCRIPT_TAG = `<script type="text/yhc-module" id="yhc-app">`
+	_BODY_END_TAG               = "</body>"
+
+	_CHUNK_TEMPLATE_REPLACEMENT = "$GLOBAL=window.__YHC_REPORT__"
+	_REPORT_SCRIPT_FORMATTER    = `<script type="application/json" id="yhc-report">%s</script>` + "\n"
+	_CHUNK_SCRIPT_FORMATTER     = `<script type="application/yhc-chunk" id="yhc-chunk-%d">%s</script>` + "\n"
+
+	_KEY_REPORT_DATA = "reportData"
+	_KEY_CHILDREN    = "children"
+	_KEY_ELEMENTS    = "elements"
+	_KEY_ELEMENT     = "element"
+	_KEY_ATTRIBUTES  = "attributes"
+	_KEY_DATA_SOURCE = "dataSource"
+	_KEY_CHUNKS      = "yhcChunks"
+)
+
+// _chunkLoaderScript inflates the gzip chunks back into the tables, then starts the report app.
+const _chunkLoaderScript = `<script>
+(function () {
+  function inflate(id) {
+    var text = document.getElementById("yhc-chunk-" + id).textContent;
+    var bin = atob(text.trim());
+    var bytes = new Uint8Array(bin.length);
+    for (var i = 0; i < bin.length; i++) {
+      bytes[i] = bin.charCodeAt(i);
+    }
+    var stream = new Blob([bytes]).stream().pipeThrough(new DecompressionStream("gzip"));
+    return new Response(stream).text().then(JSON.parse);
+  }
+  function walk(menus, tasks) {
+    (menus || []).forEach(function (menu) {
+      (menu.elements || []).forEach(function (element) {
+        var attrs = element.attributes;
+        if (!attrs || !attrs.yhcChunks) {
+          return;
+        }
+        tasks.push(Promise.all(attrs.yhcChunks.map(inflate)).then(function (parts) {
+          attrs.dataSource = [].concat.apply([], parts);
+          delete attrs.yhcChunks;
+        }));
+      });
+      walk(menu.children, tasks);
+    });
+  }
+  function start() {
+    var app = document.getElementById("yhc-app");
+    var script = document.createElement("script");
+    script.type = "module";
+    script.textContent = app.textContent;
+    document.body.appendChild(script);
+  }
+  document.addEventListener("DOMContentLoaded", function () {
+    if (typeof DecompressionStream === "undefined") {
+      document.body.textContent = "this report is split into compressed chunks which require a browser supporting DecompressionStream " +
+        "(Chrome 80+, Edge 80+, Firefox 113+, Safari 16.4+), please open it with a newer browser or regenerate it with html_chunk_rows = 0";
+      return;
+    }
+    var report = JSON.parse(document.getElementById("yhc-report").textContent);
+    var tasks = [];
+    walk(report.reportData, tasks);
+    Promise.all(tasks).then(function () {
+      window.__YHC_REPORT__ = report;
+      start();
+    }, function (err) {
+      document.body.textContent = "failed to load report data: " + err;
+    });
+  });
+})();
+</script>
+`
+
+// chunkedHtml puts the report data into the template, the data source of every table which has more than
+// chunkRows rows is split into gzip compressed chunks, the chunks are inflated by the browser when the report is opened.
+// Inflating requires DecompressionStream of the browser, a browser without it shows how to get a report without chunks.
+func chunkedHtml(template string, report *define.PandoraReport, chunkRows int) (string, error) {
+	if strings.Count(template, _MODULE_SCRIPT_TAG) != 1 || !strings.Contains(template, _TEMPLATE_KEY) {
+		return "", errors.New("unsupported html template to split report data")
+	}
+	bodyEnd := strings.LastIndex(template, _BODY_END_TAG)
+	if bodyEnd < 0 {
+		return "", errors.New("body end tag not found in html template")
+	}
+	data, err := json.Marshal(report)
+	if err != nil {
+		return "", err
+	}
+	var generic map[string]interface{}
+	if err := json.Unmarshal(data, &generic); err != nil {
+		return "", err
+	}
+	var chunks []string
+	if err := splitMenus(generic[_KEY_REPORT_DATA], chunkRows, &chunks); err != nil {
+		return "", err
+	}
+	reportData, err := json.Marshal(generic)
+	if err != nil {
+		return "", err
+	}
+	var scripts strings.Builder
+	scripts.WriteString(fmt.Sprintf(_REPORT_SCRIPT_FORMATTER, reportData))
+	for i, chunk := range chunks {
+		scripts.WriteString(fmt.Sprintf(_CHUNK_SCRIPT_FORMATTER, i, chunk))
+	}
+	scripts.WriteString(_chunkLoaderScript)
+
+	content := template[:bodyEnd] + scripts.String() + template[bodyEnd:]
+	content = strings.Replace(content, _MODULE_SCRIPT_TAG, _DEFERRED_MODULE_SCRIPT_TAG, 1)
+	content = strings.Replace(content, _TEMPLATE_KEY, _CHUNK_TEMPLATE_REPLACEMENT, 1)
+	return content, nil
+}
+
+func splitMenus(menus interface{}, chunkRows int, chunks *[]string) error {
+	menuList, ok := menus.([]interface{})
+	if !ok {
+		return nil
+	}
+	for _, m := range menuList {
+		menu, ok := m.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if err := splitElements(menu[_KEY_ELEMENTS], chunkRows, chunks); err != nil {
+			return err
+		}
+		if err := splitMenus(menu[_KEY_CHILDREN], chunkRows, chunks); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func splitElements(elements interface{}, chunkRows int, chunks *[]string) error {
+	elementList, ok := elements.([]interface{})
+	if !ok {
+		return nil
+	}
+	for _, e := range elementList {
+		element, ok := e.(map[string]interface{})
+		if !ok || element[_KEY_ELEMENT] != string(define.ET_TABLE) {
+			continue
+		}
+		attributes, ok := element[_KEY_ATTRIBUTES].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		rows, ok := attributes[_KEY_DATA_SOURCE].([]interface{})
+		if !ok || len(rows) <= chunkRows {
+			continue
+		}
+		var ids []int
+		for start := 0; start < len(rows); start += chunkRows {
+			end := start + chunkRows
+			if end > len(rows) {
+				end = len(rows)
+			}
+			chunk, err := compressChunk(rows[start:end])
+			if err != nil {
+				return err
+			}
+			ids = append(ids, len(*chunks))
+			*chunks = append(*chunks, chunk)
+		}
+		attributes[_KEY_DATA_SOURCE] = []interface{}{}
+		attributes[_KEY_CHUNKS] = ids
+	}
+	return nil
+}
+
+func compressChunk(rows []interface{}) (string, error) {
+	data, err := json.Marshal(rows)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	if _, err := writer.Write(data); err != nil {
+		return "", err
+	}
+	if err := writer.Close(); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(buf.Bytes()), nil
+}
@@ -0,0 +1,136 @@
+package reporter
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"yhc/internal/modules/yhc/check/define"
+)
+
+const _testTemplate = `<html><head><script type="module" crossorigin>const a=$GLOBAL={};</script></head><body></body></html>`
+
+func tableElement(rows int) map[string]interface{} {
+	dataSource := make([]interface{}, 0, rows)
+	for i := 0; i < rows; i++ {
+		dataSource = append(dataSource, map[string]interface{}{"ID": float64(i)})
+	}
+	return map[string]interface{}{
+		_KEY_ELEMENT:    string(define.ET_TABLE),
+		_KEY_ATTRIBUTES: map[string]interface{}{_KEY_DATA_SOURCE: dataSource},
+	}
+}
+
+func inflateChunk(t *testing.T, chunk string) []interface{} {
+	data, err := base64.StdEncoding.DecodeString(chunk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reader, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var rows []interface{}
+	if err := json.Unmarshal(raw, &rows); err != nil {
+		t.Fatal(err)
+	}
+	return rows
+}
+
+func TestSplitElements(t *testing.T) {
+	cases := []struct {
+		rows   int
+		chunks []int
+	}{
+		{rows: 0},
+		{rows: 3},
+		// a table of exactly chunkRows rows is kept inline
+		{rows: 4},
+		{rows: 5, chunks: []int{4, 1}},
+		{rows: 8, chunks: []int{4, 4}},
+		{rows: 9, chunks: []int{4, 4, 1}},
+	}
+	for _, c := range cases {
+		element := tableElement(c.rows)
+		other := map[string]interface{}{_KEY_ELEMENT: "text", _KEY_ATTRIBUTES: map[string]interface{}{_KEY_DATA_SOURCE: []interface{}{1, 2, 3, 4, 5}}}
+		// the chunks of the elements before are kept, the ids go on from them
+		chunks := []string{"previous"}
+		if err := splitElements([]interface{}{other, element}, 4, &chunks); err != nil {
+			t.Fatal(err)
+		}
+		attributes := element[_KEY_ATTRIBUTES].(map[string]interface{})
+		if len(c.chunks) == 0 {
+			if len(chunks) != 1 || len(attributes[_KEY_DATA_SOURCE].([]interface{})) != c.rows || attributes[_KEY_CHUNKS] != nil {
+				t.Fatalf("rows %d: the table should not be split: %v", c.rows, attributes)
+			}
+			continue
+		}
+		ids, _ := attributes[_KEY_CHUNKS].([]int)
+		if len(ids) != len(c.chunks) || len(chunks) != len(c.chunks)+1 || len(attributes[_KEY_DATA_SOURCE].([]interface{})) != 0 {
+			t.Fatalf("rows %d: unexpected chunks %v of %d", c.rows, ids, len(chunks))
+		}
+		next := 0
+		for i, id := range ids {
+			if id != i+1 {
+				t.Fatalf("rows %d: unexpected chunk id %d at %d", c.rows, id, i)
+			}
+			rows := inflateChunk(t, chunks[id])
+			if len(rows) != c.chunks[i] {
+				t.Fatalf("rows %d: chunk %d has %d rows, expected %d", c.rows, i, len(rows), c.chunks[i])
+			}
+			for _, row := range rows {
+				if row.(map[string]interface{})["ID"] != float64(next) {
+					t.Fatalf("rows %d: unexpected row %v, expected ID %d", c.rows, row, next)
+				}
+				next++
+			}
+		}
+		if len(other[_KEY_ATTRIBUTES].(map[string]interface{})[_KEY_DATA_SOURCE].([]interface{})) != 5 {
+			t.Fatal("the element which is not a table should not be split")
+		}
+	}
+}
+
+func TestChunkedHtml(t *testing.T) {
+	rows := make([]map[string]interface{}, 0, 5)
+	for i := 0; i < 5; i++ {
+		rows = append(rows, map[string]interface{}{"ID": i})
+	}
+	report := &define.PandoraReport{
+		ReportData: []*define.PandoraMenu{{
+			Children: []*define.PandoraMenu{{
+				Elements: []*define.PandoraElement{{
+					ElementType: define.ET_TABLE,
+					Attributes:  define.TableAttributes{DataSource: rows},
+				}},
+			}},
+		}},
+	}
+	content, err := chunkedHtml(_testTemplate, report, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		if !strings.Contains(content, fmt.Sprintf(`id="yhc-chunk-%d"`, i)) {
+			t.Fatalf("chunk %d not found in %s", i, content)
+		}
+	}
+	if strings.Contains(content, `id="yhc-chunk-3"`) || strings.Contains(content, _TEMPLATE_KEY) || strings.Contains(content, _MODULE_SCRIPT_TAG) {
+		t.Fatalf("unexpected content %s", content)
+	}
+	if !strings.Contains(content, _DEFERRED_MODULE_SCRIPT_TAG) || !strings.Contains(content, "DecompressionStream") {
+		t.Fatalf("the app should be started by the loader: %s", content)
+	}
+	if _, err := chunkedHtml("<html><body></body></html>", report, 2); err == nil {
+		t.Fatal("the template without the module script should be unsupported")
+	}
+}
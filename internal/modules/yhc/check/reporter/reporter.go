@@ -3,7 +3,6 @@ package reporter
 import (
 	"bufio"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -12,15 +11,23 @@ import (
 	"time"
 
 	"yhc/defs/bashdef"
+	"yhc/defs/compiledef"
 	"yhc/defs/confdef"
+	"yhc/defs/runtimedef"
 	"yhc/defs/timedef"
 	"yhc/i18n"
 	yhccommons "yhc/internal/modules/yhc/check/commons"
 	"yhc/internal/modules/yhc/check/define"
+	"yhc/internal/modules/yhc/check/exporter"
+	"yhc/internal/modules/yhc/redactor"
+	"yhc/internal/modules/yhc/resultpkg"
 	"yhc/log"
+	"yhc/static"
 	"yhc/utils/execerutil"
 	"yhc/utils/fileutil"
+	"yhc/utils/tarutil"
 
+	"git.yasdb.com/go/yaslog"
 	"git.yasdb.com/go/yasutil/fs"
 )
 
@@ -31,6 +38,9 @@ const (
 	_FAILED_ITEM_JSON_NAME_FORMATTER = "failed-%s.json"
 	_REPORT_NAME_FORMATTER           = "report-%s.html"
 	_WORD_REPORT_NAME_FORMATTER      = "report-%s.docx"
+	_XLSX_REPORT_NAME_FORMATTER      = "report-%s.xlsx"
+
+	_DIR_CSV = "csv"
 
 	_DIR_HTML_TEMPLATE  = "html-template"
 	_FILE_HTML_TEMPLATE = "template.html"
@@ -43,13 +53,21 @@ const (
 )
 
 type YHCReport struct {
-	YHCHome    string                                  `json:"YHCHome"`
-	BeginTime  time.Time                               `json:"beginTime"`
-	EndTime    time.Time                               `json:"endTime"`
-	CheckBase  *define.CheckerBase                     `json:"checkBase"`
-	Items      map[define.MetricName][]*define.YHCItem `json:"items"`
-	Report     *define.PandoraReport
-	FailedItem map[define.MetricName][]*define.YHCItem
+	YHCHome        string                                  `json:"YHCHome"`
+	BeginTime      time.Time                               `json:"beginTime"`
+	EndTime        time.Time                               `json:"endTime"`
+	CheckBase      *define.CheckerBase                     `json:"checkBase"`
+	Items          map[define.MetricName][]*define.YHCItem `json:"items"`
+	Report         *define.PandoraReport
+	FailedItem     map[define.MetricName][]*define.YHCItem
+	Metrics        []*confdef.YHCMetric
+	EvaluateResult *define.EvaluateResult
+	// Redactor redacts the results before any file is generated, nil means no redaction
+	Redactor *redactor.Redactor
+	// BaseManifest is used when the result is regenerated from an existing package
+	BaseManifest *resultpkg.Manifest
+	// NameSuffix is appended to the package name, such as '-redacted'
+	NameSuffix string
 }
 
 func NewYHCReport(yhcHome string, checkBase *define.CheckerBase) *YHCReport {
@@ -62,6 +80,10 @@ func NewYHCReport(yhcHome string, checkBase *define.CheckerBase) *YHCReport {
 
 func (r *YHCReport) GenResult() (string, error) {
 	log := log.Module.M("gen-result")
+	if err := r.redact(); err != nil {
+		log.Errorf("redact result err: %s", err.Error())
+		return "", err
+	}
 	if err := r.mkdir(); err != nil {
 		log.Errorf("mkdir err: %s", err.Error())
 		return "", err
@@ -82,6 +104,10 @@ func (r *YHCReport) GenResult() (string, error) {
 		log.Errorf("gen report failed: %s", err)
 		return "", err
 	}
+	if err := r.genManifest(); err != nil {
+		log.Errorf("gen manifest failed: %s", err)
+		return "", err
+	}
 	if err := r.tarResult(); err != nil {
 		log.Errorf("tar result failed: %s", err)
 		return "", err
@@ -92,6 +118,19 @@ func (r *YHCReport) GenResult() (string, error) {
 	return r.genPackageTarPath(), nil
 }
 
+func (r *YHCReport) redact() error {
+	if r.Redactor == nil {
+		return nil
+	}
+	items, report, failedItem, err := r.Redactor.Redact(r.Items, r.Report, r.FailedItem)
+	if err != nil {
+		return err
+	}
+	r.Items, r.Report, r.FailedItem = items, report, failedItem
+	r.CheckBase = r.Redactor.RedactCheckerBase(r.CheckBase)
+	return nil
+}
+
 func (r *YHCReport) genReport() error {
 	// HTML报告生成失败不影响打包，只记录错误
 	if err := r.genHtmlReport(); err != nil {
@@ -107,6 +146,12 @@ func (r *YHCReport) genReport() error {
 		fmt.Println(bashdef.WithColor(i18n.T("report.gen_word_failed"), bashdef.COLOR_RED))
 		fmt.Println(bashdef.WithColor(i18n.T("report.gen_continue"), bashdef.COLOR_YELLOW))
 	}
+	// 表格导出失败不影响打包，只记录错误
+	if err := r.genExport(); err != nil {
+		log.Module.M("gen-export").Error("Failed to export tables: ", err)
+		fmt.Println(bashdef.WithColor(i18n.T("report.gen_export_failed"), bashdef.COLOR_RED))
+		fmt.Println(bashdef.WithColor(i18n.T("report.gen_continue"), bashdef.COLOR_YELLOW))
+	}
 	return nil
 }
 
@@ -116,17 +161,16 @@ func (r *YHCReport) genHtmlReport() error {
 		log.Debug("skip to gen html report")
 		return nil
 	}
-	templateFile := r.getHtmlTemplateFile()
-	f, err := os.Open(templateFile)
+	content, err := r.readHtmlTemplate()
 	if err != nil {
 		return err
 	}
-	defer f.Close()
-
-	reader := bufio.NewReader(f)
-	content, err := io.ReadAll(reader)
-	if err != nil {
-		return err
+	if chunkRows := confdef.GetYHCConf().HtmlChunkRows; chunkRows > 0 {
+		newContentStr, err := chunkedHtml(string(content), r.Report, chunkRows)
+		if err == nil {
+			return fileutil.WriteFile(r.genReportFilePath(), []byte(newContentStr))
+		}
+		log.Warnf("split report data failed, report data will be inlined: %s", err)
 	}
 	jsonData, err := json.Marshal(r.Report)
 	if err != nil {
@@ -138,6 +182,23 @@ func (r *YHCReport) genHtmlReport() error {
 	return fileutil.WriteFile(r.genReportFilePath(), []byte(newContentStr))
 }
 
+// readHtmlTemplate prefers the template in yhc home, so that the template can be replaced without rebuilding,
+// otherwise the template embedded in the binary is used.
+func (r *YHCReport) readHtmlTemplate() ([]byte, error) {
+	templateFile := r.getHtmlTemplateFile()
+	if !fs.IsFileExist(templateFile) {
+		return static.HtmlTemplate, nil
+	}
+	f, err := os.Open(templateFile)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	reader := bufio.NewReader(f)
+	return io.ReadAll(reader)
+}
+
 func (r *YHCReport) genWordReport() error {
 	log := log.Module.M("gen-word")
 	if confdef.GetYHCConf().SkipGenWordReport {
@@ -162,6 +223,39 @@ func (r *YHCReport) genWordReport() error {
 	return nil
 }
 
+func (r *YHCReport) genExport() error {
+	log := log.Module.M("gen-export")
+	var formats []string
+	for _, format := range confdef.GetYHCConf().ExportFormats {
+		if !exporter.IsValidFormat(format) {
+			log.Warnf("unsupported export format %s, skip", format)
+			continue
+		}
+		formats = append(formats, format)
+	}
+	if len(formats) == 0 {
+		log.Debug("no export format, skip to export tables")
+		return nil
+	}
+	e := exporter.NewExporter(log, r.CheckBase, r.BeginTime, r.EndTime, r.Metrics, r.Items, r.EvaluateResult)
+	for _, format := range formats {
+		switch format {
+		case exporter.FORMAT_XLSX:
+			if err := e.ExportXlsx(r.getXlsxReportFile()); err != nil {
+				return err
+			}
+		case exporter.FORMAT_CSV:
+			if err := fs.Mkdir(r.genCsvPath()); err != nil {
+				return err
+			}
+			if err := e.ExportCsv(r.genCsvPath()); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
 func (r *YHCReport) genDataJson() error {
 	dataJson := path.Join(r.genDataPath(), fmt.Sprintf(_DATA_NAME_FORMATTER, r.BeginTime.Format(timedef.TIME_FORMAT_IN_FILE)))
 	bytes, err := json.MarshalIndent(r.Items, "", "    ")
@@ -210,6 +304,10 @@ func (r *YHCReport) getWordReportFile() string {
 	return path.Join(r.genPackageDir(), fmt.Sprintf(_WORD_REPORT_NAME_FORMATTER, r.BeginTime.Format(timedef.TIME_FORMAT_IN_FILE)))
 }
 
+func (r *YHCReport) getXlsxReportFile() string {
+	return path.Join(r.genPackageDir(), fmt.Sprintf(_XLSX_REPORT_NAME_FORMATTER, r.BeginTime.Format(timedef.TIME_FORMAT_IN_FILE)))
+}
+
 func (r *YHCReport) genReportFilePath() string {
 	return path.Join(r.genPackageDir(), fmt.Sprintf(_REPORT_NAME_FORMATTER, r.BeginTime.Format(timedef.TIME_FORMAT_IN_FILE)))
 }
@@ -219,7 +317,7 @@ func (r *YHCReport) genPackageTarPath() string {
 }
 
 func (r *YHCReport) genPackageName() string {
-	return fmt.Sprintf(_PACKAGE_NAME_FORMATTER, r.BeginTime.Format(timedef.TIME_FORMAT_IN_FILE))
+	return fmt.Sprintf(_PACKAGE_NAME_FORMATTER, r.BeginTime.Format(timedef.TIME_FORMAT_IN_FILE)) + r.NameSuffix
 }
 
 func (r *YHCReport) genPackageDir() string {
@@ -234,6 +332,10 @@ func (r *YHCReport) genDataPath() string {
 	return path.Join(r.genPackageDir(), "data")
 }
 
+func (r *YHCReport) genCsvPath() string {
+	return path.Join(r.genPackageDir(), _DIR_CSV)
+}
+
 func (r *YHCReport) getHtmlTemplateFile() string {
 	return path.Join(r.YHCHome, _DIR_HTML_TEMPLATE, _FILE_HTML_TEMPLATE)
 }
@@ -257,14 +359,104 @@ func (r *YHCReport) mkdir() error {
 	return nil
 }
 
+func (r *YHCReport) genManifest() error {
+	log := log.Module.M("gen-manifest")
+	manifest := r.newManifest(log)
+	if r.Redactor != nil {
+		manifest.Redacted = true
+		manifest.Host.Hostname = r.Redactor.RedactHostname(manifest.Host.Hostname)
+		manifest.Host.Executer = r.Redactor.RedactText(manifest.Host.Executer)
+		if manifest.Database != nil {
+			manifest.Database.YasdbHome = r.Redactor.RedactText(manifest.Database.YasdbHome)
+			manifest.Database.YasdbData = r.Redactor.RedactText(manifest.Database.YasdbData)
+			manifest.Database.ListenAddr = r.Redactor.RedactText(manifest.Database.ListenAddr)
+		}
+	}
+	data, err := resultpkg.GenManifest(r.genPackageDir(), manifest)
+	if err != nil {
+		return err
+	}
+	keyPath := confdef.GetYHCConf().GetSignKeyPath()
+	if len(keyPath) == 0 {
+		log.Debug("no sign key, skip to sign the manifest")
+		return nil
+	}
+	key, err := resultpkg.LoadPrivateKey(keyPath)
+	if err != nil {
+		return err
+	}
+	return resultpkg.SignManifest(r.genPackageDir(), data, key)
+}
+
+// newManifest returns a copy of the base manifest if any, otherwise a manifest of the current check.
+func (r *YHCReport) newManifest(log yaslog.YasLog) *resultpkg.Manifest {
+	if r.BaseManifest != nil {
+		manifest := *r.BaseManifest
+		if manifest.Database != nil {
+			database := *manifest.Database
+			manifest.Database = &database
+		}
+		manifest.CreateTime = time.Now()
+		return &manifest
+	}
+	manifest := &resultpkg.Manifest{
+		CreateTime: time.Now(),
+		App: resultpkg.AppInfo{
+			Version:     compiledef.GetAPPVersion(),
+			GitCommitID: compiledef.GetGitCommitID(),
+			GitDescribe: compiledef.GetGitDescribe(),
+			GoVersion:   compiledef.GetGoVersion(),
+		},
+		Host: resultpkg.HostInfo{
+			OS:       runtimedef.GetOSRelease().PrettyName,
+			Executer: runtimedef.GetExecuter().Username,
+		},
+		CheckWindow: resultpkg.CheckWindow{
+			Start:      r.CheckBase.Start,
+			End:        r.CheckBase.End,
+			CheckBegin: r.BeginTime,
+			CheckEnd:   r.EndTime,
+		},
+		Profile: r.CheckBase.Profile,
+		Summary: r.genSummary(),
+	}
+	hostname, err := os.Hostname()
+	if err != nil {
+		log.Warnf("get hostname failed: %s", err)
+	}
+	manifest.Host.Hostname = hostname
+	if r.CheckBase.DBInfo != nil {
+		manifest.Database = &resultpkg.DatabaseInfo{
+			DatabaseName: r.CheckBase.DBInfo.DatabaseName,
+			YasdbHome:    r.CheckBase.DBInfo.YasdbHome,
+			YasdbData:    r.CheckBase.DBInfo.YasdbData,
+			ListenAddr:   r.CheckBase.DBInfo.ListenAddr,
+		}
+	}
+	return manifest
+}
+
+func (r *YHCReport) genSummary() *resultpkg.Summary {
+	if r.EvaluateResult == nil {
+		return nil
+	}
+	summary := &resultpkg.Summary{
+		Score:        r.EvaluateResult.Score,
+		HealthStatus: r.EvaluateResult.HealthStatus,
+	}
+	if alertSummary := r.EvaluateResult.AlertSummary; alertSummary != nil {
+		summary.CriticalCount = alertSummary.CriticalCount
+		summary.WarningCount = alertSummary.WarningCount
+		summary.InfoCount = alertSummary.InfoCount
+	}
+	return summary
+}
+
 func (r *YHCReport) tarResult() error {
-	command := fmt.Sprintf("cd %s;%s czvf %s %s;rm -rf %s", r.CheckBase.Output, bashdef.CMD_TAR, r.genPackageTarName(), r.genPackageName(), r.genPackageName())
-	executer := execerutil.NewExecer(log.Logger)
-	ret, _, stderr := executer.Exec(bashdef.CMD_BASH, "-c", command)
-	if ret != 0 {
-		return errors.New(stderr)
+	if err := tarutil.TarGz(r.genPackageDir(), r.genPackageTarPath()); err != nil {
+		return err
 	}
-	return nil
+	return os.RemoveAll(r.genPackageDir())
 }
 
 func (r *YHCReport) chownResult() error {
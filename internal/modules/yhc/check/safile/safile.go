@@ -0,0 +1,547 @@
+// The safile package decodes the binary daily data files saDD of sysstat without the sar command,
+// so that the history workload is available on the hosts without sysstat and for the files copied from other hosts.
+//
+// The files of format 0x2175 (sysstat 11.7.1 and later) in either byte order are supported. The fields of the structures
+// are located by the numbers of long long, long and int fields recorded in the file as sysstat does, so that the structures
+// extended by newer versions are still decoded.
+package safile
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	SYSSTAT_MAGIC         = 0xd596
+	SYSSTAT_MAGIC_SWAPPED = 0x96d5
+	FORMAT_MAGIC          = 0x2175
+
+	// the activities decoded, the data of the others are skipped
+	A_CPU      = 1
+	A_PCSW     = 2
+	A_SWAP     = 4
+	A_PAGE     = 5
+	A_MEMORY   = 7
+	A_QUEUE    = 9
+	A_DISK     = 11
+	A_NET_DEV  = 12
+	A_NET_EDEV = 13
+
+	R_STATS      = 1
+	R_RESTART    = 2
+	R_LAST_STATS = 3
+	R_COMMENT    = 4
+
+	// C_DUPLEX_FULL is the duplex of the full duplex network interfaces
+	C_DUPLEX_FULL = 2
+
+	_file_magic_size = 76
+	_extra_desc_size = 28
+	_max_comment_len = 64
+	_utsname_len     = 65
+	_max_iface_len   = 16
+	_nr_size         = 4
+	_ull_width       = 8
+	_ul_width        = 8
+	_u_width         = 4
+	_max_items       = 1 << 16
+	// the limits of the sizes read from the file, so that a corrupt file is rejected instead of allocating too much memory
+	_max_struct_size = 1 << 16
+	_max_stats_size  = 1 << 26
+)
+
+var (
+	ErrNotSysstatFile    = errors.New("not a sysstat data file")
+	ErrUnsupportedFormat = errors.New("unsupported format of sysstat data file")
+	// ErrUnsupportedVersion is returned for the files written by sysstat before 11.7.1, which are only readable by sar
+	ErrUnsupportedVersion = errors.New("unsupported version of sysstat data file")
+)
+
+// Header is the file header of the data file.
+type Header struct {
+	Version  string
+	Time     time.Time
+	Hz       uint64
+	CPUs     int
+	Sysname  string
+	Nodename string
+	Release  string
+	Machine  string
+}
+
+// Activity is the description of an activity collected in the data file.
+type Activity struct {
+	ID    uint32
+	NrIni int32
+	Nr2   int32
+	HasNr bool
+	Size  uint32
+	Types Types
+}
+
+// Types is the numbers of long long, long and int fields of a structure, which are laid out in that order.
+type Types [3]uint32
+
+func (t Types) size() int64 {
+	return int64(t[0])*_ull_width + int64(t[1])*_ul_width + int64(t[2])*_u_width
+}
+
+// CPU is the cumulative CPU time in jiffies, the guest time is included in the user time.
+type CPU struct {
+	User, Nice, System, Idle, IOWait, Steal, HardIRQ, SoftIRQ, Guest, GuestNice uint64
+}
+
+// Task is the cumulative numbers of context switches and tasks created.
+type Task struct {
+	ContextSwitches, Processes uint64
+}
+
+// Swap is the cumulative numbers of pages swapped in and out.
+type Swap struct {
+	PswpIn, PswpOut uint64
+}
+
+// Paging is the cumulative paging counters, pgpgin and pgpgout are in kilobytes and the others are in pages.
+type Paging struct {
+	PgpgIn, PgpgOut, Faults, MajorFaults, PgFree, PgScanKswapd, PgScanDirect, PgSteal uint64
+}
+
+// Memory is the memory usage in kilobytes.
+type Memory struct {
+	Free, Buffers, Cached, Total, SwapFree, SwapTotal, SwapCached, Committed uint64
+	Active, Inactive, Dirty, AnonPages, Slab, KernelStack, PageTables        uint64
+	VmallocUsed, Available                                                   uint64
+}
+
+// Queue is the run queue and the load averages, the load averages are multiplied by 100.
+type Queue struct {
+	Running, Blocked, Threads uint64
+	Load1, Load5, Load15      uint32
+}
+
+// Disk is the cumulative counters of a block device, the ticks are in milliseconds.
+type Disk struct {
+	Major, Minor                                                uint32
+	IOs, ReadSectors, WriteSectors, DiscardSectors              uint64
+	ReadTicks, WriteTicks, DiscardTicks, TotalTicks, QueueTicks uint32
+}
+
+// NetDev is the cumulative counters of a network interface, the speed is in Mb/s.
+type NetDev struct {
+	Iface                                  string
+	RxPackets, TxPackets, RxBytes, TxBytes uint64
+	RxCompressed, TxCompressed, Multicast  uint64
+	Speed                                  uint32
+	Duplex                                 uint8
+}
+
+// NetEdev is the cumulative error counters of a network interface.
+type NetEdev struct {
+	Iface                                     string
+	Collisions, RxErrors, TxErrors, RxDropped uint64
+	TxDropped, RxFifoErrors, TxFifoErrors     uint64
+	RxFrameErrors, TxCarrierErrors            uint64
+}
+
+// Record is a record of the data file, the statistics are only set for the records of type R_STATS.
+type Record struct {
+	Type uint8
+	Time time.Time
+	// Uptime is the uptime of the host in centiseconds
+	Uptime  uint64
+	Comment string
+	// CPUs are all CPUs at first, then CPU 0, 1 and so on, the offline CPUs are all zeros
+	CPUs     []CPU
+	Task     *Task
+	Swap     *Swap
+	Paging   *Paging
+	Memory   *Memory
+	Queue    *Queue
+	Disks    []Disk
+	NetDevs  []NetDev
+	NetEdevs []NetEdev
+}
+
+// File is a decoded data file.
+type File struct {
+	Header     Header
+	Activities []Activity
+	Records    []Record
+}
+
+// layout reads the fields of a structure by the types.
+type layout struct {
+	order binary.ByteOrder
+	types Types
+	buf   []byte
+}
+
+func (l layout) ull(i int) uint64 {
+	if i >= int(l.types[0]) {
+		return 0
+	}
+	return l.order.Uint64(l.buf[i*_ull_width:])
+}
+
+func (l layout) ul(i int) uint64 {
+	if i >= int(l.types[1]) {
+		return 0
+	}
+	return l.order.Uint64(l.buf[int(l.types[0])*_ull_width+i*_ul_width:])
+}
+
+func (l layout) u(i int) uint32 {
+	if i >= int(l.types[2]) {
+		return 0
+	}
+	return l.order.Uint32(l.buf[int(l.types[0])*_ull_width+int(l.types[1])*_ul_width+i*_u_width:])
+}
+
+// num returns the i-th field of the long long and long fields, which are changed from long to long long by sysstat 12 for some activities.
+func (l layout) num(i int) uint64 {
+	if i < int(l.types[0]) {
+		return l.ull(i)
+	}
+	return l.ul(i - int(l.types[0]))
+}
+
+// chars returns the bytes after the numeric fields.
+func (l layout) chars() []byte {
+	return l.buf[l.types.size():]
+}
+
+// fileLayout is how the activities and records are laid out, which is told by the file header.
+type fileLayout struct {
+	actNr    int
+	actSize  int
+	actTypes Types
+	recSize  int
+	recTypes Types
+	extra    bool
+}
+
+type decoder struct {
+	r      *bufio.Reader
+	order  binary.ByteOrder
+	layout fileLayout
+}
+
+// Open decodes the data file of the path.
+func Open(path string) (*File, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Decode(f)
+}
+
+// Decode decodes the data file. A truncated last record is dropped with no error, since the file of today is being written by sadc.
+func Decode(r io.Reader) (*File, error) {
+	d := &decoder{r: bufio.NewReader(r)}
+	file := &File{}
+	headerSize, hdrTypes, err := d.readMagic(&file.Header)
+	if err != nil {
+		return nil, err
+	}
+	if err := d.readHeader(&file.Header, headerSize, hdrTypes); err != nil {
+		return nil, err
+	}
+	if file.Activities, err = d.readActivities(); err != nil {
+		return nil, err
+	}
+	if d.layout.extra {
+		if err := d.skipExtras(); err != nil {
+			return nil, err
+		}
+	}
+	for {
+		record, err := d.readRecord(file.Activities)
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return file, nil
+		}
+		if err != nil {
+			return file, err
+		}
+		file.Records = append(file.Records, *record)
+	}
+}
+
+func (d *decoder) read(size int) ([]byte, error) {
+	buf := make([]byte, size)
+	if _, err := io.ReadFull(d.r, buf); err != nil {
+		return nil, err
+	}
+	return buf, nil
+}
+
+// readMagic reads the file magic, of which the fields are sysstat_magic, format_magic, the version of sysstat,
+// header_size, upgraded and hdr_types_nr.
+func (d *decoder) readMagic(header *Header) (int, Types, error) {
+	buf, err := d.read(_file_magic_size)
+	if err != nil {
+		return 0, Types{}, ErrNotSysstatFile
+	}
+	switch binary.LittleEndian.Uint16(buf) {
+	case SYSSTAT_MAGIC:
+		d.order = binary.LittleEndian
+	case SYSSTAT_MAGIC_SWAPPED:
+		d.order = binary.BigEndian
+	default:
+		return 0, Types{}, ErrNotSysstatFile
+	}
+	header.Version = fmt.Sprintf("%d.%d.%d", buf[4], buf[5], buf[6])
+	if format := d.order.Uint16(buf[2:]); format != FORMAT_MAGIC {
+		return 0, Types{}, fmt.Errorf("%w: format magic 0x%x of sysstat %s, only the format 0x%x of sysstat 11.7.1 and later is decoded",
+			ErrUnsupportedVersion, format, header.Version, FORMAT_MAGIC)
+	}
+	hdrTypes := Types{d.order.Uint32(buf[16:]), d.order.Uint32(buf[20:]), d.order.Uint32(buf[24:])}
+	headerSize := d.order.Uint32(buf[8:])
+	if headerSize > _max_struct_size {
+		return 0, Types{}, fmt.Errorf("%w: file header of %d bytes", ErrUnsupportedFormat, headerSize)
+	}
+	return int(headerSize), hdrTypes, nil
+}
+
+// readHeader reads the file header, of which the fields are sa_ust_time and sa_hz, then sa_cpu_nr, sa_act_nr, sa_year,
+// act_types_nr, rec_types_nr, act_size, rec_size and extra_next, then sa_day, sa_month, sa_sizeof_long and the utsname.
+func (d *decoder) readHeader(header *Header, size int, hdrTypes Types) error {
+	if hdrTypes[0]+hdrTypes[1] < 2 || hdrTypes[2] < 11 || int64(size) < hdrTypes.size()+3+4*_utsname_len {
+		return fmt.Errorf("%w: file header types %v", ErrUnsupportedFormat, hdrTypes)
+	}
+	buf, err := d.read(size)
+	if err != nil {
+		return err
+	}
+	l := layout{order: d.order, types: hdrTypes, buf: buf}
+	header.Time = time.Unix(int64(l.ull(0)), 0)
+	header.Hz = l.ull(1)
+	if hdrTypes[0] < 2 {
+		// sa_hz is unsigned long before sysstat 12
+		header.Hz = l.ul(0)
+	}
+	header.CPUs = int(l.u(0))
+	names := l.chars()[3:]
+	for _, name := range []*string{&header.Sysname, &header.Nodename, &header.Release, &header.Machine} {
+		*name = cString(names[:_utsname_len])
+		names = names[_utsname_len:]
+	}
+	d.layout = fileLayout{
+		actNr:    int(l.u(1)),
+		actTypes: Types{l.u(3), l.u(4), l.u(5)},
+		recTypes: Types{l.u(6), l.u(7), l.u(8)},
+		actSize:  int(l.u(9)),
+		recSize:  int(l.u(10)),
+		extra:    l.u(11) != 0,
+	}
+	// the activities have id, magic, nr_ini, nr2, has_nr, size and types_nr
+	if d.layout.actTypes[2] < 9 || d.layout.actSize > _max_struct_size || int64(d.layout.actSize) < d.layout.actTypes.size() ||
+		d.layout.actNr > _max_items {
+		return fmt.Errorf("%w: activity types %v of %d bytes", ErrUnsupportedFormat, d.layout.actTypes, d.layout.actSize)
+	}
+	// the records have uptime_cs and ust_time, then extra_next, then record_type, hour, minute and second
+	if d.layout.recTypes[0] < 2 || d.layout.recSize > _max_struct_size || int64(d.layout.recSize) < d.layout.recTypes.size()+4 {
+		return fmt.Errorf("%w: record types %v", ErrUnsupportedFormat, d.layout.recTypes)
+	}
+	return nil
+}
+
+func (d *decoder) readActivities() ([]Activity, error) {
+	var activities []Activity
+	for i := 0; i < d.layout.actNr; i++ {
+		buf, err := d.read(d.layout.actSize)
+		if err != nil {
+			return nil, err
+		}
+		l := layout{order: d.order, types: d.layout.actTypes, buf: buf}
+		act := Activity{
+			ID:    l.u(0),
+			NrIni: int32(l.u(2)),
+			Nr2:   int32(l.u(3)),
+			HasNr: l.u(4) != 0,
+			Size:  l.u(5),
+			Types: Types{l.u(6), l.u(7), l.u(8)},
+		}
+		if act.Nr2 < 1 || act.Nr2 > _max_items || act.NrIni < 0 || act.NrIni > _max_items || act.Size > _max_struct_size ||
+			int64(act.Size) < act.Types.size() {
+			return nil, fmt.Errorf("%w: activity %d", ErrUnsupportedFormat, act.ID)
+		}
+		activities = append(activities, act)
+	}
+	return activities, nil
+}
+
+// skipExtras skips the extra structures, every of which is described by extra_id, extra_nr, extra_size, extra_next and extra_types_nr.
+func (d *decoder) skipExtras() error {
+	for {
+		buf, err := d.read(_extra_desc_size)
+		if err != nil {
+			return err
+		}
+		nr, size, next := d.order.Uint32(buf[4:]), d.order.Uint32(buf[8:]), d.order.Uint32(buf[12:])
+		if nr > _max_items || size > _max_struct_size || int64(nr)*int64(size) > _max_stats_size {
+			return fmt.Errorf("%w: extra structure of %d * %d bytes", ErrUnsupportedFormat, nr, size)
+		}
+		if _, err := d.r.Discard(int(nr) * int(size)); err != nil {
+			return err
+		}
+		if next == 0 {
+			return nil
+		}
+	}
+}
+
+func (d *decoder) readRecord(activities []Activity) (*Record, error) {
+	buf, err := d.read(d.layout.recSize)
+	if err != nil {
+		return nil, err
+	}
+	l := layout{order: d.order, types: d.layout.recTypes, buf: buf}
+	record := &Record{Type: l.chars()[0], Uptime: l.ull(0), Time: time.Unix(int64(l.ull(1)), 0)}
+	if l.u(0) != 0 {
+		if err := d.skipExtras(); err != nil {
+			return nil, err
+		}
+	}
+	switch record.Type {
+	case R_RESTART:
+		// the new number of CPUs follows, which is the number of items of A_CPU if it is not counted in every record
+		if buf, err = d.read(_nr_size); err == nil {
+			restartCPUs(activities, int32(d.order.Uint32(buf)))
+		}
+	case R_COMMENT:
+		if buf, err = d.read(_max_comment_len); err == nil {
+			record.Comment = cString(buf)
+		}
+	case R_STATS, R_LAST_STATS:
+		// the last stats record before a restart of old versions is a stats record too
+		record.Type = R_STATS
+		err = d.readStats(record, activities)
+	default:
+		err = fmt.Errorf("%w: record type %d", ErrUnsupportedFormat, record.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+// readStats reads the statistics of the activities in the order of the file header,
+// the number of items precedes the statistics of the activities of which the number varies.
+func (d *decoder) readStats(record *Record, activities []Activity) error {
+	for _, act := range activities {
+		nr := act.NrIni
+		if act.HasNr {
+			buf, err := d.read(_nr_size)
+			if err != nil {
+				return err
+			}
+			nr = int32(d.order.Uint32(buf))
+			if nr < 0 || nr > _max_items {
+				return fmt.Errorf("%w: %d items of activity %d", ErrUnsupportedFormat, nr, act.ID)
+			}
+		}
+		count := int64(nr) * int64(act.Nr2)
+		if count*int64(act.Size) > _max_stats_size {
+			return fmt.Errorf("%w: %d items of %d bytes of activity %d", ErrUnsupportedFormat, count, act.Size, act.ID)
+		}
+		buf, err := d.read(int(count) * int(act.Size))
+		if err != nil {
+			return err
+		}
+		items := make([]layout, 0, count)
+		for i := 0; i < int(count); i++ {
+			items = append(items, layout{order: d.order, types: act.Types, buf: buf[i*int(act.Size) : (i+1)*int(act.Size)]})
+		}
+		d.decodeActivity(record, act, items)
+	}
+	return nil
+}
+
+// decodeActivity decodes the items of the activities known, the activities with too few fields are skipped.
+func (d *decoder) decodeActivity(record *Record, act Activity, items []layout) {
+	t := act.Types
+	switch {
+	case act.ID == A_CPU && t[0] >= 8:
+		for _, l := range items {
+			record.CPUs = append(record.CPUs, CPU{
+				User: l.ull(0), Nice: l.ull(1), System: l.ull(2), Idle: l.ull(3), IOWait: l.ull(4),
+				Steal: l.ull(5), HardIRQ: l.ull(6), SoftIRQ: l.ull(7), Guest: l.ull(8), GuestNice: l.ull(9),
+			})
+		}
+	case act.ID == A_PCSW && t[0]+t[1] >= 2 && len(items) != 0:
+		record.Task = &Task{ContextSwitches: items[0].num(0), Processes: items[0].num(1)}
+	case act.ID == A_SWAP && t[0]+t[1] >= 2 && len(items) != 0:
+		record.Swap = &Swap{PswpIn: items[0].num(0), PswpOut: items[0].num(1)}
+	case act.ID == A_PAGE && t[0]+t[1] >= 8 && len(items) != 0:
+		l := items[0]
+		record.Paging = &Paging{
+			PgpgIn: l.num(0), PgpgOut: l.num(1), Faults: l.num(2), MajorFaults: l.num(3),
+			PgFree: l.num(4), PgScanKswapd: l.num(5), PgScanDirect: l.num(6), PgSteal: l.num(7),
+		}
+	case act.ID == A_MEMORY && t[0] >= 11 && len(items) != 0:
+		l := items[0]
+		record.Memory = &Memory{
+			Free: l.ull(0), Buffers: l.ull(1), Cached: l.ull(2), Total: l.ull(3), SwapFree: l.ull(4), SwapTotal: l.ull(5),
+			SwapCached: l.ull(6), Committed: l.ull(7), Active: l.ull(8), Inactive: l.ull(9), Dirty: l.ull(10),
+			AnonPages: l.ull(11), Slab: l.ull(12), KernelStack: l.ull(13), PageTables: l.ull(14), VmallocUsed: l.ull(15),
+			Available: l.ull(16),
+		}
+	case act.ID == A_QUEUE && t[0] >= 3 && t[2] >= 3 && len(items) != 0:
+		l := items[0]
+		record.Queue = &Queue{Running: l.ull(0), Blocked: l.ull(1), Threads: l.ull(2), Load1: l.u(0), Load5: l.u(1), Load15: l.u(2)}
+	case act.ID == A_DISK && t[0] >= 1 && t[1] >= 2 && t[2] >= 6:
+		for _, l := range items {
+			record.Disks = append(record.Disks, Disk{
+				IOs: l.ull(0), ReadSectors: l.ul(0), WriteSectors: l.ul(1), DiscardSectors: l.ul(2),
+				ReadTicks: l.u(0), WriteTicks: l.u(1), TotalTicks: l.u(2), QueueTicks: l.u(3), Major: l.u(4), Minor: l.u(5),
+				DiscardTicks: l.u(6),
+			})
+		}
+	case act.ID == A_NET_DEV && t[0] >= 7 && t[2] >= 1 && int64(act.Size) >= t.size()+_max_iface_len:
+		for _, l := range items {
+			chars := l.chars()
+			netDev := NetDev{
+				Iface:     cString(chars[:_max_iface_len]),
+				RxPackets: l.ull(0), TxPackets: l.ull(1), RxBytes: l.ull(2), TxBytes: l.ull(3),
+				RxCompressed: l.ull(4), TxCompressed: l.ull(5), Multicast: l.ull(6), Speed: l.u(0),
+			}
+			if len(chars) > _max_iface_len {
+				netDev.Duplex = chars[_max_iface_len]
+			}
+			record.NetDevs = append(record.NetDevs, netDev)
+		}
+	case act.ID == A_NET_EDEV && t[0]+t[1] >= 9 && int64(act.Size) >= t.size()+_max_iface_len:
+		for _, l := range items {
+			record.NetEdevs = append(record.NetEdevs, NetEdev{
+				Iface:      cString(l.chars()[:_max_iface_len]),
+				Collisions: l.num(0), RxErrors: l.num(1), TxErrors: l.num(2), RxDropped: l.num(3), TxDropped: l.num(4),
+				RxFifoErrors: l.num(5), TxFifoErrors: l.num(6), RxFrameErrors: l.num(7), TxCarrierErrors: l.num(8),
+			})
+		}
+	}
+}
+
+func restartCPUs(activities []Activity, cpus int32) {
+	if cpus < 1 || cpus > _max_items {
+		return
+	}
+	for i := range activities {
+		if activities[i].ID == A_CPU && !activities[i].HasNr {
+			activities[i].NrIni = cpus
+		}
+	}
+}
+
+func cString(b []byte) string {
+	if i := strings.IndexByte(string(b), 0); i >= 0 {
+		b = b[:i]
+	}
+	return string(b)
+}
@@ -0,0 +1,232 @@
+package safile_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"yhc/internal/modules/yhc/check/safile"
+)
+
+// encoder writes a data file in the layout of sysstat 12.
+type encoder struct {
+	bytes.Buffer
+	order binary.ByteOrder
+}
+
+func (e *encoder) ull(values ...uint64) {
+	for _, v := range values {
+		_ = binary.Write(e, e.order, v)
+	}
+}
+
+func (e *encoder) u(values ...uint32) {
+	for _, v := range values {
+		_ = binary.Write(e, e.order, v)
+	}
+}
+
+func (e *encoder) chars(s string, size int) {
+	b := make([]byte, size)
+	copy(b, s)
+	e.Write(b)
+}
+
+type activity struct {
+	id, nr, hasNr, size uint32
+	types               [3]uint32
+}
+
+var _activities = []activity{
+	{id: safile.A_CPU, nr: 2, size: 80, types: [3]uint32{10, 0, 0}},
+	{id: safile.A_PCSW, nr: 1, size: 16, types: [3]uint32{1, 1, 0}},
+	{id: safile.A_SWAP, nr: 1, size: 16, types: [3]uint32{0, 2, 0}},
+	{id: safile.A_PAGE, nr: 1, size: 64, types: [3]uint32{8, 0, 0}},
+	{id: safile.A_MEMORY, nr: 1, size: 136, types: [3]uint32{17, 0, 0}},
+	{id: safile.A_QUEUE, nr: 1, size: 40, types: [3]uint32{3, 0, 3}},
+	{id: safile.A_DISK, hasNr: 1, size: 64, types: [3]uint32{1, 3, 7}},
+	{id: safile.A_NET_DEV, hasNr: 1, size: 80, types: [3]uint32{7, 0, 1}},
+	{id: safile.A_NET_EDEV, hasNr: 1, size: 88, types: [3]uint32{9, 0, 0}},
+	// an activity unknown is skipped
+	{id: 17, nr: 1, size: 8, types: [3]uint32{1, 0, 0}},
+}
+
+func (e *encoder) header(start time.Time) {
+	e.Write([]byte{0, 0})
+	e.order.PutUint16(e.Bytes()[0:], safile.SYSSTAT_MAGIC)
+	e.Write([]byte{0, 0, 12, 6, 1, 0})
+	e.order.PutUint16(e.Bytes()[2:], safile.FORMAT_MAGIC)
+	headerSize := uint32(2*8 + 12*4 + 3 + 4*65)
+	e.u(headerSize, 0, 2, 0, 12)
+	e.chars("", 48)
+	e.ull(uint64(start.Unix()), 100)
+	e.u(2, uint32(len(_activities)), 2024, 0, 0, 9, 2, 0, 1, 36, 24, 0)
+	e.Write([]byte{2, 1, 8})
+	for _, name := range []string{"Linux", "yashan-host", "4.19.90", "x86_64"} {
+		e.chars(name, 65)
+	}
+	for _, act := range _activities {
+		e.u(act.id, 0x8a, act.nr, 1, act.hasNr, act.size, act.types[0], act.types[1], act.types[2])
+	}
+}
+
+func (e *encoder) record(typ uint8, t time.Time, uptime uint64) {
+	e.ull(uptime, uint64(t.Unix()))
+	e.u(0)
+	e.Write([]byte{typ, uint8(t.Hour()), uint8(t.Minute()), uint8(t.Second())})
+}
+
+// stats writes the statistics of cpus CPU items, every counter is scaled by n.
+func (e *encoder) stats(cpus int, n uint64) {
+	for i := 0; i < cpus; i++ {
+		e.ull(10*n, 0, 5*n, 80*n, 5*n, 0, 0, 0, 2*n, 0)
+	}
+	e.ull(1000*n, 10*n)
+	e.ull(2*n, 3*n)
+	e.ull(100*n, 200*n, 1000*n, 5*n, 50*n, 40*n, 10*n, 25*n)
+	e.ull(1000, 200, 300, 4000, 0, 1000, 0, 2500, 0, 0, 0, 0, 100, 0, 0, 0, 1500)
+	e.ull(2, 1, 300)
+	e.u(150, 100, 50)
+	e.chars("", 4)
+	e.u(1)
+	e.ull(10 * n)
+	e.ull(20*n, 40*n, 0)
+	m := uint32(n)
+	e.u(5*m, 5*m, 10*m, 20*m, 8, 0, 0)
+	e.chars("", 4)
+	e.u(1)
+	e.ull(n, n, 1024*n, 2048*n, 0, 0, 0)
+	e.u(1000)
+	e.chars("eth0", 16)
+	e.Write([]byte{safile.C_DUPLEX_FULL})
+	e.chars("", 3)
+	e.u(1)
+	e.ull(0, n, 0, 2*n, 0, 0, 0, 0, 0)
+	e.chars("eth0", 16)
+	e.ull(n)
+}
+
+func encode(order binary.ByteOrder, start time.Time) []byte {
+	e := &encoder{order: order}
+	e.header(start)
+	e.record(safile.R_STATS, start, 1000)
+	e.stats(2, 1)
+	e.record(safile.R_COMMENT, start.Add(time.Second), 1100)
+	e.chars("maintenance", 64)
+	e.record(safile.R_RESTART, start.Add(time.Minute), 100)
+	e.u(3)
+	e.record(safile.R_STATS, start.Add(2*time.Minute), 6100)
+	e.stats(3, 2)
+	// the record being written by sadc
+	e.record(safile.R_STATS, start.Add(3*time.Minute), 12100)
+	e.ull(1)
+	return e.Bytes()
+}
+
+func TestDecode(t *testing.T) {
+	start := time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC)
+	for _, order := range []binary.ByteOrder{binary.LittleEndian, binary.BigEndian} {
+		file, err := safile.Decode(bytes.NewReader(encode(order, start)))
+		if err != nil {
+			t.Fatalf("%s: %v", order, err)
+		}
+		header := file.Header
+		if header.Version != "12.6.1" || !header.Time.Equal(start) || header.Hz != 100 || header.CPUs != 2 || header.Nodename != "yashan-host" {
+			t.Fatalf("%s: unexpected header: %+v", order, header)
+		}
+		if len(file.Records) != 4 {
+			t.Fatalf("%s: unexpected records: %+v", order, file.Records)
+		}
+		if file.Records[1].Comment != "maintenance" || file.Records[2].Type != safile.R_RESTART {
+			t.Fatalf("%s: unexpected special records: %+v", order, file.Records[1:3])
+		}
+		record := file.Records[3]
+		if record.Type != safile.R_STATS || !record.Time.Equal(start.Add(2*time.Minute)) || record.Uptime != 6100 {
+			t.Fatalf("%s: unexpected record: %+v", order, record)
+		}
+		if len(record.CPUs) != 3 || record.CPUs[2].Idle != 160 || record.CPUs[0].Guest != 4 {
+			t.Fatalf("%s: unexpected cpus: %+v", order, record.CPUs)
+		}
+		if task := record.Task; task == nil || task.ContextSwitches != 2000 || task.Processes != 20 {
+			t.Fatalf("%s: unexpected task: %+v", order, task)
+		}
+		if swap := record.Swap; swap == nil || swap.PswpIn != 4 || swap.PswpOut != 6 {
+			t.Fatalf("%s: unexpected swap: %+v", order, swap)
+		}
+		if paging := record.Paging; paging == nil || paging.MajorFaults != 10 || paging.PgSteal != 50 {
+			t.Fatalf("%s: unexpected paging: %+v", order, paging)
+		}
+		if m := record.Memory; m == nil || m.Total != 4000 || m.Slab != 100 || m.Available != 1500 {
+			t.Fatalf("%s: unexpected memory: %+v", order, m)
+		}
+		if q := record.Queue; q == nil || q.Threads != 300 || q.Load1 != 150 || q.Load15 != 50 {
+			t.Fatalf("%s: unexpected queue: %+v", order, q)
+		}
+		expectedDisk := safile.Disk{Major: 8, IOs: 20, ReadSectors: 40, WriteSectors: 80, ReadTicks: 10, WriteTicks: 10, TotalTicks: 20, QueueTicks: 40}
+		if len(record.Disks) != 1 || record.Disks[0] != expectedDisk {
+			t.Fatalf("%s: unexpected disks: %+v", order, record.Disks)
+		}
+		expectedNetDev := safile.NetDev{Iface: "eth0", RxPackets: 2, TxPackets: 2, RxBytes: 2048, TxBytes: 4096, Speed: 1000, Duplex: safile.C_DUPLEX_FULL}
+		if len(record.NetDevs) != 1 || record.NetDevs[0] != expectedNetDev {
+			t.Fatalf("%s: unexpected network devices: %+v", order, record.NetDevs)
+		}
+		expectedNetEdev := safile.NetEdev{Iface: "eth0", RxErrors: 2, RxDropped: 4}
+		if len(record.NetEdevs) != 1 || record.NetEdevs[0] != expectedNetEdev {
+			t.Fatalf("%s: unexpected network errors: %+v", order, record.NetEdevs)
+		}
+	}
+}
+
+func TestDecodeInvalid(t *testing.T) {
+	if _, err := safile.Decode(bytes.NewReader([]byte("Linux 3.10.0 (host) 08/10/2023"))); !errors.Is(err, safile.ErrNotSysstatFile) {
+		t.Fatalf("expected not sysstat file, got %v", err)
+	}
+	// sysstat 10 with format magic 0x2171
+	content := encode(binary.LittleEndian, time.Now())
+	binary.LittleEndian.PutUint16(content[2:], 0x2171)
+	if _, err := safile.Decode(bytes.NewReader(content)); !errors.Is(err, safile.ErrUnsupportedVersion) {
+		t.Fatalf("expected unsupported version, got %v", err)
+	}
+	// sysstat 11.6.1 with format magic 0x2173 in big endian
+	content = encode(binary.BigEndian, time.Now())
+	binary.BigEndian.PutUint16(content[2:], 0x2173)
+	content[4], content[5], content[6] = 11, 6, 1
+	_, err := safile.Decode(bytes.NewReader(content))
+	if !errors.Is(err, safile.ErrUnsupportedVersion) || !strings.Contains(err.Error(), "0x2173 of sysstat 11.6.1") {
+		t.Fatalf("expected unsupported version of sysstat 11.6.1, got %v", err)
+	}
+}
+
+func TestDecodeMalformed(t *testing.T) {
+	const (
+		headerSizeOffset = 8
+		actSizeOffset    = 76 + 16 + 9*4
+		recSizeOffset    = actSizeOffset + 4
+		// the first activity is A_CPU, of which the fields are id, magic, nr_ini, nr2, has_nr and size
+		cpuActOffset = 76 + 2*8 + 12*4 + 3 + 4*65
+		nrIniOffset  = cpuActOffset + 2*4
+		nr2Offset    = cpuActOffset + 3*4
+		sizeOffset   = cpuActOffset + 5*4
+	)
+	cases := map[string]map[int]uint32{
+		// nr_ini * nr2 overflows int32
+		"nr2 overflow":       {nrIniOffset: 2, nr2Offset: 0x40000000},
+		"stats too large":    {nrIniOffset: 1 << 16, nr2Offset: 1 << 16},
+		"activity too big":   {sizeOffset: 0x7fffffff},
+		"header too big":     {headerSizeOffset: 0xfffffff0},
+		"activities too big": {actSizeOffset: 0x7fffffff},
+		"records too big":    {recSizeOffset: 0x7fffffff},
+	}
+	for name, patches := range cases {
+		content := encode(binary.LittleEndian, time.Now())
+		for offset, value := range patches {
+			binary.LittleEndian.PutUint32(content[offset:], value)
+		}
+		if _, err := safile.Decode(bytes.NewReader(content)); !errors.Is(err, safile.ErrUnsupportedFormat) {
+			t.Fatalf("%s: expected unsupported format, got %v", name, err)
+		}
+	}
+}
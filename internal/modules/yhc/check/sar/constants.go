@@ -5,29 +5,37 @@ import "yhc/internal/modules/yhc/check/define"
 const (
 	PREFIX_LINUX   = "Linux"
 	PREFIX_AVERAGE = "Average"
+
+	SYSCONFIG_SYSSTAT = "/etc/sysconfig/sysstat"
+	DEFAULT_SAR_DIR   = "/var/log/sa"
+	DEBIAN_SYSSTAT    = "/etc/sysstat/sysstat"
+	DEBIAN_SAR_DIR    = "/var/log/sysstat"
 )
 
 var _envs = []string{"LANG=en_US.UTF-8", "LC_TIME=en_US.UTF-8"}
 
 var WorkloadTypeToSarArgMap = map[define.WorkloadType]string{
-	define.WT_CPU:     "-u",
-	define.WT_DISK:    "-d",
-	define.WT_MEMORY:  "-r",
-	define.WT_NETWORK: "-n DEV",
+	define.WT_CPU:           "-u",
+	define.WT_DISK:          "-d",
+	define.WT_MEMORY:        "-r",
+	define.WT_NETWORK:       "-n DEV",
+	define.WT_LOAD:          "-q",
+	define.WT_PAGING:        "-B",
+	define.WT_TASK:          "-w",
+	define.WT_SWAP:          "-W",
+	define.WT_NETWORK_ERROR: "-n EDEV",
+	define.WT_CPU_CORE:      "-P ALL -u",
 }
 
-type SarParseFunc func(m define.WorkloadItem, values []string) define.WorkloadItem
-
-type SarCheckTitleFunc func(line string) bool
-
 type CPUUsage struct {
-	CPU    string  `json:"cpu"`    // cpu name, it will be 'all'
+	CPU    string  `json:"cpu"`    // cpu name, 'all' or the number of the core
 	User   float64 `json:"user"`   // percentage of CPU time spent in user space
 	Nice   float64 `json:"nice"`   // percentage of CPU time spent on low priority tasks (niceness)
 	System float64 `json:"system"` // percentage of CPU time spent in kernel space (system calls and kernel threads)
 	IOWait float64 `json:"iowait"` // percentage of CPU time waiting for I/O operations to complete
 	Steal  float64 `json:"steal"`  // percentage of CPU time stolen by other virtual machines or hypervisor in a virtualized environment
 	Idle   float64 `json:"idle"`   // percentage of CPU time idle or not utilized
+	Usage  float64 `json:"usage"`  // percentage of CPU time not idle, 100 - %idle
 }
 
 type NetworkIO struct {
@@ -71,3 +79,48 @@ type MemoryUsage struct {
 	KBDirty     int64   `json:"kBDirty"`     // kbdirty
 	RealMemUsed float64 `json:"realMemUsed"` // real mem used percent
 }
+
+type LoadAverage struct {
+	RunqSz        float64 `json:"runqSz"`        // runq-sz, number of tasks waiting for run time
+	PlistSz       float64 `json:"plistSz"`       // plist-sz, number of tasks in the task list
+	Ldavg1        float64 `json:"ldavg1"`        // ldavg-1, load average for the last minute
+	Ldavg5        float64 `json:"ldavg5"`        // ldavg-5, load average for the past 5 minutes
+	Ldavg15       float64 `json:"ldavg15"`       // ldavg-15, load average for the past 15 minutes
+	Blocked       float64 `json:"blocked"`       // blocked, number of tasks blocked waiting for I/O, since sysstat 10
+	Ldavg1PerCore float64 `json:"ldavg1PerCore"` // ldavg-1 divided by the number of CPUs
+}
+
+type Paging struct {
+	Pgpgin  float64 `json:"pgpgin"`  // pgpgin/s, kilobytes paged in from disk per second
+	Pgpgout float64 `json:"pgpgout"` // pgpgout/s, kilobytes paged out to disk per second
+	Fault   float64 `json:"fault"`   // fault/s, page faults (major + minor) per second
+	Majflt  float64 `json:"majflt"`  // majflt/s, major faults which require loading a page from disk per second
+	Pgfree  float64 `json:"pgfree"`  // pgfree/s, pages placed on the free list per second
+	Pgscank float64 `json:"pgscank"` // pgscank/s, pages scanned by the kswapd daemon per second
+	Pgscand float64 `json:"pgscand"` // pgscand/s, pages scanned directly per second
+	Pgsteal float64 `json:"pgsteal"` // pgsteal/s, pages reclaimed from cache per second
+	Vmeff   float64 `json:"vmeff"`   // %vmeff, pgsteal / pgscan, the efficiency of page reclaim
+}
+
+type TaskSwitch struct {
+	Proc  float64 `json:"proc"`  // proc/s, tasks created per second
+	Cswch float64 `json:"cswch"` // cswch/s, context switches per second
+}
+
+type Swapping struct {
+	Pswpin  float64 `json:"pswpin"`  // pswpin/s, swap pages brought in per second
+	Pswpout float64 `json:"pswpout"` // pswpout/s, swap pages brought out per second
+}
+
+type NetworkError struct {
+	Iface  string  `json:"iface"`  // interface name
+	Rxerr  float64 `json:"rxerr"`  // rxerr/s, bad packets received per second
+	Txerr  float64 `json:"txerr"`  // txerr/s, errors while transmitting packets per second
+	Coll   float64 `json:"coll"`   // coll/s, collisions per second
+	Rxdrop float64 `json:"rxdrop"` // rxdrop/s, received packets dropped per second for lack of buffers
+	Txdrop float64 `json:"txdrop"` // txdrop/s, transmitted packets dropped per second for lack of buffers
+	Txcarr float64 `json:"txcarr"` // txcarr/s, carrier errors while transmitting per second
+	Rxfram float64 `json:"rxfram"` // rxfram/s, frame alignment errors on received packets per second
+	Rxfifo float64 `json:"rxfifo"` // rxfifo/s, FIFO overrun errors on received packets per second
+	Txfifo float64 `json:"txfifo"` // txfifo/s, FIFO overrun errors on transmitted packets per second
+}
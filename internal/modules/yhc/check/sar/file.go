@@ -0,0 +1,356 @@
+package sar
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"yhc/internal/modules/yhc/check/define"
+	"yhc/internal/modules/yhc/check/safile"
+	"yhc/utils/mathutil"
+)
+
+const (
+	_decimal            = 2
+	_centiseconds_per_s = 100
+	_bits_per_byte      = 8
+	_bytes_per_kb       = 1024
+	_ms_per_s           = 1000
+
+	_cpu_all = "all"
+)
+
+// converter calculates the item of a record from the record before it, the seconds between the records are positive.
+type converter func(old, new *safile.Record, seconds float64) define.WorkloadItem
+
+var _typeToConverter = map[define.WorkloadType]converter{
+	define.WT_CPU:           convertCPU,
+	define.WT_MEMORY:        convertMemory,
+	define.WT_DISK:          convertDisk,
+	define.WT_NETWORK:       convertNetwork,
+	define.WT_LOAD:          convertLoad,
+	define.WT_PAGING:        convertPaging,
+	define.WT_TASK:          convertTask,
+	define.WT_SWAP:          convertSwap,
+	define.WT_NETWORK_ERROR: convertNetworkError,
+	define.WT_CPU_CORE:      convertCPUCore,
+}
+
+// CollectFile decodes the sysstat data file natively instead of running sar, the records between start and end are collected,
+// the zero start or end means no limit.
+func (s *Sar) CollectFile(t define.WorkloadType, path string, start, end time.Time) (define.WorkloadOutput, error) {
+	file, err := safile.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode %s, err: %w", path, err)
+	}
+	res, err := FromFile(t, file, start, end)
+	if err != nil {
+		return res, err
+	}
+	if t == define.WT_DISK {
+		return s.transferDiskOutput(res)
+	}
+	return res, nil
+}
+
+// FromFile calculates the workload of the decoded data file as sar does, the rates are calculated between the adjacent
+// stats records, so the first record of the file and the first one after a restart are skipped.
+func FromFile(t define.WorkloadType, file *safile.File, start, end time.Time) (define.WorkloadOutput, error) {
+	convert, ok := _typeToConverter[t]
+	if !ok {
+		return nil, fmt.Errorf("unsupported workload type: %s", t)
+	}
+	res := make(define.WorkloadOutput)
+	var last *safile.Record
+	for i := range file.Records {
+		record := &file.Records[i]
+		if record.Type == safile.R_RESTART {
+			last = nil
+			continue
+		}
+		if record.Type != safile.R_STATS {
+			continue
+		}
+		old := last
+		last = record
+		if old == nil || (!start.IsZero() && record.Time.Before(start)) || (!end.IsZero() && record.Time.After(end)) {
+			continue
+		}
+		seconds := record.Time.Sub(old.Time).Seconds()
+		if record.Uptime > old.Uptime {
+			seconds = float64(record.Uptime-old.Uptime) / _centiseconds_per_s
+		}
+		if seconds <= 0 {
+			continue
+		}
+		if item := convert(old, record, seconds); len(item) != 0 {
+			res[record.Time.Unix()] = item
+		}
+	}
+	return res, nil
+}
+
+// convertCPU calculates the usage of all CPUs as sar -u.
+func convertCPU(old, new *safile.Record, seconds float64) define.WorkloadItem {
+	if len(old.CPUs) == 0 || len(new.CPUs) == 0 {
+		return nil
+	}
+	usage, ok := cpuUsage(_cpu_all, old.CPUs[0], new.CPUs[0])
+	if !ok {
+		return nil
+	}
+	return define.WorkloadItem{_cpu_all: usage}
+}
+
+// convertCPUCore calculates the usage of all CPUs and every core as sar -P ALL -u,
+// the first CPU item is all CPUs and the others are the cores from 0.
+func convertCPUCore(old, new *safile.Record, seconds float64) define.WorkloadItem {
+	item := make(define.WorkloadItem)
+	for i := 0; i < len(old.CPUs) && i < len(new.CPUs); i++ {
+		name := _cpu_all
+		if i != 0 {
+			name = strconv.Itoa(i - 1)
+		}
+		// the offline cores have no ticks
+		if usage, ok := cpuUsage(name, old.CPUs[i], new.CPUs[i]); ok {
+			item[name] = usage
+		}
+	}
+	if len(item) == 0 {
+		return nil
+	}
+	return item
+}
+
+// cpuUsage calculates the usage of a CPU item, the guest time is excluded from the user time
+// and the irq time is included in the system time.
+func cpuUsage(name string, o, n safile.CPU) (CPUUsage, bool) {
+	user, nice, guest, guestNice := delta(o.User, n.User), delta(o.Nice, n.Nice), delta(o.Guest, n.Guest), delta(o.GuestNice, n.GuestNice)
+	system := delta(o.System, n.System) + delta(o.HardIRQ, n.HardIRQ) + delta(o.SoftIRQ, n.SoftIRQ)
+	iowait, steal, idle := delta(o.IOWait, n.IOWait), delta(o.Steal, n.Steal), delta(o.Idle, n.Idle)
+	total := user + nice + system + iowait + steal + idle
+	if total == 0 {
+		return CPUUsage{}, false
+	}
+	percent := func(v float64) float64 { return mathutil.Round(v*100/total, _decimal) }
+	return CPUUsage{
+		CPU:    name,
+		User:   percent(math.Max(user-guest, 0)),
+		Nice:   percent(math.Max(nice-guestNice, 0)),
+		System: percent(system),
+		IOWait: percent(iowait),
+		Steal:  percent(steal),
+		Idle:   percent(idle),
+		Usage:  percent(total - idle),
+	}, true
+}
+
+// convertMemory calculates the memory usage as sar -r of sysstat 12, of which kbmemused excludes the buffers, caches and slab.
+func convertMemory(old, new *safile.Record, seconds float64) define.WorkloadItem {
+	m := new.Memory
+	if m == nil || m.Total == 0 {
+		return nil
+	}
+	notUsed := m.Free + m.Buffers + m.Cached + m.Slab
+	if notUsed > m.Total {
+		notUsed = m.Total
+	}
+	usage := MemoryUsage{
+		KBMemFree: int64(m.Free),
+		KBAvail:   int64(m.Available),
+		KBmemUsed: int64(m.Total - notUsed),
+		MemUsed:   mathutil.Round(float64(m.Total-notUsed)*100/float64(m.Total), _decimal),
+		KBBuffers: int64(m.Buffers),
+		KBCached:  int64(m.Cached),
+		KBCommit:  int64(m.Committed),
+		Commit:    mathutil.Round(float64(m.Committed)*100/float64(m.Total+m.SwapTotal), _decimal),
+		KBActive:  int64(m.Active),
+		KBInact:   int64(m.Inactive),
+		KBDirty:   int64(m.Dirty),
+	}
+	available := m.Available
+	if available == 0 {
+		available = m.Free + m.Buffers + m.Cached
+	}
+	usage.RealMemUsed = mathutil.Round(100*(1-float64(available)/float64(m.Total)), _decimal)
+	return define.WorkloadItem{memoryUsageKey: usage}
+}
+
+// convertDisk calculates the io of the devices as sar -d, the devices are named devM-N as sar without -p.
+func convertDisk(old, new *safile.Record, seconds float64) define.WorkloadItem {
+	olds := make(map[[2]uint32]safile.Disk, len(old.Disks))
+	for _, disk := range old.Disks {
+		olds[[2]uint32{disk.Major, disk.Minor}] = disk
+	}
+	item := make(define.WorkloadItem)
+	for _, n := range new.Disks {
+		o, ok := olds[[2]uint32{n.Major, n.Minor}]
+		if !ok {
+			continue
+		}
+		ios := delta(o.IOs, n.IOs)
+		rd, wr, dc := delta(o.ReadSectors, n.ReadSectors), delta(o.WriteSectors, n.WriteSectors), delta(o.DiscardSectors, n.DiscardSectors)
+		busy := delta32(o.TotalTicks, n.TotalTicks)
+		disk := DiskIO{
+			Dev:     fmt.Sprintf("dev%d-%d", n.Major, n.Minor),
+			Tps:     mathutil.Round(ios/seconds, _decimal),
+			RdSec:   mathutil.Round(rd/seconds, _decimal),
+			WrSec:   mathutil.Round(wr/seconds, _decimal),
+			RKBSec:  mathutil.Round(rd/_sectors_per_kb/seconds, _decimal),
+			WKBSec:  mathutil.Round(wr/_sectors_per_kb/seconds, _decimal),
+			DKBSec:  mathutil.Round(dc/_sectors_per_kb/seconds, _decimal),
+			AvgquSz: mathutil.Round(delta32(o.QueueTicks, n.QueueTicks)/seconds/_ms_per_s, _decimal),
+			Util:    mathutil.Round(math.Min(busy*100/seconds/_ms_per_s, 100), _decimal),
+		}
+		if ios > 0 {
+			ticks := delta32(o.ReadTicks, n.ReadTicks) + delta32(o.WriteTicks, n.WriteTicks) + delta32(o.DiscardTicks, n.DiscardTicks)
+			disk.AvgrqSz = mathutil.Round((rd+wr+dc)/ios, _decimal)
+			disk.Await = mathutil.Round(ticks/ios, _decimal)
+			disk.Svctm = mathutil.Round(busy/ios, _decimal)
+		}
+		item[disk.Dev] = disk
+	}
+	return item
+}
+
+// convertNetwork calculates the io of the network interfaces as sar -n DEV.
+func convertNetwork(old, new *safile.Record, seconds float64) define.WorkloadItem {
+	olds := make(map[string]safile.NetDev, len(old.NetDevs))
+	for _, netDev := range old.NetDevs {
+		olds[netDev.Iface] = netDev
+	}
+	item := make(define.WorkloadItem)
+	for _, n := range new.NetDevs {
+		o, ok := olds[n.Iface]
+		if !ok {
+			continue
+		}
+		rx, tx := delta(o.RxBytes, n.RxBytes)/seconds, delta(o.TxBytes, n.TxBytes)/seconds
+		item[n.Iface] = NetworkIO{
+			Iface:  n.Iface,
+			Rxpck:  mathutil.Round(delta(o.RxPackets, n.RxPackets)/seconds, _decimal),
+			Txpck:  mathutil.Round(delta(o.TxPackets, n.TxPackets)/seconds, _decimal),
+			RxkB:   mathutil.Round(rx/_bytes_per_kb, _decimal),
+			TxkB:   mathutil.Round(tx/_bytes_per_kb, _decimal),
+			Rxcmp:  mathutil.Round(delta(o.RxCompressed, n.RxCompressed)/seconds, _decimal),
+			Txcmp:  mathutil.Round(delta(o.TxCompressed, n.TxCompressed)/seconds, _decimal),
+			Rxmcst: mathutil.Round(delta(o.Multicast, n.Multicast)/seconds, _decimal),
+			Ifutil: mathutil.Round(ifutil(n, rx, tx), _decimal),
+		}
+	}
+	return item
+}
+
+// convertLoad gets the run queue and load averages as sar -q, the number of CPUs is of the CPU items without all CPUs.
+func convertLoad(old, new *safile.Record, seconds float64) define.WorkloadItem {
+	q := new.Queue
+	if q == nil {
+		return nil
+	}
+	r := &loadRow{LoadAverage: LoadAverage{
+		RunqSz:  float64(q.Running),
+		PlistSz: float64(q.Threads),
+		Ldavg1:  float64(q.Load1) / 100,
+		Ldavg5:  float64(q.Load5) / 100,
+		Ldavg15: float64(q.Load15) / 100,
+		Blocked: float64(q.Blocked),
+	}}
+	r.setCPUs(len(new.CPUs) - 1)
+	return define.WorkloadItem{loadAverageKey: r.value()}
+}
+
+// convertPaging calculates the paging as sar -B.
+func convertPaging(old, new *safile.Record, seconds float64) define.WorkloadItem {
+	if old.Paging == nil || new.Paging == nil {
+		return nil
+	}
+	o, n := old.Paging, new.Paging
+	scanned := delta(o.PgScanKswapd, n.PgScanKswapd) + delta(o.PgScanDirect, n.PgScanDirect)
+	steal := delta(o.PgSteal, n.PgSteal)
+	paging := Paging{
+		Pgpgin:  mathutil.Round(delta(o.PgpgIn, n.PgpgIn)/seconds, _decimal),
+		Pgpgout: mathutil.Round(delta(o.PgpgOut, n.PgpgOut)/seconds, _decimal),
+		Fault:   mathutil.Round(delta(o.Faults, n.Faults)/seconds, _decimal),
+		Majflt:  mathutil.Round(delta(o.MajorFaults, n.MajorFaults)/seconds, _decimal),
+		Pgfree:  mathutil.Round(delta(o.PgFree, n.PgFree)/seconds, _decimal),
+		Pgscank: mathutil.Round(delta(o.PgScanKswapd, n.PgScanKswapd)/seconds, _decimal),
+		Pgscand: mathutil.Round(delta(o.PgScanDirect, n.PgScanDirect)/seconds, _decimal),
+		Pgsteal: mathutil.Round(steal/seconds, _decimal),
+	}
+	if scanned > 0 {
+		paging.Vmeff = mathutil.Round(math.Min(steal*100/scanned, 100), _decimal)
+	}
+	return define.WorkloadItem{pagingKey: paging}
+}
+
+// convertTask calculates the task creation and context switches as sar -w.
+func convertTask(old, new *safile.Record, seconds float64) define.WorkloadItem {
+	if old.Task == nil || new.Task == nil {
+		return nil
+	}
+	return define.WorkloadItem{taskSwitchKey: TaskSwitch{
+		Proc:  mathutil.Round(delta(old.Task.Processes, new.Task.Processes)/seconds, _decimal),
+		Cswch: mathutil.Round(delta(old.Task.ContextSwitches, new.Task.ContextSwitches)/seconds, _decimal),
+	}}
+}
+
+// convertSwap calculates the swapping as sar -W.
+func convertSwap(old, new *safile.Record, seconds float64) define.WorkloadItem {
+	if old.Swap == nil || new.Swap == nil {
+		return nil
+	}
+	return define.WorkloadItem{swappingKey: Swapping{
+		Pswpin:  mathutil.Round(delta(old.Swap.PswpIn, new.Swap.PswpIn)/seconds, _decimal),
+		Pswpout: mathutil.Round(delta(old.Swap.PswpOut, new.Swap.PswpOut)/seconds, _decimal),
+	}}
+}
+
+// convertNetworkError calculates the errors of the network interfaces as sar -n EDEV.
+func convertNetworkError(old, new *safile.Record, seconds float64) define.WorkloadItem {
+	olds := make(map[string]safile.NetEdev, len(old.NetEdevs))
+	for _, netEdev := range old.NetEdevs {
+		olds[netEdev.Iface] = netEdev
+	}
+	item := make(define.WorkloadItem)
+	for _, n := range new.NetEdevs {
+		o, ok := olds[n.Iface]
+		if !ok {
+			continue
+		}
+		rate := func(old, new uint64) float64 { return mathutil.Round(delta(old, new)/seconds, _decimal) }
+		item[n.Iface] = NetworkError{
+			Iface:  n.Iface,
+			Rxerr:  rate(o.RxErrors, n.RxErrors),
+			Txerr:  rate(o.TxErrors, n.TxErrors),
+			Coll:   rate(o.Collisions, n.Collisions),
+			Rxdrop: rate(o.RxDropped, n.RxDropped),
+			Txdrop: rate(o.TxDropped, n.TxDropped),
+			Txcarr: rate(o.TxCarrierErrors, n.TxCarrierErrors),
+			Rxfram: rate(o.RxFrameErrors, n.RxFrameErrors),
+			Rxfifo: rate(o.RxFifoErrors, n.RxFifoErrors),
+			Txfifo: rate(o.TxFifoErrors, n.TxFifoErrors),
+		}
+	}
+	return item
+}
+
+// ifutil calculates the utilization of the interface by the speed, the larger direction is counted for full duplex.
+func ifutil(netDev safile.NetDev, rx, tx float64) float64 {
+	if netDev.Speed == 0 {
+		return 0
+	}
+	bits := float64(netDev.Speed) * 1000000
+	if netDev.Duplex == safile.C_DUPLEX_FULL {
+		return math.Max(rx, tx) * _bits_per_byte * 100 / bits
+	}
+	return (rx + tx) * _bits_per_byte * 100 / bits
+}
+
+func delta(old, new uint64) float64 {
+	return mathutil.CounterDelta(float64(old), float64(new))
+}
+
+func delta32(old, new uint32) float64 {
+	return delta(uint64(old), uint64(new))
+}
@@ -0,0 +1,125 @@
+package sar_test
+
+import (
+	"testing"
+	"time"
+
+	"yhc/internal/modules/yhc/check/define"
+	"yhc/internal/modules/yhc/check/safile"
+	"yhc/internal/modules/yhc/check/sar"
+)
+
+func stats(t time.Time, uptime uint64, n uint64) safile.Record {
+	return safile.Record{
+		Type:   safile.R_STATS,
+		Time:   t,
+		Uptime: uptime,
+		CPUs:   []safile.CPU{{User: 30 * n, System: 5 * n, HardIRQ: 2 * n, SoftIRQ: 3 * n, IOWait: 10 * n, Idle: 150 * n, Guest: 10 * n}},
+		Memory: &safile.Memory{Free: 1000, Buffers: 200, Cached: 300, Slab: 500, Total: 4000, SwapTotal: 1000, Committed: 2500, Available: 2000},
+		Disks: []safile.Disk{{Major: 8, IOs: 100 * n, ReadSectors: 2000 * n, WriteSectors: 4000 * n,
+			ReadTicks: uint32(300 * n), WriteTicks: uint32(200 * n), TotalTicks: uint32(6000 * n), QueueTicks: uint32(12000 * n)}},
+		NetDevs: []safile.NetDev{{Iface: "eth0", RxPackets: 600 * n, RxBytes: 61440 * n, TxBytes: 1875000 * n, Speed: 1, Duplex: safile.C_DUPLEX_FULL}},
+		Queue:   &safile.Queue{Running: 3, Blocked: 1, Threads: 500, Load1: 400, Load5: 300, Load15: 200},
+		Task:    &safile.Task{ContextSwitches: 60000 * n, Processes: 120 * n},
+		Swap:    &safile.Swap{PswpIn: 60 * n, PswpOut: 120 * n},
+		Paging: &safile.Paging{PgpgIn: 600 * n, Faults: 6000 * n, MajorFaults: 30 * n,
+			PgScanKswapd: 100 * n, PgScanDirect: 20 * n, PgSteal: 90 * n},
+		NetEdevs: []safile.NetEdev{{Iface: "eth0", RxErrors: 6 * n, TxDropped: 12 * n}},
+	}
+}
+
+func TestFromFile(t *testing.T) {
+	start := time.Date(2024, 1, 2, 0, 0, 0, 0, time.Local)
+	file := &safile.File{Records: []safile.Record{
+		stats(start, 1000, 1),
+		stats(start.Add(time.Minute), 7000, 2),
+		{Type: safile.R_RESTART, Time: start.Add(2 * time.Minute)},
+		// the first record after a restart has no rates
+		stats(start.Add(3*time.Minute), 1000, 1),
+		stats(start.Add(4*time.Minute), 7000, 2),
+		stats(start.Add(5*time.Minute), 13000, 3),
+	}}
+	minute := func(m int) int64 { return start.Add(time.Duration(m) * time.Minute).Unix() }
+
+	cpu, err := sar.FromFile(define.WT_CPU, file, time.Time{}, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cpu) != 3 {
+		t.Fatalf("unexpected cpu times: %+v", cpu)
+	}
+	expectedCPU := sar.CPUUsage{CPU: "all", User: 10, System: 5, IOWait: 5, Idle: 75, Usage: 25}
+	if usage := cpu[minute(1)]["all"]; usage != expectedCPU {
+		t.Fatalf("expected %+v, got %+v", expectedCPU, usage)
+	}
+
+	memory, _ := sar.FromFile(define.WT_MEMORY, file, start.Add(4*time.Minute), start.Add(4*time.Minute))
+	expectedMemory := sar.MemoryUsage{KBMemFree: 1000, KBAvail: 2000, KBmemUsed: 2000, MemUsed: 50, KBBuffers: 200, KBCached: 300,
+		KBCommit: 2500, Commit: 50, RealMemUsed: 50}
+	if len(memory) != 1 || memory[minute(4)]["memory"] != expectedMemory {
+		t.Fatalf("unexpected memory usage: %+v", memory)
+	}
+
+	disk, _ := sar.FromFile(define.WT_DISK, file, time.Time{}, time.Time{})
+	expectedDisk := sar.DiskIO{Dev: "dev8-0", Tps: 1.67, RdSec: 33.33, WrSec: 66.67, RKBSec: 16.67, WKBSec: 33.33,
+		AvgrqSz: 60, AvgquSz: 0.2, Await: 5, Svctm: 60, Util: 10}
+	if d := disk[minute(5)]["dev8-0"]; d != expectedDisk {
+		t.Fatalf("expected %+v, got %+v", expectedDisk, d)
+	}
+
+	network, _ := sar.FromFile(define.WT_NETWORK, file, time.Time{}, start.Add(time.Minute))
+	expectedNetwork := sar.NetworkIO{Iface: "eth0", Rxpck: 10, RxkB: 1, TxkB: 30.52, Ifutil: 25}
+	if len(network) != 1 || network[minute(1)]["eth0"] != expectedNetwork {
+		t.Fatalf("unexpected network io: %+v", network)
+	}
+
+	activities := []struct {
+		workloadType define.WorkloadType
+		name         string
+		expected     interface{}
+	}{
+		// the item of all CPUs is counted
+		{define.WT_LOAD, "load", sar.LoadAverage{RunqSz: 3, PlistSz: 500, Ldavg1: 4, Ldavg5: 3, Ldavg15: 2, Blocked: 1}},
+		{define.WT_TASK, "task", sar.TaskSwitch{Proc: 2, Cswch: 1000}},
+		{define.WT_SWAP, "swap", sar.Swapping{Pswpin: 1, Pswpout: 2}},
+		{define.WT_PAGING, "paging", sar.Paging{Pgpgin: 10, Fault: 100, Majflt: 0.5, Pgscank: 1.67, Pgscand: 0.33, Pgsteal: 1.5, Vmeff: 75}},
+		{define.WT_NETWORK_ERROR, "eth0", sar.NetworkError{Iface: "eth0", Rxerr: 0.1, Txdrop: 0.2}},
+	}
+	for _, activity := range activities {
+		output, err := sar.FromFile(activity.workloadType, file, time.Time{}, time.Time{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if value := output[minute(4)][activity.name]; value != activity.expected {
+			t.Fatalf("%s: expected %+v, got %+v", activity.workloadType, activity.expected, value)
+		}
+	}
+
+	coreFile := &safile.File{Records: []safile.Record{
+		{Type: safile.R_STATS, Time: start, CPUs: []safile.CPU{{User: 100, Idle: 300}, {User: 90, Idle: 10}, {User: 10, Idle: 290}, {}}},
+		{Type: safile.R_STATS, Time: start.Add(time.Minute), Uptime: 6000,
+			CPUs: []safile.CPU{{User: 300, Idle: 700}, {User: 280, Idle: 20}, {User: 20, Idle: 580}, {}}},
+	}}
+	cores, err := sar.FromFile(define.WT_CPU_CORE, coreFile, time.Time{}, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the offline core without ticks is skipped
+	expectedCores := define.WorkloadItem{
+		"all": sar.CPUUsage{CPU: "all", User: 33.33, Idle: 66.67, Usage: 33.33},
+		"0":   sar.CPUUsage{CPU: "0", User: 95, Idle: 5, Usage: 95},
+		"1":   sar.CPUUsage{CPU: "1", User: 3.33, Idle: 96.67, Usage: 3.33},
+	}
+	if len(cores[minute(1)]) != len(expectedCores) {
+		t.Fatalf("unexpected cores: %+v", cores)
+	}
+	for name, expected := range expectedCores {
+		if cores[minute(1)][name] != expected {
+			t.Fatalf("expected %+v, got %+v", expected, cores[minute(1)][name])
+		}
+	}
+
+	if _, err := sar.FromFile(define.WorkloadType("unknown"), file, time.Time{}, time.Time{}); err == nil {
+		t.Fatal("expected error of unknown workload type")
+	}
+}
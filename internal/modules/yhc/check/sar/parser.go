@@ -0,0 +1,502 @@
+package sar
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"yhc/commons/constants"
+	"yhc/internal/modules/yhc/check/define"
+	"yhc/utils/mathutil"
+)
+
+const (
+	// the item names of the outputs without the item name column
+	memoryUsageKey = "memory"
+	loadAverageKey = "load"
+	pagingKey      = "paging"
+	taskSwitchKey  = "task"
+	swappingKey    = "swap"
+
+	// the columns of the item name, the output of memory has no such column
+	COLUMN_CPU   = "CPU"
+	COLUMN_IFACE = "IFACE"
+	COLUMN_DEV   = "DEV"
+
+	PERIOD_AM = "AM"
+	PERIOD_PM = "PM"
+
+	_sectors_per_kb = 2
+	_clock_format   = "15:04:05"
+	_restart_mark   = "RESTART"
+	_cpu_mark       = "CPU)"
+)
+
+// _headDateFormats are the date formats of the head line, which depend on the sysstat version, the locale and S_TIME_FORMAT.
+var _headDateFormats = []string{
+	"01/02/2006",
+	"01/02/06",
+	"2006-01-02",
+	"02.01.2006",
+	"2006/01/02",
+}
+
+// row is the data of an item in a line of sar output.
+type row interface {
+	// set sets the value of the column, the unknown columns are ignored
+	set(column string, value float64)
+	value() interface{}
+}
+
+// cpusRow is the row of which the value depends on the number of CPUs of the host.
+type cpusRow interface {
+	setCPUs(cpus int)
+}
+
+// rowDefine defines how to parse the rows, label is the item name column, or key is the item name if there is no such column.
+type rowDefine struct {
+	label  string
+	key    string
+	newRow func(name string) row
+}
+
+var _typeToRowDefine = map[define.WorkloadType]rowDefine{
+	define.WT_CPU:           {label: COLUMN_CPU, newRow: func(name string) row { return &cpuRow{CPUUsage{CPU: name}} }},
+	define.WT_CPU_CORE:      {label: COLUMN_CPU, newRow: func(name string) row { return &cpuRow{CPUUsage{CPU: name}} }},
+	define.WT_NETWORK:       {label: COLUMN_IFACE, newRow: func(name string) row { return &networkRow{NetworkIO{Iface: name}} }},
+	define.WT_DISK:          {label: COLUMN_DEV, newRow: func(name string) row { return &diskRow{DiskIO{Dev: name}} }},
+	define.WT_MEMORY:        {key: memoryUsageKey, newRow: func(string) row { return &memoryRow{} }},
+	define.WT_LOAD:          {key: loadAverageKey, newRow: func(string) row { return &loadRow{} }},
+	define.WT_PAGING:        {key: pagingKey, newRow: func(string) row { return &pagingRow{} }},
+	define.WT_TASK:          {key: taskSwitchKey, newRow: func(string) row { return &taskRow{} }},
+	define.WT_SWAP:          {key: swappingKey, newRow: func(string) row { return &swapRow{} }},
+	define.WT_NETWORK_ERROR: {label: COLUMN_IFACE, newRow: func(name string) row { return &networkErrorRow{NetworkError{Iface: name}} }},
+}
+
+// header is the column names of the sar output, label is the index of the item name column, -1 if there is none.
+type header struct {
+	columns []string
+	label   int
+}
+
+// Parser parses the sar output by the column names in the header lines instead of fixed column indexes,
+// so that the outputs of sysstat 9 to 12 with different columns, 12-hour or 24-hour time and locales are supported.
+type Parser struct {
+	rowDefine rowDefine
+	warnf     func(format string, args ...interface{})
+	// cpus is the number of CPUs in the head line, 0 if unknown
+	cpus int
+}
+
+func NewParser(t define.WorkloadType, warnf func(format string, args ...interface{})) (*Parser, error) {
+	rowDefine, ok := _typeToRowDefine[t]
+	if !ok {
+		return nil, fmt.Errorf("unsupported workload type: %s", t)
+	}
+	return &Parser{rowDefine: rowDefine, warnf: warnf}, nil
+}
+
+// Parse parses the sar output, the average lines, restart lines and the lines not matching the header are skipped.
+func (p *Parser) Parse(output string) define.WorkloadOutput {
+	res := make(define.WorkloadOutput)
+	now := time.Now()
+	date := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	var h *header
+	var last int64
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		if fields[0] == PREFIX_LINUX {
+			if d, ok := parseHeadDate(fields); ok {
+				date, last = d, 0
+			}
+			if cpus, ok := parseHeadCPUs(fields); ok {
+				p.cpus = cpus
+			}
+			h = nil
+			continue
+		}
+		// the average line starts with a localized word instead of time
+		clock, err := time.Parse(_clock_format, fields[0])
+		if err != nil {
+			continue
+		}
+		fields = fields[1:]
+		period := ""
+		if len(fields) != 0 && isPeriod(fields[0]) {
+			period, fields = strings.ToUpper(fields[0]), fields[1:]
+		}
+		if len(fields) == 0 || strings.Contains(line, _restart_mark) {
+			continue
+		}
+		if isHeader(fields) {
+			h = p.parseHeader(fields)
+			continue
+		}
+		if h == nil {
+			p.warnf("no header before line: %s, skip it", line)
+			continue
+		}
+		if len(fields) != len(h.columns) {
+			p.warnf("the columns of line: %s do not match the header, skip it", line)
+			continue
+		}
+		t := toTime(date, clock, period)
+		if last != 0 && t.Unix() < last {
+			// the output crosses midnight
+			date = date.AddDate(0, 0, 1)
+			t = t.AddDate(0, 0, 1)
+		}
+		last = t.Unix()
+		name := p.rowDefine.key
+		if h.label >= 0 {
+			name = fields[h.label]
+		}
+		r := p.rowDefine.newRow(name)
+		for i, column := range h.columns {
+			if i == h.label {
+				continue
+			}
+			value, ok := parseNumber(fields[i])
+			if !ok {
+				p.warnf("invalid value %s of column %s, line: %s", fields[i], column, line)
+				continue
+			}
+			r.set(column, value)
+		}
+		if c, ok := r.(cpusRow); ok && p.cpus > 0 {
+			c.setCPUs(p.cpus)
+		}
+		item, ok := res[last]
+		if !ok {
+			item = make(define.WorkloadItem)
+			res[last] = item
+		}
+		item[name] = r.value()
+	}
+	return res
+}
+
+func (p *Parser) parseHeader(columns []string) *header {
+	h := &header{columns: columns, label: -1}
+	if len(p.rowDefine.label) == 0 {
+		return h
+	}
+	for i, column := range columns {
+		if column == p.rowDefine.label {
+			h.label = i
+			return h
+		}
+	}
+	p.warnf("column %s not found in header: %s", p.rowDefine.label, strings.Join(columns, " "))
+	return nil
+}
+
+// parseHeadDate gets the date from the head line, e.g. 'Linux 3.10.0-1160.el7.x86_64 (host)  08/10/2023  _x86_64_  (4 CPU)'.
+func parseHeadDate(fields []string) (time.Time, bool) {
+	for _, field := range fields[1:] {
+		for _, format := range _headDateFormats {
+			if t, err := time.ParseInLocation(format, field, time.Local); err == nil {
+				return t, true
+			}
+		}
+	}
+	return time.Time{}, false
+}
+
+// parseHeadCPUs gets the number of CPUs from the head line, which ends with '(4 CPU)'.
+func parseHeadCPUs(fields []string) (int, bool) {
+	for i := 1; i < len(fields); i++ {
+		if fields[i] != _cpu_mark || !strings.HasPrefix(fields[i-1], "(") {
+			continue
+		}
+		cpus, err := strconv.Atoi(strings.TrimPrefix(fields[i-1], "("))
+		return cpus, err == nil && cpus > 0
+	}
+	return 0, false
+}
+
+// isHeader reports whether the fields are column names, the data lines always have numbers.
+func isHeader(fields []string) bool {
+	for _, field := range fields {
+		if _, ok := parseNumber(field); ok {
+			return false
+		}
+	}
+	return true
+}
+
+func isPeriod(s string) bool {
+	s = strings.ToUpper(s)
+	return s == PERIOD_AM || s == PERIOD_PM
+}
+
+// toTime converts the clock of 12-hour or 24-hour format to the time of the date.
+func toTime(date, clock time.Time, period string) time.Time {
+	hour := clock.Hour()
+	switch {
+	case period == PERIOD_AM && hour == 12:
+		hour = 0
+	case period == PERIOD_PM && hour < 12:
+		hour += 12
+	}
+	return time.Date(date.Year(), date.Month(), date.Day(), hour, clock.Minute(), clock.Second(), 0, time.Local)
+}
+
+// parseNumber parses the value, the decimal comma of some locales is supported.
+func parseNumber(s string) (float64, bool) {
+	value, err := strconv.ParseFloat(strings.Replace(s, ",", ".", 1), constants.BIT_SIZE_64)
+	return value, err == nil
+}
+
+type cpuRow struct{ CPUUsage }
+
+func (r *cpuRow) set(column string, value float64) {
+	switch column {
+	case "%user", "%usr":
+		r.User = value
+	case "%nice":
+		r.Nice = value
+	case "%system", "%sys":
+		r.System = value
+	case "%iowait":
+		r.IOWait = value
+	case "%steal":
+		r.Steal = value
+	case "%idle":
+		r.Idle = value
+	}
+}
+
+func (r *cpuRow) value() interface{} {
+	r.Usage = mathutil.Round(100-r.Idle, _decimal)
+	return r.CPUUsage
+}
+
+type networkRow struct{ NetworkIO }
+
+func (r *networkRow) set(column string, value float64) {
+	switch column {
+	case "rxpck/s":
+		r.Rxpck = value
+	case "txpck/s":
+		r.Txpck = value
+	case "rxkB/s":
+		r.RxkB = value
+	case "txkB/s":
+		r.TxkB = value
+	case "rxbyt/s":
+		r.RxkB = value / 1024
+	case "txbyt/s":
+		r.TxkB = value / 1024
+	case "rxcmp/s":
+		r.Rxcmp = value
+	case "txcmp/s":
+		r.Txcmp = value
+	case "rxmcst/s":
+		r.Rxmcst = value
+	case "%ifutil":
+		r.Ifutil = value
+	}
+}
+
+func (r *networkRow) value() interface{} { return r.NetworkIO }
+
+type diskRow struct{ DiskIO }
+
+func (r *diskRow) set(column string, value float64) {
+	switch column {
+	case "tps":
+		r.Tps = value
+	case "rd_sec/s":
+		r.RdSec = value
+	case "wr_sec/s":
+		r.WrSec = value
+	case "rkB/s":
+		r.RKBSec = value
+	case "wkB/s":
+		r.WKBSec = value
+	case "dkB/s":
+		r.DKBSec = value
+	case "avgrq-sz":
+		r.AvgrqSz = value
+	case "areq-sz":
+		// areq-sz is in kilobytes while avgrq-sz is in sectors
+		r.AvgrqSz = value * _sectors_per_kb
+	case "avgqu-sz", "aqu-sz":
+		r.AvgquSz = value
+	case "await":
+		r.Await = value
+	case "svctm":
+		r.Svctm = value
+	case "%util":
+		r.Util = value
+	}
+}
+
+// value fills both sectors and kilobytes per second, since sysstat 11 only reports kilobytes
+func (r *diskRow) value() interface{} {
+	switch {
+	case r.RdSec == 0 && r.WrSec == 0:
+		r.RdSec, r.WrSec = r.RKBSec*_sectors_per_kb, r.WKBSec*_sectors_per_kb
+	case r.RKBSec == 0 && r.WKBSec == 0:
+		r.RKBSec, r.WKBSec = r.RdSec/_sectors_per_kb, r.WrSec/_sectors_per_kb
+	}
+	return r.DiskIO
+}
+
+type memoryRow struct{ MemoryUsage }
+
+func (r *memoryRow) set(column string, value float64) {
+	switch column {
+	case "kbmemfree":
+		r.KBMemFree = int64(value)
+	case "kbavail":
+		r.KBAvail = int64(value)
+	case "kbmemused":
+		r.KBmemUsed = int64(value)
+	case "%memused":
+		r.MemUsed = value
+	case "kbbuffers":
+		r.KBBuffers = int64(value)
+	case "kbcached":
+		r.KBCached = int64(value)
+	case "kbcommit":
+		r.KBCommit = int64(value)
+	case "%commit":
+		r.Commit = value
+	case "kbactive":
+		r.KBActive = int64(value)
+	case "kbinact":
+		r.KBInact = int64(value)
+	case "kbdirty":
+		r.KBDirty = int64(value)
+	}
+}
+
+// value calculates the memory really used, the buffers and page cache are not counted.
+// Since sysstat 11.4 kbavail is reported and kbmemused no longer equals total minus free,
+// so the total is derived from %memused and the available memory is used instead.
+func (r *memoryRow) value() interface{} {
+	if r.KBAvail != 0 && r.MemUsed > 0 {
+		total := float64(r.KBmemUsed) * 100 / r.MemUsed
+		r.RealMemUsed = 100 * (1 - float64(r.KBAvail)/total)
+	} else if r.KBMemFree+r.KBmemUsed != 0 {
+		r.RealMemUsed = 100 * (1 - float64(r.KBMemFree+r.KBBuffers+r.KBCached)/float64(r.KBMemFree+r.KBmemUsed))
+	}
+	return r.MemoryUsage
+}
+
+type loadRow struct {
+	LoadAverage
+	cpus int
+}
+
+func (r *loadRow) set(column string, value float64) {
+	switch column {
+	case "runq-sz":
+		r.RunqSz = value
+	case "plist-sz":
+		r.PlistSz = value
+	case "ldavg-1":
+		r.Ldavg1 = value
+	case "ldavg-5":
+		r.Ldavg5 = value
+	case "ldavg-15":
+		r.Ldavg15 = value
+	case "blocked":
+		r.Blocked = value
+	}
+}
+
+func (r *loadRow) setCPUs(cpus int) { r.cpus = cpus }
+
+func (r *loadRow) value() interface{} {
+	if r.cpus > 0 {
+		r.Ldavg1PerCore = mathutil.Round(r.Ldavg1/float64(r.cpus), _decimal)
+	}
+	return r.LoadAverage
+}
+
+type pagingRow struct{ Paging }
+
+func (r *pagingRow) set(column string, value float64) {
+	switch column {
+	case "pgpgin/s":
+		r.Pgpgin = value
+	case "pgpgout/s":
+		r.Pgpgout = value
+	case "fault/s":
+		r.Fault = value
+	case "majflt/s":
+		r.Majflt = value
+	case "pgfree/s":
+		r.Pgfree = value
+	case "pgscank/s":
+		r.Pgscank = value
+	case "pgscand/s":
+		r.Pgscand = value
+	case "pgsteal/s":
+		r.Pgsteal = value
+	case "%vmeff":
+		r.Vmeff = value
+	}
+}
+
+func (r *pagingRow) value() interface{} { return r.Paging }
+
+type taskRow struct{ TaskSwitch }
+
+func (r *taskRow) set(column string, value float64) {
+	switch column {
+	case "proc/s":
+		r.Proc = value
+	case "cswch/s":
+		r.Cswch = value
+	}
+}
+
+func (r *taskRow) value() interface{} { return r.TaskSwitch }
+
+type swapRow struct{ Swapping }
+
+func (r *swapRow) set(column string, value float64) {
+	switch column {
+	case "pswpin/s":
+		r.Pswpin = value
+	case "pswpout/s":
+		r.Pswpout = value
+	}
+}
+
+func (r *swapRow) value() interface{} { return r.Swapping }
+
+type networkErrorRow struct{ NetworkError }
+
+func (r *networkErrorRow) set(column string, value float64) {
+	switch column {
+	case "rxerr/s":
+		r.Rxerr = value
+	case "txerr/s":
+		r.Txerr = value
+	case "coll/s":
+		r.Coll = value
+	case "rxdrop/s":
+		r.Rxdrop = value
+	case "txdrop/s":
+		r.Txdrop = value
+	case "txcarr/s":
+		r.Txcarr = value
+	case "rxfram/s":
+		r.Rxfram = value
+	case "rxfifo/s":
+		r.Rxfifo = value
+	case "txfifo/s":
+		r.Txfifo = value
+	}
+}
+
+func (r *networkErrorRow) value() interface{} { return r.NetworkError }
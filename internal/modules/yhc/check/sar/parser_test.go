@@ -0,0 +1,216 @@
+package sar_test
+
+import (
+	"math"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"yhc/internal/modules/yhc/check/define"
+	"yhc/internal/modules/yhc/check/sar"
+)
+
+func parseFixture(t *testing.T, distro string, workloadType define.WorkloadType) define.WorkloadOutput {
+	t.Helper()
+	content, err := os.ReadFile(path.Join("testdata", distro, string(workloadType)+".txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	parser, err := sar.NewParser(workloadType, t.Logf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return parser.Parse(string(content))
+}
+
+func at(year int, month time.Month, day, hour, min, sec int) int64 {
+	return time.Date(year, month, day, hour, min, sec, 0, time.Local).Unix()
+}
+
+func near(a, b float64) bool {
+	return math.Abs(a-b) < 0.01
+}
+
+func TestParseCPU(t *testing.T) {
+	cases := []struct {
+		distro string
+		times  []int64
+		user   float64
+		idle   float64
+	}{
+		{"centos6", []int64{at(2023, 8, 10, 12, 0, 1), at(2023, 8, 10, 12, 10, 1)}, 1.25, 98.15},
+		// the restart line and the repeated header are skipped
+		{"centos7", []int64{at(2023, 8, 10, 0, 10, 1), at(2023, 8, 10, 0, 30, 1)}, 5, 92},
+		// sysstat 11 prints the 12-hour times with the en_US locale
+		{"ubuntu18", []int64{at(2023, 8, 10, 13, 10, 1), at(2023, 8, 10, 13, 20, 1)}, 7.5, 89.5},
+		{"ubuntu20", []int64{at(2023, 8, 10, 13, 10, 1), at(2023, 8, 10, 13, 20, 1)}, 3, 96},
+		// the time after midnight belongs to the next day
+		{"kylin10", []int64{at(2023, 8, 10, 23, 59, 55), at(2023, 8, 11, 0, 0, 0), at(2023, 8, 11, 0, 0, 5)}, 20, 74},
+		{"debian12", []int64{at(2023, 8, 10, 8, 10, 1)}, 1.5, 97.75},
+	}
+	for _, c := range cases {
+		output := parseFixture(t, c.distro, define.WT_CPU)
+		if len(output) != len(c.times) {
+			t.Fatalf("%s: expected %d times, got %d", c.distro, len(c.times), len(output))
+		}
+		for _, timestamp := range c.times {
+			if _, ok := output[timestamp]; !ok {
+				t.Fatalf("%s: time %s not found", c.distro, time.Unix(timestamp, 0))
+			}
+		}
+		usage, ok := output[c.times[0]]["all"].(sar.CPUUsage)
+		if !ok || usage.CPU != "all" || !near(usage.User, c.user) || !near(usage.Idle, c.idle) {
+			t.Fatalf("%s: unexpected cpu usage: %+v", c.distro, output[c.times[0]]["all"])
+		}
+	}
+}
+
+func TestParseMemory(t *testing.T) {
+	cases := []struct {
+		distro      string
+		time        int64
+		memUsed     float64
+		realMemUsed float64
+	}{
+		{"centos6", at(2023, 8, 10, 12, 0, 1), 75, 50},
+		{"centos7", at(2023, 8, 10, 0, 10, 1), 87.5, 50},
+		// sysstat 11 reports kbavail while kbmemused still includes the caches
+		{"ubuntu18", at(2023, 8, 10, 13, 10, 1), 87.5, 25},
+		// kbmemused excludes the caches since kbavail is reported
+		{"ubuntu20", at(2023, 8, 10, 13, 10, 1), 25, 25},
+		{"kylin10", at(2023, 8, 10, 23, 59, 55), 40, 50},
+		{"debian12", at(2023, 8, 10, 8, 10, 1), 25, 25},
+	}
+	for _, c := range cases {
+		output := parseFixture(t, c.distro, define.WT_MEMORY)
+		usage, ok := output[c.time]["memory"].(sar.MemoryUsage)
+		if !ok || !near(usage.MemUsed, c.memUsed) || !near(usage.RealMemUsed, c.realMemUsed) {
+			t.Fatalf("%s: unexpected memory usage: %+v", c.distro, output[c.time])
+		}
+	}
+}
+
+func TestParseDisk(t *testing.T) {
+	cases := []struct {
+		distro string
+		time   int64
+		dev    string
+		expect sar.DiskIO
+	}{
+		{"centos6", at(2023, 8, 10, 12, 0, 1), "dev8-0",
+			sar.DiskIO{Dev: "dev8-0", Tps: 10, RdSec: 200, WrSec: 400, RKBSec: 100, WKBSec: 200, AvgrqSz: 60, AvgquSz: 0.05, Await: 5, Svctm: 1, Util: 1}},
+		{"centos7", at(2023, 8, 10, 0, 10, 1), "dev253-0",
+			sar.DiskIO{Dev: "dev253-0", Tps: 50, RdSec: 1024, WrSec: 2048, RKBSec: 512, WKBSec: 1024, AvgrqSz: 61.44, AvgquSz: 0.2, Await: 4, Svctm: 2, Util: 10}},
+		{"ubuntu18", at(2023, 8, 10, 13, 10, 1), "dev8-0",
+			sar.DiskIO{Dev: "dev8-0", Tps: 30, RdSec: 600, WrSec: 1400, RKBSec: 300, WKBSec: 700, AvgrqSz: 66.67, AvgquSz: 0.15, Await: 5, Svctm: 2, Util: 6}},
+		{"ubuntu20", at(2023, 8, 10, 13, 10, 1), "dev8-0",
+			sar.DiskIO{Dev: "dev8-0", Tps: 20, RdSec: 200, WrSec: 600, RKBSec: 100, WKBSec: 300, AvgrqSz: 40, AvgquSz: 0.1, Await: 3, Svctm: 1.5, Util: 3}},
+		{"kylin10", at(2023, 8, 10, 23, 59, 55), "dev259-0",
+			sar.DiskIO{Dev: "dev259-0", Tps: 300, RdSec: 4096, WrSec: 8192, RKBSec: 2048, WKBSec: 4096, AvgrqSz: 40.96, AvgquSz: 0.5, Await: 1, Util: 30}},
+		// the DEV column is the last one with --pretty
+		{"debian12", at(2023, 8, 10, 8, 10, 1), "vda",
+			sar.DiskIO{Dev: "vda", Tps: 5, RdSec: 20, WrSec: 40, RKBSec: 10, WKBSec: 20, AvgrqSz: 12, AvgquSz: 0.01, Await: 2, Util: 0.5}},
+	}
+	for _, c := range cases {
+		output := parseFixture(t, c.distro, define.WT_DISK)
+		disk, ok := output[c.time][c.dev].(sar.DiskIO)
+		if !ok || disk != c.expect {
+			t.Fatalf("%s: expected %+v, got %+v", c.distro, c.expect, output[c.time][c.dev])
+		}
+	}
+	if output := parseFixture(t, "centos6", define.WT_DISK); len(output[at(2023, 8, 10, 12, 0, 1)]) != 2 {
+		t.Fatalf("unexpected disks: %+v", output)
+	}
+}
+
+func TestParseNetwork(t *testing.T) {
+	cases := []struct {
+		distro string
+		time   int64
+		iface  string
+		rxkB   float64
+		ifutil float64
+	}{
+		{"centos6", at(2023, 8, 10, 12, 0, 1), "eth0", 3, 0},
+		{"centos7", at(2023, 8, 10, 0, 10, 1), "ens33", 12, 0},
+		{"ubuntu18", at(2023, 8, 10, 13, 10, 1), "enp0s3", 2.5, 0.02},
+		{"ubuntu20", at(2023, 8, 10, 13, 10, 1), "ens3", 6, 0.01},
+		{"kylin10", at(2023, 8, 10, 23, 59, 55), "enp1s0", 120, 0.1},
+		{"debian12", at(2023, 8, 10, 8, 10, 1), "enp0s3", 0.5, 0},
+	}
+	for _, c := range cases {
+		output := parseFixture(t, c.distro, define.WT_NETWORK)
+		network, ok := output[c.time][c.iface].(sar.NetworkIO)
+		if !ok || network.Iface != c.iface || !near(network.RxkB, c.rxkB) || !near(network.Ifutil, c.ifutil) {
+			t.Fatalf("%s: unexpected network io: %+v", c.distro, output[c.time])
+		}
+	}
+}
+
+func TestParseSystemActivity(t *testing.T) {
+	cases := []struct {
+		distro  string
+		time    int64
+		load    sar.LoadAverage
+		paging  sar.Paging
+		task    sar.TaskSwitch
+		swap    sar.Swapping
+		netErr  sar.NetworkError
+		netErrs int
+	}{
+		// the load per core is calculated by the CPUs of the head line
+		{"centos7", at(2023, 8, 10, 0, 10, 1),
+			sar.LoadAverage{RunqSz: 2, PlistSz: 350, Ldavg1: 6, Ldavg5: 5, Ldavg15: 4, Ldavg1PerCore: 1.5},
+			sar.Paging{Pgpgin: 12, Pgpgout: 340, Fault: 1500, Majflt: 2.5, Pgfree: 2000},
+			sar.TaskSwitch{Proc: 3.5, Cswch: 4200},
+			sar.Swapping{Pswpout: 1.25},
+			sar.NetworkError{Iface: "ens33", Rxerr: 0.5, Rxdrop: 1}, 2},
+		{"ubuntu20", at(2023, 8, 10, 13, 10, 1),
+			sar.LoadAverage{RunqSz: 1, PlistSz: 812, Ldavg1: 2, Ldavg5: 1.5, Ldavg15: 1, Blocked: 1, Ldavg1PerCore: 0.25},
+			sar.Paging{Pgpgin: 4, Pgpgout: 80, Fault: 900, Majflt: 0.1, Pgfree: 1200, Pgscank: 100, Pgsteal: 80, Vmeff: 80},
+			sar.TaskSwitch{Proc: 1, Cswch: 900},
+			sar.Swapping{},
+			sar.NetworkError{Iface: "ens3", Rxdrop: 0.2}, 1},
+	}
+	for _, c := range cases {
+		if output := parseFixture(t, c.distro, define.WT_LOAD); len(output) != 1 || output[c.time]["load"] != c.load {
+			t.Fatalf("%s: expected %+v, got %+v", c.distro, c.load, output)
+		}
+		if output := parseFixture(t, c.distro, define.WT_PAGING); output[c.time]["paging"] != c.paging {
+			t.Fatalf("%s: expected %+v, got %+v", c.distro, c.paging, output)
+		}
+		if output := parseFixture(t, c.distro, define.WT_TASK); output[c.time]["task"] != c.task {
+			t.Fatalf("%s: expected %+v, got %+v", c.distro, c.task, output)
+		}
+		if output := parseFixture(t, c.distro, define.WT_SWAP); output[c.time]["swap"] != c.swap {
+			t.Fatalf("%s: expected %+v, got %+v", c.distro, c.swap, output)
+		}
+		output := parseFixture(t, c.distro, define.WT_NETWORK_ERROR)
+		if len(output[c.time]) != c.netErrs || output[c.time][c.netErr.Iface] != c.netErr {
+			t.Fatalf("%s: expected %+v, got %+v", c.distro, c.netErr, output)
+		}
+	}
+}
+
+func TestParseCPUCore(t *testing.T) {
+	cases := []struct {
+		distro string
+		time   int64
+		cores  int
+		core   sar.CPUUsage
+	}{
+		{"centos7", at(2023, 8, 10, 0, 20, 1), 5, sar.CPUUsage{CPU: "0", User: 90, System: 6, Idle: 4, Usage: 96}},
+		{"ubuntu20", at(2023, 8, 10, 13, 10, 1), 3, sar.CPUUsage{CPU: "1", User: 2, System: 1, Idle: 97, Usage: 3}},
+	}
+	for _, c := range cases {
+		// the rows of all CPUs are kept beside the cores
+		output := parseFixture(t, c.distro, define.WT_CPU_CORE)
+		if len(output[c.time]) != c.cores || output[c.time]["all"] == nil {
+			t.Fatalf("%s: unexpected cores: %+v", c.distro, output)
+		}
+		if core := output[c.time][c.core.CPU]; core != c.core {
+			t.Fatalf("%s: expected %+v, got %+v", c.distro, c.core, core)
+		}
+	}
+}
@@ -1,22 +1,23 @@
 package sar
 
 import (
+	"bufio"
 	"errors"
 	"fmt"
+	"os"
 	"reflect"
 	"strings"
-	"time"
 
 	"yhc/defs/bashdef"
+	"yhc/defs/regexpdef"
 	"yhc/defs/runtimedef"
-	"yhc/defs/timedef"
 	"yhc/internal/modules/yhc/check/define"
 	"yhc/utils/execerutil"
 	"yhc/utils/osutil"
 	"yhc/utils/stringutil"
-	"yhc/utils/timeutil"
 
 	"git.yasdb.com/go/yaslog"
+	"git.yasdb.com/go/yasutil/fs"
 )
 
 const (
@@ -24,33 +25,61 @@ const (
 )
 
 type Sar struct {
-	log    yaslog.YasLog
-	parser SarParser
+	log yaslog.YasLog
 }
 
 func NewSar(yaslog yaslog.YasLog) *Sar {
 	return &Sar{
-		log:    yaslog,
-		parser: getParser(yaslog),
+		log: yaslog,
 	}
 }
 
-func getParser(yaslog yaslog.YasLog) SarParser {
-	os := runtimedef.GetOSRelease()
-	switch os.Id {
-	case osutil.KYLIN_ID:
-		return NewKylinParser(yaslog)
-	case osutil.UBUNTU_ID:
-		return NewUbuntuParser(yaslog)
-	case osutil.CENTOS_ID:
-		return NewCentosParser(yaslog)
-	default:
-		return NewBaseParser(yaslog)
+// GetSarDir returns the directory of the daily sar files, SAR_DIR of the sysstat config is preferred.
+func (s *Sar) GetSarDir() string {
+	configPath, sarDir := SYSCONFIG_SYSSTAT, DEFAULT_SAR_DIR
+	if runtimedef.GetOSRelease().Id == osutil.UBUNTU_ID {
+		configPath, sarDir = DEBIAN_SYSSTAT, DEBIAN_SAR_DIR
+	}
+	if dir := s.getSarDirFromConfig(configPath); !stringutil.IsEmpty(dir) {
+		return dir
 	}
+	return sarDir
 }
 
-func (s *Sar) GetSarDir() string {
-	return s.parser.GetSarDir()
+func (s *Sar) getSarDirFromConfig(configPath string) string {
+	if !fs.IsFileExist(configPath) {
+		return ""
+	}
+	configMap := make(map[string]string)
+	file, err := os.Open(configPath)
+	if err != nil {
+		s.log.Error(err)
+		return ""
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, stringutil.STR_HASH) {
+			// ignore line start with '#'
+			continue
+		}
+		// key=value
+		re := regexpdef.KeyValueRegexp
+		match := re.FindStringSubmatch(line)
+		if len(match) == 3 {
+			key := strings.TrimSpace(match[1])
+			value := strings.TrimSpace(match[2])
+			configMap[key] = value
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		s.log.Error(err)
+		return ""
+	}
+	return configMap["SAR_DIR"]
 }
 
 func (s *Sar) Collect(t define.WorkloadType, args ...string) (define.WorkloadOutput, error) {
@@ -64,10 +93,12 @@ func (s *Sar) Collect(t define.WorkloadType, args ...string) (define.WorkloadOut
 		err := errors.New(stderr)
 		return res, err
 	}
-	parseFunc, checkTitleFunc := s.parser.GetParserFunc(t)
-	res = s.parseSarOutput(stdout, parseFunc, checkTitleFunc)
+	parser, err := NewParser(t, s.log.Warnf)
+	if err != nil {
+		return res, err
+	}
+	res = parser.Parse(stdout)
 	if t == define.WT_DISK { // transfer Dev name
-		var err error
 		res, err = s.transferDiskOutput(res)
 		if err != nil {
 			err := errors.New(stderr)
@@ -121,8 +152,8 @@ func (s *Sar) transferDiskOutput(output define.WorkloadOutput) (define.WorkloadO
 			}
 			devName, ok := m[devNum] // get dev name
 			if !ok {
-				s.log.Errorf("can not find dev name by dev number: %s, skip it", devNum)
-				continue
+				// the dev name is printed by sar -p or --pretty
+				devName = devNum
 			}
 			disk.Dev = devName
 			newItem[devName] = disk
@@ -131,73 +162,3 @@ func (s *Sar) transferDiskOutput(output define.WorkloadOutput) (define.WorkloadO
 	}
 	return output, nil
 }
-
-// example: Linux 3.10.0-1160.el7.x86_64 (mg_4)     08/10/2023      _x86_64_        (4 CPU)
-func (s *Sar) getDateFromHeadLine(line string) string {
-	currentDate := time.Now().Format(timedef.TIME_FORMAT_DATE)
-	line = stringutil.RemoveExtraSpaces(strings.TrimSpace(line))
-	values := strings.Split(line, stringutil.STR_BLANK_SPACE)
-	if len(values) < 4 { // third item is the data
-		s.log.Error("invalid head line: %s, could not get date from the line", line)
-		return currentDate
-	}
-	arr := strings.Split(values[3], "/")
-	// the third item is the date
-	if len(arr) != 3 {
-		s.log.Errorf("invalid date str: %s, could not get real date from the date str", values[3])
-		return currentDate
-	}
-	return fmt.Sprintf("%s-%s-%s", arr[2], arr[0], arr[1])
-}
-
-// getSarTimestamp get timestamp from sar time output like '11:52:42 AM' and data like '2023:08:08'
-func (s *Sar) getSarTimestamp(date string, timeStr string, dayPeriod string) (int64, error) {
-	t, err := time.ParseInLocation(timedef.TIME_FORMAT, fmt.Sprintf("%s %s", date, timeStr), time.Local)
-	if err != nil {
-		s.log.Error(err)
-		return 0, err
-	}
-	if dayPeriod == timedef.DAY_PERIOD_AM && t.Hour() >= int(timeutil.NOON/time.Hour) {
-		t = t.Add(-timeutil.NOON)
-	}
-	if dayPeriod == timedef.DAY_PERIOD_PM && t.Hour() < int(timeutil.NOON/time.Hour) {
-		t = t.Add(timeutil.NOON)
-	}
-	return t.Unix(), nil
-}
-
-func (s *Sar) parseSarOutput(output string, parseFunc SarParseFunc, checkTitleFunc SarCheckTitleFunc) define.WorkloadOutput {
-	date := time.Now().Format(timedef.TIME_FORMAT_DATE)
-	res := make(define.WorkloadOutput)
-	output = strings.ReplaceAll(output, "\n\n", stringutil.STR_NEWLINE)
-	lines := strings.Split(output, stringutil.STR_NEWLINE)
-	for i := 0; i < len(lines); i++ {
-		line := stringutil.RemoveExtraSpaces(strings.TrimSpace(lines[i]))
-		if strings.HasPrefix(line, PREFIX_LINUX) { // get data from head line
-			date = s.getDateFromHeadLine(line)
-			continue
-		}
-		// ignore the empty line, Average line and title line
-		if stringutil.IsEmpty(line) || strings.HasPrefix(line, PREFIX_AVERAGE) || checkTitleFunc(line) {
-			continue
-		}
-		values := strings.Split(line, stringutil.STR_BLANK_SPACE)
-		if len(values) < 2 { // not enough data, skip
-			s.log.Warnf("not enough data, skip line: %s", line)
-			continue
-		}
-		// get time
-		timestamp, err := s.getSarTimestamp(date, values[0], values[1])
-		if err != nil {
-			s.log.Error(err)
-			continue
-		}
-		// get data map
-		m, ok := res[timestamp]
-		if !ok {
-			m = make(define.WorkloadItem)
-		}
-		res[timestamp] = parseFunc(m, values)
-	}
-	return res
-}
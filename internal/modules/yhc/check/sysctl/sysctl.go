@@ -0,0 +1,119 @@
+// The sysctl package reads kernel parameters from /proc/sys and compares them to the YashanDB baseline.
+package sysctl
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+
+	"yhc/defs/confdef"
+)
+
+const (
+	PROC_SYS = "/proc/sys"
+
+	_kb = 1024
+)
+
+// Read returns the value of the kernel parameter name, such as 'vm.swappiness', under root,
+// fields of the value are joined with a single space.
+func Read(root, name string) (string, error) {
+	data, err := os.ReadFile(path.Join(root, strings.ReplaceAll(name, ".", "/")))
+	if err != nil {
+		return "", err
+	}
+	return strings.Join(strings.Fields(string(data)), " "), nil
+}
+
+// Recommend returns the recommended value of the parameter on the host with memTotal bytes of memory.
+func Recommend(p *confdef.SysctlParameter, memTotal uint64, pageSize int) string {
+	if p.MemoryRatio <= 0 {
+		return strings.Join(strings.Fields(p.Value), " ")
+	}
+	value := int64(p.MemoryRatio * float64(memTotal))
+	switch p.Unit {
+	case confdef.SYSCTL_UNIT_KBYTES:
+		value /= _kb
+	case confdef.SYSCTL_UNIT_PAGES:
+		if pageSize > 0 {
+			value /= int64(pageSize)
+		}
+	}
+	if p.Min > 0 && value < p.Min {
+		value = p.Min
+	}
+	if p.Max > 0 && value > p.Max {
+		value = p.Max
+	}
+	return strconv.FormatInt(value, 10)
+}
+
+// Compare returns whether the current value meets the recommended value.
+func Compare(compare, current, recommended string) (bool, error) {
+	currents, recommends := strings.Fields(current), strings.Fields(recommended)
+	if len(currents) != len(recommends) {
+		return false, fmt.Errorf("the fields of current value %q and recommended value %q are mismatched", current, recommended)
+	}
+	if compare == confdef.SYSCTL_COMPARE_RANGE {
+		return compareRange(currents, recommends)
+	}
+	for i := range currents {
+		ok, err := compareField(compare, currents[i], recommends[i])
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// Format returns the recommended value with the compare operator, such as '>= 1048576'.
+func Format(compare, recommended string) string {
+	switch compare {
+	case confdef.SYSCTL_COMPARE_GE:
+		return ">= " + recommended
+	case confdef.SYSCTL_COMPARE_LE:
+		return "<= " + recommended
+	case confdef.SYSCTL_COMPARE_RANGE:
+		return fmt.Sprintf("[%s]", strings.Join(strings.Fields(recommended), ", "))
+	default:
+		return recommended
+	}
+}
+
+func compareRange(currents, recommends []string) (bool, error) {
+	if len(currents) != 2 {
+		return false, fmt.Errorf("range needs 2 fields, got %d", len(currents))
+	}
+	low, err := compareField(confdef.SYSCTL_COMPARE_LE, currents[0], recommends[0])
+	if err != nil {
+		return false, err
+	}
+	high, err := compareField(confdef.SYSCTL_COMPARE_GE, currents[1], recommends[1])
+	if err != nil {
+		return false, err
+	}
+	return low && high, nil
+}
+
+func compareField(compare, current, recommended string) (bool, error) {
+	cur, curErr := strconv.ParseInt(current, 10, 64)
+	rec, recErr := strconv.ParseInt(recommended, 10, 64)
+	if curErr != nil || recErr != nil {
+		if compare == confdef.SYSCTL_COMPARE_EQ {
+			return current == recommended, nil
+		}
+		return false, fmt.Errorf("%s or %s is not an integer", current, recommended)
+	}
+	switch compare {
+	case confdef.SYSCTL_COMPARE_EQ:
+		return cur == rec, nil
+	case confdef.SYSCTL_COMPARE_GE:
+		return cur >= rec, nil
+	case confdef.SYSCTL_COMPARE_LE:
+		return cur <= rec, nil
+	default:
+		return false, fmt.Errorf("invalid compare %s", compare)
+	}
+}
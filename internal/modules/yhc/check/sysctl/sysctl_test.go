@@ -0,0 +1,67 @@
+package sysctl_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"yhc/defs/confdef"
+	"yhc/internal/modules/yhc/check/sysctl"
+)
+
+func TestRead(t *testing.T) {
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "net", "ipv4"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "net", "ipv4", "ip_local_port_range"), []byte("32768\t60999\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	value, err := sysctl.Read(root, "net.ipv4.ip_local_port_range")
+	if err != nil || value != "32768 60999" {
+		t.Fatalf("unexpected value %q, err: %v", value, err)
+	}
+}
+
+func TestRecommend(t *testing.T) {
+	const memTotal = 64 << 30
+	cases := []struct {
+		param    *confdef.SysctlParameter
+		expected string
+	}{
+		{&confdef.SysctlParameter{Value: "250  32000 100 128"}, "250 32000 100 128"},
+		{&confdef.SysctlParameter{MemoryRatio: 0.5, Unit: confdef.SYSCTL_UNIT_BYTES}, "34359738368"},
+		{&confdef.SysctlParameter{MemoryRatio: 0.5, Unit: confdef.SYSCTL_UNIT_PAGES}, "8388608"},
+		{&confdef.SysctlParameter{MemoryRatio: 0.004, Unit: confdef.SYSCTL_UNIT_KBYTES, Min: 65536, Max: 262144}, "262144"},
+		{&confdef.SysctlParameter{MemoryRatio: 0.0001, Unit: confdef.SYSCTL_UNIT_KBYTES, Min: 65536}, "65536"},
+	}
+	for _, c := range cases {
+		if res := sysctl.Recommend(c.param, memTotal, 4096); res != c.expected {
+			t.Errorf("expected %s, got %s", c.expected, res)
+		}
+	}
+}
+
+func TestCompare(t *testing.T) {
+	cases := []struct {
+		compare, current, recommended string
+		expected                      bool
+	}{
+		{confdef.SYSCTL_COMPARE_LE, "60", "10", false},
+		{confdef.SYSCTL_COMPARE_LE, "10", "10", true},
+		{confdef.SYSCTL_COMPARE_GE, "250 32000 100 128", "250 32000 100 128", true},
+		{confdef.SYSCTL_COMPARE_GE, "250 32000 32 128", "250 32000 100 128", false},
+		{confdef.SYSCTL_COMPARE_EQ, "0", "0", true},
+		{confdef.SYSCTL_COMPARE_RANGE, "9000 65500", "9000 65500", true},
+		{confdef.SYSCTL_COMPARE_RANGE, "32768 60999", "9000 65500", false},
+	}
+	for _, c := range cases {
+		res, err := sysctl.Compare(c.compare, c.current, c.recommended)
+		if err != nil || res != c.expected {
+			t.Errorf("%s %s %s: expected %v, got %v, err: %v", c.current, c.compare, c.recommended, c.expected, res, err)
+		}
+	}
+	if _, err := sysctl.Compare(confdef.SYSCTL_COMPARE_GE, "1 2", "1"); err == nil {
+		t.Error("expected error of mismatched fields")
+	}
+}
@@ -0,0 +1,156 @@
+// The timeline package lays the workload series of the host and the database on a shared time axis,
+// and aligns the log events, such as run.log errors, alert.log raises, kernel events and slow SQL, to the axis.
+package timeline
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"yhc/defs/regexpdef"
+	"yhc/defs/timedef"
+	"yhc/internal/modules/yhc/check/wlstats"
+)
+
+const (
+	SOURCE_RUN_LOG   = "run.log"
+	SOURCE_ALERT_LOG = "alert.log"
+	SOURCE_KERNEL    = "kernel"
+	SOURCE_SLOW_SQL  = "slow_sql"
+
+	UNIT_PERCENT     = "%"
+	UNIT_MILLISECOND = "ms"
+)
+
+// Line is a series drawn on the timeline, keyed by the timestamp in seconds.
+type Line struct {
+	Name   string
+	Unit   string
+	Values map[int64]float64
+}
+
+// Event is a log event at a timestamp in seconds.
+type Event struct {
+	Time    int64
+	Source  string
+	Message string
+}
+
+// Timeline is the lines and the events on the axis of all timestamps of the lines.
+type Timeline struct {
+	Times  []int64
+	Lines  []*Line
+	Events []Event
+}
+
+// NewLine merges the series to a line, the value at a timestamp is the max of the series,
+// such as the utilization of the busiest disk.
+func NewLine(name, unit string, series ...*wlstats.Series) *Line {
+	line := &Line{Name: name, Unit: unit, Values: make(map[int64]float64)}
+	for _, s := range series {
+		for _, p := range s.Points {
+			if v, ok := line.Values[p.Time]; !ok || p.Value > v {
+				line.Values[p.Time] = p.Value
+			}
+		}
+	}
+	return line
+}
+
+// New builds the timeline, the empty lines are dropped and the events are ordered by time.
+func New(lines []*Line, events []Event) *Timeline {
+	t := &Timeline{}
+	times := make(map[int64]struct{})
+	for _, line := range lines {
+		if len(line.Values) == 0 {
+			continue
+		}
+		t.Lines = append(t.Lines, line)
+		for timestamp := range line.Values {
+			times[timestamp] = struct{}{}
+		}
+	}
+	for timestamp := range times {
+		t.Times = append(t.Times, timestamp)
+	}
+	sort.Slice(t.Times, func(i, j int) bool { return t.Times[i] < t.Times[j] })
+	t.Events = append(t.Events, events...)
+	sort.SliceStable(t.Events, func(i, j int) bool { return t.Events[i].Time < t.Events[j].Time })
+	return t
+}
+
+// Empty reports whether there is nothing to draw.
+func (t *Timeline) Empty() bool {
+	return len(t.Lines) == 0 && len(t.Events) == 0
+}
+
+// Units returns the units of the lines in the order of appearance, one axis per unit.
+func (t *Timeline) Units() []string {
+	var units []string
+	seen := make(map[string]bool)
+	for _, line := range t.Lines {
+		if !seen[line.Unit] {
+			seen[line.Unit] = true
+			units = append(units, line.Unit)
+		}
+	}
+	return units
+}
+
+// Align groups the events by the nearest timestamp of the axis, the events out of the axis are skipped.
+// The events are out of the axis if they are earlier than the first timestamp or later than the last timestamp
+// by more than half of the average interval.
+func (t *Timeline) Align() map[int64][]Event {
+	res := make(map[int64][]Event)
+	if len(t.Times) == 0 {
+		return res
+	}
+	first, last := t.Times[0], t.Times[len(t.Times)-1]
+	var margin int64
+	if len(t.Times) > 1 {
+		margin = (last - first) / int64(len(t.Times)-1) / 2
+	}
+	for _, event := range t.Events {
+		if event.Time < first-margin || event.Time > last+margin {
+			continue
+		}
+		nearest := t.nearest(event.Time)
+		res[nearest] = append(res[nearest], event)
+	}
+	return res
+}
+
+func (t *Timeline) nearest(timestamp int64) int64 {
+	i := sort.Search(len(t.Times), func(i int) bool { return t.Times[i] >= timestamp })
+	if i == len(t.Times) {
+		return t.Times[i-1]
+	}
+	if i == 0 || t.Times[i]-timestamp < timestamp-t.Times[i-1] {
+		return t.Times[i]
+	}
+	return t.Times[i-1]
+}
+
+// Value returns the value of the line at the timestamp, false if the line has no sample at the time.
+func (l *Line) Value(timestamp int64) (float64, bool) {
+	v, ok := l.Values[timestamp]
+	return v, ok
+}
+
+// ParseYasdbLog converts the lines of run.log or alert.log to the events, the lines without time are skipped,
+// such as the hint of no error.
+func ParseYasdbLog(source string, lines []string) []Event {
+	var res []Event
+	for _, line := range lines {
+		match := regexpdef.YasdbLogTimeRegex.FindStringSubmatch(strings.TrimSpace(line))
+		if len(match) < 2 {
+			continue
+		}
+		t, err := time.ParseInLocation(timedef.TIME_FORMAT_WITH_MICROSECOND, match[1], time.Local)
+		if err != nil {
+			continue
+		}
+		res = append(res, Event{Time: t.Unix(), Source: source, Message: strings.TrimSpace(line)})
+	}
+	return res
+}
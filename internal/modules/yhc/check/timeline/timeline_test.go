@@ -0,0 +1,69 @@
+package timeline_test
+
+import (
+	"testing"
+	"time"
+
+	"yhc/internal/modules/yhc/check/timeline"
+	"yhc/internal/modules/yhc/check/wlstats"
+)
+
+func TestNewLine(t *testing.T) {
+	sda := &wlstats.Series{Item: "sda", Field: "util", Points: []wlstats.Point{{Time: 0, Value: 10}, {Time: 600, Value: 90}}}
+	sdb := &wlstats.Series{Item: "sdb", Field: "util", Points: []wlstats.Point{{Time: 0, Value: 50}, {Time: 1200, Value: 20}}}
+	line := timeline.NewLine("disk", timeline.UNIT_PERCENT, sda, sdb)
+	expected := map[int64]float64{0: 50, 600: 90, 1200: 20}
+	for timestamp, value := range expected {
+		if v, ok := line.Value(timestamp); !ok || v != value {
+			t.Fatalf("expected %v at %d, got %v", value, timestamp, v)
+		}
+	}
+	if _, ok := line.Value(1800); ok {
+		t.Fatal("unexpected value at 1800")
+	}
+}
+
+func TestAlign(t *testing.T) {
+	cpu := &timeline.Line{Name: "cpu", Unit: timeline.UNIT_PERCENT, Values: map[int64]float64{600: 1, 0: 1, 1200: 1}}
+	dbTime := &timeline.Line{Name: "db time", Unit: timeline.UNIT_MILLISECOND, Values: map[int64]float64{900: 1}}
+	empty := &timeline.Line{Name: "empty", Unit: timeline.UNIT_PERCENT, Values: map[int64]float64{}}
+	events := []timeline.Event{
+		{Time: 1000, Source: timeline.SOURCE_SLOW_SQL},
+		{Time: 100, Source: timeline.SOURCE_RUN_LOG},
+		{Time: 700, Source: timeline.SOURCE_ALERT_LOG},
+		{Time: -500, Source: timeline.SOURCE_KERNEL},
+		{Time: 1300, Source: timeline.SOURCE_KERNEL},
+	}
+	tl := timeline.New([]*timeline.Line{cpu, empty, dbTime}, events)
+	if len(tl.Lines) != 2 || len(tl.Times) != 4 || tl.Times[0] != 0 || tl.Times[3] != 1200 {
+		t.Fatalf("unexpected timeline: %+v", tl)
+	}
+	if units := tl.Units(); len(units) != 2 || units[0] != timeline.UNIT_PERCENT || units[1] != timeline.UNIT_MILLISECOND {
+		t.Fatalf("unexpected units: %v", units)
+	}
+	if tl.Events[0].Source != timeline.SOURCE_KERNEL || tl.Events[1].Source != timeline.SOURCE_RUN_LOG {
+		t.Fatalf("events are not ordered: %+v", tl.Events)
+	}
+	marks := tl.Align()
+	if len(marks[0]) != 1 || len(marks[600]) != 1 || len(marks[900]) != 1 || len(marks[1200]) != 1 {
+		t.Fatalf("unexpected marks: %+v", marks)
+	}
+	if marks[1200][0].Source != timeline.SOURCE_KERNEL {
+		t.Fatalf("the event within half of the interval after the axis should be marked: %+v", marks)
+	}
+}
+
+func TestParseYasdbLog(t *testing.T) {
+	lines := []string{
+		"2024-01-02 15:04:05.123 1234 [ERROR] [DB] errno 3001, something failed",
+		"no obvious error",
+	}
+	events := timeline.ParseYasdbLog(timeline.SOURCE_RUN_LOG, lines)
+	if len(events) != 1 {
+		t.Fatalf("unexpected events: %+v", events)
+	}
+	expected := time.Date(2024, 1, 2, 15, 4, 5, 0, time.Local).Unix()
+	if events[0].Time != expected || events[0].Source != timeline.SOURCE_RUN_LOG || events[0].Message != lines[0] {
+		t.Fatalf("unexpected event: %+v", events[0])
+	}
+}
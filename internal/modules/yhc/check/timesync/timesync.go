@@ -0,0 +1,156 @@
+// The timesync package parses the output of chronyc, ntpq and timedatectl to get the clock synchronization status.
+package timesync
+
+import (
+	"bufio"
+	"errors"
+	"strconv"
+	"strings"
+)
+
+const (
+	SERVICE_CHRONYD   = "chronyd"
+	SERVICE_NTPD      = "ntpd"
+	SERVICE_TIMESYNCD = "systemd-timesyncd"
+	SERVICE_NONE      = "none"
+
+	_ms_per_second = 1000
+
+	_chrony_reference_id = "Reference ID"
+	_chrony_stratum      = "Stratum"
+	_chrony_system_time  = "System time"
+	_chrony_leap_status  = "Leap status"
+	_chrony_not_synced   = "Not synchronised"
+	_chrony_slow         = "slow"
+	// reference id of an unsynchronized chronyd, or a chronyd using the local clock
+	_chrony_no_reference    = "00000000"
+	_chrony_local_reference = "7F7F0101"
+
+	_ntpq_sync_peer    = "*"
+	_ntpq_field_count  = 10
+	_ntpq_stratum_idx  = 2
+	_ntpq_offset_idx   = 8
+	_timedatectl_yes   = "yes"
+	_timedatectl_sync  = "synchronized"
+	_timedatectl_ntp   = "NTP service"
+	_timedatectl_ntp_v = "Network time on"
+	_active            = "active"
+)
+
+var ErrNoSyncPeer = errors.New("no synchronized peer found")
+
+// Status is the clock synchronization status of the host, OffsetMs is positive if the local clock is ahead.
+type Status struct {
+	Service      string
+	Synchronized bool
+	Source       string
+	OffsetMs     float64
+	Stratum      int
+}
+
+// ParseChronyTracking parses the output of 'chronyc tracking'.
+func ParseChronyTracking(output string) (*Status, error) {
+	status := &Status{Service: SERVICE_CHRONYD, Synchronized: true}
+	fields := parseKeyValues(output, ":")
+	if len(fields) == 0 {
+		return nil, errors.New("invalid output of chronyc tracking")
+	}
+	if ref, ok := fields[_chrony_reference_id]; ok {
+		items := strings.Fields(ref)
+		if len(items) > 0 && (items[0] == _chrony_no_reference || items[0] == _chrony_local_reference) {
+			status.Synchronized = false
+		}
+		if len(items) > 1 {
+			status.Source = strings.Trim(items[1], "()")
+		}
+	}
+	if stratum, ok := fields[_chrony_stratum]; ok {
+		status.Stratum, _ = strconv.Atoi(stratum)
+	}
+	if systemTime, ok := fields[_chrony_system_time]; ok {
+		// such as '0.000002385 seconds fast of NTP time'
+		items := strings.Fields(systemTime)
+		if len(items) >= 3 {
+			offset, err := strconv.ParseFloat(items[0], 64)
+			if err != nil {
+				return nil, err
+			}
+			if items[2] == _chrony_slow {
+				offset = -offset
+			}
+			status.OffsetMs = offset * _ms_per_second
+		}
+	}
+	if fields[_chrony_leap_status] == _chrony_not_synced {
+		status.Synchronized = false
+	}
+	return status, nil
+}
+
+// ParseNtpq parses the output of 'ntpq -pn', the peer marked with '*' is the synchronized peer.
+func ParseNtpq(output string) (*Status, error) {
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if !strings.HasPrefix(line, _ntpq_sync_peer) {
+			continue
+		}
+		fields := strings.Fields(strings.TrimPrefix(line, _ntpq_sync_peer))
+		if len(fields) < _ntpq_field_count {
+			continue
+		}
+		stratum, err := strconv.Atoi(fields[_ntpq_stratum_idx])
+		if err != nil {
+			return nil, err
+		}
+		offset, err := strconv.ParseFloat(fields[_ntpq_offset_idx], 64)
+		if err != nil {
+			return nil, err
+		}
+		return &Status{
+			Service:      SERVICE_NTPD,
+			Synchronized: true,
+			Source:       fields[0],
+			// the stratum of the host is one more than its peer
+			Stratum: stratum + 1,
+			// the offset of ntpq is the peer relative to the local clock
+			OffsetMs: -offset,
+		}, nil
+	}
+	return &Status{Service: SERVICE_NTPD}, ErrNoSyncPeer
+}
+
+// ParseTimedatectl parses the output of 'timedatectl status', the offset and stratum are not available.
+func ParseTimedatectl(output string) *Status {
+	status := &Status{Service: SERVICE_NONE}
+	for key, value := range parseKeyValues(output, ":") {
+		switch {
+		case strings.HasSuffix(key, _timedatectl_sync):
+			// 'System clock synchronized' or 'NTP synchronized' of old versions
+			status.Synchronized = value == _timedatectl_yes
+		case key == _timedatectl_ntp:
+			if value == _active {
+				status.Service = SERVICE_TIMESYNCD
+			}
+		case key == _timedatectl_ntp_v:
+			if value == _timedatectl_yes {
+				status.Service = SERVICE_TIMESYNCD
+			}
+		}
+	}
+	return status
+}
+
+func parseKeyValues(output, sep string) map[string]string {
+	res := make(map[string]string)
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		index := strings.Index(scanner.Text(), sep)
+		if index < 0 {
+			continue
+		}
+		line := scanner.Text()
+		res[strings.TrimSpace(line[:index])] = strings.TrimSpace(line[index+len(sep):])
+	}
+	return res
+}
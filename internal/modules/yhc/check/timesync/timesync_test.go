@@ -0,0 +1,62 @@
+package timesync_test
+
+import (
+	"math"
+	"testing"
+
+	"yhc/internal/modules/yhc/check/timesync"
+)
+
+func TestParseChronyTracking(t *testing.T) {
+	output := `Reference ID    : A9FEA97B (169.254.169.123)
+Stratum         : 4
+Ref time (UTC)  : Mon Oct 19 08:00:00 2026
+System time     : 0.012500000 seconds slow of NTP time
+Last offset     : -0.000000698 seconds
+RMS offset      : 0.000012345 seconds
+Leap status     : Normal
+`
+	status, err := timesync.ParseChronyTracking(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !status.Synchronized || status.Stratum != 4 || status.Source != "169.254.169.123" || math.Abs(status.OffsetMs+12.5) > 1e-9 {
+		t.Fatalf("unexpected status: %+v", status)
+	}
+	status, err = timesync.ParseChronyTracking("Reference ID    : 00000000 ()\nStratum         : 0\nLeap status     : Not synchronised\n")
+	if err != nil || status.Synchronized {
+		t.Fatalf("unexpected status: %+v, err: %v", status, err)
+	}
+}
+
+func TestParseNtpq(t *testing.T) {
+	output := `     remote           refid      st t when poll reach   delay   offset  jitter
+==============================================================================
++10.0.0.2        .GPS.            1 u   12   64  377    0.310    1.120   0.020
+*10.0.0.1        .GPS.            1 u   33   64  377    0.250   -3.500   0.012
+`
+	status, err := timesync.ParseNtpq(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !status.Synchronized || status.Stratum != 2 || status.Source != "10.0.0.1" || status.OffsetMs != 3.5 {
+		t.Fatalf("unexpected status: %+v", status)
+	}
+	if _, err := timesync.ParseNtpq("+10.0.0.2 .GPS. 1 u 12 64 377 0.310 1.120 0.020\n"); err != timesync.ErrNoSyncPeer {
+		t.Fatalf("expected ErrNoSyncPeer, got %v", err)
+	}
+}
+
+func TestParseTimedatectl(t *testing.T) {
+	output := `               Local time: Mon 2026-10-19 16:00:00 CST
+           Universal time: Mon 2026-10-19 08:00:00 UTC
+                Time zone: Asia/Shanghai (CST, +0800)
+System clock synchronized: yes
+              NTP service: active
+          RTC in local TZ: no
+`
+	status := timesync.ParseTimedatectl(output)
+	if !status.Synchronized || status.Service != timesync.SERVICE_TIMESYNCD {
+		t.Fatalf("unexpected status: %+v", status)
+	}
+}
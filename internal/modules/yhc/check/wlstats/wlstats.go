@@ -0,0 +1,252 @@
+// The wlstats package calculates the statistics of the workload series, such as min, avg, p95, max and the time above
+// a threshold, and detects the spikes by the rolling median absolute deviation.
+package wlstats
+
+import (
+	"encoding/json"
+	"math"
+	"sort"
+	"strconv"
+
+	"yhc/commons/constants"
+	"yhc/internal/modules/yhc/check/define"
+	"yhc/utils/mathutil"
+)
+
+const (
+	// ANOMALY_WINDOW is the number of the points before a point used as its baseline
+	ANOMALY_WINDOW = 10
+	// ANOMALY_THRESHOLD is the modified z-score above which a point is a spike, as suggested by Iglewicz and Hoaglin
+	ANOMALY_THRESHOLD = 3.5
+
+	// the points before the baseline is stable are not checked
+	_min_baseline_points = 5
+	// _mad_scale makes the median absolute deviation consistent with the standard deviation of normal distribution
+	_mad_scale = 1.4826
+	// the deviation of a flat baseline is at least 10% of the median or _min_deviation,
+	// so that a tiny change of a flat series is not a spike
+	_min_deviation_ratio = 0.1
+	_min_deviation       = 0.1
+	_percentile          = 0.95
+	_decimal             = 2
+)
+
+// Point is a value of a series at a timestamp in seconds.
+type Point struct {
+	Time  int64
+	Value float64
+}
+
+// Series is the values of a numeric field of a workload item over time.
+type Series struct {
+	Item   string
+	Field  string
+	Labels map[string]string
+	Points []Point
+}
+
+// Stats is the statistics of a series.
+type Stats struct {
+	Count int
+	Min   float64
+	Avg   float64
+	P95   float64
+	Max   float64
+}
+
+// Anomaly is a spike period of a series, Start and End are the timestamps of the first and last abnormal points.
+type Anomaly struct {
+	Start    int64
+	End      int64
+	Peak     float64
+	Baseline float64
+	Score    float64
+}
+
+// FromWorkload splits the workload output to the series of every numeric field of every item ordered by item and field.
+// The numbers in strings, such as the results of SQL, are numeric, while the fields in labels and the other strings
+// are kept as the labels of the series.
+func FromWorkload(output define.WorkloadOutput, labels []string) ([]*Series, error) {
+	isLabel := make(map[string]bool, len(labels))
+	for _, label := range labels {
+		isLabel[label] = true
+	}
+	series := make(map[string]map[string]*Series)
+	itemLabels := make(map[string]map[string]string)
+	for timestamp, item := range output {
+		for name, value := range item {
+			m, err := toMap(value)
+			if err != nil {
+				return nil, err
+			}
+			if _, ok := series[name]; !ok {
+				series[name], itemLabels[name] = make(map[string]*Series), make(map[string]string)
+			}
+			for field, v := range m {
+				number, ok := toNumber(v)
+				if !ok || isLabel[field] {
+					if s, isString := v.(string); isString {
+						itemLabels[name][field] = s
+					}
+					continue
+				}
+				s, ok := series[name][field]
+				if !ok {
+					s = &Series{Item: name, Field: field}
+					series[name][field] = s
+				}
+				s.Points = append(s.Points, Point{Time: timestamp, Value: number})
+			}
+		}
+	}
+	var res []*Series
+	for name, fields := range series {
+		for _, s := range fields {
+			s.Labels = itemLabels[name]
+			sort.Slice(s.Points, func(i, j int) bool { return s.Points[i].Time < s.Points[j].Time })
+			res = append(res, s)
+		}
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Item != res[j].Item {
+			return res[i].Item < res[j].Item
+		}
+		return res[i].Field < res[j].Field
+	})
+	return res, nil
+}
+
+// Summarize calculates the statistics of the points, the p95 is the nearest rank.
+func Summarize(points []Point) Stats {
+	if len(points) == 0 {
+		return Stats{}
+	}
+	values := sortedValues(points)
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	rank := int(math.Ceil(_percentile*float64(len(values)))) - 1
+	return Stats{
+		Count: len(values),
+		Min:   mathutil.Round(values[0], _decimal),
+		Avg:   mathutil.Round(sum/float64(len(values)), _decimal),
+		P95:   mathutil.Round(values[rank], _decimal),
+		Max:   mathutil.Round(values[len(values)-1], _decimal),
+	}
+}
+
+// SecondsAbove returns the seconds in which the value is above the threshold, a point covers the interval before it,
+// and the first point covers the interval after it.
+func SecondsAbove(points []Point, threshold float64) int64 {
+	var seconds int64
+	for i, p := range points {
+		if p.Value <= threshold {
+			continue
+		}
+		switch {
+		case i > 0:
+			seconds += p.Time - points[i-1].Time
+		case len(points) > 1:
+			seconds += points[1].Time - p.Time
+		}
+	}
+	return seconds
+}
+
+// DetectAnomalies marks the points of which the modified z-score against the median of the window points before them
+// is above the threshold, and merges the adjacent abnormal points to spike periods.
+func DetectAnomalies(points []Point, window int, threshold float64) []Anomaly {
+	var res []Anomaly
+	var current *Anomaly
+	for i := _min_baseline_points; i < len(points); i++ {
+		begin := i - window
+		if begin < 0 {
+			begin = 0
+		}
+		baseline := make([]float64, 0, i-begin)
+		for _, p := range points[begin:i] {
+			baseline = append(baseline, p.Value)
+		}
+		median := medianOf(baseline)
+		deviations := make([]float64, 0, len(baseline))
+		for _, v := range baseline {
+			deviations = append(deviations, math.Abs(v-median))
+		}
+		deviation := math.Max(_mad_scale*medianOf(deviations), math.Max(_min_deviation_ratio*math.Abs(median), _min_deviation))
+		score := (points[i].Value - median) / deviation
+		if math.Abs(score) <= threshold {
+			if current != nil {
+				res, current = append(res, *current), nil
+			}
+			continue
+		}
+		if current == nil {
+			current = &Anomaly{Start: points[i].Time, Peak: mathutil.Round(points[i].Value, _decimal), Baseline: mathutil.Round(median, _decimal)}
+		}
+		current.End = points[i].Time
+		if math.Abs(score) > math.Abs(current.Score) {
+			current.Peak, current.Score = mathutil.Round(points[i].Value, _decimal), mathutil.Round(score, _decimal)
+		}
+	}
+	if current != nil {
+		res = append(res, *current)
+	}
+	return res
+}
+
+func sortedValues(points []Point) []float64 {
+	values := make([]float64, 0, len(points))
+	for _, p := range points {
+		values = append(values, p.Value)
+	}
+	sort.Float64s(values)
+	return values
+}
+
+func medianOf(values []float64) float64 {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	n := len(sorted)
+	if n == 0 {
+		return 0
+	}
+	if n%2 == 1 {
+		return sorted[n/2]
+	}
+	return (sorted[n/2-1] + sorted[n/2]) / 2
+}
+
+func toMap(value interface{}) (map[string]interface{}, error) {
+	if m, ok := value.(map[string]interface{}); ok {
+		return m, nil
+	}
+	m := map[string]interface{}{}
+	bytes, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(bytes, &m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func toNumber(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case string:
+		number, err := strconv.ParseFloat(v, constants.BIT_SIZE_64)
+		return number, err == nil
+	}
+	return 0, false
+}
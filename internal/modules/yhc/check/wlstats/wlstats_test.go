@@ -0,0 +1,84 @@
+package wlstats_test
+
+import (
+	"testing"
+
+	"yhc/internal/modules/yhc/check/define"
+	"yhc/internal/modules/yhc/check/sar"
+	"yhc/internal/modules/yhc/check/wlstats"
+)
+
+func points(values ...float64) []wlstats.Point {
+	res := make([]wlstats.Point, 0, len(values))
+	for i, v := range values {
+		res = append(res, wlstats.Point{Time: int64(i * 60), Value: v})
+	}
+	return res
+}
+
+func TestFromWorkload(t *testing.T) {
+	output := define.WorkloadOutput{
+		120: {"eth0": sar.NetworkIO{Iface: "eth0", RxkB: 3}},
+		60:  {"eth0": sar.NetworkIO{Iface: "eth0", RxkB: 1}},
+		// the numbers of SQL results are strings
+		180: {"HIT_RATE": map[string]string{"SNAP_TIME": "2024-01-02 10:00:00", "HIT_RATE": "99.5"}},
+	}
+	series, err := wlstats.FromWorkload(output, []string{"iface"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(series) < 2 {
+		t.Fatalf("unexpected series: %d", len(series))
+	}
+	hitRate := series[0]
+	if hitRate.Item != "HIT_RATE" || hitRate.Field != "HIT_RATE" || hitRate.Points[0].Value != 99.5 || hitRate.Labels["SNAP_TIME"] == "" {
+		t.Fatalf("unexpected hit rate series: %+v", hitRate)
+	}
+	for _, s := range series[1:] {
+		if s.Item != "eth0" || s.Labels["iface"] != "eth0" || len(s.Points) != 2 || s.Points[0].Time != 60 {
+			t.Fatalf("unexpected network series: %+v", s)
+		}
+		if s.Field == "rxkB" && s.Points[1].Value != 3 {
+			t.Fatalf("unexpected rxkB series: %+v", s)
+		}
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	values := make([]float64, 0, 20)
+	for i := 1; i <= 20; i++ {
+		values = append(values, float64(i))
+	}
+	stats := wlstats.Summarize(points(values...))
+	expected := wlstats.Stats{Count: 20, Min: 1, Avg: 10.5, P95: 19, Max: 20}
+	if stats != expected {
+		t.Fatalf("expected %+v, got %+v", expected, stats)
+	}
+	if stats := wlstats.Summarize(nil); stats != (wlstats.Stats{}) {
+		t.Fatalf("unexpected stats of empty series: %+v", stats)
+	}
+	// the first point covers the interval after it
+	if seconds := wlstats.SecondsAbove(points(90, 10, 85, 95), 80); seconds != 180 {
+		t.Fatalf("expected 180 seconds above threshold, got %d", seconds)
+	}
+}
+
+func TestDetectAnomalies(t *testing.T) {
+	series := points(10, 12, 11, 9, 10, 11, 10, 95, 97, 11, 10, 12, 2, 10)
+	anomalies := wlstats.DetectAnomalies(series, wlstats.ANOMALY_WINDOW, wlstats.ANOMALY_THRESHOLD)
+	if len(anomalies) != 2 {
+		t.Fatalf("unexpected anomalies: %+v", anomalies)
+	}
+	spike := anomalies[0]
+	if spike.Start != 7*60 || spike.End != 8*60 || spike.Peak != 97 || spike.Baseline != 10 || spike.Score <= wlstats.ANOMALY_THRESHOLD {
+		t.Fatalf("unexpected spike: %+v", spike)
+	}
+	// a dip is abnormal too
+	if dip := anomalies[1]; dip.Start != 12*60 || dip.Peak != 2 || dip.Score >= 0 {
+		t.Fatalf("unexpected dip: %+v", dip)
+	}
+	// a tiny change of a flat series is not a spike
+	if anomalies := wlstats.DetectAnomalies(points(0, 0, 0, 0, 0, 0, 0.05, 0), wlstats.ANOMALY_WINDOW, wlstats.ANOMALY_THRESHOLD); len(anomalies) != 0 {
+		t.Fatalf("unexpected anomalies of flat series: %+v", anomalies)
+	}
+}
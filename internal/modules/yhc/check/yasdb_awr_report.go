@@ -0,0 +1,235 @@
+package check
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"yhc/commons/constants"
+	"yhc/defs/confdef"
+	"yhc/defs/timedef"
+	"yhc/internal/modules/yhc/check/awr"
+	"yhc/internal/modules/yhc/check/define"
+	"yhc/log"
+
+	"git.yasdb.com/go/yaserr"
+	"git.yasdb.com/go/yaslog"
+)
+
+const (
+	KEY_BEGIN_TIME        = "BEGIN_TIME"
+	KEY_END_TIME          = "END_TIME"
+	KEY_STAT_NAME         = "STAT_NAME"
+	KEY_VALUE             = "VALUE"
+	KEY_EVENT_NAME        = "EVENT_NAME"
+	KEY_WAIT_CLASS        = "WAIT_CLASS"
+	KEY_TOTAL_WAITS       = "TOTAL_WAITS"
+	KEY_TIME_WAITED_MICRO = "TIME_WAITED_MICRO"
+	KEY_SQL_ID            = "SQL_ID"
+	KEY_SQL_TEXT          = "SQL_TEXT"
+	KEY_EXECUTIONS        = "EXECUTIONS"
+	KEY_ELAPSED_TIME      = "ELAPSED_TIME"
+	KEY_CPU_TIME          = "CPU_TIME"
+	KEY_BUFFER_GETS       = "BUFFER_GETS"
+	KEY_DISK_READS        = "DISK_READS"
+)
+
+const (
+	_awr_top_n = 10
+)
+
+func (c *YHCChecker) GetYasdbAWRReport(name string) (err error) {
+	var datas []*define.YHCItem
+	logger := log.Module.M(string(define.METRIC_YASDB_AWR_REPORT))
+	for _, yasdb := range c.GetCheckNodes(logger) {
+		data := &define.YHCItem{Name: define.METRIC_YASDB_AWR_REPORT, NodeID: yasdb.NodeID}
+		datas = append(datas, data)
+
+		report, e := c.awrReport(logger, yasdb)
+		if e != nil {
+			err = yaserr.Wrap(e)
+			logger.Error(err)
+			data.Error = err.Error()
+			continue
+		}
+		data.Details = report
+	}
+	c.fillResults(datas...)
+	return
+}
+
+// awrReport builds the report of the base period, the failure of the compare period is kept in the report,
+// so that the base period is still shown
+func (c *YHCChecker) awrReport(log yaslog.YasLog, yasdb *CheckNodeInfo) (*awr.Report, error) {
+	snapshots, err := c.awrSnapshots(yasdb)
+	if err != nil {
+		return nil, err
+	}
+	opts := c.base.Snapshots
+	var base awr.Period
+	if opts.BeginSnap > 0 {
+		base, err = awr.Find(snapshots, opts.BeginSnap, opts.EndSnap)
+	} else {
+		base, err = awr.Pick(snapshots, c.base.Start, c.base.End)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var errs []string
+	baseWorkload, err := c.awrWorkload(log, yasdb, base, &errs)
+	if err != nil {
+		return nil, err
+	}
+	var compareWorkload *awr.Workload
+	if compare, ok, e := c.awrComparePeriod(snapshots, base); e != nil {
+		log.Errorf("failed to pick the compare period, err: %v", e)
+		errs = append(errs, e.Error())
+	} else if ok {
+		compareWorkload, err = c.awrWorkload(log, yasdb, compare, &errs)
+		if err != nil {
+			log.Errorf("failed to get the workload of the compare period %s, err: %v", compare, err)
+			errs = append(errs, err.Error())
+		}
+	}
+	report := awr.NewReport(baseWorkload, compareWorkload)
+	report.Errors = errs
+	return report, nil
+}
+
+// awrComparePeriod returns the compare period given by the ids or the offset, false if no compare period is required
+func (c *YHCChecker) awrComparePeriod(snapshots []awr.Snapshot, base awr.Period) (awr.Period, bool, error) {
+	opts := c.base.Snapshots
+	if opts.CompareBeginSnap > 0 {
+		period, err := awr.Find(snapshots, opts.CompareBeginSnap, opts.CompareEndSnap)
+		return period, true, err
+	}
+	if opts.CompareOffset > 0 {
+		period, err := awr.Pick(snapshots, base.Begin.End.Add(-opts.CompareOffset), base.End.End.Add(-opts.CompareOffset))
+		return period, true, err
+	}
+	return awr.Period{}, false, nil
+}
+
+func (c *YHCChecker) awrSnapshots(yasdb *CheckNodeInfo) ([]awr.Snapshot, error) {
+	rows, err := yasdb.QueryMultiRows(define.SQL_QUERY_AWR_SNAPSHOTS, confdef.GetYHCConf().SqlTimeout)
+	if err != nil {
+		return nil, err
+	}
+	var res []awr.Snapshot
+	for _, row := range rows {
+		id, err := strconv.ParseInt(row[KEY_SNAP_ID], constants.BASE_DECIMAL, constants.BIT_SIZE_64)
+		if err != nil {
+			return nil, yaserr.Wrapf(err, "parse snapshot id %s", row[KEY_SNAP_ID])
+		}
+		begin, err := time.ParseInLocation(timedef.TIME_FORMAT, row[KEY_BEGIN_TIME], time.Local)
+		if err != nil {
+			return nil, yaserr.Wrap(err)
+		}
+		end, err := time.ParseInLocation(timedef.TIME_FORMAT, row[KEY_END_TIME], time.Local)
+		if err != nil {
+			return nil, yaserr.Wrap(err)
+		}
+		res = append(res, awr.Snapshot{ID: id, Begin: begin, End: end})
+	}
+	return res, nil
+}
+
+// awrWorkload returns the workload of the period, the stats are required, while the failures of the wait events
+// and the top SQL are appended to the errs
+func (c *YHCChecker) awrWorkload(log yaslog.YasLog, yasdb *CheckNodeInfo, period awr.Period, errs *[]string) (*awr.Workload, error) {
+	begin, end := period.Begin.ID, period.End.ID
+	stats, err := c.awrStats(yasdb, begin, end)
+	if err != nil {
+		return nil, err
+	}
+	events, err := c.awrEvents(yasdb, begin, end)
+	if err != nil {
+		log.Errorf("failed to get the wait events of %s, err: %v", period, err)
+		*errs = append(*errs, err.Error())
+	}
+	sqls, err := c.awrSQLStats(yasdb, begin, end)
+	if err != nil {
+		log.Errorf("failed to get the SQL stats of %s, err: %v", period, err)
+		*errs = append(*errs, err.Error())
+	}
+	return awr.NewWorkload(period, stats, events, sqls, _awr_top_n), nil
+}
+
+func (c *YHCChecker) awrStats(yasdb *CheckNodeInfo, begin, end int64) (map[string]float64, error) {
+	sql := fmt.Sprintf(define.SQL_QUERY_AWR_SYSSTAT_FORMATER, begin, end)
+	rows, err := yasdb.QueryMultiRows(sql, confdef.GetYHCConf().SqlTimeout)
+	if err != nil {
+		return nil, err
+	}
+	beginStats, endStats := make(map[string]float64), make(map[string]float64)
+	for _, row := range rows {
+		value, err := strconv.ParseFloat(row[KEY_VALUE], constants.BIT_SIZE_64)
+		if err != nil {
+			return nil, yaserr.Wrapf(err, "parse value of stat %s", row[KEY_STAT_NAME])
+		}
+		if row[KEY_SNAP_ID] == strconv.FormatInt(begin, constants.BASE_DECIMAL) {
+			beginStats[row[KEY_STAT_NAME]] = value
+		} else {
+			endStats[row[KEY_STAT_NAME]] = value
+		}
+	}
+	if len(endStats) == 0 {
+		return nil, fmt.Errorf("no stats of snapshot %d found from sql '%s'", end, sql)
+	}
+	return awr.Delta(beginStats, endStats), nil
+}
+
+func (c *YHCChecker) awrEvents(yasdb *CheckNodeInfo, begin, end int64) ([]*awr.Event, error) {
+	rows, err := yasdb.QueryMultiRows(fmt.Sprintf(define.SQL_QUERY_AWR_SYSTEM_EVENT_FORMATER, begin, end), confdef.GetYHCConf().SqlTimeout)
+	if err != nil {
+		return nil, err
+	}
+	var beginRows, endRows []map[string]string
+	for _, row := range rows {
+		if row[KEY_SNAP_ID] == strconv.FormatInt(begin, constants.BASE_DECIMAL) {
+			beginRows = append(beginRows, row)
+		} else {
+			endRows = append(endRows, row)
+		}
+	}
+	beginEvents, err := parseWaitEvents(beginRows)
+	if err != nil {
+		return nil, err
+	}
+	endEvents, err := parseWaitEvents(endRows)
+	if err != nil {
+		return nil, err
+	}
+	return awr.EventDelta(beginEvents, endEvents), nil
+}
+
+func (c *YHCChecker) awrSQLStats(yasdb *CheckNodeInfo, begin, end int64) ([]*awr.SQLStat, error) {
+	rows, err := yasdb.QueryMultiRows(fmt.Sprintf(define.SQL_QUERY_AWR_SQLSTAT_FORMATER, begin, end), confdef.GetYHCConf().SqlTimeout)
+	if err != nil {
+		return nil, err
+	}
+	var res []*awr.SQLStat
+	for _, row := range rows {
+		stat := &awr.SQLStat{SQLID: row[KEY_SQL_ID], SQLText: row[KEY_SQL_TEXT]}
+		fields := []struct {
+			key   string
+			value *float64
+		}{
+			{KEY_EXECUTIONS, &stat.Executions},
+			{KEY_ELAPSED_TIME, &stat.ElapsedTime},
+			{KEY_CPU_TIME, &stat.CPUTime},
+			{KEY_BUFFER_GETS, &stat.BufferGets},
+			{KEY_DISK_READS, &stat.DiskReads},
+		}
+		for _, field := range fields {
+			if len(row[field.key]) == 0 {
+				continue
+			}
+			if *field.value, err = strconv.ParseFloat(row[field.key], constants.BIT_SIZE_64); err != nil {
+				return nil, yaserr.Wrapf(err, "parse %s of sql %s", field.key, stat.SQLID)
+			}
+		}
+		res = append(res, stat)
+	}
+	return res, nil
+}
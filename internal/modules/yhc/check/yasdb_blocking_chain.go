@@ -0,0 +1,148 @@
+package check
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"yhc/commons/constants"
+	"yhc/defs/confdef"
+	"yhc/defs/timedef"
+	"yhc/internal/modules/yhc/check/define"
+	"yhc/internal/modules/yhc/check/locktree"
+	"yhc/log"
+	"yhc/utils/stringutil"
+
+	"git.yasdb.com/go/yaserr"
+)
+
+const (
+	KEY_LOCK_WAITER           = "WAITER"
+	KEY_LOCK_BLOCKER          = "BLOCKER"
+	KEY_LOCK_BLOCKERS         = "BLOCKERS"
+	KEY_LOCK_WAIT_SECONDS     = "WAIT_SECONDS"
+	KEY_LOCK_SID              = "SID"
+	KEY_LOCK_USERNAME         = "USERNAME"
+	KEY_LOCK_PROGRAM          = "PROGRAM"
+	KEY_LOCK_STATUS           = "STATUS"
+	KEY_LOCK_IDLE_SECONDS     = "IDLE_SECONDS"
+	KEY_LOCK_TX_START         = "TX_START"
+	KEY_LOCK_BLOCKED_SESSIONS = "BLOCKED_SESSIONS"
+	KEY_LOCK_CHAIN_DEPTH      = "CHAIN_DEPTH"
+	KEY_LOCK_CHAIN_AGE        = "CHAIN_AGE"
+)
+
+const (
+	_session_status_active = "ACTIVE"
+)
+
+// GetYasdbBlockingChain reports the blocking chains, a row for each root blocker with the blocked sessions nested as its children.
+func (c *YHCChecker) GetYasdbBlockingChain(name string) (err error) {
+	var datas []*define.YHCItem
+	logger := log.Module.M(string(define.METRIC_YASDB_BLOCKING_CHAIN))
+	for _, yasdb := range c.GetCheckNodes(logger) {
+		data := &define.YHCItem{Name: define.METRIC_YASDB_BLOCKING_CHAIN, NodeID: yasdb.NodeID}
+		datas = append(datas, data)
+
+		chains, e := c.blockingChains(yasdb)
+		if e != nil {
+			err = yaserr.Wrap(e)
+			logger.Error(err)
+			data.Error = err.Error()
+			continue
+		}
+		rows := make([]map[string]interface{}, 0, len(chains))
+		for _, chain := range chains {
+			row := blockingNodeRow(chain.Root)
+			row[KEY_LOCK_CHAIN_DEPTH] = chain.Depth
+			row[KEY_LOCK_CHAIN_AGE] = chain.AgeSeconds
+			rows = append(rows, row)
+		}
+		data.Details = rows
+	}
+	c.fillResults(datas...)
+	return
+}
+
+func (c *YHCChecker) blockingChains(yasdb *CheckNodeInfo) ([]*locktree.Chain, error) {
+	waitRows, err := yasdb.QueryMultiRows(define.SQL_QUERY_LOCK_WAITS, confdef.GetYHCConf().SqlTimeout)
+	if err != nil {
+		return nil, err
+	}
+	if len(waitRows) == 0 {
+		return nil, nil
+	}
+	waits := make([]locktree.Wait, 0, len(waitRows))
+	for _, row := range waitRows {
+		seconds, err := parseOptionalFloat(row[KEY_LOCK_WAIT_SECONDS])
+		if err != nil {
+			return nil, yaserr.Wrapf(err, "parse wait seconds of session %s", row[KEY_LOCK_WAITER])
+		}
+		waits = append(waits, locktree.Wait{Waiter: row[KEY_LOCK_WAITER], Blocker: row[KEY_LOCK_BLOCKER], Seconds: seconds})
+	}
+	sessionRows, err := yasdb.QueryMultiRows(define.SQL_QUERY_LOCK_SESSIONS, confdef.GetYHCConf().SqlTimeout)
+	if err != nil {
+		return nil, err
+	}
+	sessions := make(map[string]*locktree.Session, len(sessionRows))
+	for _, row := range sessionRows {
+		session := &locktree.Session{
+			SID:     row[KEY_LOCK_SID],
+			User:    row[KEY_LOCK_USERNAME],
+			Program: row[KEY_LOCK_PROGRAM],
+			SQLID:   row[KEY_SQL_ID],
+			Status:  row[KEY_LOCK_STATUS],
+		}
+		// the seconds since the last call is the time the session has been idle only if it is not running a call
+		if !strings.EqualFold(session.Status, _session_status_active) {
+			if session.IdleSeconds, err = parseOptionalFloat(row[KEY_LOCK_IDLE_SECONDS]); err != nil {
+				return nil, yaserr.Wrapf(err, "parse idle seconds of session %s", session.SID)
+			}
+		}
+		if len(row[KEY_LOCK_TX_START]) != 0 {
+			if session.TxStart, err = time.ParseInLocation(timedef.TIME_FORMAT, row[KEY_LOCK_TX_START], time.Local); err != nil {
+				return nil, yaserr.Wrapf(err, "parse transaction start of session %s", session.SID)
+			}
+		}
+		sessions[session.SID] = session
+	}
+	return locktree.Build(sessions, waits), nil
+}
+
+// blockingNodeRow returns the row of the session with the rows of the sessions it blocks nested, the SID is the key
+// of the row since a session appears only once in the chains, and all the blockers of a session blocked by several
+// sessions are listed though it is nested under one of them.
+func blockingNodeRow(node *locktree.Node) map[string]interface{} {
+	session := node.Session
+	var txStart string
+	if !session.TxStart.IsZero() {
+		txStart = session.TxStart.Format(timedef.TIME_FORMAT)
+	}
+	row := map[string]interface{}{
+		define.TABLE_ROW_KEY:      session.SID,
+		KEY_LOCK_SID:              session.SID,
+		KEY_LOCK_BLOCKERS:         strings.Join(node.Blockers, stringutil.STR_COMMA),
+		KEY_LOCK_USERNAME:         session.User,
+		KEY_LOCK_PROGRAM:          session.Program,
+		KEY_SQL_ID:                session.SQLID,
+		KEY_LOCK_TX_START:         txStart,
+		KEY_LOCK_IDLE_SECONDS:     session.IdleSeconds,
+		KEY_LOCK_WAIT_SECONDS:     node.WaitSeconds,
+		KEY_LOCK_BLOCKED_SESSIONS: node.Blocked,
+	}
+	if len(node.Children) != 0 {
+		children := make([]map[string]interface{}, 0, len(node.Children))
+		for _, child := range node.Children {
+			children = append(children, blockingNodeRow(child))
+		}
+		row[define.TABLE_ROW_CHILDREN] = children
+	}
+	return row
+}
+
+func parseOptionalFloat(value string) (float64, error) {
+	if len(value) == 0 {
+		return 0, nil
+	}
+	return strconv.ParseFloat(value, constants.BIT_SIZE_64)
+}
@@ -18,8 +18,6 @@ const (
 
 func (c *YHCChecker) GetYasdbHistoryBufferHitRate(name string) (err error) {
 	var datas []*define.YHCItem
-	defer c.fillResults(datas...)
-
 	logger := log.Module.M(string(define.METRIC_YASDB_HISTORY_BUFFER_HIT_RATE))
 	for _, yasdb := range c.GetCheckNodes(logger) {
 		data := &define.YHCItem{Name: define.METRIC_YASDB_HISTORY_BUFFER_HIT_RATE, NodeID: yasdb.NodeID}
@@ -48,5 +46,6 @@ func (c *YHCChecker) GetYasdbHistoryBufferHitRate(name string) (err error) {
 		}
 		data.Details = content
 	}
+	c.fillResults(datas...)
 	return
 }
@@ -0,0 +1,34 @@
+package check
+
+import (
+	"testing"
+	"time"
+
+	"yhc/commons/yasdb"
+	"yhc/internal/modules/yhc/check/define"
+	"yhc/log"
+
+	"git.yasdb.com/go/yaslog"
+	"github.com/stretchr/testify/assert"
+)
+
+// newUnreachableChecker returns a checker of which the queries of the database fail.
+func newUnreachableChecker(t *testing.T) *YHCChecker {
+	log.Module = yaslog.NewDefaultConsoleLogger()
+	end := time.Now()
+	return NewYHCChecker(&define.CheckerBase{
+		DBInfo: &yasdb.YashanDB{YasdbHome: t.TempDir(), ListenAddr: "127.0.0.1:0"},
+		Start:  end.Add(-time.Hour),
+		End:    end,
+	}, nil)
+}
+
+func TestGetYasdbHistoryBufferHitRate(t *testing.T) {
+	checker := newUnreachableChecker(t)
+	// the items of the nodes are filled after the loop, a deferred fill got the empty items of its arguments
+	_ = checker.GetYasdbHistoryBufferHitRate(string(define.METRIC_YASDB_HISTORY_BUFFER_HIT_RATE))
+	items := checker.Result[define.METRIC_YASDB_HISTORY_BUFFER_HIT_RATE]
+	if assert.Len(t, items, 1) {
+		assert.NotEmpty(t, items[0].Error)
+	}
+}
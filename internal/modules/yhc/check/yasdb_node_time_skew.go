@@ -0,0 +1,76 @@
+package check
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"yhc/defs/confdef"
+	"yhc/defs/timedef"
+	"yhc/internal/modules/yhc/check/define"
+	"yhc/log"
+	"yhc/utils/mathutil"
+
+	"git.yasdb.com/go/yaserr"
+)
+
+const (
+	KEY_NODE_TIME = "NODE_TIME"
+
+	KEY_TIME_SKEW_NODE_TIME  = "nodeTime"
+	KEY_TIME_SKEW_LOCAL_TIME = "localTime"
+	KEY_TIME_SKEW_MS         = "skewMs"
+	KEY_TIME_SKEW_ABS_MS     = "absSkewMs"
+	KEY_TIME_SKEW_QUERY_MS   = "queryMs"
+)
+
+// GetYasdbNodeTimeSkew compares the systimestamp of each node to the local clock,
+// the local time is taken as the middle of the query, so the error of the skew is at most half of queryMs.
+func (c *YHCChecker) GetYasdbNodeTimeSkew(name string) (err error) {
+	var datas []*define.YHCItem
+	defer func() {
+		c.fillResults(datas...)
+	}()
+
+	logger := log.Module.M(string(define.METRIC_YASDB_NODE_TIME_SKEW))
+	for _, yasdb := range c.GetCheckNodes(logger) {
+		data := &define.YHCItem{Name: define.METRIC_YASDB_NODE_TIME_SKEW, NodeID: yasdb.NodeID}
+		datas = append(datas, data)
+
+		before := time.Now()
+		var res []map[string]string
+		res, err = yasdb.QueryMultiRows(define.SQL_QUERY_SYSTIMESTAMP, confdef.GetYHCConf().SqlTimeout)
+		after := time.Now()
+		if err != nil {
+			err = yaserr.Wrap(err)
+			logger.Error(err)
+			data.Error = err.Error()
+			continue
+		}
+		if len(res) == 0 {
+			err = fmt.Errorf("failed to get info by sql '%s'", define.SQL_QUERY_SYSTIMESTAMP)
+			logger.Error(err)
+			data.Error = err.Error()
+			continue
+		}
+		var nodeTime time.Time
+		nodeTime, err = time.ParseInLocation(timedef.TIME_FORMAT_WITH_MICROSECOND, res[0][KEY_NODE_TIME], time.Local)
+		if err != nil {
+			err = yaserr.Wrap(err)
+			logger.Error(err)
+			data.Error = err.Error()
+			continue
+		}
+		queryDuration := after.Sub(before)
+		localTime := before.Add(queryDuration / 2)
+		skewMs := float64(nodeTime.Sub(localTime)) / float64(time.Millisecond)
+		data.Details = map[string]any{
+			KEY_TIME_SKEW_NODE_TIME:  nodeTime.Format(timedef.TIME_FORMAT_WITH_MICROSECOND),
+			KEY_TIME_SKEW_LOCAL_TIME: localTime.Format(timedef.TIME_FORMAT_WITH_MICROSECOND),
+			KEY_TIME_SKEW_MS:         mathutil.Round(skewMs, decimal),
+			KEY_TIME_SKEW_ABS_MS:     mathutil.Round(math.Abs(skewMs), decimal),
+			KEY_TIME_SKEW_QUERY_MS:   queryDuration.Milliseconds(),
+		}
+	}
+	return
+}
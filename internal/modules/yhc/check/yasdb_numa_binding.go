@@ -0,0 +1,113 @@
+package check
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"yhc/internal/modules/yhc/check/define"
+	"yhc/internal/modules/yhc/check/numa"
+	"yhc/log"
+	"yhc/utils/processutil"
+
+	"git.yasdb.com/go/yaserr"
+)
+
+const (
+	KEY_NUMA_BINDING_PID          = "pid"
+	KEY_NUMA_BINDING_NODE_COUNT   = "nodeCount"
+	KEY_NUMA_BINDING_MEM_POLICY   = "memPolicy"
+	KEY_NUMA_BINDING_POLICY_MODE  = "policyMode"
+	KEY_NUMA_BINDING_CPUS_ALLOWED = "cpusAllowed"
+	KEY_NUMA_BINDING_MEMS_ALLOWED = "memsAllowed"
+	KEY_NUMA_BINDING_CPU_BOUND    = "cpuBound"
+)
+
+// GetYasdbNumaBinding reports whether the memory of the yasdb process is bound or interleaved across the NUMA nodes,
+// and whether the process is bound to part of the CPUs, such as started by numactl or taskset.
+func (c *YHCChecker) GetYasdbNumaBinding(name string) (err error) {
+	data := &define.YHCItem{Name: define.METRIC_YASDB_NUMA_BINDING}
+	defer c.fillResults(data)
+
+	logger := log.Module.M(string(define.METRIC_YASDB_NUMA_BINDING))
+	processes, err := processutil.GetYasdbProcess(c.base.DBInfo.YasdbData)
+	if err != nil {
+		err = yaserr.Wrap(err)
+		logger.Error(err)
+		data.Error = err.Error()
+		return
+	}
+	if len(processes) == 0 {
+		err = errors.New("yasdb process not found")
+		logger.Error(err)
+		data.Error = err.Error()
+		return
+	}
+	pid := processes[0].Pid
+	nodes, err := numa.ReadNodes(numa.SYS_NODE)
+	if err != nil {
+		err = yaserr.Wrap(err)
+		logger.Error(err)
+		data.Error = err.Error()
+		return
+	}
+	mapsFile, err := os.Open(fmt.Sprintf("/proc/%d/numa_maps", pid))
+	if err != nil {
+		err = yaserr.Wrap(err)
+		logger.Error(err)
+		data.Error = err.Error()
+		return
+	}
+	defer mapsFile.Close()
+	policy, err := numa.ParseNumaMaps(mapsFile)
+	if err != nil {
+		err = yaserr.Wrap(err)
+		logger.Error(err)
+		data.Error = err.Error()
+		return
+	}
+	statusFile, err := os.Open(fmt.Sprintf("/proc/%d/status", pid))
+	if err != nil {
+		err = yaserr.Wrap(err)
+		logger.Error(err)
+		data.Error = err.Error()
+		return
+	}
+	defer statusFile.Close()
+	cpusAllowed, memsAllowed, err := numa.ParseAllowedLists(statusFile)
+	if err != nil {
+		err = yaserr.Wrap(err)
+		logger.Error(err)
+		data.Error = err.Error()
+		return
+	}
+	cpuBound := STR_FALSE
+	if online, e := numa.ReadOnlineCPUs(numa.SYS_CPU); e != nil {
+		logger.Warnf("read online cpus err: %s", e.Error())
+	} else if isCPUBound(cpusAllowed, online) {
+		cpuBound = STR_TRUE
+	}
+	data.Details = map[string]any{
+		KEY_NUMA_BINDING_PID:          pid,
+		KEY_NUMA_BINDING_NODE_COUNT:   len(nodes),
+		KEY_NUMA_BINDING_MEM_POLICY:   policy,
+		KEY_NUMA_BINDING_POLICY_MODE:  numa.PolicyMode(policy),
+		KEY_NUMA_BINDING_CPUS_ALLOWED: cpusAllowed,
+		KEY_NUMA_BINDING_MEMS_ALLOWED: memsAllowed,
+		KEY_NUMA_BINDING_CPU_BOUND:    cpuBound,
+	}
+	return
+}
+
+// isCPUBound returns true if the process is allowed to run on fewer CPUs than online.
+func isCPUBound(allowed, online string) bool {
+	allowedCPUs, err := numa.ParseList(allowed)
+	if err != nil {
+		return false
+	}
+	onlineCPUs, err := numa.ParseList(online)
+	if err != nil {
+		return false
+	}
+	return len(allowedCPUs) < len(onlineCPUs)
+}
@@ -0,0 +1,155 @@
+package check
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/user"
+
+	"yhc/internal/modules/yhc/check/define"
+	"yhc/internal/modules/yhc/check/limits"
+	"yhc/log"
+	"yhc/utils/mathutil"
+	"yhc/utils/processutil"
+	"yhc/utils/userutil"
+
+	"git.yasdb.com/go/yaserr"
+)
+
+const (
+	KEY_LIMIT_NAME          = "name"
+	KEY_LIMIT_SOFT          = "soft"
+	KEY_LIMIT_HARD          = "hard"
+	KEY_LIMIT_CONF_SOFT     = "confSoft"
+	KEY_LIMIT_CONF_HARD     = "confHard"
+	KEY_LIMIT_RECOMMENDED   = "recommended"
+	KEY_LIMIT_COMPLIANT     = "compliant"
+	KEY_LIMIT_CURRENT       = "current"
+	KEY_LIMIT_USAGE_PERCENT = "usagePercent"
+	KEY_LIMIT_THREADS       = "threads"
+)
+
+var (
+	// _limitOrder is the order of the limits in the report
+	_limitOrder = []string{limits.LIMIT_NOFILE, limits.LIMIT_NPROC, limits.LIMIT_MEMLOCK, limits.LIMIT_CORE, limits.LIMIT_STACK}
+
+	// _recommendedLimits are the minimum soft limits of the yasdb process, memlock and stack are in KB
+	_recommendedLimits = map[string]int64{
+		limits.LIMIT_NOFILE:  65536,
+		limits.LIMIT_NPROC:   65536,
+		limits.LIMIT_MEMLOCK: limits.UNLIMITED,
+		limits.LIMIT_CORE:    limits.UNLIMITED,
+		limits.LIMIT_STACK:   8192,
+	}
+)
+
+// GetYasdbProcessLimits reports the effective limits of the running yasdb process and the limits configured for its user.
+func (c *YHCChecker) GetYasdbProcessLimits(name string) (err error) {
+	data := &define.YHCItem{Name: define.METRIC_YASDB_PROCESS_LIMITS}
+	defer c.fillResults(data)
+
+	logger := log.Module.M(string(define.METRIC_YASDB_PROCESS_LIMITS))
+	processes, err := processutil.GetYasdbProcess(c.base.DBInfo.YasdbData)
+	if err != nil {
+		err = yaserr.Wrap(err)
+		logger.Error(err)
+		data.Error = err.Error()
+		return
+	}
+	if len(processes) == 0 {
+		err = errors.New("yasdb process not found")
+		logger.Error(err)
+		data.Error = err.Error()
+		return
+	}
+	process := processes[0]
+	f, err := os.Open(fmt.Sprintf("/proc/%d/limits", process.Pid))
+	if err != nil {
+		err = yaserr.Wrap(err)
+		logger.Error(err)
+		data.Error = err.Error()
+		return
+	}
+	defer f.Close()
+	procLimits, err := limits.ParseProcLimits(f)
+	if err != nil {
+		err = yaserr.Wrap(err)
+		logger.Error(err)
+		data.Error = err.Error()
+		return
+	}
+	var groups []string
+	if u, e := user.Lookup(process.User); e == nil {
+		groups = userutil.GetUserGroups(u)
+	}
+	confLimits, err := limits.ParseLimitsConf(limits.LIMITS_CONF, limits.LIMITS_DIR, process.User, groups)
+	if err != nil {
+		// the effective limits are still reported
+		logger.Warnf("parse limits conf err: %s", err.Error())
+		err = nil
+	}
+	usages, threads := c.getYasdbProcessUsages(process.Pid)
+	res := []map[string]any{}
+	for _, item := range _limitOrder {
+		procLimit, ok := procLimits[item]
+		if !ok {
+			continue
+		}
+		confLimit, ok := confLimits[item]
+		if !ok {
+			confLimit = limits.Limit{Soft: limits.NOT_SET, Hard: limits.NOT_SET}
+		}
+		compliant := STR_TRUE
+		if limits.IsLess(procLimit.Soft, _recommendedLimits[item]) {
+			compliant = STR_FALSE
+		}
+		row := map[string]any{
+			KEY_LIMIT_NAME:        item,
+			KEY_LIMIT_SOFT:        limits.Format(procLimit.Soft),
+			KEY_LIMIT_HARD:        limits.Format(procLimit.Hard),
+			KEY_LIMIT_CONF_SOFT:   limits.Format(confLimit.Soft),
+			KEY_LIMIT_CONF_HARD:   limits.Format(confLimit.Hard),
+			KEY_LIMIT_RECOMMENDED: limits.Format(_recommendedLimits[item]),
+			KEY_LIMIT_COMPLIANT:   compliant,
+			KEY_LIMIT_CURRENT:     "-",
+			KEY_LIMIT_THREADS:     "-",
+		}
+		// the threads of the yasdb process itself are a part of the tasks counted by nproc
+		if item == limits.LIMIT_NPROC && threads >= 0 {
+			row[KEY_LIMIT_THREADS] = fmt.Sprint(threads)
+		}
+		if usage, ok := usages[item]; ok {
+			row[KEY_LIMIT_CURRENT] = fmt.Sprint(usage)
+			if procLimit.Soft > 0 {
+				row[KEY_LIMIT_USAGE_PERCENT] = mathutil.Round(float64(usage)*100/float64(procLimit.Soft), decimal)
+			}
+		}
+		res = append(res, row)
+	}
+	data.Details = res
+	return
+}
+
+// getYasdbProcessUsages returns the count of open fds of the process and the count of the tasks of its user,
+// since nproc limits the tasks of all processes of the real uid, and the threads of the process, -1 if unknown.
+// Reading the fds of a process owned by another user needs root.
+func (c *YHCChecker) getYasdbProcessUsages(pid int) (map[string]int, int) {
+	logger := log.Module.M(string(define.METRIC_YASDB_PROCESS_LIMITS))
+	usages := make(map[string]int)
+	if fds, err := os.ReadDir(fmt.Sprintf("/proc/%d/fd", pid)); err != nil {
+		logger.Warnf("read fds of %d err: %s", pid, err.Error())
+	} else {
+		usages[limits.LIMIT_NOFILE] = len(fds)
+	}
+	status, err := limits.ParseProcStatus(fmt.Sprintf("/proc/%d/status", pid))
+	if err != nil {
+		logger.Warnf("read status of %d err: %s", pid, err.Error())
+		return usages, -1
+	}
+	if tasks, err := limits.CountUserTasks("/proc", status.RealUID); err != nil {
+		logger.Warnf("count tasks of uid %d err: %s", status.RealUID, err.Error())
+	} else {
+		usages[limits.LIMIT_NPROC] = tasks
+	}
+	return usages, status.Threads
+}
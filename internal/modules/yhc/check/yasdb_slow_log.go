@@ -37,8 +37,6 @@ var _slowParameter = []string{
 
 func (c *YHCChecker) GetYasdbSlowLogParameter(name string) (err error) {
 	var datas []*define.YHCItem
-	defer c.fillResults(datas...)
-
 	logger := log.Module.M(string(define.METRIC_YASDB_SLOW_LOG_PARAMETER))
 	for _, yasdb := range c.GetCheckNodes(logger) {
 		data := &define.YHCItem{Name: define.METRIC_YASDB_SLOW_LOG_PARAMETER, NodeID: yasdb.NodeID}
@@ -59,13 +57,12 @@ func (c *YHCChecker) GetYasdbSlowLogParameter(name string) (err error) {
 		}
 		data.Details = pmap
 	}
+	c.fillResults(datas...)
 	return
 }
 
 func (c *YHCChecker) GetYasdbSlowLog(name string) (err error) {
 	var datas []*define.YHCItem
-	defer c.fillResults(datas...)
-
 	logger := log.Module.M(string(define.METRIC_YASDB_SLOW_LOG))
 	for _, yasdb := range c.GetCheckNodes(logger) {
 		data := &define.YHCItem{Name: define.METRIC_YASDB_SLOW_LOG, NodeID: yasdb.NodeID}
@@ -82,6 +79,7 @@ func (c *YHCChecker) GetYasdbSlowLog(name string) (err error) {
 		}
 		data.Details = slowSQLs
 	}
+	c.fillResults(datas...)
 	return
 }
 
@@ -0,0 +1,22 @@
+package check
+
+import (
+	"testing"
+
+	"yhc/internal/modules/yhc/check/define"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetYasdbSlowLog(t *testing.T) {
+	checker := newUnreachableChecker(t)
+	// the items of the nodes are filled after the loop, a deferred fill got the empty items of its arguments
+	_ = checker.GetYasdbSlowLogParameter(string(define.METRIC_YASDB_SLOW_LOG_PARAMETER))
+	_ = checker.GetYasdbSlowLog(string(define.METRIC_YASDB_SLOW_LOG))
+	for _, name := range []define.MetricName{define.METRIC_YASDB_SLOW_LOG_PARAMETER, define.METRIC_YASDB_SLOW_LOG} {
+		items := checker.Result[name]
+		if assert.Len(t, items, 1, name) {
+			assert.NotEmpty(t, items[0].Error, name)
+		}
+	}
+}
@@ -0,0 +1,234 @@
+package check
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"yhc/commons/constants"
+	"yhc/defs/confdef"
+	"yhc/internal/modules/yhc/check/awr"
+	"yhc/internal/modules/yhc/check/define"
+	"yhc/log"
+
+	"git.yasdb.com/go/yaserr"
+	"git.yasdb.com/go/yaslog"
+)
+
+const (
+	KEY_EVENT           = "EVENT"
+	KEY_WAITS           = "WAITS"
+	KEY_TIME_WAITED     = "TIME_WAITED"
+	KEY_AVG_WAIT        = "AVG_WAIT"
+	KEY_DB_TIME_PERCENT = "DB_TIME_PERCENT"
+	KEY_CATEGORY        = "CATEGORY"
+)
+
+// waitEventCounters is the counters of the wait events and DB time of a node at a moment
+type waitEventCounters struct {
+	events []*awr.Event
+	dbTime float64
+}
+
+// waitEventSample is the increments of the wait events and DB time of a node over scrape_interval * scrape_times
+type waitEventSample struct {
+	events []*awr.Event
+	dbTime float64
+	err    error
+}
+
+// getWaitEventSamples samples the wait events of all nodes once, the current wait metrics share the samples.
+func (c *YHCChecker) getWaitEventSamples(logger yaslog.YasLog) map[string]*waitEventSample {
+	c.waitEventSampleOnce.Do(func() {
+		nodes := c.GetCheckNodes(logger)
+		olds := make([]*waitEventCounters, len(nodes))
+		errs := make([]error, len(nodes))
+		for i, node := range nodes {
+			olds[i], errs[i] = c.queryWaitEventCounters(node)
+		}
+		conf := confdef.GetYHCConf()
+		duration := time.Duration(conf.GetScrapeInterval()*conf.GetScrapeTimes()) * time.Second
+		logger.Infof("sampling wait events for %s", duration)
+		time.Sleep(duration)
+		c.waitEventSamples = make(map[string]*waitEventSample, len(nodes))
+		for i, node := range nodes {
+			sample := &waitEventSample{err: errs[i]}
+			c.waitEventSamples[node.NodeID] = sample
+			if sample.err != nil {
+				continue
+			}
+			new, err := c.queryWaitEventCounters(node)
+			if err != nil {
+				sample.err = err
+				continue
+			}
+			sample.events = awr.EventDelta(olds[i].events, new.events)
+			sample.dbTime = new.dbTime - olds[i].dbTime
+		}
+	})
+	return c.waitEventSamples
+}
+
+func (c *YHCChecker) queryWaitEventCounters(yasdb *CheckNodeInfo) (*waitEventCounters, error) {
+	rows, err := yasdb.QueryMultiRows(define.SQL_QUERY_SYSTEM_EVENT, confdef.GetYHCConf().SqlTimeout)
+	if err != nil {
+		return nil, err
+	}
+	events, err := parseWaitEvents(rows)
+	if err != nil {
+		return nil, err
+	}
+	dbTimes, err := yasdb.QueryMultiRows(define.SQL_QUERY_DB_TIME, confdef.GetYHCConf().SqlTimeout)
+	if err != nil {
+		return nil, err
+	}
+	if len(dbTimes) == 0 {
+		return nil, fmt.Errorf("failed to get DB time from sql '%s'", define.SQL_QUERY_DB_TIME)
+	}
+	dbTime, err := strconv.ParseFloat(dbTimes[0][KEY_VALUE], constants.BIT_SIZE_64)
+	if err != nil {
+		return nil, yaserr.Wrap(err)
+	}
+	return &waitEventCounters{events: events, dbTime: dbTime}, nil
+}
+
+func parseWaitEvents(rows []map[string]string) ([]*awr.Event, error) {
+	var events []*awr.Event
+	for _, row := range rows {
+		event := &awr.Event{Name: row[KEY_EVENT_NAME], WaitClass: row[KEY_WAIT_CLASS]}
+		var err error
+		if event.Waits, err = strconv.ParseFloat(row[KEY_TOTAL_WAITS], constants.BIT_SIZE_64); err != nil {
+			return nil, yaserr.Wrapf(err, "parse waits of event %s", event.Name)
+		}
+		if event.TimeWaitedMicro, err = strconv.ParseFloat(row[KEY_TIME_WAITED_MICRO], constants.BIT_SIZE_64); err != nil {
+			return nil, yaserr.Wrapf(err, "parse time waited of event %s", event.Name)
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// GetYasdbCurrentWaitEvent reports the top wait events by the time waited over the scrape window.
+func (c *YHCChecker) GetYasdbCurrentWaitEvent(name string) (err error) {
+	return c.currentWaits(define.METRIC_YASDB_CURRENT_WAIT_EVENT, func(events []*awr.Event, dbTime float64) []map[string]interface{} {
+		return waitEventRows(awr.TopWaitEvents(events, dbTime, _awr_top_n))
+	})
+}
+
+// GetYasdbCurrentWaitClass reports the wait time by wait class over the scrape window.
+func (c *YHCChecker) GetYasdbCurrentWaitClass(name string) (err error) {
+	return c.currentWaits(define.METRIC_YASDB_CURRENT_WAIT_CLASS, func(events []*awr.Event, dbTime float64) []map[string]interface{} {
+		return waitClassRows(awr.WaitClasses(events, dbTime))
+	})
+}
+
+// GetYasdbHistoryWaitEvent reports the top wait events by the time waited between the snapshots of the check window.
+func (c *YHCChecker) GetYasdbHistoryWaitEvent(name string) (err error) {
+	return c.historyWaits(define.METRIC_YASDB_HISTORY_WAIT_EVENT, func(events []*awr.Event, dbTime float64) []map[string]interface{} {
+		return waitEventRows(awr.TopWaitEvents(events, dbTime, _awr_top_n))
+	})
+}
+
+// GetYasdbHistoryWaitClass reports the wait time by wait class between the snapshots of the check window.
+func (c *YHCChecker) GetYasdbHistoryWaitClass(name string) (err error) {
+	return c.historyWaits(define.METRIC_YASDB_HISTORY_WAIT_CLASS, func(events []*awr.Event, dbTime float64) []map[string]interface{} {
+		return waitClassRows(awr.WaitClasses(events, dbTime))
+	})
+}
+
+func (c *YHCChecker) currentWaits(metric define.MetricName, toRows func(events []*awr.Event, dbTime float64) []map[string]interface{}) (err error) {
+	var datas []*define.YHCItem
+	logger := log.Module.M(string(metric))
+	samples := c.getWaitEventSamples(logger)
+	for _, yasdb := range c.GetCheckNodes(logger) {
+		data := &define.YHCItem{Name: metric, NodeID: yasdb.NodeID}
+		datas = append(datas, data)
+
+		sample, ok := samples[yasdb.NodeID]
+		if !ok {
+			err = fmt.Errorf("no wait events sampled of node %s", yasdb.NodeID)
+			logger.Error(err)
+			data.Error = err.Error()
+			continue
+		}
+		if sample.err != nil {
+			err = yaserr.Wrap(sample.err)
+			logger.Error(err)
+			data.Error = err.Error()
+			continue
+		}
+		data.Details = toRows(sample.events, sample.dbTime)
+	}
+	c.fillResults(datas...)
+	return
+}
+
+func (c *YHCChecker) historyWaits(metric define.MetricName, toRows func(events []*awr.Event, dbTime float64) []map[string]interface{}) (err error) {
+	var datas []*define.YHCItem
+	logger := log.Module.M(string(metric))
+	for _, yasdb := range c.GetCheckNodes(logger) {
+		data := &define.YHCItem{Name: metric, NodeID: yasdb.NodeID}
+		datas = append(datas, data)
+
+		events, dbTime, e := c.historyWaitEvents(yasdb)
+		if e != nil {
+			err = yaserr.Wrap(e)
+			logger.Error(err)
+			data.Error = err.Error()
+			continue
+		}
+		data.Details = toRows(events, dbTime)
+	}
+	c.fillResults(datas...)
+	return
+}
+
+// historyWaitEvents returns the increments of the wait events and DB time between the snapshots picked by the check window
+func (c *YHCChecker) historyWaitEvents(yasdb *CheckNodeInfo) ([]*awr.Event, float64, error) {
+	snapshots, err := c.awrSnapshots(yasdb)
+	if err != nil {
+		return nil, 0, err
+	}
+	period, err := awr.Pick(snapshots, c.base.Start, c.base.End)
+	if err != nil {
+		return nil, 0, err
+	}
+	stats, err := c.awrStats(yasdb, period.Begin.ID, period.End.ID)
+	if err != nil {
+		return nil, 0, err
+	}
+	events, err := c.awrEvents(yasdb, period.Begin.ID, period.End.ID)
+	if err != nil {
+		return nil, 0, err
+	}
+	return events, stats[awr.STAT_DB_TIME], nil
+}
+
+func waitEventRows(events []*awr.WaitEvent) []map[string]interface{} {
+	res := make([]map[string]interface{}, 0, len(events))
+	for _, e := range events {
+		res = append(res, map[string]interface{}{
+			KEY_EVENT:           e.Name,
+			KEY_WAIT_CLASS:      e.WaitClass,
+			KEY_WAITS:           e.Waits,
+			KEY_TIME_WAITED:     e.TimeSeconds,
+			KEY_AVG_WAIT:        e.AvgWaitMs,
+			KEY_DB_TIME_PERCENT: e.DBTimePercent,
+		})
+	}
+	return res
+}
+
+func waitClassRows(classes []*awr.WaitClass) []map[string]interface{} {
+	res := make([]map[string]interface{}, 0, len(classes))
+	for _, class := range classes {
+		res = append(res, map[string]interface{}{
+			KEY_WAIT_CLASS:      class.Name,
+			KEY_CATEGORY:        class.Category,
+			KEY_WAITS:           class.Waits,
+			KEY_TIME_WAITED:     class.TimeSeconds,
+			KEY_DB_TIME_PERCENT: class.DBTimePercent,
+		})
+	}
+	return res
+}
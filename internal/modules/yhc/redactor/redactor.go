@@ -0,0 +1,406 @@
+// The redactor package removes sensitive data from the results of health checks,
+// so that the result packages can be shared with support.
+package redactor
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"yhc/commons/yasdb"
+	"yhc/defs/confdef"
+	"yhc/internal/modules/yhc/check/define"
+)
+
+const (
+	MASK_TEXT = "******"
+
+	// values shorter than this are only redacted by keys, replacing them in any text does more harm than good,
+	// such as replacing the user 'sys' or 'root' in every log line and description
+	_min_dict_length = 6
+	_salt_length     = 16
+	_hash_length     = 8
+
+	_prefix_host = "host-"
+	_prefix_ip   = "ip-"
+	_prefix_user = "user-"
+	_prefix_path = "path-"
+)
+
+type redactFunc func(string) string
+
+// Redactor redacts values by the column names first, every redacted value is remembered,
+// so that the same value in any other text, such as log lines, is replaced consistently.
+type Redactor struct {
+	salt        []byte
+	keyFuncs    map[string]redactFunc
+	dropMetrics map[string]struct{}
+	dict        map[string]string
+	dictKeys    []string
+	ipFunc      redactFunc
+	hostFunc    redactFunc
+	// alertLabels keeps the generic copies of alert labels until they are written back
+	alertLabels map[*define.YHCAlert]map[string]interface{}
+}
+
+func NewRedactor(conf *confdef.RedactConf) (*Redactor, error) {
+	r := &Redactor{
+		keyFuncs:    make(map[string]redactFunc),
+		dropMetrics: make(map[string]struct{}),
+		dict:        make(map[string]string),
+		alertLabels: make(map[*define.YHCAlert]map[string]interface{}),
+	}
+	if len(conf.Salt) != 0 {
+		r.salt = []byte(conf.Salt)
+	} else {
+		r.salt = make([]byte, _salt_length)
+		if _, err := rand.Read(r.salt); err != nil {
+			return nil, err
+		}
+	}
+	for _, metric := range conf.DropMetrics {
+		r.dropMetrics[metric] = struct{}{}
+	}
+	r.ipFunc = r.modeFunc(conf.IP.Mode, _prefix_ip)
+	r.hostFunc = r.modeFunc(conf.Hostname.Mode, _prefix_host)
+	r.addRule(conf.Hostname, _prefix_host)
+	r.addRule(conf.IP, _prefix_ip)
+	r.addRule(conf.Username, _prefix_user)
+	r.addRule(conf.Path, _prefix_path)
+	if conf.Sql.StripLiterals {
+		for _, key := range conf.Sql.Keys {
+			r.keyFuncs[strings.ToLower(key)] = StripSqlLiterals
+		}
+	}
+	return r, nil
+}
+
+// IsDropped returns true if the metric should be removed from the results.
+func (r *Redactor) IsDropped(metric string) bool {
+	_, ok := r.dropMetrics[metric]
+	return ok
+}
+
+// Redact redacts the check results, items and failed items are keyed by metric names,
+// the returned values are copies and the details are converted to generic json values.
+func (r *Redactor) Redact(items map[define.MetricName][]*define.YHCItem, report *define.PandoraReport, failedItems map[define.MetricName][]*define.YHCItem) (map[define.MetricName][]*define.YHCItem, *define.PandoraReport, map[define.MetricName][]*define.YHCItem, error) {
+	redactedItems, err := r.copyItems(items)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	redactedFailedItems, err := r.copyItems(failedItems)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	redactedReport, err := r.copyReport(report)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	// the values of all keys are collected before any text is redacted
+	values := r.collectValues(redactedItems, redactedFailedItems, redactedReport)
+	for _, v := range values {
+		r.walk(v, true)
+	}
+	r.sortDict()
+	for _, v := range values {
+		r.walk(v, false)
+	}
+	r.redactItemTexts(redactedItems)
+	r.redactItemTexts(redactedFailedItems)
+	r.redactReportTexts(redactedReport)
+	return redactedItems, redactedReport, redactedFailedItems, nil
+}
+
+// RedactText replaces the values redacted before and ip addresses in the text.
+func (r *Redactor) RedactText(s string) string {
+	if len(s) == 0 {
+		return s
+	}
+	for _, key := range r.dictKeys {
+		s = replaceWord(s, key, r.dict[key])
+	}
+	return ReplaceIPv4(ReplaceIPv6(s, r.ipFunc), r.ipFunc)
+}
+
+// RedactHostname redacts the value by the hostname rule, even if no metric has a hostname key,
+// the hostname is remembered so that it is replaced in the texts redacted afterwards.
+func (r *Redactor) RedactHostname(s string) string {
+	if r.hostFunc == nil || len(strings.TrimSpace(s)) == 0 {
+		return r.RedactText(s)
+	}
+	if redacted, ok := r.dict[s]; ok {
+		return redacted
+	}
+	redacted := r.hostFunc(s)
+	if len(s) >= _min_dict_length {
+		r.dict[s] = redacted
+		r.sortDict()
+	}
+	return redacted
+}
+
+// RedactCheckerBase returns a copy of the base whose database and node information are redacted as text,
+// it should be called after Redact so that the values redacted by keys are replaced too.
+func (r *Redactor) RedactCheckerBase(base *define.CheckerBase) *define.CheckerBase {
+	if base == nil {
+		return nil
+	}
+	res := *base
+	if base.DBInfo != nil {
+		dbInfo := *base.DBInfo
+		dbInfo.YasdbHome = r.RedactText(dbInfo.YasdbHome)
+		dbInfo.YasdbData = r.RedactText(dbInfo.YasdbData)
+		dbInfo.YasdbUser = r.RedactText(dbInfo.YasdbUser)
+		dbInfo.ListenAddr = r.RedactText(dbInfo.ListenAddr)
+		dbInfo.YasdbPassword = ""
+		res.DBInfo = &dbInfo
+	}
+	res.NodeInfos = make([]*yasdb.NodeInfo, 0, len(base.NodeInfos))
+	for _, node := range base.NodeInfos {
+		nodeInfo := *node
+		nodeInfo.ListenAddr = r.RedactText(nodeInfo.ListenAddr)
+		nodeInfo.User = r.RedactText(nodeInfo.User)
+		nodeInfo.Password = ""
+		res.NodeInfos = append(res.NodeInfos, &nodeInfo)
+	}
+	return &res
+}
+
+func (r *Redactor) addRule(rule confdef.RedactRule, prefix string) {
+	fn := r.modeFunc(rule.Mode, prefix)
+	if fn == nil {
+		return
+	}
+	for _, key := range rule.Keys {
+		r.keyFuncs[strings.ToLower(key)] = fn
+	}
+}
+
+func (r *Redactor) modeFunc(mode string, prefix string) redactFunc {
+	switch mode {
+	case confdef.REDACT_MODE_MASK:
+		return func(string) string { return MASK_TEXT }
+	case confdef.REDACT_MODE_HASH:
+		return func(s string) string { return prefix + r.hash(s) }
+	default:
+		return nil
+	}
+}
+
+func (r *Redactor) hash(s string) string {
+	mac := hmac.New(sha256.New, r.salt)
+	mac.Write([]byte(s))
+	return hex.EncodeToString(mac.Sum(nil))[:_hash_length]
+}
+
+// redactKey redacts the value by the rule of the key, ok is false if the key has no rule.
+func (r *Redactor) redactKey(key, value string) (string, bool) {
+	fn, ok := r.keyFuncs[strings.ToLower(key)]
+	if !ok {
+		return value, false
+	}
+	if len(strings.TrimSpace(value)) == 0 {
+		return value, true
+	}
+	redacted := fn(value)
+	if redacted != value && len(value) >= _min_dict_length {
+		r.dict[value] = redacted
+	}
+	return redacted, true
+}
+
+func (r *Redactor) sortDict() {
+	r.dictKeys = make([]string, 0, len(r.dict))
+	for key := range r.dict {
+		r.dictKeys = append(r.dictKeys, key)
+	}
+	// longer values are replaced first, so that 'host-a' will not be broken by replacing 'host'
+	sort.Slice(r.dictKeys, func(i, j int) bool {
+		if len(r.dictKeys[i]) != len(r.dictKeys[j]) {
+			return len(r.dictKeys[i]) > len(r.dictKeys[j])
+		}
+		return r.dictKeys[i] < r.dictKeys[j]
+	})
+}
+
+// walk redacts generic json values in place, values of known keys are redacted when keyed is true,
+// other strings are redacted as text when keyed is false. The strings of an array under a known key are redacted by the key.
+func (r *Redactor) walk(v interface{}, keyed bool) {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		for key, child := range value {
+			_, hasRule := r.keyFuncs[strings.ToLower(key)]
+			switch c := child.(type) {
+			case string:
+				if hasRule && keyed {
+					value[key], _ = r.redactKey(key, c)
+				} else if !hasRule && !keyed {
+					value[key] = r.RedactText(c)
+				}
+			case []interface{}:
+				if !hasRule {
+					r.walk(c, keyed)
+					continue
+				}
+				for i, element := range c {
+					s, ok := element.(string)
+					if !ok {
+						r.walk(element, keyed)
+					} else if keyed {
+						c[i], _ = r.redactKey(key, s)
+					}
+				}
+			default:
+				r.walk(child, keyed)
+			}
+		}
+	case []interface{}:
+		for i, child := range value {
+			if s, ok := child.(string); ok {
+				if !keyed {
+					value[i] = r.RedactText(s)
+				}
+				continue
+			}
+			r.walk(child, keyed)
+		}
+	}
+}
+
+func (r *Redactor) collectValues(items, failedItems map[define.MetricName][]*define.YHCItem, report *define.PandoraReport) []interface{} {
+	var values []interface{}
+	for _, m := range []map[define.MetricName][]*define.YHCItem{items, failedItems} {
+		for _, metricItems := range m {
+			for _, item := range metricItems {
+				values = append(values, item.Details)
+				for _, alerts := range item.Alerts {
+					for _, alert := range alerts {
+						// labels are redacted as a generic map, and written back in redactItemTexts
+						labels := make(map[string]interface{}, len(alert.Labels))
+						for k, v := range alert.Labels {
+							labels[k] = v
+						}
+						values = append(values, labels)
+						r.alertLabels[alert] = labels
+					}
+				}
+			}
+		}
+	}
+	if report != nil {
+		walkMenus(report.ReportData, func(element *define.PandoraElement) {
+			values = append(values, element.Attributes, element.Solts, element.Config, element.Extend)
+		})
+	}
+	return values
+}
+
+func (r *Redactor) redactItemTexts(items map[define.MetricName][]*define.YHCItem) {
+	for _, metricItems := range items {
+		for _, item := range metricItems {
+			item.Error = r.RedactText(item.Error)
+			if s, ok := item.Details.(string); ok {
+				item.Details = r.RedactText(s)
+			}
+			for _, alerts := range item.Alerts {
+				for _, alert := range alerts {
+					if labels, ok := r.alertLabels[alert]; ok {
+						for k, v := range labels {
+							alert.Labels[k], _ = v.(string)
+						}
+						delete(r.alertLabels, alert)
+					}
+					if s, ok := alert.Value.(string); ok {
+						alert.Value = r.RedactText(s)
+					}
+				}
+			}
+		}
+	}
+}
+
+func (r *Redactor) redactReportTexts(report *define.PandoraReport) {
+	if report == nil {
+		return
+	}
+	walkMenus(report.ReportData, func(element *define.PandoraElement) {
+		element.InnerText = r.RedactText(element.InnerText)
+		element.ElementTitle = r.RedactText(element.ElementTitle)
+	})
+	for key, value := range report.Labels {
+		report.Labels[key] = r.RedactText(value)
+	}
+}
+
+// copyItems copies the items except the dropped metrics, details are converted to generic json values.
+func (r *Redactor) copyItems(items map[define.MetricName][]*define.YHCItem) (map[define.MetricName][]*define.YHCItem, error) {
+	if items == nil {
+		return nil, nil
+	}
+	res := make(map[define.MetricName][]*define.YHCItem, len(items))
+	for name, metricItems := range items {
+		if r.IsDropped(string(name)) {
+			continue
+		}
+		copied := make([]*define.YHCItem, 0, len(metricItems))
+		for _, item := range metricItems {
+			data, err := json.Marshal(item)
+			if err != nil {
+				return nil, err
+			}
+			newItem := new(define.YHCItem)
+			if err := json.Unmarshal(data, newItem); err != nil {
+				return nil, err
+			}
+			// name is not serialized
+			newItem.Name = item.Name
+			copied = append(copied, newItem)
+		}
+		res[name] = copied
+	}
+	return res, nil
+}
+
+func (r *Redactor) copyReport(report *define.PandoraReport) (*define.PandoraReport, error) {
+	if report == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(report)
+	if err != nil {
+		return nil, err
+	}
+	res := new(define.PandoraReport)
+	if err := json.Unmarshal(data, res); err != nil {
+		return nil, err
+	}
+	res.ReportData = r.dropMenuElements(res.ReportData)
+	return res, nil
+}
+
+func (r *Redactor) dropMenuElements(menus []*define.PandoraMenu) []*define.PandoraMenu {
+	for _, menu := range menus {
+		elements := make([]*define.PandoraElement, 0, len(menu.Elements))
+		for _, element := range menu.Elements {
+			if r.IsDropped(element.MetricName) {
+				continue
+			}
+			elements = append(elements, element)
+		}
+		menu.Elements = elements
+		menu.Children = r.dropMenuElements(menu.Children)
+	}
+	return menus
+}
+
+func walkMenus(menus []*define.PandoraMenu, fn func(*define.PandoraElement)) {
+	for _, menu := range menus {
+		for _, element := range menu.Elements {
+			fn(element)
+		}
+		walkMenus(menu.Children, fn)
+	}
+}
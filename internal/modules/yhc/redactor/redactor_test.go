@@ -0,0 +1,155 @@
+package redactor_test
+
+import (
+	"strings"
+	"testing"
+
+	"yhc/defs/confdef"
+	"yhc/internal/modules/yhc/check/define"
+	"yhc/internal/modules/yhc/redactor"
+)
+
+func newRedactor(t *testing.T) *redactor.Redactor {
+	r, err := redactor.NewRedactor(&confdef.RedactConf{
+		Salt:        "test",
+		Hostname:    confdef.RedactRule{Mode: confdef.REDACT_MODE_HASH, Keys: []string{"hostname"}},
+		IP:          confdef.RedactRule{Mode: confdef.REDACT_MODE_MASK, Keys: []string{"ipv4"}},
+		Username:    confdef.RedactRule{Mode: confdef.REDACT_MODE_MASK, Keys: []string{"USERNAME"}},
+		Sql:         confdef.SqlRedactRule{StripLiterals: true, Keys: []string{"sql_text"}},
+		DropMetrics: []string{"dropped"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+func TestStripSqlLiterals(t *testing.T) {
+	sql := "SELECT * FROM T1 WHERE NAME = 'it''s' AND ID IN (1, 2.5)"
+	expected := "SELECT * FROM T1 WHERE NAME = ? AND ID IN (?, ?)"
+	if res := redactor.StripSqlLiterals(sql); res != expected {
+		t.Errorf("expected %s, got %s", expected, res)
+	}
+}
+
+func TestRedact(t *testing.T) {
+	r := newRedactor(t)
+	items := map[define.MetricName][]*define.YHCItem{
+		"host_info": {{
+			Name:    "host_info",
+			Details: map[string]interface{}{"hostname": "db-node01", "os": "linux"},
+		}},
+		"sessions": {{
+			Name: "sessions",
+			Details: []map[string]string{
+				{"USERNAME": "scott_app", "SQL_TEXT": "select * from t where id = 10"},
+			},
+			Alerts: map[string][]*define.YHCAlert{
+				confdef.AL_WARNING: {{Labels: map[string]string{"username": "scott_app"}, Value: "10.1.2.3"}},
+			},
+		}},
+		"logs": {{
+			Name:    "logs",
+			Details: []string{"connect from db-node01 10.1.2.3 by scott_app", "granted to dba_role, sys time 10s"},
+		}},
+		"dropped": {{Name: "dropped", Details: "secret"}},
+		// the array under a known key is redacted element by element, the short values are only redacted by the key
+		"roles": {{
+			Name:    "roles",
+			Details: []map[string]interface{}{{"USERNAME": []string{"dba_role", "sys"}, "ROLE": "sys"}},
+		}},
+	}
+	report := &define.PandoraReport{
+		ReportData: []*define.PandoraMenu{{
+			Elements: []*define.PandoraElement{
+				{MetricName: "host_info", ElementType: define.ET_DESCRIPTION, Attributes: define.DescriptionAttributes{
+					Data: []*define.DescriptionData{{Label: "hostname", Value: "db-node01"}},
+				}},
+				{MetricName: "dropped", ElementType: define.ET_PRE, InnerText: "secret"},
+			},
+		}},
+	}
+	redactedItems, redactedReport, _, err := r.Redact(items, report, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := redactedItems["dropped"]; ok {
+		t.Error("dropped metric is not removed")
+	}
+	host := redactedItems["host_info"][0].Details.(map[string]interface{})["hostname"].(string)
+	if !strings.HasPrefix(host, "host-") {
+		t.Errorf("hostname is not hashed: %s", host)
+	}
+	session := redactedItems["sessions"][0].Details.([]interface{})[0].(map[string]interface{})
+	if session["USERNAME"] != redactor.MASK_TEXT || session["SQL_TEXT"] != "select * from t where id = ?" {
+		t.Errorf("unexpected session: %v", session)
+	}
+	alert := redactedItems["sessions"][0].Alerts[confdef.AL_WARNING][0]
+	if alert.Labels["username"] != redactor.MASK_TEXT || alert.Value != redactor.MASK_TEXT {
+		t.Errorf("unexpected alert: %+v", alert)
+	}
+	line := redactedItems["logs"][0].Details.([]interface{})[0].(string)
+	expected := "connect from " + host + " " + redactor.MASK_TEXT + " by " + redactor.MASK_TEXT
+	if line != expected {
+		t.Errorf("expected %s, got %s", expected, line)
+	}
+	if line := redactedItems["logs"][0].Details.([]interface{})[1].(string); line != "granted to "+redactor.MASK_TEXT+", sys time 10s" {
+		t.Errorf("unexpected line: %s", line)
+	}
+	role := redactedItems["roles"][0].Details.([]interface{})[0].(map[string]interface{})
+	if users := role["USERNAME"].([]interface{}); users[0] != redactor.MASK_TEXT || users[1] != redactor.MASK_TEXT || role["ROLE"] != "sys" {
+		t.Errorf("unexpected role: %v", role)
+	}
+	elements := redactedReport.ReportData[0].Elements
+	if len(elements) != 1 {
+		t.Fatalf("dropped element is not removed")
+	}
+	data := elements[0].Attributes.(map[string]interface{})["data"].([]interface{})[0].(map[string]interface{})
+	if data["value"] != host {
+		t.Errorf("description is not redacted consistently: %v", data)
+	}
+	if items["host_info"][0].Details.(map[string]interface{})["hostname"] != "db-node01" {
+		t.Error("the origin items are modified")
+	}
+}
+
+func TestReplaceIPv6(t *testing.T) {
+	mask := func(string) string { return redactor.MASK_TEXT }
+	cases := map[string]string{
+		"connect from 2001:db8::8a2e:370:7334 failed":     "connect from ****** failed",
+		"listen on [fe80::1]:1688":                        "listen on [******]:1688",
+		"peer fe80::a00:27ff:fe4e:66a1%eth0: reset":       "peer ******%eth0: reset",
+		"mapped ::ffff:10.1.2.3 and 2001:DB8:0:0:0:0:0:1": "mapped ****** and ******",
+		// loopback, unspecified, times and mac addresses are kept
+		"local ::1 and :: at 12:30:45": "local ::1 and :: at 12:30:45",
+		"mac 52:54:00:12:34:56":        "mac 52:54:00:12:34:56",
+		"sql select a::int from t":     "sql select a::int from t",
+	}
+	for text, expected := range cases {
+		if res := redactor.ReplaceIPv6(text, mask); res != expected {
+			t.Errorf("expected %s, got %s", expected, res)
+		}
+	}
+}
+
+func TestRedactHostname(t *testing.T) {
+	r := newRedactor(t)
+	items := map[define.MetricName][]*define.YHCItem{
+		"logs": {{Name: "logs", Details: []string{"listen on fe80::1:2"}}},
+	}
+	redactedItems, _, _, err := r.Redact(items, &define.PandoraReport{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if line := redactedItems["logs"][0].Details.([]interface{})[0].(string); line != "listen on "+redactor.MASK_TEXT {
+		t.Errorf("ipv6 address is not redacted: %s", line)
+	}
+	// no metric has the hostname, the hostname of the manifest is still redacted by the hostname rule
+	host := r.RedactHostname("db-node01")
+	if !strings.HasPrefix(host, "host-") {
+		t.Errorf("hostname is not hashed: %s", host)
+	}
+	if text := r.RedactText("executed on db-node01"); text != "executed on "+host {
+		t.Errorf("hostname is not redacted consistently: %s", text)
+	}
+}
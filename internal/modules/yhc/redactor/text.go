@@ -0,0 +1,95 @@
+package redactor
+
+import (
+	"net"
+	"regexp"
+	"strings"
+)
+
+var (
+	_ipv4Regexp = regexp.MustCompile(`\b(?:(?:25[0-5]|2[0-4]\d|1\d\d|[1-9]?\d)\.){3}(?:25[0-5]|2[0-4]\d|1\d\d|[1-9]?\d)\b`)
+	// the candidates of ipv6 addresses, they are validated by net.ParseIP, so that times and mac addresses are kept
+	_ipv6CandidateRegexp = regexp.MustCompile(`[0-9A-Fa-f]{0,4}(?::[0-9A-Fa-f]{0,4}){2,7}(?:(?:\.\d{1,3}){3})?`)
+	// quoted strings first, so that the numbers in strings are not replaced twice
+	_sqlLiteralRegexp = regexp.MustCompile(`'(?:[^']|'')*'|\b\d+(?:\.\d+)?\b`)
+)
+
+const (
+	_sql_literal_placeholder = "?"
+)
+
+// StripSqlLiterals replaces string and number literals in the sql with '?',
+// numbers which are part of identifiers, such as 'T1', are kept.
+func StripSqlLiterals(sql string) string {
+	return _sqlLiteralRegexp.ReplaceAllString(sql, _sql_literal_placeholder)
+}
+
+// ReplaceIPv4 replaces the ipv4 addresses in the text with fn, the loopback and unspecified addresses are kept.
+func ReplaceIPv4(s string, fn redactFunc) string {
+	if fn == nil {
+		return s
+	}
+	return _ipv4Regexp.ReplaceAllStringFunc(s, func(ip string) string {
+		if strings.HasPrefix(ip, "127.") || ip == "0.0.0.0" {
+			return ip
+		}
+		return fn(ip)
+	})
+}
+
+// ReplaceIPv6 replaces the ipv6 addresses in the text with fn, the loopback and unspecified addresses are kept.
+// The ipv4-mapped addresses, such as '::ffff:10.1.2.3', are replaced as a whole.
+func ReplaceIPv6(s string, fn redactFunc) string {
+	if fn == nil {
+		return s
+	}
+	var b strings.Builder
+	last := 0
+	for _, loc := range _ipv6CandidateRegexp.FindAllStringIndex(s, -1) {
+		start, end := loc[0], loc[1]
+		// the colons at the end belong to the text, such as 'addr fe80::1: unreachable'
+		for end > start+2 && s[end-1] == ':' && s[end-2] != ':' {
+			end--
+		}
+		if !isBoundary(s, start-1) || !isBoundary(s, end) {
+			continue
+		}
+		ip := net.ParseIP(s[start:end])
+		if ip == nil || ip.IsLoopback() || ip.IsUnspecified() {
+			continue
+		}
+		b.WriteString(s[last:start])
+		b.WriteString(fn(s[start:end]))
+		last = end
+	}
+	b.WriteString(s[last:])
+	return b.String()
+}
+
+// replaceWord replaces old with new in s, only when old is not a part of a longer word.
+func replaceWord(s, old, new string) string {
+	var b strings.Builder
+	for {
+		index := strings.Index(s, old)
+		if index < 0 {
+			b.WriteString(s)
+			return b.String()
+		}
+		end := index + len(old)
+		if isBoundary(s, index-1) && isBoundary(s, end) {
+			b.WriteString(s[:index])
+			b.WriteString(new)
+		} else {
+			b.WriteString(s[:end])
+		}
+		s = s[end:]
+	}
+}
+
+func isBoundary(s string, index int) bool {
+	if index < 0 || index >= len(s) {
+		return true
+	}
+	c := s[index]
+	return !(c == '_' || c == '-' || (c >= '0' && c <= '9') || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z'))
+}
@@ -0,0 +1,147 @@
+// The resultpkg package maintains the integrity of the result packages of health checks.
+// A manifest with the SHA-256 of every file is written into the package before it is packed,
+// and the manifest can be signed with a local ed25519 key.
+package resultpkg
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"yhc/utils/fileutil"
+)
+
+const (
+	MANIFEST_FILE  = "manifest.json"
+	SIGNATURE_FILE = "manifest.sig"
+
+	MANIFEST_VERSION = 1
+)
+
+type Manifest struct {
+	Version     int           `json:"version"`
+	CreateTime  time.Time     `json:"createTime"`
+	App         AppInfo       `json:"app"`
+	Host        HostInfo      `json:"host"`
+	Database    *DatabaseInfo `json:"database,omitempty"`
+	CheckWindow CheckWindow   `json:"checkWindow"`
+	Profile     string        `json:"profile,omitempty"`
+	Summary     *Summary      `json:"summary,omitempty"`
+	Redacted    bool          `json:"redacted,omitempty"`
+	Files       []*FileDigest `json:"files"`
+}
+
+type AppInfo struct {
+	Version     string `json:"version"`
+	GitCommitID string `json:"gitCommitID"`
+	GitDescribe string `json:"gitDescribe"`
+	GoVersion   string `json:"goVersion"`
+}
+
+type HostInfo struct {
+	Hostname string `json:"hostname"`
+	OS       string `json:"os,omitempty"`
+	Executer string `json:"executer,omitempty"`
+}
+
+type DatabaseInfo struct {
+	DatabaseName string `json:"databaseName,omitempty"`
+	YasdbHome    string `json:"yasdbHome,omitempty"`
+	YasdbData    string `json:"yasdbData,omitempty"`
+	ListenAddr   string `json:"listenAddr,omitempty"`
+}
+
+// CheckWindow contains the time range of the checked data and the time when the check ran.
+type CheckWindow struct {
+	Start      time.Time `json:"start"`
+	End        time.Time `json:"end"`
+	CheckBegin time.Time `json:"checkBegin"`
+	CheckEnd   time.Time `json:"checkEnd"`
+}
+
+// Summary contains the evaluation of the check, so that packages can be listed without unpacking the reports.
+type Summary struct {
+	Score         float64 `json:"score"`
+	HealthStatus  string  `json:"healthStatus,omitempty"`
+	CriticalCount int     `json:"criticalCount"`
+	WarningCount  int     `json:"warningCount"`
+	InfoCount     int     `json:"infoCount"`
+}
+
+type FileDigest struct {
+	Path   string `json:"path"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// GenManifest fills the digests of all files in dir and writes the manifest to dir,
+// the manifest bytes are returned so that they can be signed.
+func GenManifest(dir string, manifest *Manifest) ([]byte, error) {
+	manifest.Version = MANIFEST_VERSION
+	manifest.Files = []*FileDigest{}
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, walkErr error) error {
+		if walkErr != nil {
+			return walkErr
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if isManifestFile(rel) {
+			return nil
+		}
+		f, err := os.Open(p)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		digest, err := Digest(rel, f)
+		if err != nil {
+			return err
+		}
+		manifest.Files = append(manifest.Files, digest)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(manifest.Files, func(i, j int) bool {
+		return manifest.Files[i].Path < manifest.Files[j].Path
+	})
+	data, err := json.MarshalIndent(manifest, "", "    ")
+	if err != nil {
+		return nil, err
+	}
+	if err := fileutil.WriteFile(filepath.Join(dir, MANIFEST_FILE), data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// Digest calculates the SHA-256 of the content read from reader.
+func Digest(name string, reader io.Reader) (*FileDigest, error) {
+	h := sha256.New()
+	size, err := io.Copy(h, reader)
+	if err != nil {
+		return nil, err
+	}
+	return &FileDigest{
+		Path:   name,
+		Size:   size,
+		SHA256: hex.EncodeToString(h.Sum(nil)),
+	}, nil
+}
+
+func isManifestFile(name string) bool {
+	return name == MANIFEST_FILE || name == SIGNATURE_FILE
+}
@@ -0,0 +1,88 @@
+package resultpkg_test
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"yhc/internal/modules/yhc/resultpkg"
+	"yhc/utils/tarutil"
+)
+
+func genPackage(t *testing.T) string {
+	dir := filepath.Join(t.TempDir(), "yhc-test")
+	if err := os.MkdirAll(filepath.Join(dir, "data"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range map[string]string{"report.html": "<html></html>", "data/data.json": "{}"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func writeKeys(t *testing.T, dir string) (string, string) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	privateBytes, _ := x509.MarshalPKCS8PrivateKey(privateKey)
+	publicBytes, _ := x509.MarshalPKIXPublicKey(publicKey)
+	privateFile, publicFile := filepath.Join(dir, "yhc.key"), filepath.Join(dir, "yhc.pub")
+	os.WriteFile(privateFile, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateBytes}), 0600)
+	os.WriteFile(publicFile, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicBytes}), 0644)
+	return privateFile, publicFile
+}
+
+func TestVerifyPackage(t *testing.T) {
+	dir := genPackage(t)
+	privateFile, publicFile := writeKeys(t, t.TempDir())
+	manifest, err := resultpkg.GenManifest(dir, &resultpkg.Manifest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	privateKey, err := resultpkg.LoadPrivateKey(privateFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := resultpkg.SignManifest(dir, manifest, privateKey); err != nil {
+		t.Fatal(err)
+	}
+	publicKey, err := resultpkg.LoadPublicKey(publicFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tarFile := dir + ".tar.gz"
+	if err := tarutil.TarGz(dir, tarFile); err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range []string{dir, tarFile} {
+		result, err := resultpkg.VerifyPackage(p, publicKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !result.OK() || !result.Trusted || len(result.Manifest.Files) != 2 {
+			t.Fatalf("unexpected result of %s: %+v", p, result)
+		}
+	}
+
+	// tamper the package
+	os.WriteFile(filepath.Join(dir, "report.html"), []byte("<html>changed</html>"), 0644)
+	os.WriteFile(filepath.Join(dir, "extra.txt"), []byte("extra"), 0644)
+	os.Remove(filepath.Join(dir, "data", "data.json"))
+	result, err := resultpkg.VerifyPackage(dir, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.OK() || len(result.Mismatched) != 1 || len(result.Missing) != 1 || len(result.Unexpected) != 1 {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+	if !result.Signed || result.SignatureError != nil || result.Trusted {
+		t.Fatalf("unexpected signature result: %+v", result)
+	}
+}
@@ -0,0 +1,130 @@
+package resultpkg
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"yhc/utils/fileutil"
+)
+
+const (
+	ALGORITHM_ED25519 = "ed25519"
+
+	_pem_private_key = "PRIVATE KEY"
+	_pem_public_key  = "PUBLIC KEY"
+)
+
+var (
+	ErrNotEd25519Key = errors.New("the key is not an ed25519 key")
+)
+
+// Signature is written to manifest.sig, the public key is carried so that the
+// package can be checked without the key, the fingerprint must be compared out of band.
+type Signature struct {
+	Algorithm string `json:"algorithm"`
+	PublicKey string `json:"publicKey"`
+	Signature string `json:"signature"`
+}
+
+// LoadPrivateKey reads a PKCS #8 PEM encoded ed25519 private key, such as the one generated by
+// 'openssl genpkey -algorithm ed25519 -out yhc.key'.
+func LoadPrivateKey(fname string) (ed25519.PrivateKey, error) {
+	block, err := readPem(fname, _pem_private_key)
+	if err != nil {
+		return nil, err
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	privateKey, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, ErrNotEd25519Key
+	}
+	return privateKey, nil
+}
+
+// LoadPublicKey reads a PKIX PEM encoded ed25519 public key, such as the one generated by
+// 'openssl pkey -in yhc.key -pubout -out yhc.pub'.
+func LoadPublicKey(fname string) (ed25519.PublicKey, error) {
+	block, err := readPem(fname, _pem_public_key)
+	if err != nil {
+		return nil, err
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	publicKey, ok := key.(ed25519.PublicKey)
+	if !ok {
+		return nil, ErrNotEd25519Key
+	}
+	return publicKey, nil
+}
+
+// SignManifest signs the manifest bytes and writes the signature to dir.
+func SignManifest(dir string, manifest []byte, key ed25519.PrivateKey) error {
+	signature := &Signature{
+		Algorithm: ALGORITHM_ED25519,
+		PublicKey: base64.StdEncoding.EncodeToString(key.Public().(ed25519.PublicKey)),
+		Signature: base64.StdEncoding.EncodeToString(ed25519.Sign(key, manifest)),
+	}
+	data, err := json.MarshalIndent(signature, "", "    ")
+	if err != nil {
+		return err
+	}
+	return fileutil.WriteFile(filepath.Join(dir, SIGNATURE_FILE), data)
+}
+
+// Fingerprint returns the hex encoded SHA-256 of the public key.
+func Fingerprint(key ed25519.PublicKey) string {
+	sum := sha256.Sum256(key)
+	return hex.EncodeToString(sum[:])
+}
+
+// verifySignature checks the signature of the manifest and returns the public key used to sign.
+func verifySignature(manifest, signatureData []byte) (ed25519.PublicKey, error) {
+	signature := new(Signature)
+	if err := json.Unmarshal(signatureData, signature); err != nil {
+		return nil, err
+	}
+	if signature.Algorithm != ALGORITHM_ED25519 {
+		return nil, fmt.Errorf("unsupported signature algorithm %s", signature.Algorithm)
+	}
+	publicKey, err := base64.StdEncoding.DecodeString(signature.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+	if len(publicKey) != ed25519.PublicKeySize {
+		return nil, ErrNotEd25519Key
+	}
+	sig, err := base64.StdEncoding.DecodeString(signature.Signature)
+	if err != nil {
+		return nil, err
+	}
+	if !ed25519.Verify(publicKey, manifest, sig) {
+		return publicKey, errors.New("signature does not match the manifest")
+	}
+	return publicKey, nil
+}
+
+func readPem(fname string, blockType string) (*pem.Block, error) {
+	data, err := os.ReadFile(fname)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != blockType {
+		return nil, fmt.Errorf("no %s pem block found in %s", blockType, fname)
+	}
+	return block, nil
+}
@@ -0,0 +1,141 @@
+package resultpkg
+
+import (
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"yhc/utils/tarutil"
+)
+
+var (
+	ErrManifestNotFound = errors.New("manifest.json not found in the result package")
+)
+
+type VerifyResult struct {
+	Manifest   *Manifest
+	Mismatched []string // files whose size or SHA-256 differs from the manifest
+	Missing    []string // files in the manifest but not in the package
+	Unexpected []string // files in the package but not in the manifest
+
+	Signed         bool
+	SignatureError error
+	Fingerprint    string
+	// Trusted is true when the signature is made by the public key given by the user
+	Trusted bool
+}
+
+// OK returns true when all files match the manifest and the signature, if any, is valid.
+func (r *VerifyResult) OK() bool {
+	return len(r.Mismatched) == 0 && len(r.Missing) == 0 && len(r.Unexpected) == 0 && r.SignatureError == nil
+}
+
+// VerifyPackage checks a result package, which can be the yhc-*.tar.gz file or the unpacked directory.
+// If trustedKey is not nil, the package must be signed by the key.
+func VerifyPackage(p string, trustedKey ed25519.PublicKey) (*VerifyResult, error) {
+	digests, manifestData, signatureData, err := readPackage(p)
+	if err != nil {
+		return nil, err
+	}
+	if manifestData == nil {
+		return nil, ErrManifestNotFound
+	}
+	manifest := new(Manifest)
+	if err := json.Unmarshal(manifestData, manifest); err != nil {
+		return nil, err
+	}
+	result := &VerifyResult{Manifest: manifest}
+	compareDigests(result, manifest, digests)
+	if signatureData != nil {
+		result.Signed = true
+		publicKey, err := verifySignature(manifestData, signatureData)
+		result.SignatureError = err
+		if publicKey != nil {
+			result.Fingerprint = Fingerprint(publicKey)
+		}
+		if err == nil && trustedKey != nil {
+			result.Trusted = publicKey.Equal(trustedKey)
+			if !result.Trusted {
+				result.SignatureError = errors.New("the package is not signed by the given public key")
+			}
+		}
+	} else if trustedKey != nil {
+		result.SignatureError = errors.New("the package is not signed")
+	}
+	return result, nil
+}
+
+func compareDigests(result *VerifyResult, manifest *Manifest, digests map[string]*FileDigest) {
+	expected := make(map[string]*FileDigest, len(manifest.Files))
+	for _, file := range manifest.Files {
+		expected[file.Path] = file
+		actual, ok := digests[file.Path]
+		if !ok {
+			result.Missing = append(result.Missing, file.Path)
+			continue
+		}
+		if actual.Size != file.Size || actual.SHA256 != file.SHA256 {
+			result.Mismatched = append(result.Mismatched, file.Path)
+		}
+	}
+	for name := range digests {
+		if _, ok := expected[name]; !ok {
+			result.Unexpected = append(result.Unexpected, name)
+		}
+	}
+	sort.Strings(result.Unexpected)
+}
+
+func readPackage(p string) (digests map[string]*FileDigest, manifest, signature []byte, err error) {
+	digests = make(map[string]*FileDigest)
+	collect := func(name string, reader io.Reader) (e error) {
+		switch name {
+		case MANIFEST_FILE:
+			manifest, e = io.ReadAll(reader)
+			return
+		case SIGNATURE_FILE:
+			signature, e = io.ReadAll(reader)
+			return
+		}
+		digest, err := Digest(name, reader)
+		if err != nil {
+			return err
+		}
+		digests[name] = digest
+		return nil
+	}
+	info, err := os.Stat(p)
+	if err != nil {
+		return
+	}
+	if !info.IsDir() {
+		err = tarutil.WalkTarGz(p, func(name string, size int64, reader io.Reader) error {
+			return collect(tarutil.TrimTopDir(name), reader)
+		})
+		return
+	}
+	err = filepath.WalkDir(p, func(fname string, d fs.DirEntry, walkErr error) error {
+		if walkErr != nil {
+			return walkErr
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(p, fname)
+		if err != nil {
+			return err
+		}
+		f, err := os.Open(fname)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		return collect(filepath.ToSlash(rel), f)
+	})
+	return
+}
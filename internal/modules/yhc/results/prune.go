@@ -0,0 +1,75 @@
+package results
+
+import (
+	"os"
+	"time"
+
+	"yhc/defs/confdef"
+)
+
+type PruneResult struct {
+	Kept      []*Package
+	Expired   []*Package
+	FreedSize int64
+	DryRun    bool
+}
+
+// Plan splits the packages sorted by time descending into the kept and the expired ones.
+// The newest package and the newest KeepLastPerProfile packages of every profile are always kept,
+// the others are expired once any limit of the policy is exceeded. Once a package exceeds the total size,
+// all the packages older than it are expired too, so that an older package is never kept instead of a newer one.
+func Plan(pkgs []*Package, policy confdef.RetentionPolicy, now time.Time) (kept, expired []*Package) {
+	var keptSize int64
+	var full bool
+	profileCount := make(map[string]int)
+	for i, pkg := range pkgs {
+		profile := pkg.GetProfile()
+		protected := i == 0 || profileCount[profile] < policy.KeepLastPerProfile
+		profileCount[profile]++
+		if !protected && policy.MaxTotalSize > 0 && keptSize+pkg.Size > policy.MaxTotalSize {
+			full = true
+		}
+		if !protected && (full || isExpired(pkg, policy, now, len(kept))) {
+			expired = append(expired, pkg)
+			continue
+		}
+		kept = append(kept, pkg)
+		keptSize += pkg.Size
+	}
+	return
+}
+
+// Prune removes the expired packages in dir, nothing is removed if dryRun is true.
+func Prune(dir string, policy confdef.RetentionPolicy, dryRun bool) (*PruneResult, error) {
+	pkgs, err := List(dir)
+	if err != nil {
+		return nil, err
+	}
+	if policy.KeepLastPerProfile > 0 {
+		for _, pkg := range pkgs {
+			// a broken package has no profile and can still be pruned
+			pkg.Manifest, _ = readManifest(pkg.Path)
+		}
+	}
+	res := &PruneResult{DryRun: dryRun}
+	res.Kept, res.Expired = Plan(pkgs, policy, time.Now())
+	for _, pkg := range res.Expired {
+		if !dryRun {
+			if err := os.Remove(pkg.Path); err != nil {
+				return res, err
+			}
+		}
+		res.FreedSize += pkg.Size
+	}
+	return res, nil
+}
+
+func isExpired(pkg *Package, policy confdef.RetentionPolicy, now time.Time, keptCount int) bool {
+	if policy.MaxAge > 0 && now.Sub(pkg.Time) > policy.MaxAge {
+		return true
+	}
+	if policy.MaxCount > 0 && keptCount >= policy.MaxCount {
+		return true
+	}
+	return false
+}
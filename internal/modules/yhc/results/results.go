@@ -0,0 +1,169 @@
+// The results package lists the result packages in the output directory
+// and removes the expired ones by the retention policy.
+package results
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"yhc/defs/confdef"
+	"yhc/defs/timedef"
+	"yhc/internal/modules/yhc/check/define"
+	"yhc/internal/modules/yhc/resultpkg"
+	"yhc/utils/tarutil"
+)
+
+const (
+	REDACTED_SUFFIX = "-redacted"
+
+	_package_prefix = "yhc-"
+	_tar_gz_ext     = ".tar.gz"
+	_data_prefix    = "data/data-"
+	_json_ext       = ".json"
+)
+
+// errStopWalk stops walking the package once the needed file has been read.
+var errStopWalk = errors.New("stop walk")
+
+type Package struct {
+	Name     string
+	Path     string
+	Time     time.Time // begin time of the check, parsed from the package name
+	Size     int64
+	Redacted bool
+	// Manifest is nil if the package was generated by an old version
+	Manifest *resultpkg.Manifest
+	// Summary is read from the manifest, or counted from the data json without a score
+	Summary *resultpkg.Summary
+}
+
+// GetProfile returns the profile of the package, packages without manifest have an empty profile.
+func (p *Package) GetProfile() string {
+	if p.Manifest == nil {
+		return ""
+	}
+	return p.Manifest.Profile
+}
+
+// GetDatabaseName returns the database name recorded in the manifest.
+func (p *Package) GetDatabaseName() string {
+	if p.Manifest == nil || p.Manifest.Database == nil {
+		return ""
+	}
+	return p.Manifest.Database.DatabaseName
+}
+
+// List returns the result packages in dir sorted by time descending,
+// files which are not named like 'yhc-<time>.tar.gz' are ignored.
+func List(dir string) ([]*Package, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	pkgs := []*Package{}
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() {
+			continue
+		}
+		pkg, ok := parsePackageName(entry.Name())
+		if !ok {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return nil, err
+		}
+		pkg.Path = path.Join(dir, entry.Name())
+		pkg.Size = info.Size()
+		pkgs = append(pkgs, pkg)
+	}
+	sort.SliceStable(pkgs, func(i, j int) bool {
+		return pkgs[i].Time.After(pkgs[j].Time)
+	})
+	return pkgs, nil
+}
+
+// LoadSummary reads the manifest of the package, the alert counts are read from the data json
+// if the package has no manifest.
+func LoadSummary(pkg *Package) error {
+	manifest, err := readManifest(pkg.Path)
+	if err != nil {
+		return err
+	}
+	if manifest != nil {
+		pkg.Manifest = manifest
+		pkg.Summary = manifest.Summary
+		return nil
+	}
+	pkg.Summary, err = countAlerts(pkg.Path)
+	return err
+}
+
+func parsePackageName(name string) (*Package, bool) {
+	if !strings.HasPrefix(name, _package_prefix) || !strings.HasSuffix(name, _tar_gz_ext) {
+		return nil, false
+	}
+	pkg := &Package{Name: name}
+	timeStr := strings.TrimSuffix(strings.TrimPrefix(name, _package_prefix), _tar_gz_ext)
+	if strings.HasSuffix(timeStr, REDACTED_SUFFIX) {
+		pkg.Redacted = true
+		timeStr = strings.TrimSuffix(timeStr, REDACTED_SUFFIX)
+	}
+	t, err := time.ParseInLocation(timedef.TIME_FORMAT_IN_FILE, timeStr, time.Local)
+	if err != nil {
+		return nil, false
+	}
+	pkg.Time = t
+	return pkg, true
+}
+
+func readManifest(fname string) (*resultpkg.Manifest, error) {
+	var manifest *resultpkg.Manifest
+	err := tarutil.WalkTarGz(fname, func(name string, size int64, reader io.Reader) error {
+		if tarutil.TrimTopDir(name) != resultpkg.MANIFEST_FILE {
+			return nil
+		}
+		manifest = new(resultpkg.Manifest)
+		if err := json.NewDecoder(reader).Decode(manifest); err != nil {
+			return err
+		}
+		return errStopWalk
+	})
+	if err != nil && err != errStopWalk {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+func countAlerts(fname string) (*resultpkg.Summary, error) {
+	var summary *resultpkg.Summary
+	err := tarutil.WalkTarGz(fname, func(name string, size int64, reader io.Reader) error {
+		name = tarutil.TrimTopDir(name)
+		if !strings.HasPrefix(name, _data_prefix) || !strings.HasSuffix(name, _json_ext) {
+			return nil
+		}
+		items := make(map[define.MetricName][]*define.YHCItem)
+		if err := json.NewDecoder(reader).Decode(&items); err != nil {
+			return err
+		}
+		summary = new(resultpkg.Summary)
+		for _, metricItems := range items {
+			for _, item := range metricItems {
+				summary.CriticalCount += len(item.Alerts[confdef.AL_CRITICAL])
+				summary.WarningCount += len(item.Alerts[confdef.AL_WARNING])
+				summary.InfoCount += len(item.Alerts[confdef.AL_INFO])
+			}
+		}
+		return errStopWalk
+	})
+	if err != nil && err != errStopWalk {
+		return nil, err
+	}
+	return summary, nil
+}
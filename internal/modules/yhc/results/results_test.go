@@ -0,0 +1,120 @@
+package results_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"yhc/defs/confdef"
+	"yhc/defs/timedef"
+	"yhc/internal/modules/yhc/resultpkg"
+	"yhc/internal/modules/yhc/results"
+	"yhc/utils/tarutil"
+)
+
+func genPackage(t *testing.T, output string, begin time.Time, profile string) {
+	tmp := t.TempDir()
+	dir := filepath.Join(tmp, "yhc-"+begin.Format(timedef.TIME_FORMAT_IN_FILE))
+	if err := os.MkdirAll(filepath.Join(dir, "data"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	manifest := &resultpkg.Manifest{
+		Profile: profile,
+		Summary: &resultpkg.Summary{Score: 90, CriticalCount: 1},
+	}
+	if _, err := resultpkg.GenManifest(dir, manifest); err != nil {
+		t.Fatal(err)
+	}
+	if err := tarutil.TarGz(dir, filepath.Join(output, filepath.Base(dir)+".tar.gz")); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestList(t *testing.T) {
+	output := t.TempDir()
+	now := time.Now().Truncate(time.Second)
+	genPackage(t, output, now.Add(-time.Hour), "check")
+	genPackage(t, output, now, "after-install")
+	os.WriteFile(filepath.Join(output, "yhc-invalid.tar.gz"), []byte{}, 0644)
+	pkgs, err := results.List(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pkgs) != 2 || !pkgs[0].Time.Equal(now) {
+		t.Fatalf("unexpected packages: %v", pkgs)
+	}
+	if err := results.LoadSummary(pkgs[0]); err != nil {
+		t.Fatal(err)
+	}
+	if pkgs[0].GetProfile() != "after-install" || pkgs[0].Summary == nil || pkgs[0].Summary.CriticalCount != 1 {
+		data, _ := json.Marshal(pkgs[0])
+		t.Fatalf("unexpected package: %s", data)
+	}
+}
+
+func TestPlan(t *testing.T) {
+	now := time.Now()
+	var pkgs []*results.Package
+	for i := 0; i < 6; i++ {
+		profile := "check"
+		if i == 4 {
+			profile = "after-install"
+		}
+		pkgs = append(pkgs, &results.Package{
+			Name:     string(rune('a' + i)),
+			Time:     now.Add(-time.Duration(i) * 24 * time.Hour),
+			Size:     100,
+			Manifest: &resultpkg.Manifest{Profile: profile},
+		})
+	}
+	cases := []struct {
+		policy  confdef.RetentionPolicy
+		expired string
+	}{
+		{confdef.RetentionPolicy{}, ""},
+		{confdef.RetentionPolicy{MaxCount: 3}, "def"},
+		{confdef.RetentionPolicy{MaxCount: 3, KeepLastPerProfile: 1}, "df"},
+		{confdef.RetentionPolicy{MaxAge: 36 * time.Hour}, "cdef"},
+		{confdef.RetentionPolicy{MaxTotalSize: 250}, "cdef"},
+		{confdef.RetentionPolicy{MaxTotalSize: 1}, "bcdef"},
+	}
+	for _, c := range cases {
+		_, expired := results.Plan(pkgs, c.policy, now)
+		names := ""
+		for _, pkg := range expired {
+			names += pkg.Name
+		}
+		if names != c.expired {
+			t.Errorf("policy %+v: expected %q expired, got %q", c.policy, c.expired, names)
+		}
+	}
+	// the packages older than the one exceeding the total size are expired even if they are smaller
+	pkgs[1].Size, pkgs[2].Size = 200, 50
+	_, expired := results.Plan(pkgs, confdef.RetentionPolicy{MaxTotalSize: 250}, now)
+	if len(expired) != 5 || expired[0].Name != "b" {
+		t.Errorf("unexpected expired packages: %v", expired)
+	}
+}
+
+func TestPrune(t *testing.T) {
+	output := t.TempDir()
+	now := time.Now()
+	for i := 0; i < 3; i++ {
+		genPackage(t, output, now.Add(-time.Duration(i)*time.Hour), "check")
+	}
+	res, err := results.Prune(output, confdef.RetentionPolicy{MaxCount: 1}, true)
+	if err != nil || len(res.Expired) != 2 {
+		t.Fatalf("unexpected dry run result: %v, err: %v", res, err)
+	}
+	if pkgs, _ := results.List(output); len(pkgs) != 3 {
+		t.Fatal("packages are removed in dry run")
+	}
+	if _, err := results.Prune(output, confdef.RetentionPolicy{MaxCount: 1}, false); err != nil {
+		t.Fatal(err)
+	}
+	if pkgs, _ := results.List(output); len(pkgs) != 1 {
+		t.Fatalf("expected 1 package left, got %d", len(pkgs))
+	}
+}
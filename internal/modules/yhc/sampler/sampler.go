@@ -0,0 +1,108 @@
+package sampler
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"yhc/internal/modules/yhc/check/define"
+	"yhc/internal/modules/yhc/check/gopsutil"
+
+	"git.yasdb.com/go/yaslog"
+)
+
+// WorkloadTypes are the workload types sampled by yhcd.
+var WorkloadTypes = []define.WorkloadType{
+	define.WT_CPU,
+	define.WT_MEMORY,
+	define.WT_DISK,
+	define.WT_NETWORK,
+}
+
+// IsSampled reports whether the workload type is sampled by yhcd.
+func IsSampled(t define.WorkloadType) bool {
+	for _, sampled := range WorkloadTypes {
+		if sampled == t {
+			return true
+		}
+	}
+	return false
+}
+
+// Sampler samples the workload types every interval seconds by gopsutil and appends the samples to the store.
+type Sampler struct {
+	log      yaslog.YasLog
+	store    *Store
+	interval int
+}
+
+func NewSampler(log yaslog.YasLog, store *Store, interval int) *Sampler {
+	return &Sampler{log: log, store: store, interval: interval}
+}
+
+// Run samples until the context is done, the failures are logged and retried in the next interval.
+func (s *Sampler) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, t := range WorkloadTypes {
+		wg.Add(1)
+		go func(t define.WorkloadType) {
+			defer wg.Done()
+			s.sample(ctx, t)
+		}(t)
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		s.prune(ctx)
+	}()
+	wg.Wait()
+}
+
+func (s *Sampler) sample(ctx context.Context, t define.WorkloadType) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+		// the collectors sleep an interval, so the samples are continuous
+		output, err := gopsutil.Collect(t, s.interval, 1)
+		if err != nil {
+			s.log.Errorf("failed to sample %s, err: %v", t, err)
+			if !sleep(ctx, time.Duration(s.interval)*time.Second) {
+				return
+			}
+			continue
+		}
+		for timestamp, item := range output {
+			if err := s.store.Append(t, timestamp, item); err != nil {
+				s.log.Errorf("failed to store the sample of %s, err: %v", t, err)
+			}
+		}
+	}
+}
+
+func (s *Sampler) prune(ctx context.Context) {
+	for {
+		if removed, err := s.store.Prune(time.Now()); err != nil {
+			s.log.Errorf("failed to prune the samples, err: %v", err)
+		} else if removed != 0 {
+			s.log.Infof("pruned %d expired segments of the samples", removed)
+		}
+		if !sleep(ctx, SEGMENT_DURATION) {
+			return
+		}
+	}
+}
+
+// sleep sleeps d, returns false if the context is done.
+func sleep(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
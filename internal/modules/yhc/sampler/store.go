@@ -0,0 +1,174 @@
+// The sampler package samples the host workload in yhcd by gopsutil and keeps the samples in a local ring buffer,
+// which is read as the history workload on the hosts without sysstat.
+package sampler
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"yhc/internal/modules/yhc/check/define"
+)
+
+const (
+	// SEGMENT_DURATION is the time range of a segment file, the expired samples are removed by segments
+	SEGMENT_DURATION = time.Hour
+
+	_segment_ext = ".jsonl"
+	_dir_perm    = 0750
+	_file_perm   = 0640
+)
+
+// record is a line of a segment file, the keys are short to keep the files compact.
+type record struct {
+	Time  int64               `json:"t"`
+	Items define.WorkloadItem `json:"v"`
+}
+
+// Store is a ring buffer of the samples on disk, the samples of a workload type are appended to the hourly segment files
+// under the directory of the type, and the segments older than the retention are removed.
+type Store struct {
+	dir       string
+	retention time.Duration
+	mtx       sync.Mutex
+}
+
+func NewStore(dir string, retention time.Duration) *Store {
+	return &Store{dir: dir, retention: retention}
+}
+
+// Append appends the sample of the workload type taken at timestamp.
+func (s *Store) Append(t define.WorkloadType, timestamp int64, item define.WorkloadItem) error {
+	line, err := json.Marshal(record{Time: timestamp, Items: item})
+	if err != nil {
+		return err
+	}
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	dir := path.Join(s.dir, string(t))
+	if err := os.MkdirAll(dir, _dir_perm); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path.Join(dir, segmentName(timestamp)), os.O_CREATE|os.O_APPEND|os.O_WRONLY, _file_perm)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.Write(append(line, '\n'))
+	return err
+}
+
+// Read returns the samples of the workload type between start and end, the missing directory means no samples.
+// The values of the items are decoded from json as maps.
+func (s *Store) Read(t define.WorkloadType, start, end time.Time) (define.WorkloadOutput, error) {
+	res := make(define.WorkloadOutput)
+	segments, err := s.segments(t)
+	if err != nil {
+		return res, err
+	}
+	for _, segment := range segments {
+		if !segment.start.Before(end) || !segment.start.Add(SEGMENT_DURATION).After(start) {
+			continue
+		}
+		if err := readSegment(segment.path, start, end, res); err != nil {
+			return res, err
+		}
+	}
+	return res, nil
+}
+
+// Prune removes the segments of all workload types ending before now minus the retention.
+func (s *Store) Prune(now time.Time) (removed int, err error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	expired := now.Add(-s.retention)
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		segments, err := s.segments(define.WorkloadType(entry.Name()))
+		if err != nil {
+			return removed, err
+		}
+		for _, segment := range segments {
+			if segment.start.Add(SEGMENT_DURATION).After(expired) {
+				continue
+			}
+			if err := os.Remove(segment.path); err != nil {
+				return removed, err
+			}
+			removed++
+		}
+	}
+	return removed, nil
+}
+
+type segment struct {
+	path  string
+	start time.Time
+}
+
+// segments returns the segment files of the workload type ordered by time, the unknown files are ignored.
+func (s *Store) segments(t define.WorkloadType) ([]segment, error) {
+	dir := path.Join(s.dir, string(t))
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var res []segment
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, _segment_ext) {
+			continue
+		}
+		start, err := strconv.ParseInt(strings.TrimSuffix(name, _segment_ext), 10, 64)
+		if err != nil {
+			continue
+		}
+		res = append(res, segment{path: path.Join(dir, name), start: time.Unix(start, 0)})
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].start.Before(res[j].start) })
+	return res, nil
+}
+
+func segmentName(timestamp int64) string {
+	start := timestamp - timestamp%int64(SEGMENT_DURATION/time.Second)
+	return fmt.Sprintf("%d%s", start, _segment_ext)
+}
+
+// readSegment reads the samples between start and end into output, the broken lines written by a crash are skipped.
+func readSegment(file string, start, end time.Time, output define.WorkloadOutput) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, bufio.MaxScanTokenSize), 16*bufio.MaxScanTokenSize)
+	for scanner.Scan() {
+		var r record
+		if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+			continue
+		}
+		if r.Time < start.Unix() || r.Time > end.Unix() {
+			continue
+		}
+		output[r.Time] = r.Items
+	}
+	return scanner.Err()
+}
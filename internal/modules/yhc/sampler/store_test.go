@@ -0,0 +1,55 @@
+package sampler_test
+
+import (
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"yhc/internal/modules/yhc/check/define"
+	"yhc/internal/modules/yhc/sampler"
+)
+
+func TestStore(t *testing.T) {
+	dir := t.TempDir()
+	store := sampler.NewStore(dir, 2*time.Hour)
+	start := time.Date(2024, 1, 2, 10, 0, 0, 0, time.UTC)
+	for i := 0; i < 4; i++ {
+		timestamp := start.Add(time.Duration(i) * 50 * time.Minute).Unix()
+		item := define.WorkloadItem{"all": map[string]interface{}{"cpu": "all", "user": float64(i)}}
+		if err := store.Append(define.WT_CPU, timestamp, item); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// a broken line written by a crash is skipped
+	f, err := os.OpenFile(path.Join(dir, string(define.WT_CPU), "1704189600.jsonl"), os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString(`{"t":17041`)
+	f.Close()
+
+	output, err := store.Read(define.WT_CPU, start.Add(time.Hour), start.Add(3*time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(output) != 2 {
+		t.Fatalf("unexpected samples: %+v", output)
+	}
+	usage, ok := output[start.Add(150*time.Minute).Unix()]["all"].(map[string]interface{})
+	if !ok || usage["user"] != float64(3) {
+		t.Fatalf("unexpected sample: %+v", output)
+	}
+	if output, err := store.Read(define.WT_DISK, start, start.Add(time.Hour)); err != nil || len(output) != 0 {
+		t.Fatalf("expected no samples of disk, got %+v, err: %v", output, err)
+	}
+
+	// the segments of 10:00 and 11:00 end before 12:30
+	removed, err := store.Prune(start.Add(270 * time.Minute))
+	if err != nil || removed != 2 {
+		t.Fatalf("expected 2 segments removed, got %d, err: %v", removed, err)
+	}
+	if output, _ := store.Read(define.WT_CPU, start, start.Add(3*time.Hour)); len(output) != 1 {
+		t.Fatalf("unexpected samples after pruning: %+v", output)
+	}
+}
@@ -0,0 +1,11 @@
+// The static package embeds the built html report template into the binary,
+// so that the html report can be generated without any external file.
+package static
+
+import _ "embed"
+
+// HtmlTemplate is the single-file report template built from html-template,
+// all the JS, CSS and fonts are inlined in it.
+//
+//go:embed template.html
+var HtmlTemplate []byte
@@ -246,6 +246,16 @@ func CheckDirAccess(dir string, excludeMap map[string]struct{}) (res map[string]
 }
 
 func GetFilesAccess(dir string) (map[string]os.FileMode, map[string]error) {
+	return getFilesMode(dir, os.ModePerm)
+}
+
+// GetFilesMode returns the modes of the files under dir with the type bits and the setuid, setgid and sticky bits,
+// the symlinks are not followed.
+func GetFilesMode(dir string) (map[string]os.FileMode, map[string]error) {
+	return getFilesMode(dir, os.ModeType|os.ModePerm|os.ModeSetuid|os.ModeSetgid|os.ModeSticky)
+}
+
+func getFilesMode(dir string, mask os.FileMode) (map[string]os.FileMode, map[string]error) {
 	permissionsMap := make(map[string]os.FileMode)
 	errs := make(map[string]error)
 	_ = filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
@@ -254,7 +264,7 @@ func GetFilesAccess(dir string) (map[string]os.FileMode, map[string]error) {
 			return nil
 		}
 		if !info.IsDir() {
-			permissionsMap[path] = info.Mode().Perm()
+			permissionsMap[path] = info.Mode() & mask
 		}
 		return nil
 	})
@@ -21,6 +21,14 @@ func Round(num float64, decimal int) float64 {
 	return math.Round(num*pow) / pow
 }
 
+// CounterDelta returns the increment of a counter, 0 if the counter is reset.
+func CounterDelta(old, new float64) float64 {
+	if new < old {
+		return 0
+	}
+	return new - old
+}
+
 func GenHumanReadableNumber(num float64, decimal int) string {
 	if num == 0 || num < thousand {
 		return yasutil.FormatFloat(num, decimal)
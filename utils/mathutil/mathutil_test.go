@@ -16,3 +16,33 @@ func TestNumber(t *testing.T) {
 	res := mathutil.GenHumanReadableNumber(10000000, 2)
 	fmt.Println(res)
 }
+
+func TestCounterDelta(t *testing.T) {
+	if d := mathutil.CounterDelta(100, 150); d != 50 {
+		t.Errorf("expected 50, got %v", d)
+	}
+	if d := mathutil.CounterDelta(150, 100); d != 0 {
+		t.Errorf("expected 0 for a reset counter, got %v", d)
+	}
+}
+
+func TestParseSize(t *testing.T) {
+	cases := map[string]int64{
+		"1024": 1024,
+		"1k":   1024,
+		"512M": 512 << 20,
+		"1.5G": 3 << 29,
+		"2TB":  2 << 40,
+	}
+	for s, expected := range cases {
+		res, err := mathutil.ParseSize(s)
+		if err != nil || res != expected {
+			t.Errorf("parse %s: expected %d, got %d, err: %v", s, expected, res, err)
+		}
+	}
+	for _, s := range []string{"", "G", "-1G", "1X", "NaN", "nanK", "Inf", "+InfG", "-inf", "1e30G"} {
+		if _, err := mathutil.ParseSize(s); err == nil {
+			t.Errorf("expected error of %q", s)
+		}
+	}
+}
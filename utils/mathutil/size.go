@@ -0,0 +1,41 @@
+package mathutil
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+const (
+	_kib = 1 << 10
+	_mib = 1 << 20
+	_gib = 1 << 30
+	_tib = 1 << 40
+)
+
+var _sizeUnits = []struct {
+	suffix string
+	unit   float64
+}{
+	{"TB", _tib}, {"GB", _gib}, {"MB", _mib}, {"KB", _kib},
+	{"T", _tib}, {"G", _gib}, {"M", _mib}, {"K", _kib}, {"B", 1},
+}
+
+// ParseSize parses a size such as '512M', '1.5G' or '1024' to bytes, units are 1024 based and case insensitive.
+func ParseSize(s string) (int64, error) {
+	str := strings.ToUpper(strings.TrimSpace(s))
+	unit := float64(1)
+	for _, u := range _sizeUnits {
+		if strings.HasSuffix(str, u.suffix) {
+			str, unit = strings.TrimSpace(strings.TrimSuffix(str, u.suffix)), u.unit
+			break
+		}
+	}
+	num, err := strconv.ParseFloat(str, 64)
+	// ParseFloat accepts 'NaN' and 'Inf', and the bytes must fit in int64
+	if err != nil || num < 0 || math.IsNaN(num) || math.IsInf(num, 0) || num*unit >= math.MaxInt64 {
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+	return int64(num * unit), nil
+}
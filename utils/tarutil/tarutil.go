@@ -0,0 +1,154 @@
+// The tarutil package packs directories to gzip compressed tar archives and reads them back,
+// so that no external tar command is needed.
+package tarutil
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	_dir_mode  = 0755
+	_file_mode = 0644
+)
+
+// WalkFunc is called for every regular file in the archive, name is the slash separated path in the archive.
+type WalkFunc func(name string, size int64, reader io.Reader) error
+
+// TarGz packs the directory src to the gzip compressed tar file dest,
+// the entries in the archive are prefixed with the base name of src.
+func TarGz(src, dest string) (err error) {
+	f, err := os.Create(dest)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			os.Remove(dest)
+		}
+	}()
+	gw := gzip.NewWriter(f)
+	tw := tar.NewWriter(gw)
+	base := filepath.Base(src)
+	err = filepath.WalkDir(src, func(p string, d fs.DirEntry, walkErr error) error {
+		if walkErr != nil {
+			return walkErr
+		}
+		rel, err := filepath.Rel(src, p)
+		if err != nil {
+			return err
+		}
+		return addEntry(tw, p, path.Join(base, filepath.ToSlash(rel)), d)
+	})
+	if err != nil {
+		return err
+	}
+	if err = tw.Close(); err != nil {
+		return err
+	}
+	return gw.Close()
+}
+
+// WalkTarGz calls fn for every regular file in the gzip compressed tar file.
+func WalkTarGz(fname string, fn WalkFunc) error {
+	f, err := os.Open(fname)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	gr, err := gzip.NewReader(f)
+	if err != nil {
+		return err
+	}
+	defer gr.Close()
+	tr := tar.NewReader(gr)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		if err := fn(path.Clean(header.Name), header.Size, tr); err != nil {
+			return err
+		}
+	}
+}
+
+// UntarGz unpacks the gzip compressed tar file src to the directory dest,
+// entries which point outside of dest are rejected.
+func UntarGz(src, dest string) error {
+	return WalkTarGz(src, func(name string, size int64, reader io.Reader) error {
+		target := filepath.Join(dest, filepath.FromSlash(name))
+		if !strings.HasPrefix(target, filepath.Clean(dest)+string(filepath.Separator)) {
+			return fmt.Errorf("invalid entry %s in %s", name, src)
+		}
+		if err := os.MkdirAll(filepath.Dir(target), _dir_mode); err != nil {
+			return err
+		}
+		f, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, _file_mode)
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(f, reader); err != nil {
+			f.Close()
+			return err
+		}
+		return f.Close()
+	})
+}
+
+// TrimTopDir removes the first element of the slash separated path in the archive.
+func TrimTopDir(name string) string {
+	name = strings.TrimPrefix(name, "./")
+	if index := strings.Index(name, "/"); index >= 0 {
+		return name[index+1:]
+	}
+	return name
+}
+
+func addEntry(tw *tar.Writer, fname, name string, d fs.DirEntry) error {
+	info, err := d.Info()
+	if err != nil {
+		return err
+	}
+	if !info.Mode().IsRegular() && !info.IsDir() {
+		// symlinks and devices are not expected in the result package
+		return nil
+	}
+	header, err := tar.FileInfoHeader(info, "")
+	if err != nil {
+		return err
+	}
+	header.Name = name
+	if info.IsDir() {
+		header.Name += "/"
+	}
+	if err := tw.WriteHeader(header); err != nil {
+		return err
+	}
+	if info.IsDir() {
+		return nil
+	}
+	f, err := os.Open(fname)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = io.Copy(tw, f)
+	return err
+}
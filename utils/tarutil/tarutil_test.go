@@ -0,0 +1,90 @@
+package tarutil_test
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"yhc/utils/tarutil"
+)
+
+func TestTarGz(t *testing.T) {
+	tmp := t.TempDir()
+	src := filepath.Join(tmp, "yhc-test")
+	if err := os.MkdirAll(filepath.Join(src, "data"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		"report.html":    "<html></html>",
+		"data/data.json": "{}",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(src, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	dest := filepath.Join(tmp, "yhc-test.tar.gz")
+	if err := tarutil.TarGz(src, dest); err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]string{}
+	err := tarutil.WalkTarGz(dest, func(name string, size int64, reader io.Reader) error {
+		data, err := io.ReadAll(reader)
+		if err != nil {
+			return err
+		}
+		if int64(len(data)) != size {
+			t.Errorf("size of %s mismatched", name)
+		}
+		got[name] = string(data)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range files {
+		if got["yhc-test/"+name] != content {
+			t.Errorf("unexpected content of %s: %q", name, got["yhc-test/"+name])
+		}
+	}
+	if len(got) != len(files) {
+		t.Errorf("unexpected files: %v", got)
+	}
+}
+
+func TestUntarGz(t *testing.T) {
+	tmp := t.TempDir()
+	src := filepath.Join(tmp, "yhc-test")
+	if err := os.MkdirAll(filepath.Join(src, "data"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(src, "data", "data.json"), []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	tarFile := filepath.Join(tmp, "yhc-test.tar.gz")
+	if err := tarutil.TarGz(src, tarFile); err != nil {
+		t.Fatal(err)
+	}
+	dest := filepath.Join(tmp, "dest")
+	if err := tarutil.UntarGz(tarFile, dest); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(filepath.Join(dest, "yhc-test", "data", "data.json"))
+	if err != nil || string(data) != "{}" {
+		t.Fatalf("unexpected content %q, err: %v", data, err)
+	}
+}
+
+func TestTrimTopDir(t *testing.T) {
+	cases := map[string]string{
+		"yhc-test/data/data.json": "data/data.json",
+		"./yhc-test/report.html":  "report.html",
+		"report.html":             "report.html",
+	}
+	for name, expected := range cases {
+		if res := tarutil.TrimTopDir(name); res != expected {
+			t.Errorf("expected %s, got %s", expected, res)
+		}
+	}
+}
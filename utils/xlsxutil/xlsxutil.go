@@ -0,0 +1,316 @@
+// The xlsxutil package writes simple Office Open XML spreadsheets (.xlsx) without third-party dependencies.
+package xlsxutil
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	MAX_SHEET_NAME_LENGTH = 31
+	MAX_CELL_LENGTH       = 32767
+
+	_invalid_sheet_name_chars = "[]:*?/\\"
+	_default_sheet_name       = "Sheet"
+
+	_style_normal = 0
+	_style_header = 1
+)
+
+const (
+	_content_types_xml = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">
+<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>
+<Default Extension="xml" ContentType="application/xml"/>
+<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>
+<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>
+%s</Types>`
+	_content_types_sheet_formatter = `<Override PartName="/xl/worksheets/sheet%d.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>
+`
+	_root_rels_xml = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>
+</Relationships>`
+	_workbook_xml = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+<sheets>
+%s</sheets>
+</workbook>`
+	_workbook_sheet_formatter = `<sheet name="%s" sheetId="%d" r:id="rId%d"/>
+`
+	_workbook_rels_xml = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+%s<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>
+</Relationships>`
+	_workbook_rels_sheet_formatter = `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet%d.xml"/>
+`
+	// style 0 is the default style, style 1 is used for the header row
+	_styles_xml = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">
+<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>
+<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>
+<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>
+<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>
+<cellXfs count="2"><xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/><xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/></cellXfs>
+</styleSheet>`
+	_sheet_header = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`
+	_sheet_footer = `</sheetData></worksheet>`
+)
+
+type Workbook struct {
+	sheets []*Sheet
+	names  map[string]struct{}
+}
+
+type Sheet struct {
+	Name string
+	// Header is written as the first row in bold, it can be empty.
+	Header []string
+	Rows   [][]interface{}
+}
+
+func NewWorkbook() *Workbook {
+	return &Workbook{
+		names: make(map[string]struct{}),
+	}
+}
+
+// AddSheet adds a sheet to the workbook, the sheet name will be sanitized and deduplicated.
+func (w *Workbook) AddSheet(name string, header []string, rows [][]interface{}) *Sheet {
+	sheet := &Sheet{
+		Name:   w.uniqueSheetName(SanitizeSheetName(name)),
+		Header: header,
+		Rows:   rows,
+	}
+	w.sheets = append(w.sheets, sheet)
+	return sheet
+}
+
+func (w *Workbook) Sheets() []*Sheet {
+	return w.sheets
+}
+
+// SaveAs writes the workbook to the file.
+func (w *Workbook) SaveAs(fname string) error {
+	f, err := os.Create(fname)
+	if err != nil {
+		return err
+	}
+	if err := w.Write(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Write writes the workbook as a zip archive to the writer.
+func (w *Workbook) Write(writer io.Writer) error {
+	if len(w.sheets) == 0 {
+		w.AddSheet(_default_sheet_name, nil, nil)
+	}
+	zw := zip.NewWriter(writer)
+	var contentTypes, workbookSheets, workbookRels bytes.Buffer
+	for i, sheet := range w.sheets {
+		index := i + 1
+		contentTypes.WriteString(fmt.Sprintf(_content_types_sheet_formatter, index))
+		workbookSheets.WriteString(fmt.Sprintf(_workbook_sheet_formatter, escape(sheet.Name), index, index))
+		workbookRels.WriteString(fmt.Sprintf(_workbook_rels_sheet_formatter, index, index))
+	}
+	files := []struct {
+		name    string
+		content string
+	}{
+		{name: "[Content_Types].xml", content: fmt.Sprintf(_content_types_xml, contentTypes.String())},
+		{name: "_rels/.rels", content: _root_rels_xml},
+		{name: "xl/workbook.xml", content: fmt.Sprintf(_workbook_xml, workbookSheets.String())},
+		{name: "xl/_rels/workbook.xml.rels", content: fmt.Sprintf(_workbook_rels_xml, workbookRels.String(), len(w.sheets)+1)},
+		{name: "xl/styles.xml", content: _styles_xml},
+	}
+	for _, file := range files {
+		fw, err := zw.Create(file.name)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(fw, file.content); err != nil {
+			return err
+		}
+	}
+	for i, sheet := range w.sheets {
+		fw, err := zw.Create(fmt.Sprintf("xl/worksheets/sheet%d.xml", i+1))
+		if err != nil {
+			return err
+		}
+		if err := sheet.write(fw); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+func (s *Sheet) write(writer io.Writer) error {
+	var buf bytes.Buffer
+	buf.WriteString(_sheet_header)
+	rowIndex := 1
+	if len(s.Header) != 0 {
+		cells := make([]interface{}, 0, len(s.Header))
+		for _, h := range s.Header {
+			cells = append(cells, h)
+		}
+		writeRow(&buf, rowIndex, cells, _style_header)
+		rowIndex++
+	}
+	for _, row := range s.Rows {
+		writeRow(&buf, rowIndex, row, _style_normal)
+		rowIndex++
+	}
+	buf.WriteString(_sheet_footer)
+	_, err := buf.WriteTo(writer)
+	return err
+}
+
+func writeRow(buf *bytes.Buffer, rowIndex int, cells []interface{}, style int) {
+	buf.WriteString(fmt.Sprintf(`<row r="%d">`, rowIndex))
+	for i, cell := range cells {
+		if cell == nil {
+			continue
+		}
+		ref := CellName(i, rowIndex)
+		styleAttr := ""
+		if style != _style_normal {
+			styleAttr = fmt.Sprintf(` s="%d"`, style)
+		}
+		if num, ok := toNumber(cell); ok {
+			buf.WriteString(fmt.Sprintf(`<c r="%s"%s><v>%s</v></c>`, ref, styleAttr, num))
+			continue
+		}
+		buf.WriteString(fmt.Sprintf(`<c r="%s" t="inlineStr"%s><is><t xml:space="preserve">%s</t></is></c>`, ref, styleAttr, escape(truncate(ToString(cell), MAX_CELL_LENGTH))))
+	}
+	buf.WriteString(`</row>`)
+}
+
+// CellName converts zero-based column index and one-based row index to a cell reference, such as 'A1'.
+func CellName(col, row int) string {
+	name := ""
+	for col >= 0 {
+		name = string(rune('A'+col%26)) + name
+		col = col/26 - 1
+	}
+	return name + strconv.Itoa(row)
+}
+
+// SanitizeSheetName removes characters which are not allowed in sheet names and truncates the name to 31 characters.
+func SanitizeSheetName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(_invalid_sheet_name_chars, r) {
+			return '_'
+		}
+		return r
+	}, strings.TrimSpace(name))
+	name = strings.Trim(name, "'")
+	if len(name) == 0 {
+		return _default_sheet_name
+	}
+	return truncate(name, MAX_SHEET_NAME_LENGTH)
+}
+
+// ToString converts the cell value to the text shown in the cell.
+func ToString(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	case fmt.Stringer:
+		return v.String()
+	case error:
+		return v.Error()
+	}
+	if num, ok := toNumber(value); ok {
+		return num
+	}
+	if b, ok := value.(bool); ok {
+		return strconv.FormatBool(b)
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(data)
+}
+
+func (w *Workbook) uniqueSheetName(name string) string {
+	res := name
+	for i := 2; ; i++ {
+		if _, ok := w.names[strings.ToLower(res)]; !ok {
+			break
+		}
+		suffix := fmt.Sprintf("(%d)", i)
+		res = truncate(name, MAX_SHEET_NAME_LENGTH-len(suffix)) + suffix
+	}
+	w.names[strings.ToLower(res)] = struct{}{}
+	return res
+}
+
+func toNumber(value interface{}) (string, bool) {
+	switch v := value.(type) {
+	case int:
+		return strconv.FormatInt(int64(v), 10), true
+	case int8:
+		return strconv.FormatInt(int64(v), 10), true
+	case int16:
+		return strconv.FormatInt(int64(v), 10), true
+	case int32:
+		return strconv.FormatInt(int64(v), 10), true
+	case int64:
+		return strconv.FormatInt(v, 10), true
+	case uint:
+		return strconv.FormatUint(uint64(v), 10), true
+	case uint8:
+		return strconv.FormatUint(uint64(v), 10), true
+	case uint16:
+		return strconv.FormatUint(uint64(v), 10), true
+	case uint32:
+		return strconv.FormatUint(uint64(v), 10), true
+	case uint64:
+		return strconv.FormatUint(v, 10), true
+	case float32:
+		if math.IsNaN(float64(v)) || math.IsInf(float64(v), 0) {
+			return "", false
+		}
+		return strconv.FormatFloat(float64(v), 'f', -1, 32), true
+	case float64:
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return "", false
+		}
+		return strconv.FormatFloat(v, 'f', -1, 64), true
+	case json.Number:
+		return v.String(), true
+	}
+	return "", false
+}
+
+func truncate(s string, max int) string {
+	if utf8.RuneCountInString(s) <= max {
+		return s
+	}
+	return string([]rune(s)[:max])
+}
+
+func escape(s string) string {
+	var buf bytes.Buffer
+	// EscapeText replaces characters which are invalid in xml with U+FFFD
+	_ = xml.EscapeText(&buf, []byte(s))
+	return buf.String()
+}
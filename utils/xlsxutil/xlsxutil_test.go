@@ -0,0 +1,77 @@
+package xlsxutil_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"yhc/utils/xlsxutil"
+)
+
+func TestCellName(t *testing.T) {
+	cases := map[string][2]int{
+		"A1":   {0, 1},
+		"Z2":   {25, 2},
+		"AA3":  {26, 3},
+		"AZ4":  {51, 4},
+		"BA5":  {52, 5},
+		"ZZ6":  {701, 6},
+		"AAA7": {702, 7},
+	}
+	for expected, c := range cases {
+		if name := xlsxutil.CellName(c[0], c[1]); name != expected {
+			t.Errorf("expected %s, got %s", expected, name)
+		}
+	}
+}
+
+func TestSanitizeSheetName(t *testing.T) {
+	if name := xlsxutil.SanitizeSheetName("a/b:c[d]"); name != "a_b_c_d_" {
+		t.Errorf("unexpected sheet name %s", name)
+	}
+	if name := xlsxutil.SanitizeSheetName(strings.Repeat("表", 40)); len([]rune(name)) != xlsxutil.MAX_SHEET_NAME_LENGTH {
+		t.Errorf("unexpected sheet name length %d", len([]rune(name)))
+	}
+}
+
+func TestWrite(t *testing.T) {
+	wb := xlsxutil.NewWorkbook()
+	wb.AddSheet("tablespace", []string{"name", "size"}, [][]interface{}{{"SYSTEM<&>", 1.5}, {"USERS", 2}})
+	wb.AddSheet("tablespace", nil, nil)
+	sheets := wb.Sheets()
+	if sheets[1].Name != "tablespace(2)" {
+		t.Fatalf("unexpected duplicated sheet name %s", sheets[1].Name)
+	}
+	var buf bytes.Buffer
+	if err := wb.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	reader, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{}
+	for _, f := range reader.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, _ := io.ReadAll(rc)
+		rc.Close()
+		files[f.Name] = string(data)
+	}
+	for _, name := range []string{"[Content_Types].xml", "xl/workbook.xml", "xl/worksheets/sheet1.xml", "xl/worksheets/sheet2.xml"} {
+		if _, ok := files[name]; !ok {
+			t.Fatalf("%s not found in workbook", name)
+		}
+	}
+	sheet := files["xl/worksheets/sheet1.xml"]
+	if !strings.Contains(sheet, "SYSTEM&lt;&amp;&gt;") {
+		t.Errorf("cell text is not escaped: %s", sheet)
+	}
+	if !strings.Contains(sheet, `<c r="B2"><v>1.5</v></c>`) {
+		t.Errorf("number cell not found: %s", sheet)
+	}
+}